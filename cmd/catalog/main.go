@@ -0,0 +1,192 @@
+// catalog 命令行导入导出书目，与 POST /api/books/import、GET /api/books/export 使用相同的逻辑。
+//
+//	catalog import -format csv [-dry-run] [-map title="Book Title"] [-progress 1000] <文件|->
+//	catalog export -format marcxml [-map ...] [-o 文件]
+//
+// 导入报告以 JSON 写到标准输出，进度写到标准错误；有记录导入失败时退出码为 2
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/metadata"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/migration"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/persistence"
+	"github.com/azel-ko/final-ddd/internal/pkg/bookio"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	migr "github.com/azel-ko/final-ddd/internal/pkg/database/migration"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+)
+
+// mapFlag 可重复的 -map 字段=来源 参数
+type mapFlag map[string]string
+
+func (m mapFlag) String() string {
+	return fmt.Sprint(map[string]string(m))
+}
+
+func (m mapFlag) Set(value string) error {
+	field, source, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected field=source, got %q", value)
+	}
+	m[strings.TrimSpace(field)] = source
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// 加载配置并连接数据库
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	logger.Init(cfg.Log.Level)
+
+	repo, db, err := persistence.NewRepository(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+	migrator := migr.NewMigrator(db)
+	migration.RegisterMigrations(migrator)
+	if err := migrator.Run(); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	bookService := services.NewBookService(repo, metadata.NewProvider(cfg.Metadata, nil), eventbus.New())
+	catalogService := services.NewCatalogService(repo, bookService)
+
+	// Ctrl-C 时停止导入导出，已处理的记录保持不变
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch os.Args[1] {
+	case "import":
+		os.Exit(runImport(ctx, catalogService, os.Args[2:]))
+	case "export":
+		os.Exit(runExport(ctx, catalogService, os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  catalog import -format csv|jsonl|marc21|marcxml [-dry-run] [-map field=source] [-progress n] <file|->")
+	fmt.Fprintln(os.Stderr, "  catalog export -format csv|jsonl|marc21|marcxml [-map field=source] [-o file]")
+	os.Exit(64)
+}
+
+func runImport(ctx context.Context, catalogService *services.CatalogService, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	formatName := fs.String("format", "", "file format; defaults to the file extension")
+	dryRun := fs.Bool("dry-run", false, "validate only, do not write to the database")
+	every := fs.Int("progress", 1000, "report progress every n records")
+	mapping := mapFlag{}
+	fs.Var(mapping, "map", "field mapping field=source, repeatable")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	path := fs.Arg(0)
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", path, err)
+		}
+		defer file.Close()
+		input = file
+		if *formatName == "" {
+			*formatName = filepath.Ext(path)
+		}
+	}
+
+	format, err := bookio.ParseFormat(*formatName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fieldMapping, err := services.CatalogMapping(format, mapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := catalogService.Import(ctx, input, services.ImportOptions{
+		Format:        format,
+		Mapping:       fieldMapping,
+		DryRun:        *dryRun,
+		ProgressEvery: *every,
+		Progress: func(report dto.ImportReport) {
+			fmt.Fprintf(os.Stderr, "processed %d: %d created, %d updated, %d unchanged, %d failed\n",
+				report.Processed, report.Created, report.Updated, report.Unchanged, report.Failed)
+		},
+	})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import stopped: %v\n", err)
+		return 1
+	}
+	if report.Failed > 0 {
+		return 2
+	}
+	return 0
+}
+
+func runExport(ctx context.Context, catalogService *services.CatalogService, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	formatName := fs.String("format", "", "file format; defaults to the output file extension")
+	outputPath := fs.String("o", "-", "output file, - for stdout")
+	mapping := mapFlag{}
+	fs.Var(mapping, "map", "field mapping field=source, repeatable")
+	fs.Parse(args)
+
+	var output io.Writer = os.Stdout
+	if *outputPath != "-" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *outputPath, err)
+		}
+		defer file.Close()
+		output = file
+		if *formatName == "" {
+			*formatName = filepath.Ext(*outputPath)
+		}
+	}
+
+	format, err := bookio.ParseFormat(*formatName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fieldMapping, err := services.CatalogMapping(format, mapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	count, err := catalogService.Export(ctx, output, format, fieldMapping)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export stopped after %d records: %v\n", count, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "exported %d records\n", count)
+	return 0
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/azel-ko/final-ddd/internal/infrastructure/cache"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/mail"
 	"github.com/azel-ko/final-ddd/internal/infrastructure/migration"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/payment"
 	"github.com/azel-ko/final-ddd/internal/infrastructure/persistence"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/storage"
 	"github.com/azel-ko/final-ddd/internal/interfaces/http/router"
 	"github.com/azel-ko/final-ddd/internal/pkg/config"
 	inits "github.com/azel-ko/final-ddd/internal/pkg/database/inits"
 	migr "github.com/azel-ko/final-ddd/internal/pkg/database/migration"
 	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"github.com/azel-ko/final-ddd/internal/pkg/scheduler"
 	"go.uber.org/zap"
 )
 
@@ -61,8 +66,28 @@ func main() {
 	redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
 	redisCache := cache.NewRedisCache(redisAddr, cfg.Redis.Password)
 
-	// 设置路由
-	r := router.Setup(cfg, repo, redisCache)
+	// 初始化上传文件存储
+	store, err := storage.NewStorage(cfg.Storage)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage", zap.Error(err))
+	}
+
+	// 初始化邮件发送
+	mailer, err := mail.NewMailer(cfg.Mail)
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+
+	// 初始化支付渠道
+	payments, err := payment.NewProvider(cfg.Payments)
+	if err != nil {
+		logger.Fatal("Failed to initialize payment provider", zap.Error(err))
+	}
+
+	// 设置路由，同时注册后台任务
+	jobs := scheduler.New()
+	r := router.Setup(cfg, repo, redisCache, store, mailer, payments, jobs)
+	jobs.Start(context.Background())
 
 	// 启动服务器
 	if err := r.Run(cfg.GetServerAddress()); err != nil {
@@ -170,7 +170,7 @@ build_backend() {
 
     # 构建二进制文件
     echo "编译 Go 代码..."
-    go build -ldflags "
+    go build -tags sqlite_fts5 -ldflags "
         -X 'github.com/azel-ko/final-ddd/internal/pkg/version.Version=$VERSION'
         -X 'github.com/azel-ko/final-ddd/internal/pkg/version.BuildTime=$BUILD_TIME'
         -X 'github.com/azel-ko/final-ddd/internal/pkg/version.CommitHash=$COMMIT_HASH'
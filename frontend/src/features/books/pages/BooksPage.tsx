@@ -11,6 +11,7 @@ import {
   Typography,
   Row,
   Col,
+  Image,
 } from 'antd'
 import {
   PlusOutlined,
@@ -59,6 +60,22 @@ export default function BooksPage() {
       key: 'id',
       width: 80,
     },
+    {
+      title: '封面',
+      key: 'cover',
+      width: 72,
+      render: (_, record) =>
+        record.covers ? (
+          <Image
+            src={record.covers.small}
+            width={48}
+            preview={{ src: record.covers.large }}
+            alt={record.title}
+          />
+        ) : (
+          <BookOutlined style={{ fontSize: 24, color: '#bfbfbf' }} />
+        ),
+    },
     {
       title: '书名',
       dataIndex: 'title',
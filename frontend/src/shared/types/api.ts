@@ -79,11 +79,20 @@ export interface UpdateProfileRequest {
 }
 
 // 图书相关类型
+export interface BookCovers {
+  small: string
+  medium: string
+  large: string
+  original: string
+}
+
 export interface Book {
   id: number
   title: string
   author: string
   isbn: string
+  cover_url?: string
+  covers?: BookCovers | null
   created_at?: string
   updated_at?: string
 }
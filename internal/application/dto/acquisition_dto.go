@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// SuggestAcquisitionRequest 荐购一本书，ISBN 和书名至少提供一个
+type SuggestAcquisitionRequest struct {
+	ISBN   string `json:"isbn" binding:"required_without=Title,max=20"`
+	Title  string `json:"title" binding:"required_without=ISBN,max=255"`
+	Author string `json:"author" binding:"max=255"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+type AcquisitionListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=suggested approved rejected ordered received cancelled"`
+}
+
+// AcquisitionNoteRequest 审核、驳回、取消时的说明，会记入历史
+type AcquisitionNoteRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// OrderAcquisitionRequest 向供应商下单，CostCents 为总价（以最小货币单位计）
+type OrderAcquisitionRequest struct {
+	Vendor    string `json:"vendor" binding:"required,max=255"`
+	Quantity  int    `json:"quantity" binding:"required,min=1,max=1000"`
+	CostCents int64  `json:"cost_cents" binding:"min=0"`
+	Currency  string `json:"currency" binding:"required,len=3,alpha"`
+	Note      string `json:"note" binding:"max=1000"`
+}
+
+// ReceiveAcquisitionRequest 到货入藏；ISBN、书名、作者留空时沿用荐购时的信息
+type ReceiveAcquisitionRequest struct {
+	ISBN   string `json:"isbn" binding:"max=20"`
+	Title  string `json:"title" binding:"max=255"`
+	Author string `json:"author" binding:"max=255"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+type AcquisitionResponse struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	ISBN      string    `json:"isbn"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Note      string    `json:"note"`
+	Status    string    `json:"status"`
+	Vendor    string    `json:"vendor"`
+	Quantity  int       `json:"quantity"`
+	CostCents int64     `json:"cost_cents"`
+	Currency  string    `json:"currency"`
+	BookID    *uint     `json:"book_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AcquisitionEventResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    uint      `json:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AcquisitionDetailResponse 采购记录及其完整的状态历史
+type AcquisitionDetailResponse struct {
+	AcquisitionResponse
+	History []AcquisitionEventResponse `json:"history"`
+}
+
+type PaginatedAcquisitionResponse struct {
+	Items []AcquisitionResponse `json:"items"`
+	Total int64                 `json:"total"`
+}
+
+func ToAcquisitionResponse(acquisition *entities.Acquisition) AcquisitionResponse {
+	return AcquisitionResponse{
+		ID:        acquisition.ID,
+		UserID:    acquisition.UserID,
+		UserName:  acquisition.UserName,
+		ISBN:      acquisition.ISBN,
+		Title:     acquisition.Title,
+		Author:    acquisition.Author,
+		Note:      acquisition.Note,
+		Status:    acquisition.Status,
+		Vendor:    acquisition.Vendor,
+		Quantity:  acquisition.Quantity,
+		CostCents: acquisition.CostCents,
+		Currency:  acquisition.Currency,
+		BookID:    acquisition.BookID,
+		CreatedAt: acquisition.CreatedAt,
+		UpdatedAt: acquisition.UpdatedAt,
+	}
+}
+
+func ToAcquisitionResponseList(acquisitions []entities.Acquisition) []AcquisitionResponse {
+	responses := make([]AcquisitionResponse, len(acquisitions))
+	for i := range acquisitions {
+		responses[i] = ToAcquisitionResponse(&acquisitions[i])
+	}
+	return responses
+}
+
+func ToAcquisitionEventResponseList(events []entities.AcquisitionEvent) []AcquisitionEventResponse {
+	responses := make([]AcquisitionEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = AcquisitionEventResponse{
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			ActorID:    event.ActorID,
+			ActorName:  event.ActorName,
+			Note:       event.Note,
+			CreatedAt:  event.CreatedAt,
+		}
+	}
+	return responses
+}
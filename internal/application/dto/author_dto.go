@@ -0,0 +1,94 @@
+package dto
+
+import "github.com/azel-ko/final-ddd/internal/domain/entities"
+
+type AuthorRequest struct {
+	Name     string `json:"name" binding:"required,max=255"`
+	SortName string `json:"sort_name" binding:"max=255"`
+	Bio      string `json:"bio"`
+	ISNI     string `json:"isni" binding:"max=32"`
+	ORCID    string `json:"orcid" binding:"max=32"`
+	VIAF     string `json:"viaf" binding:"max=32"`
+}
+
+type AuthorResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	SortName string `json:"sort_name"`
+	Bio      string `json:"bio,omitempty"`
+	ISNI     string `json:"isni,omitempty"`
+	ORCID    string `json:"orcid,omitempty"`
+	VIAF     string `json:"viaf,omitempty"`
+}
+
+type PaginatedAuthorResponse struct {
+	Items []AuthorResponse `json:"items"`
+	Total int64            `json:"total"`
+}
+
+// BookContributorRequest 图书的一位责任者，Role 缺省为 author
+type BookContributorRequest struct {
+	AuthorID uint   `json:"author_id" binding:"required"`
+	Role     string `json:"role"`
+}
+
+type SetBookAuthorsRequest struct {
+	Authors []BookContributorRequest `json:"authors" binding:"required,dive"`
+}
+
+type ContributorResponse struct {
+	AuthorID uint   `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+func ToAuthorEntity(req *AuthorRequest) *entities.Author {
+	author := &entities.Author{}
+	ApplyAuthorRequest(author, req)
+	return author
+}
+
+// ApplyAuthorRequest 用请求内容覆盖作者字段，未提供排序名时由姓名推导
+func ApplyAuthorRequest(author *entities.Author, req *AuthorRequest) {
+	author.Name = req.Name
+	author.SortName = req.SortName
+	if author.SortName == "" {
+		author.SortName = entities.DeriveSortName(req.Name)
+	}
+	author.Bio = req.Bio
+	author.ISNI = req.ISNI
+	author.ORCID = req.ORCID
+	author.VIAF = req.VIAF
+}
+
+func ToAuthorResponse(author *entities.Author) *AuthorResponse {
+	return &AuthorResponse{
+		ID:       author.ID,
+		Name:     author.Name,
+		SortName: author.SortName,
+		Bio:      author.Bio,
+		ISNI:     author.ISNI,
+		ORCID:    author.ORCID,
+		VIAF:     author.VIAF,
+	}
+}
+
+func ToAuthorResponseList(authors []entities.Author) []AuthorResponse {
+	responses := make([]AuthorResponse, len(authors))
+	for i, author := range authors {
+		responses[i] = *ToAuthorResponse(&author)
+	}
+	return responses
+}
+
+func ToContributorResponseList(contributors []entities.Contributor) []ContributorResponse {
+	responses := make([]ContributorResponse, len(contributors))
+	for i, contributor := range contributors {
+		responses[i] = ContributorResponse{
+			AuthorID: contributor.AuthorID,
+			Name:     contributor.Name,
+			Role:     contributor.Role,
+		}
+	}
+	return responses
+}
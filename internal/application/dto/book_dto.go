@@ -1,46 +1,186 @@
 package dto
 
-import "github.com/azel-ko/final-ddd/internal/domain/entities"
+import (
+	"math"
+	"time"
 
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// CreateBookRequest 新建图书；Authors 为空时按 Author 文本拆分并自动关联作者。
+// AutoFill 为 true 时按 ISBN 查询外部书目，补全请求中留空的字段
 type CreateBookRequest struct {
-	Title  string `json:"title" binding:"required"`
-	Author string `json:"author" binding:"required"`
-	ISBN   string `json:"isbn" binding:"required"`
+	Title    string                   `json:"title" binding:"required_unless=AutoFill true,max=255"`
+	Subtitle string                   `json:"subtitle" binding:"max=255"`
+	Author   string                   `json:"author" binding:"required_without_all=Authors AutoFill,max=255"`
+	ISBN     string                   `json:"isbn" binding:"required"`
+	Authors  []BookContributorRequest `json:"authors" binding:"omitempty,dive"`
+
+	Publisher   string `json:"publisher" binding:"max=255"`
+	PublishDate string `json:"publish_date" binding:"max=10"`
+	CoverURL    string `json:"cover_url" binding:"omitempty,url,max=512"`
+	PageCount   int    `json:"page_count" binding:"min=0,max=100000"`
+	Edition     string `json:"edition" binding:"max=100"`
+	Description string `json:"description" binding:"max=10000"`
+	AutoFill    bool   `json:"auto_fill"`
+
+	// WorkID 把新书作为已有作品的一个版本，为空时新建作品
+	WorkID   *uint  `json:"work_id"`
+	Format   string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language string `json:"language" binding:"max=3"` // ISO 639 语言代码
 }
 
 type UpdateBookRequest struct {
-	Title  string `json:"title" binding:"required"`
-	Author string `json:"author" binding:"required"`
-	ISBN   string `json:"isbn" binding:"required"`
+	Title    string                   `json:"title" binding:"required,max=255"`
+	Subtitle string                   `json:"subtitle" binding:"max=255"`
+	Author   string                   `json:"author" binding:"required_without=Authors,max=255"`
+	ISBN     string                   `json:"isbn" binding:"required"`
+	Authors  []BookContributorRequest `json:"authors" binding:"omitempty,dive"`
+
+	Publisher   string `json:"publisher" binding:"max=255"`
+	PublishDate string `json:"publish_date" binding:"max=10"`
+	CoverURL    string `json:"cover_url" binding:"omitempty,url,max=512"`
+	PageCount   int    `json:"page_count" binding:"min=0,max=100000"`
+	Edition     string `json:"edition" binding:"max=100"`
+	Description string `json:"description" binding:"max=10000"`
+
+	Format   string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language string `json:"language" binding:"max=3"`
+}
+
+// BookListQuery 图书列表的筛选参数
+type BookListQuery struct {
+	Title    string `form:"title"`
+	Author   string `form:"author"`
+	AuthorID uint   `form:"author_id"`
+
+	CategoryID uint     `form:"category_id"` // 包含子分类
+	Tags       []string `form:"tag"`         // 可重复，也可逗号分隔；需同时具备
+
+	Publisher string `form:"publisher"`
+	Language  string `form:"language" binding:"max=3"`
+	Format    string `form:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+
+	BranchID uint `form:"branch_id"` // 只列出在该分馆有馆藏的图书
+
+	PublishedFrom int `form:"published_from" binding:"min=0,max=9999"` // 出版年份下限（含）
+	PublishedTo   int `form:"published_to" binding:"min=0,max=9999"`   // 出版年份上限（含）
+	MinPages      int `form:"min_pages" binding:"min=0"`
+	MaxPages      int `form:"max_pages" binding:"min=0"`
+
+	// rating：平均评分从高到低；title：书名字母顺序；published：出版日期从新到旧；
+	// pages：页数从少到多；added：入藏时间从新到旧
+	Sort string `form:"sort" binding:"omitempty,oneof=rating title published pages added"`
+
+	CollapseEditions bool `form:"collapse_editions"` // 每个作品只返回一行，并给出版本数
 }
 
 type BookResponse struct {
-	ID     uint   `json:"id"`
-	Title  string `json:"title"`
-	Author string `json:"author"`
-	ISBN   string `json:"isbn"`
+	ID              uint    `json:"id"`
+	Title           string  `json:"title"`
+	Subtitle        string  `json:"subtitle"`
+	Author          string  `json:"author"`
+	ISBN            string  `json:"isbn"`
+	Publisher       string  `json:"publisher"`
+	PublishDate     string  `json:"publish_date"`
+	CoverURL        string  `json:"cover_url"`
+	PageCount       int     `json:"page_count"`
+	Edition         string  `json:"edition"`
+	Description     string  `json:"description"`
+	TotalCopies     int64   `json:"total_copies"`
+	AvailableCopies int64   `json:"available_copies"`
+	RatingAverage   float64 `json:"rating_average"` // 已公开书评的平均星级，保留两位小数
+	RatingCount     int64   `json:"rating_count"`
+
+	WorkID       uint   `json:"work_id"`
+	Format       string `json:"format"`
+	Language     string `json:"language"`
+	EditionCount int64  `json:"edition_count,omitempty"` // 按作品合并列出时同一作品的版本数
+
+	Authors    []ContributorResponse `json:"authors"`
+	Categories []CategoryRefResponse `json:"categories"`
+	Tags       []string              `json:"tags"`
+
+	Covers *BookCoverResponse `json:"covers"` // 已上传的封面，没有时为 null
+
+	Branches []BranchAvailabilityResponse `json:"branches"` // 各分馆的副本数和可借数，只列出有馆藏的分馆
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func ToBookEntity(req *CreateBookRequest) *entities.Book {
 	return &entities.Book{
-		Title:  req.Title,
-		Author: req.Author,
-		ISBN:   req.ISBN,
+		Title:       req.Title,
+		Subtitle:    req.Subtitle,
+		Author:      req.Author,
+		ISBN:        req.ISBN,
+		Publisher:   req.Publisher,
+		PublishDate: req.PublishDate,
+		CoverURL:    req.CoverURL,
+		PageCount:   req.PageCount,
+		Edition:     req.Edition,
+		Description: req.Description,
+		Format:      req.Format,
+		Language:    req.Language,
+	}
+}
+
+// ToUpdateBookRequest 以图书当前的字段生成整体替换的修改请求，调用方只需覆盖要修改的字段
+func ToUpdateBookRequest(book *entities.Book) *UpdateBookRequest {
+	return &UpdateBookRequest{
+		Title:       book.Title,
+		Subtitle:    book.Subtitle,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Publisher:   book.Publisher,
+		PublishDate: book.PublishDate,
+		CoverURL:    book.CoverURL,
+		PageCount:   book.PageCount,
+		Edition:     book.Edition,
+		Description: book.Description,
+		Format:      book.Format,
+		Language:    book.Language,
 	}
 }
 
 func ToBookResponse(book *entities.Book) *BookResponse {
 	return &BookResponse{
-		ID:     book.ID,
-		Title:  book.Title,
-		Author: book.Author,
-		ISBN:   book.ISBN,
+		ID:          book.ID,
+		Title:       book.Title,
+		Subtitle:    book.Subtitle,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Publisher:   book.Publisher,
+		PublishDate: book.PublishDate,
+		CoverURL:    book.CoverURL,
+		PageCount:   book.PageCount,
+		Edition:     book.Edition,
+		Description: book.Description,
+		Covers:      ToBookCoverResponse(book),
+		CreatedAt:   book.CreatedAt,
+		UpdatedAt:   book.UpdatedAt,
+
+		RatingAverage: math.Round(book.RatingAverage*100) / 100,
+		RatingCount:   book.RatingCount,
+
+		WorkID:       book.WorkID,
+		Format:       book.Format,
+		Language:     book.Language,
+		EditionCount: book.EditionCount,
 	}
 }
 
+// ApplyAvailability 将副本统计填充到图书响应中
+func (r *BookResponse) ApplyAvailability(availability entities.CopyAvailability) {
+	r.TotalCopies = availability.Total
+	r.AvailableCopies = availability.Available
+}
+
 type PaginatedBookResponse struct {
-	Items []BookResponse `json:"items"`
-	Total int64          `json:"total"`
+	Items  []BookResponse      `json:"items"`
+	Total  int64               `json:"total"`
+	Facets *BookFacetsResponse `json:"facets,omitempty"`
 }
 
 func ToBookResponseList(books []entities.Book) []BookResponse {
@@ -50,3 +190,10 @@ func ToBookResponseList(books []entities.Book) []BookResponse {
 	}
 	return bookResponses
 }
+
+// BookScanResponse 条码照片的识别结果：馆藏中已有该书时返回 book，否则返回预填好的新建请求 create
+type BookScanResponse struct {
+	ISBN   string             `json:"isbn"`
+	Book   *BookResponse      `json:"book,omitempty"`
+	Create *CreateBookRequest `json:"create,omitempty"`
+}
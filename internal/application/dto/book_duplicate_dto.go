@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+type DuplicateListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending merged dismissed"` // 默认 pending
+}
+
+// MergeDuplicateRequest 合并一对疑似重复的图书，survivor_id 为保留的那本，必须是这对图书之一
+type MergeDuplicateRequest struct {
+	SurvivorID uint `json:"survivor_id" binding:"required"`
+}
+
+// MergeBookRequest 把 duplicate_id 图书直接合并到当前图书，不需要先出现在审核队列中
+type MergeBookRequest struct {
+	DuplicateID uint `json:"duplicate_id" binding:"required"`
+}
+
+// BookDuplicateResponse 一对疑似重复的图书；已合并的图书不再存在，对应的 book 或 other 为 null
+type BookDuplicateResponse struct {
+	ID          uint          `json:"id"`
+	BookID      uint          `json:"book_id"`
+	OtherBookID uint          `json:"other_book_id"`
+	Reason      string        `json:"reason"`
+	Score       float64       `json:"score"`
+	Status      string        `json:"status"`
+	Book        *BookResponse `json:"book"`
+	Other       *BookResponse `json:"other"`
+	ResolvedBy  *uint         `json:"resolved_by"`
+	ResolvedAt  *time.Time    `json:"resolved_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type PaginatedBookDuplicateResponse struct {
+	Items []BookDuplicateResponse `json:"items"`
+	Total int64                   `json:"total"`
+}
+
+func ToBookDuplicateResponse(duplicate *entities.BookDuplicate) BookDuplicateResponse {
+	return BookDuplicateResponse{
+		ID:          duplicate.ID,
+		BookID:      duplicate.BookID,
+		OtherBookID: duplicate.OtherBookID,
+		Reason:      duplicate.Reason,
+		Score:       duplicate.Score,
+		Status:      duplicate.Status,
+		ResolvedBy:  duplicate.ResolvedBy,
+		ResolvedAt:  duplicate.ResolvedAt,
+		CreatedAt:   duplicate.CreatedAt,
+	}
+}
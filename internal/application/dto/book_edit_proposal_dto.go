@@ -0,0 +1,124 @@
+package dto
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// ProposeBookEditRequest 对图书提出修改建议，只需填写要修改的字段，未填写的字段保持不变
+type ProposeBookEditRequest struct {
+	Title       *string `json:"title" binding:"omitempty,min=1,max=255"`
+	Subtitle    *string `json:"subtitle" binding:"omitempty,max=255"`
+	Publisher   *string `json:"publisher" binding:"omitempty,max=255"`
+	PublishDate *string `json:"publish_date" binding:"omitempty,max=10"`
+	PageCount   *int    `json:"page_count" binding:"omitempty,min=0,max=100000"`
+	Edition     *string `json:"edition" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=10000"`
+	Format      *string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language    *string `json:"language" binding:"omitempty,max=3"`
+
+	Note string `json:"note" binding:"max=1000"` // 修改理由，供审核参考
+}
+
+// Fields 以字段名为键返回填写了的字段，文本去掉首尾空白
+func (r *ProposeBookEditRequest) Fields() map[string]string {
+	fields := make(map[string]string)
+	text := func(name string, value *string) {
+		if value != nil {
+			fields[name] = strings.TrimSpace(*value)
+		}
+	}
+	text("title", r.Title)
+	text("subtitle", r.Subtitle)
+	text("publisher", r.Publisher)
+	text("publish_date", r.PublishDate)
+	if r.PageCount != nil {
+		fields["page_count"] = strconv.Itoa(*r.PageCount)
+	}
+	text("edition", r.Edition)
+	text("description", r.Description)
+	text("format", r.Format)
+	text("language", r.Language)
+	return fields
+}
+
+type EditProposalListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected withdrawn"`
+	BookID uint   `form:"book_id"`
+}
+
+// ReviewEditProposalRequest 审核意见，通过时可选，驳回时必填
+type ReviewEditProposalRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// ProposedChangeResponse 一个字段的修改：提交时的值、提议的值和图书现在的值，供逐字段对照审核
+type ProposedChangeResponse struct {
+	Field    string  `json:"field"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Current  *string `json:"current"`  // 图书已删除时为 null
+	Conflict bool    `json:"conflict"` // 待审核的建议提交后该字段又被修改过
+}
+
+type BookEditProposalResponse struct {
+	ID           uint                     `json:"id"`
+	BookID       uint                     `json:"book_id"`
+	BookTitle    string                   `json:"book_title"`
+	UserID       uint                     `json:"user_id"`
+	UserName     string                   `json:"user_name"`
+	Status       string                   `json:"status"`
+	Note         string                   `json:"note"`
+	Changes      []ProposedChangeResponse `json:"changes"`
+	Conflict     bool                     `json:"conflict"` // 任一字段冲突时为 true，此时不能通过
+	ReviewerID   *uint                    `json:"reviewer_id"`
+	ReviewerName string                   `json:"reviewer_name,omitempty"`
+	ReviewNote   string                   `json:"review_note"`
+	ReviewedAt   *time.Time               `json:"reviewed_at"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+type PaginatedBookEditProposalResponse struct {
+	Items []BookEditProposalResponse `json:"items"`
+	Total int64                      `json:"total"`
+}
+
+// ToBookEditProposalResponse book 为图书现在的状态，已删除时为 nil；只有待审核的建议才标记冲突
+func ToBookEditProposalResponse(proposal *entities.BookEditProposal, changes []entities.FieldChange, book *entities.Book) BookEditProposalResponse {
+	response := BookEditProposalResponse{
+		ID:           proposal.ID,
+		BookID:       proposal.BookID,
+		BookTitle:    proposal.BookTitle,
+		UserID:       proposal.UserID,
+		UserName:     proposal.UserName,
+		Status:       proposal.Status,
+		Note:         proposal.Note,
+		Changes:      make([]ProposedChangeResponse, len(changes)),
+		ReviewerID:   proposal.ReviewerID,
+		ReviewerName: proposal.ReviewerName,
+		ReviewNote:   proposal.ReviewNote,
+		ReviewedAt:   proposal.ReviewedAt,
+		CreatedAt:    proposal.CreatedAt,
+		UpdatedAt:    proposal.UpdatedAt,
+	}
+	conflicts := make(map[string]bool)
+	if book != nil && proposal.Status == entities.EditProposalStatusPending {
+		for _, conflict := range entities.EditConflicts(book, changes) {
+			conflicts[conflict.Field] = true
+		}
+	}
+	for i, change := range changes {
+		item := ProposedChangeResponse{Field: change.Field, From: change.From, To: change.To, Conflict: conflicts[change.Field]}
+		if book != nil {
+			current := entities.BookFieldValue(book, change.Field)
+			item.Current = &current
+		}
+		response.Changes[i] = item
+	}
+	response.Conflict = len(conflicts) > 0
+	return response
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// EbookDownloadURLPrefix 签名下载链接的路径前缀，完整路径为 /api/ebooks/download/{授权ID}?expires=...&signature=...
+const EbookDownloadURLPrefix = "/api/ebooks/download"
+
+type BookFileResponse struct {
+	ID         uint      `json:"id"`
+	BookID     uint      `json:"book_id"`
+	Format     string    `json:"format"`
+	FileName   string    `json:"file_name"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	UploadedBy uint      `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EbookMetadataResponse 从 EPUB 包文件中读到的书目信息
+type EbookMetadataResponse struct {
+	Title       string   `json:"title"`
+	Subtitle    string   `json:"subtitle"`
+	Creators    []string `json:"creators"`
+	ISBN        string   `json:"isbn"`
+	Publisher   string   `json:"publisher"`
+	PublishDate string   `json:"publish_date"`
+	Language    string   `json:"language"`
+	Description string   `json:"description"`
+}
+
+// UploadBookFileResponse 上传结果。EPUB 的书目信息会补全图书中留空的字段，filled 列出被补全的字段
+type UploadBookFileResponse struct {
+	File     BookFileResponse       `json:"file"`
+	Metadata *EbookMetadataResponse `json:"metadata,omitempty"`
+	Filled   []string               `json:"filled"`
+}
+
+// DownloadLinkResponse 签名下载链接，过期前无需登录即可下载
+type DownloadLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func ToBookFileResponse(file *entities.BookFile) BookFileResponse {
+	return BookFileResponse{
+		ID:         file.ID,
+		BookID:     file.BookID,
+		Format:     file.Format,
+		FileName:   file.FileName,
+		Size:       file.Size,
+		SHA256:     file.SHA256,
+		UploadedBy: file.UploadedBy,
+		CreatedAt:  file.CreatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// BookDiffQuery 比较两个修订，from 为空时取 to 的上一个修订，to 为空时取最新修订
+type BookDiffQuery struct {
+	From int `form:"from" binding:"omitempty,min=1"`
+	To   int `form:"to" binding:"omitempty,min=1"`
+}
+
+// RevertBookRequest 把图书恢复为 revision 修订时的状态
+type RevertBookRequest struct {
+	Revision int `json:"revision" binding:"required,min=1"`
+}
+
+type BookRevisionResponse struct {
+	Revision     int       `json:"revision"`
+	Action       string    `json:"action"`
+	ActorID      *uint     `json:"actor_id"`
+	ActorName    string    `json:"actor_name,omitempty"`
+	RevertedFrom *int      `json:"reverted_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// ChangedFields 相对上一个修订发生变化的字段，第一个修订为空
+	ChangedFields []string `json:"changed_fields"`
+}
+
+type PaginatedBookRevisionResponse struct {
+	Items []BookRevisionResponse `json:"items"`
+	Total int64                  `json:"total"`
+}
+
+type BookSnapshotResponse struct {
+	Title       string                `json:"title"`
+	Subtitle    string                `json:"subtitle"`
+	Author      string                `json:"author"`
+	ISBN        string                `json:"isbn"`
+	Publisher   string                `json:"publisher"`
+	PublishDate string                `json:"publish_date"`
+	CoverURL    string                `json:"cover_url"`
+	PageCount   int                   `json:"page_count"`
+	Edition     string                `json:"edition"`
+	Description string                `json:"description"`
+	Format      string                `json:"format"`
+	Language    string                `json:"language"`
+	Authors     []ContributorResponse `json:"authors"`
+}
+
+// BookRevisionDetailResponse 一个修订及其完整快照
+type BookRevisionDetailResponse struct {
+	BookRevisionResponse
+	Snapshot BookSnapshotResponse `json:"snapshot"`
+}
+
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type BookDiffResponse struct {
+	BookID  uint                  `json:"book_id"`
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []FieldChangeResponse `json:"changes"`
+}
+
+func ToBookRevisionResponse(revision *entities.BookRevision, changes []entities.FieldChange) BookRevisionResponse {
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+	return BookRevisionResponse{
+		Revision:      revision.Revision,
+		Action:        revision.Action,
+		ActorID:       revision.ActorID,
+		ActorName:     revision.ActorName,
+		RevertedFrom:  revision.RevertedFrom,
+		CreatedAt:     revision.CreatedAt,
+		ChangedFields: fields,
+	}
+}
+
+func ToBookSnapshotResponse(snapshot entities.BookSnapshot) BookSnapshotResponse {
+	authors := make([]ContributorResponse, len(snapshot.Contributors))
+	for i, contributor := range snapshot.Contributors {
+		authors[i] = ContributorResponse{AuthorID: contributor.AuthorID, Name: contributor.Name, Role: contributor.Role}
+	}
+	return BookSnapshotResponse{
+		Title:       snapshot.Title,
+		Subtitle:    snapshot.Subtitle,
+		Author:      snapshot.Author,
+		ISBN:        snapshot.ISBN,
+		Publisher:   snapshot.Publisher,
+		PublishDate: snapshot.PublishDate,
+		CoverURL:    snapshot.CoverURL,
+		PageCount:   snapshot.PageCount,
+		Edition:     snapshot.Edition,
+		Description: snapshot.Description,
+		Format:      snapshot.Format,
+		Language:    snapshot.Language,
+		Authors:     authors,
+	}
+}
+
+func ToFieldChangeResponseList(changes []entities.FieldChange) []FieldChangeResponse {
+	responses := make([]FieldChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = FieldChangeResponse{Field: change.Field, From: change.From, To: change.To}
+	}
+	return responses
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "github.com/azel-ko/final-ddd/internal/domain/entities"
+
+// BookSearchQuery 全文检索参数
+type BookSearchQuery struct {
+	Q string `form:"q" binding:"required,max=200"`
+
+	CollapseEditions bool `form:"collapse_editions"` // 每个作品只返回一行
+}
+
+// BookHighlightsResponse 命中字段的高亮片段，命中词用 <mark></mark> 包裹
+type BookHighlightsResponse struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type BookSearchHitResponse struct {
+	BookResponse
+	Rank       float64                `json:"rank"`
+	Highlights BookHighlightsResponse `json:"highlights"`
+}
+
+type BookSearchResponse struct {
+	Items []BookSearchHitResponse `json:"items"`
+	Total int64                   `json:"total"`
+}
+
+func ToBookSearchHitResponseList(hits []entities.BookSearchHit) []BookSearchHitResponse {
+	responses := make([]BookSearchHitResponse, len(hits))
+	for i, hit := range hits {
+		responses[i] = BookSearchHitResponse{
+			BookResponse: *ToBookResponse(&hit.Book),
+			Rank:         hit.Rank,
+			Highlights: BookHighlightsResponse{
+				Title:  hit.TitleSnippet,
+				Author: hit.AuthorSnippet,
+			},
+		}
+	}
+	return responses
+}
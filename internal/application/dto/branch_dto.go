@@ -0,0 +1,153 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// OpeningPeriodRequest 每周某天的一个开放时段，weekday 0 为星期日，时间为分馆当地的 HH:MM
+type OpeningPeriodRequest struct {
+	Weekday int    `json:"weekday" binding:"min=0,max=6"`
+	Open    string `json:"open" binding:"required,len=5"`
+	Close   string `json:"close" binding:"required,len=5"`
+}
+
+// BranchRequest 新建或修改分馆，修改时整体替换
+type BranchRequest struct {
+	Name         string                 `json:"name" binding:"required,max=100"`
+	Address      string                 `json:"address" binding:"max=500"`
+	Timezone     string                 `json:"timezone" binding:"required,max=64"`
+	OpeningHours []OpeningPeriodRequest `json:"opening_hours" binding:"max=50,dive"`
+}
+
+type OpeningPeriodResponse struct {
+	Weekday int    `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+type BranchResponse struct {
+	ID           uint                    `json:"id"`
+	Name         string                  `json:"name"`
+	Address      string                  `json:"address"`
+	Timezone     string                  `json:"timezone"`
+	OpeningHours []OpeningPeriodResponse `json:"opening_hours"`
+	OpenNow      bool                    `json:"open_now"`
+}
+
+// BranchAvailabilityResponse 图书在一个分馆的副本数和可借数
+type BranchAvailabilityResponse struct {
+	BranchID   uint   `json:"branch_id"`
+	BranchName string `json:"branch_name"`
+	Total      int64  `json:"total"`
+	Available  int64  `json:"available"`
+}
+
+func (r *BranchRequest) Periods() []entities.OpeningPeriod {
+	periods := make([]entities.OpeningPeriod, len(r.OpeningHours))
+	for i, period := range r.OpeningHours {
+		periods[i] = entities.OpeningPeriod{Weekday: time.Weekday(period.Weekday), Open: period.Open, Close: period.Close}
+	}
+	return periods
+}
+
+func ToBranchResponse(branch *entities.Branch, now time.Time) BranchResponse {
+	periods := branch.Periods()
+	hours := make([]OpeningPeriodResponse, len(periods))
+	for i, period := range periods {
+		hours[i] = OpeningPeriodResponse{Weekday: int(period.Weekday), Open: period.Open, Close: period.Close}
+	}
+	return BranchResponse{
+		ID:           branch.ID,
+		Name:         branch.Name,
+		Address:      branch.Address,
+		Timezone:     branch.Timezone,
+		OpeningHours: hours,
+		OpenNow:      branch.IsOpenAt(now),
+	}
+}
+
+func ToBranchResponseList(branches []entities.Branch, now time.Time) []BranchResponse {
+	responses := make([]BranchResponse, len(branches))
+	for i := range branches {
+		responses[i] = ToBranchResponse(&branches[i], now)
+	}
+	return responses
+}
+
+func ToBranchAvailabilityResponseList(availability []entities.BranchAvailability) []BranchAvailabilityResponse {
+	responses := make([]BranchAvailabilityResponse, len(availability))
+	for i, item := range availability {
+		responses[i] = BranchAvailabilityResponse{
+			BranchID:   item.BranchID,
+			BranchName: item.BranchName,
+			Total:      item.Total,
+			Available:  item.Available,
+		}
+	}
+	return responses
+}
+
+// CreateTransferRequest 申请把副本调拨到另一个分馆，副本可用 copy_id 或 barcode 指定
+type CreateTransferRequest struct {
+	CopyID     uint   `json:"copy_id" binding:"required_without=Barcode"`
+	Barcode    string `json:"barcode" binding:"required_without=CopyID,max=64"`
+	ToBranchID uint   `json:"to_branch_id" binding:"required"`
+	Note       string `json:"note" binding:"max=1000"`
+}
+
+type TransferListQuery struct {
+	BranchID uint   `form:"branch_id"`
+	CopyID   uint   `form:"copy_id"`
+	Status   string `form:"status" binding:"omitempty,oneof=requested in_transit received cancelled"`
+}
+
+type TransferResponse struct {
+	ID             uint       `json:"id"`
+	CopyID         uint       `json:"copy_id"`
+	BookID         uint       `json:"book_id"`
+	Barcode        string     `json:"barcode"`
+	FromBranchID   uint       `json:"from_branch_id"`
+	FromBranchName string     `json:"from_branch_name"`
+	ToBranchID     uint       `json:"to_branch_id"`
+	ToBranchName   string     `json:"to_branch_name"`
+	Status         string     `json:"status"`
+	Note           string     `json:"note"`
+	RequestedBy    uint       `json:"requested_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	ReceivedAt     *time.Time `json:"received_at"`
+}
+
+type PaginatedTransferResponse struct {
+	Items []TransferResponse `json:"items"`
+	Total int64              `json:"total"`
+}
+
+func ToTransferResponse(transfer *entities.BranchTransfer) TransferResponse {
+	return TransferResponse{
+		ID:             transfer.ID,
+		CopyID:         transfer.CopyID,
+		BookID:         transfer.BookID,
+		Barcode:        transfer.Barcode,
+		FromBranchID:   transfer.FromBranchID,
+		FromBranchName: transfer.FromBranchName,
+		ToBranchID:     transfer.ToBranchID,
+		ToBranchName:   transfer.ToBranchName,
+		Status:         transfer.Status,
+		Note:           transfer.Note,
+		RequestedBy:    transfer.RequestedBy,
+		CreatedAt:      transfer.CreatedAt,
+		ShippedAt:      transfer.ShippedAt,
+		ReceivedAt:     transfer.ReceivedAt,
+	}
+}
+
+func ToTransferResponseList(transfers []entities.BranchTransfer) []TransferResponse {
+	responses := make([]TransferResponse, len(transfers))
+	for i := range transfers {
+		responses[i] = ToTransferResponse(&transfers[i])
+	}
+	return responses
+}
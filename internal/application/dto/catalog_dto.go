@@ -0,0 +1,40 @@
+package dto
+
+// CatalogImportQuery 导入参数；Mapping 以 map[字段]=来源 的形式传入，如 map[title]=Book Title
+type CatalogImportQuery struct {
+	Format   string            `form:"format"`
+	DryRun   bool              `form:"dry_run"`
+	Progress bool              `form:"progress"` // 以 NDJSON 流的形式返回进度
+	Mapping  map[string]string `form:"-"`
+}
+
+type CatalogExportQuery struct {
+	Format  string            `form:"format" binding:"required"`
+	Mapping map[string]string `form:"-"`
+}
+
+// ImportRowError 导入时出错的记录，Position 为记录在源文件中的行号或序号
+type ImportRowError struct {
+	Position int    `json:"position"`
+	ISBN     string `json:"isbn,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ImportReport 导入结果；DryRun 时各项计数表示实际导入时将会发生的情况
+type ImportReport struct {
+	DryRun          bool             `json:"dry_run"`
+	Processed       int              `json:"processed"`
+	Created         int              `json:"created"`
+	Updated         int              `json:"updated"`
+	Unchanged       int              `json:"unchanged"`
+	Failed          int              `json:"failed"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+}
+
+// ImportProgressEvent 导入进度流中的一行
+type ImportProgressEvent struct {
+	Type   string        `json:"type"` // progress、report 或 error
+	Report *ImportReport `json:"report,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
@@ -0,0 +1,105 @@
+package dto
+
+import "github.com/azel-ko/final-ddd/internal/domain/entities"
+
+// CategoryRequest 创建或修改分类，ParentID 为空表示顶级分类
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required,max=255"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type CategoryResponse struct {
+	ID       uint               `json:"id"`
+	ParentID *uint              `json:"parent_id,omitempty"`
+	Name     string             `json:"name"`
+	Path     string             `json:"path"`
+	Depth    int                `json:"depth"`
+	Children []CategoryResponse `json:"children,omitempty"`
+}
+
+type CategoryRefResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type SetBookCategoriesRequest struct {
+	CategoryIDs []uint `json:"category_ids" binding:"required"`
+}
+
+type SetBookTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,dive,max=64"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// FacetResponse 分面中的一项；标签分面以 Name 为准
+type FacetResponse struct {
+	ID    uint   `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type BookFacetsResponse struct {
+	Categories []FacetResponse `json:"categories"`
+	Tags       []FacetResponse `json:"tags"`
+}
+
+func ToCategoryResponse(category *entities.Category) *CategoryResponse {
+	return &CategoryResponse{
+		ID:       category.ID,
+		ParentID: category.ParentID,
+		Name:     category.Name,
+		Path:     category.Path,
+		Depth:    category.Depth,
+	}
+}
+
+// ToCategoryTree 把按路径排序的分类列表组装成树
+func ToCategoryTree(categories []entities.Category) []CategoryResponse {
+	children := make(map[uint][]entities.Category)
+	var roots []entities.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(nodes []entities.Category) []CategoryResponse
+	build = func(nodes []entities.Category) []CategoryResponse {
+		responses := make([]CategoryResponse, len(nodes))
+		for i := range nodes {
+			responses[i] = *ToCategoryResponse(&nodes[i])
+			responses[i].Children = build(children[nodes[i].ID])
+		}
+		return responses
+	}
+	return build(roots)
+}
+
+func ToCategoryRefResponseList(categories []entities.Category) []CategoryRefResponse {
+	responses := make([]CategoryRefResponse, len(categories))
+	for i, category := range categories {
+		responses[i] = CategoryRefResponse{ID: category.ID, Name: category.Name, Path: category.Path}
+	}
+	return responses
+}
+
+func ToFacetResponseList(counts []entities.FacetCount) []FacetResponse {
+	responses := make([]FacetResponse, len(counts))
+	for i, count := range counts {
+		responses[i] = FacetResponse{ID: count.ID, Name: count.Name, Count: count.Count}
+	}
+	return responses
+}
+
+func ToBookFacetsResponse(facets *entities.BookFacets) *BookFacetsResponse {
+	return &BookFacetsResponse{
+		Categories: ToFacetResponseList(facets.Categories),
+		Tags:       ToFacetResponseList(facets.Tags),
+	}
+}
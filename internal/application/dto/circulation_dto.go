@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// CreateBookCopyRequest 登记副本，未指定分馆时归入 ID 最小的分馆
+type CreateBookCopyRequest struct {
+	Barcode   string `json:"barcode" binding:"required,max=64"`
+	Condition string `json:"condition"`
+	BranchID  uint   `json:"branch_id"`
+}
+
+type UpdateBookCopyRequest struct {
+	Condition *string `json:"condition"`
+	Status    *string `json:"status"`
+}
+
+type BookCopyResponse struct {
+	ID         uint   `json:"id"`
+	BookID     uint   `json:"book_id"`
+	BranchID   uint   `json:"branch_id"`
+	BranchName string `json:"branch_name"`
+	Barcode    string `json:"barcode"`
+	Condition  string `json:"condition"`
+	Status     string `json:"status"`
+}
+
+// CheckoutRequest 借出请求，CopyID 与 Barcode 二选一；UserID 仅馆员可指定
+type CheckoutRequest struct {
+	CopyID  uint   `json:"copy_id"`
+	Barcode string `json:"barcode"`
+	UserID  uint   `json:"user_id"`
+}
+
+type LoanResponse struct {
+	ID           uint       `json:"id"`
+	CopyID       uint       `json:"copy_id"`
+	BookID       uint       `json:"book_id"`
+	UserID       uint       `json:"user_id"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	RenewCount   int        `json:"renew_count"`
+	Overdue      bool       `json:"overdue"`
+}
+
+func ToBookCopyEntity(bookID uint, req *CreateBookCopyRequest) *entities.BookCopy {
+	return &entities.BookCopy{
+		BookID:    bookID,
+		BranchID:  req.BranchID,
+		Barcode:   req.Barcode,
+		Condition: req.Condition,
+		Status:    entities.CopyStatusAvailable,
+	}
+}
+
+func ToBookCopyResponse(bookCopy *entities.BookCopy) *BookCopyResponse {
+	return &BookCopyResponse{
+		ID:         bookCopy.ID,
+		BookID:     bookCopy.BookID,
+		BranchID:   bookCopy.BranchID,
+		BranchName: bookCopy.BranchName,
+		Barcode:    bookCopy.Barcode,
+		Condition:  bookCopy.Condition,
+		Status:     bookCopy.Status,
+	}
+}
+
+func ToBookCopyResponseList(copies []entities.BookCopy) []BookCopyResponse {
+	responses := make([]BookCopyResponse, len(copies))
+	for i, bookCopy := range copies {
+		responses[i] = *ToBookCopyResponse(&bookCopy)
+	}
+	return responses
+}
+
+func ToLoanResponse(loan *entities.Loan, now time.Time) *LoanResponse {
+	return &LoanResponse{
+		ID:           loan.ID,
+		CopyID:       loan.CopyID,
+		BookID:       loan.BookID,
+		UserID:       loan.UserID,
+		CheckedOutAt: loan.CheckedOutAt,
+		DueAt:        loan.DueAt,
+		ReturnedAt:   loan.ReturnedAt,
+		RenewCount:   loan.RenewCount,
+		Overdue:      loan.IsOverdue(now),
+	}
+}
+
+func ToLoanResponseList(loans []entities.Loan, now time.Time) []LoanResponse {
+	responses := make([]LoanResponse, len(loans))
+	for i, loan := range loans {
+		responses[i] = *ToLoanResponse(&loan, now)
+	}
+	return responses
+}
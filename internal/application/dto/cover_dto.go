@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// CoverURLPrefix 封面图片的访问路径前缀，完整路径为 /api/covers/{图书ID}/{版本}/{文件名}
+const CoverURLPrefix = "/api/covers"
+
+// FetchCoverRequest 从 URL 下载封面；URL 为空时使用图书的 cover_url
+type FetchCoverRequest struct {
+	URL string `json:"url" binding:"omitempty,http_url,max=512"`
+}
+
+// BookCoverResponse 已上传封面各尺寸的地址。地址中带有内容版本，封面更换后地址随之变化
+type BookCoverResponse struct {
+	Small    string `json:"small"`
+	Medium   string `json:"medium"`
+	Large    string `json:"large"`
+	Original string `json:"original"`
+}
+
+// ToBookCoverResponse 没有上传封面时返回 nil
+func ToBookCoverResponse(book *entities.Book) *BookCoverResponse {
+	if !book.HasCover() {
+		return nil
+	}
+	url := func(size string) string {
+		return fmt.Sprintf("%s/%d/%s/%s", CoverURLPrefix, book.ID, book.CoverImage, book.CoverFileName(size))
+	}
+	return &BookCoverResponse{
+		Small:    url(entities.CoverSizeSmall),
+		Medium:   url(entities.CoverSizeMedium),
+		Large:    url(entities.CoverSizeLarge),
+		Original: url(entities.CoverOriginal),
+	}
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+type HoldResponse struct {
+	ID            uint       `json:"id"`
+	BookID        uint       `json:"book_id"`
+	UserID        uint       `json:"user_id"`
+	CopyID        *uint      `json:"copy_id,omitempty"`
+	Status        string     `json:"status"`
+	QueuePosition int64      `json:"queue_position,omitempty"`
+	PlacedAt      time.Time  `json:"placed_at"`
+	ReadyAt       *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// PickupItemResponse 预约架上待取的一条记录
+type PickupItemResponse struct {
+	HoldResponse
+	Barcode   string `json:"barcode"`
+	BookTitle string `json:"book_title"`
+	UserName  string `json:"user_name"`
+	UserEmail string `json:"user_email"`
+}
+
+func ToHoldResponse(hold *entities.Hold) *HoldResponse {
+	return &HoldResponse{
+		ID:        hold.ID,
+		BookID:    hold.BookID,
+		UserID:    hold.UserID,
+		CopyID:    hold.CopyID,
+		Status:    hold.Status,
+		PlacedAt:  hold.PlacedAt,
+		ReadyAt:   hold.ReadyAt,
+		ExpiresAt: hold.ExpiresAt,
+	}
+}
@@ -0,0 +1,32 @@
+package dto
+
+// BarcodeImageQuery 条码图片的输出格式和每个模块的像素数
+type BarcodeImageQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=png svg"`
+	Scale  int    `form:"scale" binding:"omitempty,min=1,max=20"`
+}
+
+// LabelImageQuery 内部编号标签，默认使用 Code 128
+type LabelImageQuery struct {
+	Format    string `form:"format" binding:"omitempty,oneof=png svg"`
+	Scale     int    `form:"scale" binding:"omitempty,min=1,max=20"`
+	Symbology string `form:"symbology" binding:"omitempty,oneof=code128 qr"`
+}
+
+// LabelSheetRequest 批量打印标签。symbology 为 ean13 时印 ISBN 条码，否则印内部编号；
+// per_copy 为 true 时为每本书的每个副本各印一张副本条码标签
+type LabelSheetRequest struct {
+	BookIDs   []uint `json:"book_ids" binding:"required,min=1,max=500,dive,min=1"`
+	Symbology string `json:"symbology" binding:"omitempty,oneof=ean13 code128 qr"`
+	PerCopy   bool   `json:"per_copy"`
+	Paper     string `json:"paper" binding:"omitempty,oneof=a4 letter"`
+	Columns   int    `json:"columns" binding:"omitempty,min=1,max=6"`
+	Rows      int    `json:"rows" binding:"omitempty,min=1,max=20"`
+}
+
+// LabelFile 生成的图片或 PDF
+type LabelFile struct {
+	ContentType string
+	Filename    string
+	Data        []byte
+}
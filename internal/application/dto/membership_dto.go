@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// MembershipPlanRequest 新建或修改会员计划，修改时整体替换；修改后的有效期和权益从下次办理或续期起生效，
+// 在借数量上限和电子书权益对现有会员立即生效
+type MembershipPlanRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Description   string `json:"description" binding:"max=1000"`
+	DurationDays  int    `json:"duration_days" binding:"required,min=1,max=3660"`
+	GraceDays     int    `json:"grace_days" binding:"min=0,max=365"`
+	MaxLoans      int    `json:"max_loans" binding:"min=0,max=1000"` // 0 表示不限
+	DigitalAccess bool   `json:"digital_access"`
+	Fee           int64  `json:"fee" binding:"min=0"` // 以分为单位
+	Active        *bool  `json:"active"`              // 省略时新建为启用，修改时保持不变
+}
+
+type MembershipPlanListQuery struct {
+	All bool `form:"all"` // 馆员可以列出已停用的计划
+}
+
+type MembershipPlanResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	DurationDays  int       `json:"duration_days"`
+	GraceDays     int       `json:"grace_days"`
+	MaxLoans      int       `json:"max_loans"`
+	DigitalAccess bool      `json:"digital_access"`
+	Fee           int64     `json:"fee"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// EnrollMembershipRequest 为读者办理会员，已有会员资格时改用续期
+type EnrollMembershipRequest struct {
+	PlanID   uint       `json:"plan_id" binding:"required"`
+	StartsAt *time.Time `json:"starts_at"` // 省略时从现在开始
+}
+
+// RenewMembershipRequest 续期一期，plan_id 省略时沿用当前计划
+type RenewMembershipRequest struct {
+	PlanID uint `json:"plan_id"`
+}
+
+type MembershipListQuery struct {
+	PlanID uint   `form:"plan_id"`
+	Status string `form:"status" binding:"omitempty,oneof=active grace expired"`
+}
+
+// MembershipResponse 会员资格及其计划的权益
+type MembershipResponse struct {
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"user_id"`
+	UserName      string     `json:"user_name,omitempty"`
+	PlanID        uint       `json:"plan_id"`
+	PlanName      string     `json:"plan_name"`
+	Status        string     `json:"status"` // active、grace 或 expired
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        time.Time  `json:"ends_at"`
+	GraceEndsAt   time.Time  `json:"grace_ends_at"`
+	MaxLoans      int        `json:"max_loans"`
+	DigitalAccess bool       `json:"digital_access"`
+	RenewedAt     *time.Time `json:"renewed_at"`
+	DowngradedAt  *time.Time `json:"downgraded_at"`
+}
+
+type PaginatedMembershipResponse struct {
+	Items []MembershipResponse `json:"items"`
+	Total int64                `json:"total"`
+}
+
+func ToMembershipPlanResponse(plan *entities.MembershipPlan) MembershipPlanResponse {
+	return MembershipPlanResponse{
+		ID:            plan.ID,
+		Name:          plan.Name,
+		Description:   plan.Description,
+		DurationDays:  plan.DurationDays,
+		GraceDays:     plan.GraceDays,
+		MaxLoans:      plan.MaxLoans,
+		DigitalAccess: plan.DigitalAccess,
+		Fee:           plan.Fee,
+		Active:        plan.Active,
+		CreatedAt:     plan.CreatedAt,
+		UpdatedAt:     plan.UpdatedAt,
+	}
+}
+
+// ToMembershipResponse plan 为会员资格当前的计划，已删除时为 nil
+func ToMembershipResponse(membership *entities.Membership, plan *entities.MembershipPlan, now time.Time) MembershipResponse {
+	response := MembershipResponse{
+		ID:           membership.ID,
+		UserID:       membership.UserID,
+		UserName:     membership.UserName,
+		PlanID:       membership.PlanID,
+		PlanName:     membership.PlanName,
+		Status:       membership.Status(now),
+		StartsAt:     membership.StartsAt,
+		EndsAt:       membership.EndsAt,
+		GraceEndsAt:  membership.GraceEndsAt,
+		RenewedAt:    membership.RenewedAt,
+		DowngradedAt: membership.DowngradedAt,
+	}
+	if plan != nil {
+		response.MaxLoans = plan.MaxLoans
+		response.DigitalAccess = plan.DigitalAccess
+	}
+	return response
+}
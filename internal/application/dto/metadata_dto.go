@@ -0,0 +1,36 @@
+package dto
+
+import "github.com/azel-ko/final-ddd/internal/domain/metadata"
+
+// BookLookupQuery 按 ISBN 预览外部书目信息
+type BookLookupQuery struct {
+	ISBN string `form:"isbn" binding:"required"`
+}
+
+type BookMetadataResponse struct {
+	ISBN        string   `json:"isbn"`
+	Title       string   `json:"title"`
+	Authors     []string `json:"authors"`
+	Publisher   string   `json:"publisher"`
+	PublishDate string   `json:"publish_date"`
+	CoverURL    string   `json:"cover_url"`
+	Source      string   `json:"source"`
+	// ExistingBookID 本馆已收录该 ISBN 时为对应图书的 ID
+	ExistingBookID *uint `json:"existing_book_id,omitempty"`
+}
+
+func ToBookMetadataResponse(record *metadata.BookMetadata) *BookMetadataResponse {
+	authors := record.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	return &BookMetadataResponse{
+		ISBN:        record.ISBN,
+		Title:       record.Title,
+		Authors:     authors,
+		Publisher:   record.Publisher,
+		PublishDate: record.PublishDate,
+		CoverURL:    record.CoverURL,
+		Source:      record.Source,
+	}
+}
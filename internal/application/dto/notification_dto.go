@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+type NotificationListQuery struct {
+	Unread bool `form:"unread"` // 只列出未读的通知
+}
+
+// MarkNotificationsReadRequest 要标记为已读的通知，ids 为空时标记全部
+type MarkNotificationsReadRequest struct {
+	IDs []uint `json:"ids" binding:"max=100"`
+}
+
+type NotificationResponse struct {
+	ID        uint            `json:"id"`
+	Kind      string          `json:"kind"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data,omitempty"` // 与类型相关的附加数据，例如保存的检索 ID 和图书 ID 列表
+	Read      bool            `json:"read"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type PaginatedNotificationResponse struct {
+	Items  []NotificationResponse `json:"items"`
+	Total  int64                  `json:"total"`
+	Unread int64                  `json:"unread"` // 全部未读通知的条数
+}
+
+type MarkNotificationsReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+func ToNotificationResponse(notification *entities.Notification) NotificationResponse {
+	response := NotificationResponse{
+		ID:        notification.ID,
+		Kind:      notification.Kind,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+	if json.Valid([]byte(notification.Data)) {
+		response.Data = json.RawMessage(notification.Data)
+	}
+	return response
+}
@@ -0,0 +1,129 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// CreatePaymentRequest 发起一笔在线支付：membership 按计划费用办理或续期会员，balance 结清当前欠款
+type CreatePaymentRequest struct {
+	Purpose string `json:"purpose" binding:"required,oneof=membership balance"`
+	PlanID  uint   `json:"plan_id"` // 用途为 membership 时必填
+}
+
+type PaymentListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending authorized captured refunded failed"`
+}
+
+// RefundPaymentRequest 退款，amount 省略时退回全部可退金额。请求头 Idempotency-Key 相同的重复提交只退一次
+type RefundPaymentRequest struct {
+	Amount int64  `json:"amount" binding:"min=0"` // 以分为单位
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// ChargeRequest 向读者登记一笔费用（罚款、赔偿等），计入其欠款
+type ChargeRequest struct {
+	Amount      int64  `json:"amount" binding:"required,min=1"` // 以分为单位
+	Description string `json:"description" binding:"required,max=255"`
+}
+
+type PaymentRefundResponse struct {
+	ID          uint      `json:"id"`
+	Amount      int64     `json:"amount"`
+	Reason      string    `json:"reason"`
+	Status      string    `json:"status"`
+	ProviderRef string    `json:"provider_ref"`
+	ActorID     uint      `json:"actor_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PaymentResponse struct {
+	ID             uint                    `json:"id"`
+	UserID         uint                    `json:"user_id"`
+	Purpose        string                  `json:"purpose"`
+	PlanID         *uint                   `json:"plan_id"`
+	Amount         int64                   `json:"amount"`
+	Currency       string                  `json:"currency"`
+	Provider       string                  `json:"provider"`
+	ProviderRef    string                  `json:"provider_ref"`
+	Status         string                  `json:"status"`
+	RefundedAmount int64                   `json:"refunded_amount"`
+	CapturedAt     *time.Time              `json:"captured_at"`
+	CreatedAt      time.Time               `json:"created_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+	ClientSecret   string                  `json:"client_secret,omitempty"` // 仅在创建时返回，前端用于向渠道确认支付
+	Refunds        []PaymentRefundResponse `json:"refunds,omitempty"`
+}
+
+type PaginatedPaymentResponse struct {
+	Items []PaymentResponse `json:"items"`
+	Total int64             `json:"total"`
+}
+
+// BalanceResponse 读者应收款账户的余额。balance 为正表示欠款，为负表示预付
+type BalanceResponse struct {
+	UserID      uint   `json:"user_id"`
+	Currency    string `json:"currency"`
+	Balance     int64  `json:"balance"`
+	Outstanding int64  `json:"outstanding"` // 需要支付的金额
+	Credit      int64  `json:"credit"`      // 预付的金额
+}
+
+// LedgerEntryResponse 读者账户的一条流水，amount 为正表示新增欠款，为负表示付款或冲减
+type LedgerEntryResponse struct {
+	ID            uint      `json:"id"`
+	TransactionID uint      `json:"transaction_id"`
+	Kind          string    `json:"kind"`
+	Description   string    `json:"description"`
+	PaymentID     *uint     `json:"payment_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PaginatedLedgerEntryResponse struct {
+	Items []LedgerEntryResponse `json:"items"`
+	Total int64                 `json:"total"`
+}
+
+func ToPaymentResponse(payment *entities.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:             payment.ID,
+		UserID:         payment.UserID,
+		Purpose:        payment.Purpose,
+		PlanID:         payment.PlanID,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		Provider:       payment.Provider,
+		ProviderRef:    payment.ProviderRef,
+		Status:         payment.Status,
+		RefundedAmount: payment.RefundedAmount,
+		CapturedAt:     payment.CapturedAt,
+		CreatedAt:      payment.CreatedAt,
+		UpdatedAt:      payment.UpdatedAt,
+	}
+}
+
+func ToPaymentRefundResponse(refund *entities.PaymentRefund) PaymentRefundResponse {
+	return PaymentRefundResponse{
+		ID:          refund.ID,
+		Amount:      refund.Amount,
+		Reason:      refund.Reason,
+		Status:      refund.Status,
+		ProviderRef: refund.ProviderRef,
+		ActorID:     refund.ActorID,
+		CreatedAt:   refund.CreatedAt,
+	}
+}
+
+func ToLedgerEntryResponse(entry *entities.LedgerEntry) LedgerEntryResponse {
+	return LedgerEntryResponse{
+		ID:            entry.ID,
+		TransactionID: entry.TransactionID,
+		Kind:          entry.Kind,
+		Description:   entry.Description,
+		PaymentID:     entry.PaymentID,
+		Amount:        entry.Amount,
+		CreatedAt:     entry.CreatedAt,
+	}
+}
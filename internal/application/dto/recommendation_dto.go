@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"math"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// 推荐理由
+const (
+	SimilarReasonCoViewed   = "co_viewed"   // 看过这本书的读者也看过
+	SimilarReasonSameAuthor = "same_author" // 同一作者的其他作品
+)
+
+type SimilarBooksQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type SimilarBookResponse struct {
+	ID     uint               `json:"id"`
+	Title  string             `json:"title"`
+	Author string             `json:"author"`
+	ISBN   string             `json:"isbn"`
+	Covers *BookCoverResponse `json:"covers"`
+	Score  float64            `json:"score"`
+	Reason string             `json:"reason"`
+}
+
+func ToSimilarBookResponse(book *entities.Book, score float64, reason string) SimilarBookResponse {
+	return SimilarBookResponse{
+		ID:     book.ID,
+		Title:  book.Title,
+		Author: book.Author,
+		ISBN:   book.ISBN,
+		Covers: ToBookCoverResponse(book),
+		Score:  math.Round(score*1000) / 1000,
+		Reason: reason,
+	}
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// ReviewRequest 新建或修改书评
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Text   string `json:"text" binding:"max=5000"`
+}
+
+// RejectReviewRequest 驳回书评，Note 为驳回原因，会展示给书评作者
+type RejectReviewRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+// ReviewModerationQuery 审核队列的筛选参数
+type ReviewModerationQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
+
+type ReviewResponse struct {
+	ID        uint      `json:"id"`
+	BookID    uint      `json:"book_id"`
+	UserID    uint      `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	FlaggedWords   []string   `json:"flagged_words,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	ModerationNote string     `json:"moderation_note,omitempty"`
+}
+
+type PaginatedReviewResponse struct {
+	Items []ReviewResponse `json:"items"`
+	Total int64            `json:"total"`
+}
+
+func ToReviewResponse(review *entities.Review) *ReviewResponse {
+	response := &ReviewResponse{
+		ID:             review.ID,
+		BookID:         review.BookID,
+		UserID:         review.UserID,
+		UserName:       review.UserName,
+		Rating:         review.Rating,
+		Text:           review.Text,
+		Status:         review.Status,
+		CreatedAt:      review.CreatedAt,
+		UpdatedAt:      review.UpdatedAt,
+		ModeratedAt:    review.ModeratedAt,
+		ModerationNote: review.ModerationNote,
+	}
+	if review.FlaggedWords != "" {
+		response.FlaggedWords = strings.Split(review.FlaggedWords, ",")
+	}
+	return response
+}
+
+func ToReviewResponseList(reviews []entities.Review) []ReviewResponse {
+	responses := make([]ReviewResponse, len(reviews))
+	for i := range reviews {
+		responses[i] = *ToReviewResponse(&reviews[i])
+	}
+	return responses
+}
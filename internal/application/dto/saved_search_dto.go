@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// SavedSearchCriteria 保存的筛选条件，字段含义与图书列表的同名查询参数相同，都为空时匹配所有新书
+type SavedSearchCriteria struct {
+	Title      string   `json:"title" binding:"max=255"`
+	Author     string   `json:"author" binding:"max=255"`
+	AuthorID   uint     `json:"author_id"`
+	CategoryID uint     `json:"category_id"`
+	Tags       []string `json:"tags" binding:"max=20"`
+
+	Publisher string `json:"publisher" binding:"max=255"`
+	Language  string `json:"language" binding:"max=3"`
+	Format    string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook"`
+
+	BranchID uint `json:"branch_id"`
+
+	PublishedFrom int `json:"published_from" binding:"min=0,max=9999"`
+	PublishedTo   int `json:"published_to" binding:"min=0,max=9999"`
+	MinPages      int `json:"min_pages" binding:"min=0"`
+	MaxPages      int `json:"max_pages" binding:"min=0"`
+}
+
+// SavedSearchRequest 创建或修改保存的检索
+type SavedSearchRequest struct {
+	Name        string              `json:"name" binding:"required,max=100"`
+	Criteria    SavedSearchCriteria `json:"criteria"`
+	Frequency   string              `json:"frequency" binding:"required,oneof=hourly daily weekly"`
+	EmailDigest bool                `json:"email_digest"` // 除站内通知外，把新书汇总发到注册邮箱
+}
+
+// ToEntity 转换为实体中保存的形式，文本去掉首尾空白
+func (c SavedSearchCriteria) ToEntity() entities.SavedSearchCriteria {
+	return entities.SavedSearchCriteria{
+		Title:         strings.TrimSpace(c.Title),
+		Author:        strings.TrimSpace(c.Author),
+		AuthorID:      c.AuthorID,
+		CategoryID:    c.CategoryID,
+		Tags:          c.Tags,
+		Publisher:     strings.TrimSpace(c.Publisher),
+		Language:      strings.ToLower(strings.TrimSpace(c.Language)),
+		Format:        c.Format,
+		BranchID:      c.BranchID,
+		PublishedFrom: c.PublishedFrom,
+		PublishedTo:   c.PublishedTo,
+		MinPages:      c.MinPages,
+		MaxPages:      c.MaxPages,
+	}
+}
+
+// ToBookListQuery 把保存的条件转换为图书列表的查询
+func ToBookListQuery(criteria entities.SavedSearchCriteria) *BookListQuery {
+	return &BookListQuery{
+		Title:         criteria.Title,
+		Author:        criteria.Author,
+		AuthorID:      criteria.AuthorID,
+		CategoryID:    criteria.CategoryID,
+		Tags:          criteria.Tags,
+		Publisher:     criteria.Publisher,
+		Language:      criteria.Language,
+		Format:        criteria.Format,
+		BranchID:      criteria.BranchID,
+		PublishedFrom: criteria.PublishedFrom,
+		PublishedTo:   criteria.PublishedTo,
+		MinPages:      criteria.MinPages,
+		MaxPages:      criteria.MaxPages,
+	}
+}
+
+type SavedSearchResponse struct {
+	ID          uint                         `json:"id"`
+	Name        string                       `json:"name"`
+	Criteria    entities.SavedSearchCriteria `json:"criteria"`
+	Frequency   string                       `json:"frequency"`
+	EmailDigest bool                         `json:"email_digest"`
+	NextRunAt   time.Time                    `json:"next_run_at"`
+	LastRunAt   *time.Time                   `json:"last_run_at"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	UpdatedAt   time.Time                    `json:"updated_at"`
+}
+
+func ToSavedSearchResponse(search *entities.SavedSearch, criteria entities.SavedSearchCriteria) SavedSearchResponse {
+	return SavedSearchResponse{
+		ID:          search.ID,
+		Name:        search.Name,
+		Criteria:    criteria,
+		Frequency:   search.Frequency,
+		EmailDigest: search.EmailDigest,
+		NextRunAt:   search.NextRunAt,
+		LastRunAt:   search.LastRunAt,
+		CreatedAt:   search.CreatedAt,
+		UpdatedAt:   search.UpdatedAt,
+	}
+}
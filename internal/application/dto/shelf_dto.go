@@ -0,0 +1,147 @@
+package dto
+
+import (
+	"math"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// SharedShelfURLPrefix 分享书架的访问路径前缀，完整路径为 /api/shelves/shared/{令牌}
+const SharedShelfURLPrefix = "/api/shelves/shared"
+
+type ShelfRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type AddShelfBookRequest struct {
+	BookID uint `json:"book_id" binding:"required"`
+}
+
+// ReadingProgressRequest 更新阅读进度，未提供的字段保持不变。
+// 可以按页数（current_page、total_pages）或百分比（percent）记录，日期格式为 YYYY-MM-DD
+type ReadingProgressRequest struct {
+	CurrentPage *int     `json:"current_page" binding:"omitempty,min=0"`
+	TotalPages  *int     `json:"total_pages" binding:"omitempty,min=1"`
+	Percent     *float64 `json:"percent" binding:"omitempty,min=0,max=100"`
+	StartedAt   *string  `json:"started_at" binding:"omitempty,datetime=2006-01-02"`
+	FinishedAt  *string  `json:"finished_at" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type ReadingStatsQuery struct {
+	Year int `form:"year" binding:"omitempty,min=1900,max=9999"`
+}
+
+type ShelfResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	BookCount int64     `json:"book_count"`
+	Shared    bool      `json:"shared"`
+	ShareURL  string    `json:"share_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShelfBookResponse 书架上展示的图书摘要
+type ShelfBookResponse struct {
+	ID     uint               `json:"id"`
+	Title  string             `json:"title"`
+	Author string             `json:"author"`
+	ISBN   string             `json:"isbn"`
+	Covers *BookCoverResponse `json:"covers"`
+}
+
+type ReadingProgressResponse struct {
+	BookID      uint       `json:"book_id"`
+	CurrentPage int        `json:"current_page"`
+	TotalPages  int        `json:"total_pages"`
+	Percent     float64    `json:"percent"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ShelfItemResponse struct {
+	Book     ShelfBookResponse        `json:"book"`
+	AddedAt  time.Time                `json:"added_at"`
+	Progress *ReadingProgressResponse `json:"progress,omitempty"`
+}
+
+type PaginatedShelfItemResponse struct {
+	Items []ShelfItemResponse `json:"items"`
+	Total int64               `json:"total"`
+}
+
+// SharedShelfResponse 通过分享链接查看的书架，不包含阅读进度
+type SharedShelfResponse struct {
+	Name      string              `json:"name"`
+	Kind      string              `json:"kind"`
+	OwnerName string              `json:"owner_name"`
+	Items     []ShelfItemResponse `json:"items"`
+	Total     int64               `json:"total"`
+}
+
+type ReadingStatsResponse struct {
+	Year              int     `json:"year"`
+	BooksFinished     int     `json:"books_finished"`
+	PagesRead         int     `json:"pages_read"`
+	FinishedByMonth   [12]int `json:"finished_by_month"`
+	AverageDaysToRead float64 `json:"average_days_to_read"`
+	CurrentlyReading  int64   `json:"currently_reading"`
+}
+
+func ToShelfResponse(shelf *entities.Shelf) *ShelfResponse {
+	response := &ShelfResponse{
+		ID:        shelf.ID,
+		Name:      shelf.Name,
+		Kind:      shelf.Kind,
+		BookCount: shelf.BookCount,
+		Shared:    shelf.ShareToken != nil,
+		CreatedAt: shelf.CreatedAt,
+	}
+	if shelf.ShareToken != nil {
+		response.ShareURL = SharedShelfURLPrefix + "/" + *shelf.ShareToken
+	}
+	return response
+}
+
+func ToShelfResponseList(shelves []entities.Shelf) []ShelfResponse {
+	responses := make([]ShelfResponse, len(shelves))
+	for i := range shelves {
+		responses[i] = *ToShelfResponse(&shelves[i])
+	}
+	return responses
+}
+
+func ToShelfBookResponse(book *entities.Book) ShelfBookResponse {
+	return ShelfBookResponse{
+		ID:     book.ID,
+		Title:  book.Title,
+		Author: book.Author,
+		ISBN:   book.ISBN,
+		Covers: ToBookCoverResponse(book),
+	}
+}
+
+func ToReadingProgressResponse(progress *entities.ReadingProgress) *ReadingProgressResponse {
+	return &ReadingProgressResponse{
+		BookID:      progress.BookID,
+		CurrentPage: progress.CurrentPage,
+		TotalPages:  progress.TotalPages,
+		Percent:     math.Round(progress.Percent*10) / 10,
+		StartedAt:   progress.StartedAt,
+		FinishedAt:  progress.FinishedAt,
+		UpdatedAt:   progress.UpdatedAt,
+	}
+}
+
+func ToReadingStatsResponse(stats *entities.ReadingStats) *ReadingStatsResponse {
+	return &ReadingStatsResponse{
+		Year:              stats.Year,
+		BooksFinished:     stats.BooksFinished,
+		PagesRead:         stats.PagesRead,
+		FinishedByMonth:   stats.FinishedByMonth,
+		AverageDaysToRead: math.Round(stats.AverageDaysToRead*10) / 10,
+		CurrentlyReading:  stats.CurrentlyReading,
+	}
+}
@@ -23,6 +23,7 @@ type UserProfileResponse struct {
 	Email     string    `json:"email"`
 	Role      string    `json:"role"`
 	CreatedAt string `json:"created_at"`
+	Membership *MembershipResponse `json:"membership"` // 未办理会员时为 null
 }
 
 type UpdateUserProfileRequest struct {
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+type UpdateWorkRequest struct {
+	Title string `json:"title" binding:"required,max=255"`
+}
+
+// MergeWorksRequest 把 work_ids 作品的全部版本并入当前作品
+type MergeWorksRequest struct {
+	WorkIDs []uint `json:"work_ids" binding:"required,min=1,dive,required"`
+}
+
+// SplitWorkRequest 把 book_ids 版本从当前作品拆分为一个新作品，title 为空时使用第一个版本的书名
+type SplitWorkRequest struct {
+	BookIDs []uint `json:"book_ids" binding:"required,min=1,dive,required"`
+	Title   string `json:"title" binding:"max=255"`
+}
+
+type WorkListQuery struct {
+	Title string `form:"title"`
+}
+
+type WorkResponse struct {
+	ID           uint      `json:"id"`
+	Title        string    `json:"title"`
+	EditionCount int64     `json:"edition_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// EditionResponse 作品的一个版本
+type EditionResponse struct {
+	ID          uint               `json:"id"`
+	Title       string             `json:"title"`
+	ISBN        string             `json:"isbn"`
+	Format      string             `json:"format"`
+	Language    string             `json:"language"`
+	Publisher   string             `json:"publisher"`
+	PublishDate string             `json:"publish_date"`
+	Covers      *BookCoverResponse `json:"covers"`
+}
+
+type WorkDetailResponse struct {
+	WorkResponse
+	Editions []EditionResponse `json:"editions"`
+}
+
+type PaginatedWorkResponse struct {
+	Items []WorkResponse `json:"items"`
+	Total int64          `json:"total"`
+}
+
+func ToWorkResponse(work *entities.Work) *WorkResponse {
+	return &WorkResponse{
+		ID:           work.ID,
+		Title:        work.Title,
+		EditionCount: work.EditionCount,
+		CreatedAt:    work.CreatedAt,
+	}
+}
+
+func ToWorkResponseList(works []entities.Work) []WorkResponse {
+	responses := make([]WorkResponse, len(works))
+	for i := range works {
+		responses[i] = *ToWorkResponse(&works[i])
+	}
+	return responses
+}
+
+func ToWorkDetailResponse(work *entities.Work, editions []entities.Book) *WorkDetailResponse {
+	response := &WorkDetailResponse{
+		WorkResponse: *ToWorkResponse(work),
+		Editions:     make([]EditionResponse, len(editions)),
+	}
+	for i := range editions {
+		book := &editions[i]
+		response.Editions[i] = EditionResponse{
+			ID:          book.ID,
+			Title:       book.Title,
+			ISBN:        book.ISBN,
+			Format:      book.Format,
+			Language:    book.Language,
+			Publisher:   book.Publisher,
+			PublishDate: book.PublishDate,
+			Covers:      ToBookCoverResponse(book),
+		}
+	}
+	return response
+}
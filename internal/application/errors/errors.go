@@ -7,4 +7,7 @@ var (
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrISBNAlreadyExists  = errors.New("ISBN already exists")
 	ErrInvalidInput       = errors.New("invalid input")
+	ErrForbidden          = errors.New("operation not permitted")
+
+	ErrBarcodeAlreadyExists = errors.New("barcode already exists")
 )
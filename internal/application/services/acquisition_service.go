@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AcquisitionService 荐购与采购：读者提交建议，馆员审核后下单、到货，到货时自动编目入藏
+type AcquisitionService struct {
+	repo  repository.Repository
+	books *BookService
+}
+
+func NewAcquisitionService(repo repository.Repository, books *BookService) *AcquisitionService {
+	return &AcquisitionService{repo: repo, books: books}
+}
+
+// Suggest 提交荐购。已收录或已有人荐购的 ISBN 不能重复提交；
+// 只给了 ISBN 时尝试从外部书目补全书名和作者，查询失败不影响提交
+func (s *AcquisitionService) Suggest(ctx context.Context, actorID uint, req *dto.SuggestAcquisitionRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition := &entities.Acquisition{
+		UserID: actorID,
+		Title:  strings.TrimSpace(req.Title),
+		Author: strings.TrimSpace(req.Author),
+		Note:   strings.TrimSpace(req.Note),
+		Status: entities.AcquisitionStatusSuggested,
+	}
+	if req.ISBN != "" {
+		isbn, err := entities.ParseISBN(req.ISBN)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.GetBookByISBN(isbn.String()); err == nil {
+			return nil, entities.ErrBookAlreadyInCatalog
+		}
+		if _, err := s.repo.FindOpenAcquisitionByISBN(isbn.String()); err == nil {
+			return nil, entities.ErrAcquisitionAlreadySuggested
+		}
+		acquisition.ISBN = isbn.String()
+
+		if acquisition.Title == "" {
+			if record, err := s.books.LookupMetadata(ctx, isbn.String()); err == nil {
+				acquisition.Title = record.Title
+				if acquisition.Author == "" {
+					acquisition.Author = strings.Join(record.Authors, "; ")
+				}
+			}
+		}
+	}
+
+	event := &entities.AcquisitionEvent{ToStatus: acquisition.Status, ActorID: actorID, Note: acquisition.Note}
+	if err := s.repo.CreateAcquisition(acquisition, event); err != nil {
+		return nil, err
+	}
+	return s.detail(acquisition.ID)
+}
+
+// ListAcquisitions 馆员可以看到全部采购记录，读者只能看到自己提交的
+func (s *AcquisitionService) ListAcquisitions(actorID uint, actorRole string, page, pageSize int, query *dto.AcquisitionListQuery) (*dto.PaginatedAcquisitionResponse, error) {
+	filter := repository.AcquisitionFilter{Status: query.Status}
+	if !entities.IsStaffRole(actorRole) {
+		filter.UserID = actorID
+	}
+
+	offset := (page - 1) * pageSize
+	acquisitions, total, err := s.repo.ListAcquisitions(filter, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedAcquisitionResponse{Items: dto.ToAcquisitionResponseList(acquisitions), Total: total}, nil
+}
+
+// GetAcquisition 采购记录及其状态历史，读者只能查看自己提交的
+func (s *AcquisitionService) GetAcquisition(actorID uint, actorRole string, id int) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.getAcquisitionFor(actorID, actorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(acquisition.ID)
+}
+
+// Approve 同意采购
+func (s *AcquisitionService) Approve(actorID uint, id int, req *dto.AcquisitionNoteRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.repo.GetAcquisition(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return s.transition(acquisition, entities.AcquisitionStatusApproved, actorID, req.Note)
+}
+
+// Reject 驳回荐购，说明会展示给提交人
+func (s *AcquisitionService) Reject(actorID uint, id int, req *dto.AcquisitionNoteRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.repo.GetAcquisition(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return s.transition(acquisition, entities.AcquisitionStatusRejected, actorID, req.Note)
+}
+
+// Order 记录向供应商下单的数量和金额
+func (s *AcquisitionService) Order(actorID uint, id int, req *dto.OrderAcquisitionRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.repo.GetAcquisition(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	acquisition.Vendor = strings.TrimSpace(req.Vendor)
+	acquisition.Quantity = req.Quantity
+	acquisition.CostCents = req.CostCents
+	acquisition.Currency = strings.ToUpper(req.Currency)
+	return s.transition(acquisition, entities.AcquisitionStatusOrdered, actorID, req.Note)
+}
+
+// Receive 到货入藏：馆藏中已有该 ISBN 时关联到已有图书，到货时填写的书名和作者
+// 经由 BookService 更新到该图书上；否则新建图书，书名或作者不全时按 ISBN 从外部书目补全
+func (s *AcquisitionService) Receive(ctx context.Context, actorID uint, id int, req *dto.ReceiveAcquisitionRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.repo.GetAcquisition(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if !acquisition.CanTransition(entities.AcquisitionStatusReceived) {
+		return nil, entities.ErrInvalidAcquisitionTransition
+	}
+
+	if req.ISBN != "" {
+		acquisition.ISBN = req.ISBN
+	}
+	if title := strings.TrimSpace(req.Title); title != "" {
+		acquisition.Title = title
+	}
+	if author := strings.TrimSpace(req.Author); author != "" {
+		acquisition.Author = author
+	}
+	if acquisition.ISBN == "" {
+		return nil, fmt.Errorf("%w: an ISBN is required to catalog the received item", errors.ErrInvalidInput)
+	}
+	isbn, err := entities.ParseISBN(acquisition.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	acquisition.ISBN = isbn.String()
+
+	// 外部书目的查询较慢，在事务开始前完成
+	book, err := s.repo.GetBookByISBN(isbn.String())
+	var create *dto.CreateBookRequest
+	if err != nil {
+		create = &dto.CreateBookRequest{ISBN: isbn.String(), Title: acquisition.Title, Author: acquisition.Author}
+		if create.Title == "" || create.Author == "" {
+			if err := s.books.autoFill(ctx, isbn, create); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 图书的新建或修改与状态转换在同一事务内，采购已被其他请求改动时馆藏也保持不变
+	err = s.books.inTransaction(func(tx *BookService) error {
+		var err error
+		if create != nil {
+			book, err = tx.createBook(ctx, actorID, create)
+		} else {
+			book, err = s.applyReceivedMetadata(tx, actorID, book, req)
+		}
+		if err != nil {
+			return err
+		}
+		acquisition.BookID = &book.ID
+		acquisition.Title = book.Title
+		acquisition.Author = book.Author
+		return s.changeStatus(tx.repo, acquisition, entities.AcquisitionStatusReceived, actorID, req.Note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(acquisition.ID)
+}
+
+// applyReceivedMetadata 经由 books 把到货时填写的书名和作者写到已有图书上，与手动编辑一样记录修订；
+// 没有填写或与现有值相同时不修改
+func (s *AcquisitionService) applyReceivedMetadata(books *BookService, actorID uint, book *entities.Book, req *dto.ReceiveAcquisitionRequest) (*entities.Book, error) {
+	update := dto.ToUpdateBookRequest(book)
+	changed := false
+	if title := strings.TrimSpace(req.Title); title != "" && title != book.Title {
+		update.Title = title
+		changed = true
+	}
+	if author := strings.TrimSpace(req.Author); author != "" && !sameAuthors(author, book.Author) {
+		update.Author = author
+		changed = true
+	}
+	if !changed {
+		return book, nil
+	}
+	return books.updateBook(actorID, int(book.ID), update)
+}
+
+// Cancel 取消采购。提交人可以撤回尚未审核的荐购，馆员可以取消任何进行中的采购
+func (s *AcquisitionService) Cancel(actorID uint, actorRole string, id int, req *dto.AcquisitionNoteRequest) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.getAcquisitionFor(actorID, actorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	if !entities.IsStaffRole(actorRole) && acquisition.Status != entities.AcquisitionStatusSuggested {
+		return nil, errors.ErrForbidden
+	}
+	return s.transition(acquisition, entities.AcquisitionStatusCancelled, actorID, req.Note)
+}
+
+// transition 校验并执行状态转换，同时写入历史
+func (s *AcquisitionService) transition(acquisition *entities.Acquisition, to string, actorID uint, note string) (*dto.AcquisitionDetailResponse, error) {
+	if err := s.changeStatus(s.repo, acquisition, to, actorID, note); err != nil {
+		return nil, err
+	}
+	return s.detail(acquisition.ID)
+}
+
+// changeStatus 在 repo 上执行状态转换并写入历史，repo 可以是事务内的仓储
+func (s *AcquisitionService) changeStatus(repo repository.Repository, acquisition *entities.Acquisition, to string, actorID uint, note string) error {
+	previousStatus := acquisition.Status
+	event, err := acquisition.Transition(to, actorID, strings.TrimSpace(note))
+	if err != nil {
+		return err
+	}
+	if err := repo.UpdateAcquisition(acquisition, previousStatus, event); err != nil {
+		return err
+	}
+
+	logger.Info("acquisition status changed",
+		zap.Uint("acquisition_id", acquisition.ID),
+		zap.Uint("actor_id", actorID),
+		zap.String("from", previousStatus),
+		zap.String("to", to),
+	)
+	return nil
+}
+
+// getAcquisitionFor 读取采购记录并校验操作者是提交人本人或馆员
+func (s *AcquisitionService) getAcquisitionFor(actorID uint, actorRole string, id int) (*entities.Acquisition, error) {
+	acquisition, err := s.repo.GetAcquisition(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if acquisition.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return nil, errors.ErrForbidden
+	}
+	return acquisition, nil
+}
+
+func (s *AcquisitionService) detail(id uint) (*dto.AcquisitionDetailResponse, error) {
+	acquisition, err := s.repo.GetAcquisition(int(id))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	events, err := s.repo.ListAcquisitionEvents(acquisition.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AcquisitionDetailResponse{
+		AcquisitionResponse: dto.ToAcquisitionResponse(acquisition),
+		History:             dto.ToAcquisitionEventResponseList(events),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+type AuthorService struct {
+	repo repository.Repository
+}
+
+func NewAuthorService(repo repository.Repository) *AuthorService {
+	return &AuthorService{repo: repo}
+}
+
+func (s *AuthorService) CreateAuthor(req *dto.AuthorRequest) (*dto.AuthorResponse, error) {
+	author := dto.ToAuthorEntity(req)
+	if err := s.repo.CreateAuthor(author); err != nil {
+		return nil, err
+	}
+	return dto.ToAuthorResponse(author), nil
+}
+
+func (s *AuthorService) GetAuthor(id int) (*dto.AuthorResponse, error) {
+	author, err := s.repo.GetAuthor(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return dto.ToAuthorResponse(author), nil
+}
+
+func (s *AuthorService) UpdateAuthor(id int, req *dto.AuthorRequest) (*dto.AuthorResponse, error) {
+	author, err := s.repo.GetAuthor(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	dto.ApplyAuthorRequest(author, req)
+	if err := s.repo.UpdateAuthor(author); err != nil {
+		return nil, err
+	}
+	return dto.ToAuthorResponse(author), nil
+}
+
+func (s *AuthorService) DeleteAuthor(id int) error {
+	if _, err := s.repo.GetAuthor(id); err != nil {
+		return errors.ErrNotFound
+	}
+	return s.repo.DeleteAuthor(id)
+}
+
+func (s *AuthorService) ListAuthors(page, pageSize int, name string) (*dto.PaginatedAuthorResponse, error) {
+	offset := (page - 1) * pageSize
+	authors, total, err := s.repo.ListAuthors(offset, pageSize, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedAuthorResponse{
+		Items: dto.ToAuthorResponseList(authors),
+		Total: total,
+	}, nil
+}
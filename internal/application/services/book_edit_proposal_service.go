@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// BookEditProposalService 读者对图书著录的修改建议：读者提交要修改的字段，馆员逐字段对照后通过或驳回。
+// 通过时经 BookService 在一个事务内写入图书并记录修订；提交后相关字段又被修改过的建议不能通过
+type BookEditProposalService struct {
+	repo  repository.Repository
+	books *BookService
+}
+
+func NewBookEditProposalService(repo repository.Repository, books *BookService) *BookEditProposalService {
+	return &BookEditProposalService{repo: repo, books: books}
+}
+
+// Propose 提交修改建议，只记录与图书现在的值不同的字段
+func (s *BookEditProposalService) Propose(actorID uint, bookID int, req *dto.ProposeBookEditRequest) (*dto.BookEditProposalResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	fields := req.Fields()
+	if title, ok := fields["title"]; ok && title == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", errors.ErrInvalidInput)
+	}
+	if !entities.IsValidPublishDate(fields["publish_date"]) || !entities.IsValidLanguageCode(fields["language"]) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	proposal, err := entities.NewBookEditProposal(book, actorID, fields, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBookEditProposal(proposal); err != nil {
+		return nil, err
+	}
+
+	logger.Info("book edit proposed",
+		zap.Uint("proposal_id", proposal.ID),
+		zap.Uint("book_id", proposal.BookID),
+		zap.Uint("user_id", proposal.UserID),
+	)
+	return s.detail(proposal.ID)
+}
+
+// List 馆员可以看到全部修改建议，读者只能看到自己提交的
+func (s *BookEditProposalService) List(actorID uint, actorRole string, page, pageSize int, query *dto.EditProposalListQuery) (*dto.PaginatedBookEditProposalResponse, error) {
+	filter := repository.BookEditProposalFilter{BookID: query.BookID, Status: query.Status}
+	if !entities.IsStaffRole(actorRole) {
+		filter.UserID = actorID
+	}
+
+	offset := (page - 1) * pageSize
+	proposals, total, err := s.repo.ListBookEditProposals(filter, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(proposals))
+	for i, proposal := range proposals {
+		ids[i] = proposal.BookID
+	}
+	books, err := s.repo.ListBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*entities.Book, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+
+	items := make([]dto.BookEditProposalResponse, len(proposals))
+	for i := range proposals {
+		response, err := s.toResponse(&proposals[i], byID[proposals[i].BookID])
+		if err != nil {
+			return nil, err
+		}
+		items[i] = *response
+	}
+	return &dto.PaginatedBookEditProposalResponse{Items: items, Total: total}, nil
+}
+
+// Get 修改建议及其与图书现在的值的对照，读者只能查看自己提交的
+func (s *BookEditProposalService) Get(actorID uint, actorRole string, id int) (*dto.BookEditProposalResponse, error) {
+	proposal, err := s.getProposalFor(actorID, actorRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(proposal.ID)
+}
+
+// Approve 通过修改建议并写入图书
+func (s *BookEditProposalService) Approve(actorID uint, id int, req *dto.ReviewEditProposalRequest) (*dto.BookEditProposalResponse, error) {
+	proposal, err := s.repo.GetBookEditProposal(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if proposal.Status != entities.EditProposalStatusPending {
+		return nil, entities.ErrEditProposalResolved
+	}
+	proposal.Resolve(entities.EditProposalStatusApproved, actorID, strings.TrimSpace(req.Note), time.Now())
+	if _, err := s.books.ApplyEditProposal(actorID, proposal); err != nil {
+		return nil, err
+	}
+
+	logger.Info("book edit proposal approved",
+		zap.Uint("proposal_id", proposal.ID),
+		zap.Uint("book_id", proposal.BookID),
+		zap.Uint("reviewer_id", actorID),
+	)
+	return s.detail(proposal.ID)
+}
+
+// Reject 驳回修改建议，审核意见会展示给提交人
+func (s *BookEditProposalService) Reject(actorID uint, id int, req *dto.ReviewEditProposalRequest) (*dto.BookEditProposalResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, fmt.Errorf("%w: a note is required when rejecting a proposal", errors.ErrInvalidInput)
+	}
+	proposal, err := s.repo.GetBookEditProposal(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return s.resolve(proposal, entities.EditProposalStatusRejected, actorID, note)
+}
+
+// Withdraw 提交人撤回尚未审核的修改建议
+func (s *BookEditProposalService) Withdraw(actorID uint, id int) (*dto.BookEditProposalResponse, error) {
+	proposal, err := s.repo.GetBookEditProposal(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if proposal.UserID != actorID {
+		return nil, errors.ErrForbidden
+	}
+	return s.resolve(proposal, entities.EditProposalStatusWithdrawn, actorID, "")
+}
+
+// resolve 保存驳回或撤回的结果
+func (s *BookEditProposalService) resolve(proposal *entities.BookEditProposal, status string, actorID uint, note string) (*dto.BookEditProposalResponse, error) {
+	if proposal.Status != entities.EditProposalStatusPending {
+		return nil, entities.ErrEditProposalResolved
+	}
+	proposal.Resolve(status, actorID, note, time.Now())
+	if err := s.repo.ResolveBookEditProposal(proposal); err != nil {
+		return nil, err
+	}
+
+	logger.Info("book edit proposal resolved",
+		zap.Uint("proposal_id", proposal.ID),
+		zap.Uint("actor_id", actorID),
+		zap.String("status", status),
+	)
+	return s.detail(proposal.ID)
+}
+
+// getProposalFor 读取修改建议并校验操作者是提交人本人或馆员
+func (s *BookEditProposalService) getProposalFor(actorID uint, actorRole string, id int) (*entities.BookEditProposal, error) {
+	proposal, err := s.repo.GetBookEditProposal(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if proposal.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return nil, errors.ErrForbidden
+	}
+	return proposal, nil
+}
+
+func (s *BookEditProposalService) detail(id uint) (*dto.BookEditProposalResponse, error) {
+	proposal, err := s.repo.GetBookEditProposal(int(id))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	// 图书已删除时仍可查看建议，只是没有现在的值可以对照
+	book, _ := s.repo.GetBook(int(proposal.BookID))
+	return s.toResponse(proposal, book)
+}
+
+func (s *BookEditProposalService) toResponse(proposal *entities.BookEditProposal, book *entities.Book) (*dto.BookEditProposalResponse, error) {
+	changes, err := proposal.DecodeChanges()
+	if err != nil {
+		return nil, err
+	}
+	response := dto.ToBookEditProposalResponse(proposal, changes, book)
+	return &response, nil
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/domain/storage"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/ebook"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"github.com/azel-ko/final-ddd/internal/pkg/signedurl"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxEbookSize       = 50 << 20
+	defaultDownloadLinkTTL    = 15 * time.Minute
+	defaultMaxConcurrentLoans = 3
+	maxBookFileNameLength     = 255
+	// downloadLinkKeyLabel 未配置签名密钥时从 jwt.key 派生下载链接密钥所用的标签
+	downloadLinkKeyLabel = "final-ddd ebook download links v1"
+)
+
+// BookFileService 负责电子书文件的上传和借阅。文件按 ebooks/{图书ID}/{内容摘要}.{格式} 保存在对象存储中，
+// 读者只能通过有效期很短的签名链接下载；每个链接对应一条下载授权，有效期内占用该书的一个并发名额
+type BookFileService struct {
+	repo          repository.Repository
+	books         *BookService
+	memberships   *MembershipService
+	store         storage.ObjectStorage
+	signer        *signedurl.Signer
+	maxSize       int64
+	linkTTL       time.Duration
+	maxConcurrent int
+}
+
+func NewBookFileService(repo repository.Repository, books *BookService, memberships *MembershipService, store storage.ObjectStorage, cfg config.EbookConfig, jwtKey string) *BookFileService {
+	s := &BookFileService{
+		repo:          repo,
+		books:         books,
+		memberships:   memberships,
+		store:         store,
+		maxSize:       cfg.MaxSize,
+		linkTTL:       cfg.LinkTTL,
+		maxConcurrent: cfg.MaxConcurrent,
+	}
+	if s.maxSize <= 0 {
+		s.maxSize = defaultMaxEbookSize
+	}
+	if s.linkTTL <= 0 {
+		s.linkTTL = defaultDownloadLinkTTL
+	}
+	if s.maxConcurrent <= 0 {
+		s.maxConcurrent = defaultMaxConcurrentLoans
+	}
+	key := cfg.SigningKey
+	if key == "" {
+		// 不直接复用 JWT 密钥签名，而是派生出独立的密钥
+		logger.Warn("ebooks.signing_key is not set, deriving the download link key from jwt.key; configure a separate key")
+		key = signedurl.DeriveKey(jwtKey, downloadLinkKeyLabel)
+	}
+	s.signer = signedurl.NewSigner(key)
+	return s
+}
+
+// List 列出图书的电子书文件
+func (s *BookFileService) List(bookID int) ([]dto.BookFileResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	files, err := s.repo.ListBookFiles(book.ID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.BookFileResponse, len(files))
+	for i := range files {
+		responses[i] = dto.ToBookFileResponse(&files[i])
+	}
+	return responses, nil
+}
+
+// Upload 保存 EPUB 或 PDF 文件。EPUB 的书目信息用来补全图书中留空的字段；
+// 同一本书已有内容相同的文件时直接返回该文件
+func (s *BookFileService) Upload(ctx context.Context, actorID uint, bookID int, body io.Reader, fileName string) (*dto.UploadBookFileResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("%w: limit is %d bytes", entities.ErrBookFileTooLarge, s.maxSize)
+	}
+	format, err := ebook.Detect(data)
+	if err != nil {
+		return nil, entities.ErrUnsupportedBookFile
+	}
+
+	response := &dto.UploadBookFileResponse{Filled: []string{}}
+	if format == ebook.FormatEPUB {
+		meta, err := ebook.ReadEPUBMetadata(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", entities.ErrUnsupportedBookFile, err)
+		}
+		response.Metadata = toEbookMetadataResponse(meta)
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	file, err := s.findFile(book.ID, digest)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		file = &entities.BookFile{
+			BookID:     book.ID,
+			Format:     string(format),
+			FileName:   bookFileName(fileName, book, format),
+			StorageKey: bookFileKey(book.ID, digest, format),
+			Size:       int64(len(data)),
+			SHA256:     digest,
+			UploadedBy: actorID,
+		}
+		if err := s.store.Put(ctx, file.StorageKey, bytes.NewReader(data), format.ContentType()); err != nil {
+			return nil, err
+		}
+		if err := s.repo.CreateBookFile(file); err != nil {
+			s.removeObject(ctx, file)
+			return nil, err
+		}
+		logger.Info("book file uploaded",
+			zap.Uint("file_id", file.ID),
+			zap.Uint("book_id", file.BookID),
+			zap.String("format", file.Format),
+			zap.Int64("size", file.Size),
+		)
+	}
+	response.File = dto.ToBookFileResponse(file)
+
+	if response.Metadata != nil {
+		filled, err := s.prefill(actorID, book, response.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		response.Filled = filled
+	}
+	return response, nil
+}
+
+// Delete 删除电子书文件，已发出的下载链接随之失效
+func (s *BookFileService) Delete(ctx context.Context, bookID, fileID int) error {
+	file, err := s.getFile(bookID, fileID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteBookFile(file.ID); err != nil {
+		return err
+	}
+	s.removeObject(ctx, file)
+	return nil
+}
+
+// IssueLink 为读者生成文件的签名下载链接。读者已有未过期的授权时沿用该授权，
+// 否则新建授权，该书同时持有授权的其他读者已达上限时返回 entities.ErrDownloadLimitReached。
+// 读者的会员计划须包含电子书借阅
+func (s *BookFileService) IssueLink(userID uint, bookID, fileID int) (*dto.DownloadLinkResponse, error) {
+	file, err := s.getFile(bookID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.memberships.CheckDigitalAccess(userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant, err := s.repo.FindActiveDownloadGrant(file.ID, userID, now)
+	if err != nil {
+		grant = &entities.DownloadGrant{
+			FileID:    file.ID,
+			BookID:    file.BookID,
+			UserID:    userID,
+			ExpiresAt: now.Add(s.linkTTL),
+			CreatedAt: now,
+		}
+		if err := s.repo.CreateDownloadGrant(grant, s.maxConcurrent); err != nil {
+			return nil, err
+		}
+		logger.Info("download link issued",
+			zap.Uint("grant_id", grant.ID),
+			zap.Uint("file_id", grant.FileID),
+			zap.Uint("user_id", grant.UserID),
+		)
+	}
+
+	return &dto.DownloadLinkResponse{
+		URL:       s.signer.Sign(downloadPath(grant.ID), grant.ExpiresAt),
+		ExpiresAt: grant.ExpiresAt,
+	}, nil
+}
+
+// Open 校验签名链接并读取文件；签名错误、链接过期、授权或文件已删除时都返回 entities.ErrDownloadLinkInvalid
+func (s *BookFileService) Open(ctx context.Context, grantID int, expires, signature string) (*storage.Object, *entities.BookFile, error) {
+	now := time.Now()
+	if err := s.signer.Verify(downloadPath(uint(grantID)), expires, signature, now); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", entities.ErrDownloadLinkInvalid, err)
+	}
+	grant, err := s.repo.GetDownloadGrant(grantID)
+	if err != nil || !grant.IsActive(now) {
+		return nil, nil, entities.ErrDownloadLinkInvalid
+	}
+	file, err := s.repo.GetBookFile(int(grant.FileID))
+	if err != nil {
+		return nil, nil, entities.ErrDownloadLinkInvalid
+	}
+	object, err := s.store.Get(ctx, file.StorageKey)
+	if err != nil {
+		if stderrors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, errors.ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return object, file, nil
+}
+
+// PruneGrants 清理已过期的下载授权
+func (s *BookFileService) PruneGrants(ctx context.Context) error {
+	pruned, err := s.repo.PruneDownloadGrants(time.Now())
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		logger.Info("expired download grants pruned", zap.Int64("count", pruned))
+	}
+	return nil
+}
+
+// HandleBookDeleted 图书删除后清理其电子书文件。合并的图书文件已改到保留的图书上，这里不会再列出
+func (s *BookFileService) HandleBookDeleted(event events.Event) error {
+	deleted, ok := event.(events.BookDeleted)
+	if !ok {
+		return nil
+	}
+	files, err := s.repo.ListBookFiles(deleted.Book.ID)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for i := range files {
+		if err := s.repo.DeleteBookFile(files[i].ID); err != nil {
+			return err
+		}
+		s.removeObject(ctx, &files[i])
+	}
+	return nil
+}
+
+// prefill 用 EPUB 中的书目信息补全图书留空的字段，有变化时在同一事务内记录修订，返回被补全的字段名
+func (s *BookFileService) prefill(actorID uint, book *entities.Book, meta *dto.EbookMetadataResponse) ([]string, error) {
+	filled := []string{}
+	updated := *book
+	fill := func(field string, target *string, value string) {
+		if *target == "" && value != "" {
+			*target = value
+			filled = append(filled, field)
+		}
+	}
+	fill("subtitle", &updated.Subtitle, meta.Subtitle)
+	fill("publisher", &updated.Publisher, meta.Publisher)
+	if entities.IsValidPublishDate(meta.PublishDate) {
+		fill("publish_date", &updated.PublishDate, meta.PublishDate)
+	}
+	if entities.IsValidLanguageCode(meta.Language) {
+		fill("language", &updated.Language, meta.Language)
+	}
+	fill("description", &updated.Description, meta.Description)
+	if len(filled) == 0 {
+		return filled, nil
+	}
+
+	err := s.books.inTransaction(func(tx *BookService) error {
+		if err := tx.ensureBaseline(book); err != nil {
+			return err
+		}
+		if err := tx.repo.UpdateBook(&updated); err != nil {
+			return err
+		}
+		return tx.recordRevision(&updated, entities.BookRevisionUpdate, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filled, nil
+}
+
+// findFile 查找图书中内容相同的文件，没有时返回 nil
+func (s *BookFileService) findFile(bookID uint, digest string) (*entities.BookFile, error) {
+	files, err := s.repo.ListBookFiles(bookID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if files[i].SHA256 == digest {
+			return &files[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// getFile 读取文件并确认它属于路径中的图书
+func (s *BookFileService) getFile(bookID, fileID int) (*entities.BookFile, error) {
+	file, err := s.repo.GetBookFile(fileID)
+	if err != nil || file.BookID != uint(bookID) {
+		return nil, errors.ErrNotFound
+	}
+	return file, nil
+}
+
+// removeObject 删除文件内容，失败时只记录日志
+func (s *BookFileService) removeObject(ctx context.Context, file *entities.BookFile) {
+	if err := s.store.Delete(ctx, file.StorageKey); err != nil {
+		logger.Warn("failed to delete book file",
+			zap.Uint("book_id", file.BookID),
+			zap.String("key", file.StorageKey),
+			zap.Error(err),
+		)
+	}
+}
+
+func toEbookMetadataResponse(meta *ebook.Metadata) *dto.EbookMetadataResponse {
+	date := meta.Date
+	// 完整时间戳只取日期部分，避免被当作无法识别的写法只保留年份
+	if i := strings.IndexByte(date, 'T'); i > 0 {
+		date = date[:i]
+	}
+	language := strings.ToLower(meta.Language)
+	// 书目只记录语言代码，zh-CN、en-US 等地区后缀去掉
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	creators := meta.Creators
+	if creators == nil {
+		creators = []string{}
+	}
+	return &dto.EbookMetadataResponse{
+		Title:       meta.Title,
+		Subtitle:    meta.Subtitle,
+		Creators:    creators,
+		ISBN:        meta.ISBN,
+		Publisher:   meta.Publisher,
+		PublishDate: entities.NormalizePublishDate(date),
+		Language:    language,
+		Description: meta.Description,
+	}
+}
+
+// bookFileName 下载时建议的文件名：取上传时文件名的最后一段，没有时用书名，扩展名与格式一致
+func bookFileName(uploaded string, book *entities.Book, format ebook.Format) string {
+	name := strings.TrimSpace(path.Base(strings.ReplaceAll(uploaded, "\\", "/")))
+	if name == "." || name == "/" {
+		name = ""
+	}
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" {
+		name = strings.TrimSpace(book.Title)
+	}
+	if name == "" {
+		name = "book-" + strconv.FormatUint(uint64(book.ID), 10)
+	}
+	extension := "." + string(format)
+	for len(name)+len(extension) > maxBookFileNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name + extension
+}
+
+func bookFileKey(bookID uint, digest string, format ebook.Format) string {
+	return fmt.Sprintf("ebooks/%d/%s.%s", bookID, digest[:16], format)
+}
+
+func downloadPath(grantID uint) string {
+	return fmt.Sprintf("%s/%d", dto.EbookDownloadURLPrefix, grantID)
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDuplicateThreshold = 0.8
+	duplicateScanBatchSize    = 500
+)
+
+// BookMergeService 定期检测疑似重复的图书放入审核队列，由管理员决定合并还是忽略。
+// 合并时保留一本，另一本的副本、借阅、书评等记录改到保留的图书上，原 ID 重定向到保留的图书
+type BookMergeService struct {
+	repo      repository.Repository
+	books     *BookService
+	publisher events.Publisher
+	threshold float64
+}
+
+func NewBookMergeService(repo repository.Repository, books *BookService, publisher events.Publisher, cfg config.DuplicateConfig) *BookMergeService {
+	s := &BookMergeService{repo: repo, books: books, publisher: publisher, threshold: cfg.Threshold}
+	if s.threshold <= 0 || s.threshold > 1 {
+		s.threshold = defaultDuplicateThreshold
+	}
+	return s
+}
+
+// FindDuplicates 遍历全部图书重新检测疑似重复，并同步到审核队列
+func (s *BookMergeService) FindDuplicates(ctx context.Context) error {
+	finder := entities.NewDuplicateFinder()
+	scanned := 0
+	err := s.repo.ForEachBook(duplicateScanBatchSize, func(books []entities.Book) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for i := range books {
+			finder.Add(&books[i])
+		}
+		scanned += len(books)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	candidates := finder.Candidates(s.threshold)
+	added, err := s.repo.SyncBookDuplicates(candidates)
+	if err != nil {
+		return err
+	}
+	logger.Info("book duplicates detected",
+		zap.Int("books", scanned),
+		zap.Int("candidates", len(candidates)),
+		zap.Int("added", added),
+	)
+	return nil
+}
+
+// ListDuplicates 审核队列，按得分从高到低排列，并附上两本图书的详情供比较
+func (s *BookMergeService) ListDuplicates(page, pageSize int, query *dto.DuplicateListQuery) (*dto.PaginatedBookDuplicateResponse, error) {
+	status := query.Status
+	if status == "" {
+		status = entities.DuplicateStatusPending
+	}
+	offset := (page - 1) * pageSize
+	duplicates, total, err := s.repo.ListBookDuplicates(status, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.BookDuplicateResponse, len(duplicates))
+	for i := range duplicates {
+		items[i] = dto.ToBookDuplicateResponse(&duplicates[i])
+	}
+	if err := s.attachBooks(items); err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedBookDuplicateResponse{Items: items, Total: total}, nil
+}
+
+// MergeDuplicate 合并审核队列中的一对图书，req.SurvivorID 为保留的那本
+func (s *BookMergeService) MergeDuplicate(actorID uint, id int, req *dto.MergeDuplicateRequest) (*dto.BookResponse, error) {
+	duplicate, err := s.repo.GetBookDuplicate(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if !duplicate.Contains(req.SurvivorID) {
+		return nil, fmt.Errorf("%w: survivor must be book %d or %d", errors.ErrInvalidInput, duplicate.BookID, duplicate.OtherBookID)
+	}
+	duplicateID := duplicate.BookID
+	if duplicateID == req.SurvivorID {
+		duplicateID = duplicate.OtherBookID
+	}
+	if err := duplicate.Resolve(entities.DuplicateStatusMerged, actorID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.merge(actorID, req.SurvivorID, duplicateID, duplicate)
+}
+
+// MergeBook 把 req.DuplicateID 直接合并到 survivorID
+func (s *BookMergeService) MergeBook(actorID uint, survivorID int, req *dto.MergeBookRequest) (*dto.BookResponse, error) {
+	return s.merge(actorID, uint(survivorID), req.DuplicateID, nil)
+}
+
+// Dismiss 确认一对图书不是重复，之后的检测不会再把它们加入队列
+func (s *BookMergeService) Dismiss(actorID uint, id int) (*dto.BookDuplicateResponse, error) {
+	duplicate, err := s.repo.GetBookDuplicate(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := duplicate.Resolve(entities.DuplicateStatusDismissed, actorID, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResolveBookDuplicate(duplicate); err != nil {
+		return nil, err
+	}
+
+	items := []dto.BookDuplicateResponse{dto.ToBookDuplicateResponse(duplicate)}
+	if err := s.attachBooks(items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// merge 合并时在同一事务内为被合并的图书记录一个修订保存其最后的状态，之后仍可查看历史或恢复
+func (s *BookMergeService) merge(actorID, survivorID, duplicateID uint, duplicate *entities.BookDuplicate) (*dto.BookResponse, error) {
+	if survivorID == duplicateID {
+		return nil, entities.ErrBookMergeSelf
+	}
+	survivor, err := s.repo.GetBook(int(survivorID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	merged, err := s.repo.GetBook(int(duplicateID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: book %d does not exist", errors.ErrNotFound, duplicateID)
+	}
+
+	snapshot, err := s.books.snapshotBook(merged)
+	if err != nil {
+		return nil, err
+	}
+	var released []uint
+	err = s.books.inTransaction(func(tx *BookService) error {
+		var err error
+		if released, err = tx.repo.MergeBooks(survivor.ID, merged.ID, duplicate); err != nil {
+			return err
+		}
+		revision := &entities.BookRevision{BookID: merged.ID, Action: entities.BookRevisionMerge, Snapshot: snapshot.Encode()}
+		return tx.addRevision(revision, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 被合并图书的封面文件不再使用，与删除图书一样清理
+	s.publisher.Publish(events.BookDeleted{Book: *merged})
+	// 读者在两本书上都已到书时取消了其中一个，空出的副本交给队首的预约
+	for _, copyID := range released {
+		s.publisher.Publish(events.CopyAvailable{CopyID: copyID, BookID: survivor.ID})
+	}
+
+	logger.Info("books merged",
+		zap.Uint("book_id", survivor.ID),
+		zap.Uint("merged_book_id", merged.ID),
+		zap.Uint("actor_id", actorID),
+	)
+	return s.books.GetBook(int(survivor.ID))
+}
+
+// attachBooks 填充每对组合中两本图书的详情，已不存在的图书留空
+func (s *BookMergeService) attachBooks(items []dto.BookDuplicateResponse) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var ids []uint
+	for _, item := range items {
+		ids = append(ids, item.BookID, item.OtherBookID)
+	}
+	books, err := s.repo.ListBooksByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*dto.BookResponse, len(books))
+	responses := make([]*dto.BookResponse, len(books))
+	for i := range books {
+		responses[i] = dto.ToBookResponse(&books[i])
+		byID[books[i].ID] = responses[i]
+	}
+	s.books.enrich(responses...)
+
+	for i := range items {
+		items[i].Book = byID[items[i].BookID]
+		items[i].Other = byID[items[i].OtherBookID]
+	}
+	return nil
+}
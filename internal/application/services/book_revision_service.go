@@ -0,0 +1,211 @@
+package services
+
+import (
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+// BookRevisionService 查询图书的修订历史、比较修订，并把图书恢复到历史状态。
+// 修订本身由 BookService 和 CatalogService 在每次新增、修改、删除时写入
+type BookRevisionService struct {
+	repo  repository.Repository
+	books *BookService
+}
+
+func NewBookRevisionService(repo repository.Repository, books *BookService) *BookRevisionService {
+	return &BookRevisionService{repo: repo, books: books}
+}
+
+// History 按修订号倒序列出图书的修订及各自相对上一个修订改动的字段。图书已删除时仍可查询
+func (s *BookRevisionService) History(bookID, page, pageSize int) (*dto.PaginatedBookRevisionResponse, error) {
+	offset := (page - 1) * pageSize
+	// 多取一条，用于计算本页最早一个修订的改动
+	revisions, total, err := s.repo.ListBookRevisions(uint(bookID), offset, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		if _, err := s.repo.GetBook(bookID); err != nil {
+			return nil, errors.ErrNotFound
+		}
+	}
+
+	snapshots := make([]entities.BookSnapshot, len(revisions))
+	for i := range revisions {
+		if snapshots[i], err = revisions[i].DecodeSnapshot(); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]dto.BookRevisionResponse, 0, pageSize)
+	for i := 0; i < len(revisions) && i < pageSize; i++ {
+		var changes []entities.FieldChange
+		if i+1 < len(snapshots) {
+			changes = snapshots[i+1].Diff(snapshots[i])
+		}
+		items = append(items, dto.ToBookRevisionResponse(&revisions[i], changes))
+	}
+	return &dto.PaginatedBookRevisionResponse{Items: items, Total: total}, nil
+}
+
+// Revision 单个修订及其完整快照
+func (s *BookRevisionService) Revision(bookID, number int) (*dto.BookRevisionDetailResponse, error) {
+	revision, err := s.repo.GetBookRevision(uint(bookID), number)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	snapshot, err := revision.DecodeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []entities.FieldChange
+	if previous, err := s.repo.GetBookRevision(uint(bookID), number-1); err == nil {
+		before, err := previous.DecodeSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		changes = before.Diff(snapshot)
+	}
+	return &dto.BookRevisionDetailResponse{
+		BookRevisionResponse: dto.ToBookRevisionResponse(revision, changes),
+		Snapshot:             dto.ToBookSnapshotResponse(snapshot),
+	}, nil
+}
+
+// Diff 逐字段比较两个修订。to 默认为最新修订，from 默认为 to 的上一个修订；
+// to 为第一个修订且未指定 from 时与空白图书比较
+func (s *BookRevisionService) Diff(bookID int, query *dto.BookDiffQuery) (*dto.BookDiffResponse, error) {
+	to := query.To
+	if to == 0 {
+		latest, total, err := s.repo.ListBookRevisions(uint(bookID), 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		if total == 0 {
+			return nil, errors.ErrNotFound
+		}
+		to = latest[0].Revision
+	}
+	from := query.From
+	if from == 0 {
+		from = to - 1
+	}
+
+	after, err := s.snapshot(bookID, to)
+	if err != nil {
+		return nil, err
+	}
+	var before entities.BookSnapshot
+	if from > 0 {
+		if before, err = s.snapshot(bookID, from); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.BookDiffResponse{
+		BookID:  uint(bookID),
+		From:    from,
+		To:      to,
+		Changes: dto.ToFieldChangeResponseList(before.Diff(after)),
+	}, nil
+}
+
+// Revert 把图书恢复为指定修订的状态，并记录一个新的恢复修订；图书已删除时按原 ID 重新创建。
+// 恢复前的状态仍保留在历史中，所以恢复本身也可以再被恢复
+func (s *BookRevisionService) Revert(actorID uint, bookID int, req *dto.RevertBookRequest) (*dto.BookResponse, error) {
+	snapshot, err := s.snapshot(bookID, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := s.repo.GetBookByISBN(snapshot.ISBN); err == nil && existing.ID != uint(bookID) {
+		return nil, errors.ErrISBNAlreadyExists
+	}
+	contributors, err := s.resolveContributors(snapshot.Contributors)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.GetBook(bookID)
+	deleted := err != nil
+	if deleted {
+		book = &entities.Book{ID: uint(bookID)}
+	}
+	book.Title = snapshot.Title
+	book.Subtitle = snapshot.Subtitle
+	book.Author = snapshot.Author
+	book.ISBN = snapshot.ISBN
+	book.Publisher = snapshot.Publisher
+	book.PublishDate = snapshot.PublishDate
+	book.CoverURL = snapshot.CoverURL
+	book.PageCount = snapshot.PageCount
+	book.Edition = snapshot.Edition
+	book.Description = snapshot.Description
+	book.Format = snapshot.Format
+	book.Language = snapshot.Language
+
+	err = s.books.inTransaction(func(tx *BookService) error {
+		var err error
+		if deleted {
+			err = tx.repo.CreateBook(book)
+		} else {
+			err = tx.repo.UpdateBook(book)
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.linkAuthors(book, contributors); err != nil {
+			return err
+		}
+
+		current, err := tx.snapshotBook(book)
+		if err != nil {
+			return err
+		}
+		revertedFrom := req.Revision
+		revision := &entities.BookRevision{
+			BookID:       book.ID,
+			Action:       entities.BookRevisionRevert,
+			RevertedFrom: &revertedFrom,
+			Snapshot:     current.Encode(),
+		}
+		return tx.addRevision(revision, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.books.enrich(response)
+	return response, nil
+}
+
+func (s *BookRevisionService) snapshot(bookID, number int) (entities.BookSnapshot, error) {
+	revision, err := s.repo.GetBookRevision(uint(bookID), number)
+	if err != nil {
+		return entities.BookSnapshot{}, errors.ErrNotFound
+	}
+	return revision.DecodeSnapshot()
+}
+
+// resolveContributors 把快照中的责任者转换为关联请求。快照之后被删除的作者按姓名查找，
+// 找不到时重新创建，保证恢复后的署名与快照一致
+func (s *BookRevisionService) resolveContributors(contributors []entities.SnapshotContributor) ([]dto.BookContributorRequest, error) {
+	requests := make([]dto.BookContributorRequest, 0, len(contributors))
+	for _, contributor := range contributors {
+		author, err := s.repo.GetAuthor(int(contributor.AuthorID))
+		if err != nil {
+			author, err = s.repo.FindAuthorByName(contributor.Name)
+		}
+		if err != nil {
+			author = &entities.Author{Name: contributor.Name, SortName: entities.DeriveSortName(contributor.Name)}
+			if err := s.repo.CreateAuthor(author); err != nil {
+				return nil, err
+			}
+		}
+		requests = append(requests, dto.BookContributorRequest{AuthorID: author.ID, Role: contributor.Role})
+	}
+	return requests, nil
+}
@@ -1,39 +1,104 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
 	"github.com/azel-ko/final-ddd/internal/application/dto"
 	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
 	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// maxScanImageSize 条码照片的大小上限
+const maxScanImageSize = 10 << 20
+
 type BookService struct {
-	repo repository.Repository
+	repo      repository.Repository
+	metadata  metadata.MetadataProvider
+	publisher events.Publisher
 }
 
-func NewBookService(repo repository.Repository) *BookService {
-	return &BookService{repo: repo}
+func NewBookService(repo repository.Repository, provider metadata.MetadataProvider, publisher events.Publisher) *BookService {
+	return &BookService{repo: repo, metadata: provider, publisher: publisher}
 }
 
-func (s *BookService) CreateBook(req *dto.CreateBookRequest) (*dto.BookResponse, error) {
-	if _, err := s.repo.GetBookByISBN(req.ISBN); err == nil {
+func (s *BookService) CreateBook(ctx context.Context, actorID uint, req *dto.CreateBookRequest) (*dto.BookResponse, error) {
+	book, err := s.createBook(ctx, actorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
+}
+
+// createBook 校验并新建图书，在同一事务内关联责任者并记录新建修订
+func (s *BookService) createBook(ctx context.Context, actorID uint, req *dto.CreateBookRequest) (*entities.Book, error) {
+	isbn, err := entities.ParseISBN(req.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetBookByISBN(isbn.String()); err == nil {
 		return nil, errors.ErrISBNAlreadyExists
 	}
+	if req.AutoFill {
+		if err := s.autoFill(ctx, isbn, req); err != nil {
+			return nil, err
+		}
+	}
+	if !entities.IsValidPublishDate(req.PublishDate) || !entities.IsValidLanguageCode(req.Language) {
+		return nil, errors.ErrInvalidInput
+	}
 
 	book := dto.ToBookEntity(req)
-	if err := s.repo.CreateBook(book); err != nil {
+	book.ISBN = isbn.String()
+	if req.WorkID != nil {
+		if _, err := s.repo.GetWork(int(*req.WorkID)); err != nil {
+			return nil, fmt.Errorf("%w: work %d does not exist", errors.ErrInvalidInput, *req.WorkID)
+		}
+		book.WorkID = *req.WorkID
+	}
+	err = s.inTransaction(func(tx *BookService) error {
+		if err := tx.repo.CreateBook(book); err != nil {
+			return err
+		}
+		if err := tx.linkAuthors(book, req.Authors); err != nil {
+			return err
+		}
+		return tx.recordRevision(book, entities.BookRevisionCreate, actorID)
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	return dto.ToBookResponse(book), nil
+	return book, nil
 }
 
-func (s *BookService) GetBookByISBN(isbn string) (*dto.BookResponse, error) {
-	book, err := s.repo.GetBookByISBN(isbn)
+// GetBookByISBN 按 ISBN 查找图书，ISBN-10 和带分隔符的写法都会先规范化
+func (s *BookService) GetBookByISBN(raw string) (*dto.BookResponse, error) {
+	isbn, err := entities.ParseISBN(raw)
 	if err != nil {
 		return nil, err
 	}
+	book, err := s.repo.GetBookByISBN(isbn.String())
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
 
-	return dto.ToBookResponse(book), nil
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
 }
 
 func (s *BookService) GetBook(id int) (*dto.BookResponse, error) {
@@ -41,40 +106,552 @@ func (s *BookService) GetBook(id int) (*dto.BookResponse, error) {
 	if err != nil {
 		return nil, errors.ErrNotFound
 	}
-	return dto.ToBookResponse(book), nil
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
+}
+
+// MergedInto 图书已被合并到另一本时返回保留下来的图书 ID
+func (s *BookService) MergedInto(id int) (uint, bool) {
+	redirect, err := s.repo.GetBookRedirect(uint(id))
+	if err != nil {
+		return 0, false
+	}
+	return redirect.TargetBookID, true
+}
+
+func (s *BookService) UpdateBook(actorID uint, id int, req *dto.UpdateBookRequest) (*dto.BookResponse, error) {
+	book, err := s.updateBook(actorID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
 }
 
-func (s *BookService) UpdateBook(id int, req *dto.UpdateBookRequest) (*dto.BookResponse, error) {
+// updateBook 校验并整体替换图书的字段，在同一事务内按需重新关联责任者并记录修改修订
+func (s *BookService) updateBook(actorID uint, id int, req *dto.UpdateBookRequest) (*entities.Book, error) {
 	book, err := s.repo.GetBook(id)
 	if err != nil {
 		return nil, errors.ErrNotFound
 	}
 
-	book.ISBN = req.ISBN
+	isbn, err := entities.ParseISBN(req.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := s.repo.GetBookByISBN(isbn.String()); err == nil && existing.ID != book.ID {
+		return nil, errors.ErrISBNAlreadyExists
+	}
+
+	if !entities.IsValidPublishDate(req.PublishDate) || !entities.IsValidLanguageCode(req.Language) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	relink := len(req.Authors) > 0 || req.Author != book.Author
+	before := *book
+	book.ISBN = isbn.String()
 	book.Title = req.Title
+	book.Subtitle = req.Subtitle
 	book.Author = req.Author
+	book.Publisher = req.Publisher
+	book.PublishDate = req.PublishDate
+	book.CoverURL = req.CoverURL
+	book.PageCount = req.PageCount
+	book.Edition = req.Edition
+	book.Description = req.Description
+	book.Format = req.Format
+	book.Language = req.Language
 
-	if err := s.repo.UpdateBook(book); err != nil {
+	err = s.inTransaction(func(tx *BookService) error {
+		if err := tx.ensureBaseline(&before); err != nil {
+			return err
+		}
+		if err := tx.repo.UpdateBook(book); err != nil {
+			return err
+		}
+		if relink {
+			if err := tx.linkAuthors(book, req.Authors); err != nil {
+				return err
+			}
+		}
+		return tx.recordRevision(book, entities.BookRevisionUpdate, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// ApplyEditProposal 通过读者的修改建议：在一个事务内检查冲突、写入提议的字段、标记建议已通过，
+// 并以审核人的名义记录修订。proposal 需已调用 Resolve 填好审核结果
+func (s *BookService) ApplyEditProposal(actorID uint, proposal *entities.BookEditProposal) (*dto.BookResponse, error) {
+	book, err := s.repo.GetBook(int(proposal.BookID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	var updated *entities.Book
+	err = s.inTransaction(func(tx *BookService) error {
+		if err := tx.ensureBaseline(book); err != nil {
+			return err
+		}
+		var err error
+		if updated, err = tx.repo.ApplyBookEditProposal(proposal); err != nil {
+			return err
+		}
+		return tx.recordRevision(updated, entities.BookRevisionUpdate, actorID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return dto.ToBookResponse(book), nil
+	response := dto.ToBookResponse(updated)
+	s.enrich(response)
+	return response, nil
 }
 
-func (s *BookService) DeleteBook(id int) error {
-	return s.repo.DeleteBook(id)
+func (s *BookService) DeleteBook(actorID uint, id int) error {
+	book, err := s.repo.GetBook(id)
+	if err != nil {
+		return errors.ErrNotFound
+	}
+	// 删除修订保存删除前的状态，责任者关联随图书一起删除，所以要先生成快照
+	snapshot, err := s.snapshotBook(book)
+	if err != nil {
+		return err
+	}
+	revision := &entities.BookRevision{BookID: book.ID, Action: entities.BookRevisionDelete, Snapshot: snapshot.Encode()}
+	err = s.inTransaction(func(tx *BookService) error {
+		if err := tx.repo.DeleteBook(id); err != nil {
+			return err
+		}
+		return tx.addRevision(revision, actorID)
+	})
+	if err != nil {
+		return err
+	}
+	s.publisher.Publish(events.BookDeleted{Book: *book})
+	return nil
 }
 
-func (s *BookService) ListBooks(page, pageSize int, title, author string) (*dto.PaginatedBookResponse, error) {
+// LookupMetadata 按 ISBN 查询外部书目，预览可用于补全的图书信息
+func (s *BookService) LookupMetadata(ctx context.Context, raw string) (*dto.BookMetadataResponse, error) {
+	isbn, err := entities.ParseISBN(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.metadata.LookupISBN(ctx, isbn)
+	if err != nil {
+		if stderrors.Is(err, metadata.ErrNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	response := dto.ToBookMetadataResponse(record)
+	if book, err := s.repo.GetBookByISBN(isbn.String()); err == nil {
+		response.ExistingBookID = &book.ID
+	}
+	return response, nil
+}
+
+// ScanISBN 识别照片中的 EAN-13 条码并按 ISBN 查找图书。馆藏中没有时返回预填的新建请求：
+// 能查到外部书目就补全书名、作者等字段，查不到则只带 ISBN 并开启自动补全
+func (s *BookService) ScanISBN(ctx context.Context, body io.Reader) (*dto.BookScanResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxScanImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxScanImageSize {
+		return nil, fmt.Errorf("%w: image exceeds %d bytes", errors.ErrInvalidInput, maxScanImageSize)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported image: %v", errors.ErrInvalidInput, err)
+	}
+
+	code, err := barcode.ScanEAN13(img)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+	}
+	isbn, err := entities.ParseISBN(code)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.BookScanResponse{ISBN: isbn.String()}
+	if book, err := s.repo.GetBookByISBN(isbn.String()); err == nil {
+		response.Book = dto.ToBookResponse(book)
+		s.enrich(response.Book)
+		return response, nil
+	}
+
+	response.Create = &dto.CreateBookRequest{ISBN: isbn.String()}
+	if err := s.autoFill(ctx, isbn, response.Create); err != nil || response.Create.Title == "" {
+		response.Create.AutoFill = true
+	}
+	return response, nil
+}
+
+// autoFill 用外部书目补全请求中留空的字段，已填写的字段保持不变。
+// 查询失败时只要必填字段已经齐全就继续创建，否则返回错误
+func (s *BookService) autoFill(ctx context.Context, isbn entities.ISBN, req *dto.CreateBookRequest) error {
+	record, err := s.metadata.LookupISBN(ctx, isbn)
+	if err != nil {
+		logger.Warn("failed to look up book metadata", zap.String("isbn", isbn.String()), zap.Error(err))
+		if req.Title != "" && (req.Author != "" || len(req.Authors) > 0) {
+			return nil
+		}
+		if stderrors.Is(err, metadata.ErrNotFound) {
+			return fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+		}
+		return err
+	}
+
+	if req.Title == "" {
+		req.Title = record.Title
+	}
+	if req.Author == "" && len(req.Authors) == 0 {
+		req.Author = strings.Join(record.Authors, "; ")
+	}
+	if req.Publisher == "" {
+		req.Publisher = record.Publisher
+	}
+	if req.PublishDate == "" {
+		req.PublishDate = record.PublishDate
+	}
+	if req.CoverURL == "" {
+		req.CoverURL = record.CoverURL
+	}
+	if req.Author == "" && len(req.Authors) == 0 {
+		return fmt.Errorf("%w: metadata has no authors", errors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// SetBookAuthors 替换图书的责任者列表
+func (s *BookService) SetBookAuthors(actorID uint, id int, req *dto.SetBookAuthorsRequest) (*dto.BookResponse, error) {
+	book, err := s.repo.GetBook(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if len(req.Authors) == 0 {
+		return nil, errors.ErrInvalidInput
+	}
+	err = s.inTransaction(func(tx *BookService) error {
+		if err := tx.ensureBaseline(book); err != nil {
+			return err
+		}
+		if err := tx.linkAuthors(book, req.Authors); err != nil {
+			return err
+		}
+		return tx.recordRevision(book, entities.BookRevisionUpdate, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
+}
+
+// SetBookCategories 替换图书所属的分类
+func (s *BookService) SetBookCategories(id int, req *dto.SetBookCategoriesRequest) (*dto.BookResponse, error) {
+	book, err := s.repo.GetBook(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	seen := make(map[uint]bool, len(req.CategoryIDs))
+	categoryIDs := make([]uint, 0, len(req.CategoryIDs))
+	for _, categoryID := range req.CategoryIDs {
+		if seen[categoryID] {
+			continue
+		}
+		if _, err := s.repo.GetCategory(int(categoryID)); err != nil {
+			return nil, errors.ErrNotFound
+		}
+		seen[categoryID] = true
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	if err := s.repo.SetBookCategories(book.ID, categoryIDs); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
+}
+
+// SetBookTags 替换图书的标签
+func (s *BookService) SetBookTags(id int, req *dto.SetBookTagsRequest) (*dto.BookResponse, error) {
+	book, err := s.repo.GetBook(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := s.repo.SetBookTags(book.ID, normalizeTags(req.Tags)); err != nil {
+		return nil, err
+	}
+
+	response := dto.ToBookResponse(book)
+	s.enrich(response)
+	return response, nil
+}
+
+func (s *BookService) ListBooks(page, pageSize int, query *dto.BookListQuery) (*dto.PaginatedBookResponse, error) {
+	filter, err := s.buildFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
 	offset := (page - 1) * pageSize
-	books, total, err := s.repo.ListBooks(offset, pageSize, title, author)
+	books, total, err := s.repo.ListBooks(offset, pageSize, filter)
 	if err != nil {
 		return nil, err // Consider wrapping error (e.g., errors.ErrDatabase)
 	}
 
 	bookResponses := dto.ToBookResponseList(books)
-	return &dto.PaginatedBookResponse{
+	refs := make([]*dto.BookResponse, len(bookResponses))
+	for i := range bookResponses {
+		refs[i] = &bookResponses[i]
+	}
+	s.enrich(refs...)
+
+	response := &dto.PaginatedBookResponse{
 		Items: bookResponses,
 		Total: total,
+	}
+	if facets, err := s.repo.BookFacets(filter); err != nil {
+		logger.Warn("failed to compute book facets", zap.Error(err))
+	} else {
+		response.Facets = dto.ToBookFacetsResponse(facets)
+	}
+	return response, nil
+}
+
+// SearchBooks 全文检索图书，结果按相关度排序
+func (s *BookService) SearchBooks(page, pageSize int, query *dto.BookSearchQuery) (*dto.BookSearchResponse, error) {
+	terms := entities.SearchTerms(query.Q)
+	if len(terms) == 0 {
+		return nil, errors.ErrInvalidInput
+	}
+
+	offset := (page - 1) * pageSize
+	hits, total, err := s.repo.SearchBooks(terms, query.CollapseEditions, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := dto.ToBookSearchHitResponseList(hits)
+	refs := make([]*dto.BookResponse, len(items))
+	for i := range items {
+		refs[i] = &items[i].BookResponse
+	}
+	s.enrich(refs...)
+
+	return &dto.BookSearchResponse{
+		Items: items,
+		Total: total,
 	}, nil
 }
+
+// buildFilter 把列表查询参数转换为仓储层的筛选条件
+func (s *BookService) buildFilter(query *dto.BookListQuery) (repository.BookFilter, error) {
+	filter := repository.BookFilter{
+		Title:    query.Title,
+		Author:   query.Author,
+		AuthorID: query.AuthorID,
+		Tags:     normalizeTags(query.Tags),
+		Sort:     query.Sort,
+
+		Publisher:     strings.TrimSpace(query.Publisher),
+		Language:      strings.ToLower(query.Language),
+		Format:        query.Format,
+		PublishedFrom: query.PublishedFrom,
+		PublishedTo:   query.PublishedTo,
+		MinPages:      query.MinPages,
+		MaxPages:      query.MaxPages,
+
+		CollapseEditions: query.CollapseEditions,
+	}
+	if !entities.IsValidLanguageCode(filter.Language) ||
+		(filter.PublishedTo != 0 && filter.PublishedFrom > filter.PublishedTo) ||
+		(filter.MaxPages != 0 && filter.MinPages > filter.MaxPages) {
+		return filter, errors.ErrInvalidInput
+	}
+	if query.CategoryID != 0 {
+		category, err := s.repo.GetCategory(int(query.CategoryID))
+		if err != nil {
+			return filter, errors.ErrNotFound
+		}
+		filter.CategoryPath = category.Path
+	}
+	if query.BranchID != 0 {
+		if _, err := s.repo.GetBranch(int(query.BranchID)); err != nil {
+			return filter, errors.ErrNotFound
+		}
+		filter.BranchID = query.BranchID
+	}
+	return filter, nil
+}
+
+// normalizeTags 规范化并去重标签，支持逗号分隔的写法
+func normalizeTags(raw []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, item := range raw {
+		for _, part := range strings.Split(item, ",") {
+			tag := entities.NormalizeTag(part)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// linkAuthors 关联图书的责任者。指定了责任者列表时按列表关联，
+// 否则把图书的作者文本拆分为姓名，按姓名查找或创建作者后关联
+func (s *BookService) linkAuthors(book *entities.Book, contributors []dto.BookContributorRequest) error {
+	var links []entities.BookAuthor
+	var names []entities.Contributor
+
+	// 同一作者以同一角色重复出现时只保留第一次
+	seen := make(map[entities.BookAuthor]bool)
+	add := func(author *entities.Author, role string) {
+		key := entities.BookAuthor{AuthorID: author.ID, Role: role}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		links = append(links, entities.BookAuthor{BookID: book.ID, AuthorID: author.ID, Role: role, Position: len(links)})
+		names = append(names, entities.Contributor{AuthorID: author.ID, Name: author.Name, Role: role})
+	}
+
+	if len(contributors) > 0 {
+		for _, contributor := range contributors {
+			role := contributor.Role
+			if role == "" {
+				role = entities.AuthorRoleAuthor
+			}
+			if !entities.IsValidAuthorRole(role) {
+				return errors.ErrInvalidInput
+			}
+			author, err := s.repo.GetAuthor(int(contributor.AuthorID))
+			if err != nil {
+				return errors.ErrNotFound
+			}
+			add(author, role)
+		}
+	} else {
+		for _, name := range entities.SplitAuthorNames(book.Author) {
+			author, err := s.repo.FindAuthorByName(name)
+			if err != nil {
+				author = &entities.Author{Name: name, SortName: entities.DeriveSortName(name)}
+				if err := s.repo.CreateAuthor(author); err != nil {
+					return err
+				}
+			}
+			add(author, entities.AuthorRoleAuthor)
+		}
+	}
+
+	book.Author = entities.Byline(names)
+	return s.repo.SetBookAuthors(book.ID, links, book.Author)
+}
+
+// snapshotBook 读取图书当前的责任者并生成快照
+func (s *BookService) snapshotBook(book *entities.Book) (entities.BookSnapshot, error) {
+	contributors, err := s.repo.ListBookContributors([]uint{book.ID})
+	if err != nil {
+		return entities.BookSnapshot{}, err
+	}
+	return entities.NewBookSnapshot(book, contributors[book.ID]), nil
+}
+
+// inTransaction 在一个仓储事务内执行 fn。fn 收到的 BookService 通过该事务读写，
+// 图书的修改、责任者关联和修订要么一起提交，要么一起回滚
+func (s *BookService) inTransaction(fn func(tx *BookService) error) error {
+	return s.repo.Transaction(func(repo repository.Repository) error {
+		tx := *s
+		tx.repo = repo
+		return fn(&tx)
+	})
+}
+
+// recordRevision 为图书当前的状态记录一个修订，actorID 为 0 表示系统操作
+func (s *BookService) recordRevision(book *entities.Book, action string, actorID uint) error {
+	snapshot, err := s.snapshotBook(book)
+	if err != nil {
+		return err
+	}
+	return s.addRevision(&entities.BookRevision{BookID: book.ID, Action: action, Snapshot: snapshot.Encode()}, actorID)
+}
+
+func (s *BookService) addRevision(revision *entities.BookRevision, actorID uint) error {
+	if actorID != 0 {
+		revision.ActorID = &actorID
+	}
+	return s.repo.AddBookRevision(revision)
+}
+
+// ensureBaseline 图书还没有任何修订时（开始记录历史之前就已存在），
+// 先把修改前的状态记为基线修订，保证第一次修改也能追溯和恢复
+func (s *BookService) ensureBaseline(book *entities.Book) error {
+	_, total, err := s.repo.ListBookRevisions(book.ID, 0, 1)
+	if err != nil || total > 0 {
+		return err
+	}
+	return s.recordRevision(book, entities.BookRevisionBaseline, 0)
+}
+
+// enrich 为图书响应批量填充副本数量和责任者，读取失败时只记录日志不影响主流程
+func (s *BookService) enrich(responses ...*dto.BookResponse) {
+	if len(responses) == 0 {
+		return
+	}
+
+	ids := make([]uint, len(responses))
+	for i, response := range responses {
+		ids[i] = response.ID
+	}
+
+	availability, err := s.repo.GetCopyAvailability(ids)
+	if err != nil {
+		logger.Warn("failed to load copy availability", zap.Error(err))
+	}
+	branches, err := s.repo.GetBranchAvailability(ids)
+	if err != nil {
+		logger.Warn("failed to load branch availability", zap.Error(err))
+	}
+	contributors, err := s.repo.ListBookContributors(ids)
+	if err != nil {
+		logger.Warn("failed to load book contributors", zap.Error(err))
+	}
+	categories, err := s.repo.ListBookCategories(ids)
+	if err != nil {
+		logger.Warn("failed to load book categories", zap.Error(err))
+	}
+	tags, err := s.repo.ListBookTags(ids)
+	if err != nil {
+		logger.Warn("failed to load book tags", zap.Error(err))
+	}
+
+	for _, response := range responses {
+		response.ApplyAvailability(availability[response.ID])
+		response.Branches = dto.ToBranchAvailabilityResponseList(branches[response.ID])
+		response.Authors = dto.ToContributorResponseList(contributors[response.ID])
+		response.Categories = dto.ToCategoryRefResponseList(categories[response.ID])
+		response.Tags = tags[response.ID]
+		if response.Tags == nil {
+			response.Tags = []string{}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+// BranchService 管理分馆信息，副本的馆际调拨见 TransferService
+type BranchService struct {
+	repo repository.Repository
+}
+
+func NewBranchService(repo repository.Repository) *BranchService {
+	return &BranchService{repo: repo}
+}
+
+func (s *BranchService) ListBranches() ([]dto.BranchResponse, error) {
+	branches, err := s.repo.ListBranches()
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToBranchResponseList(branches, time.Now()), nil
+}
+
+func (s *BranchService) GetBranch(id int) (*dto.BranchResponse, error) {
+	branch, err := s.repo.GetBranch(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	response := dto.ToBranchResponse(branch, time.Now())
+	return &response, nil
+}
+
+func (s *BranchService) CreateBranch(req *dto.BranchRequest) (*dto.BranchResponse, error) {
+	branch := &entities.Branch{}
+	if err := s.apply(branch, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBranch(branch); err != nil {
+		return nil, err
+	}
+	response := dto.ToBranchResponse(branch, time.Now())
+	return &response, nil
+}
+
+// UpdateBranch 整体替换分馆信息
+func (s *BranchService) UpdateBranch(id int, req *dto.BranchRequest) (*dto.BranchResponse, error) {
+	branch, err := s.repo.GetBranch(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := s.apply(branch, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBranch(branch); err != nil {
+		return nil, err
+	}
+	response := dto.ToBranchResponse(branch, time.Now())
+	return &response, nil
+}
+
+// DeleteBranch 删除分馆，仍有馆藏或未结束的调拨时不能删除
+func (s *BranchService) DeleteBranch(id int) error {
+	branch, err := s.repo.GetBranch(id)
+	if err != nil {
+		return errors.ErrNotFound
+	}
+	return s.repo.DeleteBranch(branch.ID)
+}
+
+// apply 校验请求并写入分馆，名称不能与其他分馆重复
+func (s *BranchService) apply(branch *entities.Branch, req *dto.BranchRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.ErrInvalidInput
+	}
+	if existing, err := s.repo.GetBranchByName(name); err == nil && existing.ID != branch.ID {
+		return entities.ErrBranchNameTaken
+	}
+	if err := branch.SetTimezone(req.Timezone); err != nil {
+		return err
+	}
+	if err := branch.SetOpeningHours(req.Periods()); err != nil {
+		return err
+	}
+	branch.Name = name
+	branch.Address = strings.TrimSpace(req.Address)
+	return nil
+}
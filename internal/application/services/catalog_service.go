@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/bookio"
+)
+
+const (
+	// maxReportErrors 导入报告中最多保留的错误条数，超出部分只计数
+	maxReportErrors = 1000
+	// defaultProgressEvery 默认每处理多少条记录报告一次进度
+	defaultProgressEvery = 1000
+	exportBatchSize      = 500
+	maxBookTextLength    = 255
+	maxCoverURLLength    = 512
+	maxEditionLength     = 100
+	maxDescriptionLength = 10000
+	maxPageCount         = 100000
+)
+
+// 单条记录的导入结果
+type importOutcome int
+
+const (
+	outcomeCreated importOutcome = iota
+	outcomeUpdated
+	outcomeUnchanged
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Format  bookio.Format
+	Mapping bookio.Mapping // 为 nil 时使用格式的默认映射
+	DryRun  bool           // 只校验并统计，不写入数据库
+	ActorID uint           // 记入图书修订的操作人，0 表示系统操作（例如命令行导入）
+
+	Progress      func(report dto.ImportReport) // 进度回调，可为 nil
+	ProgressEvery int
+}
+
+// CatalogService 以流的方式导入导出书目，按 ISBN 新增或更新图书
+type CatalogService struct {
+	repo  repository.Repository
+	books *BookService
+}
+
+func NewCatalogService(repo repository.Repository, books *BookService) *CatalogService {
+	return &CatalogService{repo: repo, books: books}
+}
+
+// Import 逐条读取并导入记录。单条记录出错时记入报告并继续；
+// 源文件无法继续读取或 ctx 被取消时停止，并返回已处理部分的报告
+func (s *CatalogService) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*dto.ImportReport, error) {
+	reader, err := bookio.NewReader(opts.Format, r, opts.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+	}
+	every := opts.ProgressEvery
+	if every <= 0 {
+		every = defaultProgressEvery
+	}
+
+	report := &dto.ImportReport{DryRun: opts.DryRun, Errors: []dto.ImportRowError{}}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var recordErr *bookio.RecordError
+		if stderrors.As(err, &recordErr) {
+			report.Processed++
+			addImportError(report, recordErr.Position, "", recordErr.Err)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+		}
+
+		report.Processed++
+		outcome, err := s.importRecord(ctx, record, opts)
+		if err != nil {
+			addImportError(report, record.Position, record.ISBN, err)
+		} else {
+			switch outcome {
+			case outcomeCreated:
+				report.Created++
+			case outcomeUpdated:
+				report.Updated++
+			default:
+				report.Unchanged++
+			}
+		}
+
+		if opts.Progress != nil && report.Processed%every == 0 {
+			opts.Progress(*report)
+		}
+	}
+	return report, nil
+}
+
+func addImportError(report *dto.ImportReport, position int, isbn string, err error) {
+	report.Failed++
+	if len(report.Errors) >= maxReportErrors {
+		report.ErrorsTruncated = true
+		return
+	}
+	report.Errors = append(report.Errors, dto.ImportRowError{Position: position, ISBN: isbn, Error: err.Error()})
+}
+
+// importRecord 按 ISBN 新增或更新一本图书；更新时只覆盖记录中非空的字段。
+// 写入经由 BookService 完成，与手动编辑一样校验字段、关联责任者并记录修订
+func (s *CatalogService) importRecord(ctx context.Context, record *bookio.Record, opts ImportOptions) (importOutcome, error) {
+	isbn, err := entities.ParseISBN(record.ISBN)
+	if err != nil {
+		return 0, err
+	}
+	if err := normalizeImportRecord(record); err != nil {
+		return 0, err
+	}
+	pageCount, err := parseImportPageCount(record.PageCount)
+	if err != nil {
+		return 0, err
+	}
+
+	book, err := s.repo.GetBookByISBN(isbn.String())
+	if err != nil {
+		if record.Title == "" {
+			return 0, fmt.Errorf("missing %s", bookio.FieldTitle)
+		}
+		if record.Author == "" {
+			return 0, fmt.Errorf("missing %s", bookio.FieldAuthor)
+		}
+		if opts.DryRun {
+			return outcomeCreated, nil
+		}
+
+		_, err := s.books.createBook(ctx, opts.ActorID, &dto.CreateBookRequest{
+			ISBN:        isbn.String(),
+			Title:       record.Title,
+			Author:      record.Author,
+			Publisher:   record.Publisher,
+			PublishDate: record.PublishDate,
+			CoverURL:    record.CoverURL,
+			Subtitle:    record.Subtitle,
+			Edition:     record.Edition,
+			PageCount:   pageCount,
+			Description: record.Description,
+		})
+		return outcomeCreated, err
+	}
+
+	req := dto.ToUpdateBookRequest(book)
+	changed := false
+	apply := func(target *string, value string) {
+		if value != "" && *target != value {
+			*target = value
+			changed = true
+		}
+	}
+	apply(&req.Title, record.Title)
+	apply(&req.Publisher, record.Publisher)
+	apply(&req.PublishDate, record.PublishDate)
+	apply(&req.CoverURL, record.CoverURL)
+	apply(&req.Subtitle, record.Subtitle)
+	apply(&req.Edition, record.Edition)
+	apply(&req.Description, record.Description)
+	if pageCount != 0 && req.PageCount != pageCount {
+		req.PageCount = pageCount
+		changed = true
+	}
+	// 只是分隔符不同的作者文本不算修改，避免重新关联责任者
+	if record.Author != "" && !sameAuthors(record.Author, book.Author) {
+		req.Author = record.Author
+		changed = true
+	}
+
+	if !changed {
+		return outcomeUnchanged, nil
+	}
+	if opts.DryRun {
+		return outcomeUpdated, nil
+	}
+	if _, err := s.books.updateBook(opts.ActorID, int(book.ID), req); err != nil {
+		return 0, err
+	}
+	return outcomeUpdated, nil
+}
+
+// sameAuthors 比较拆分后的责任者姓名，忽略分隔符的差异
+func sameAuthors(a, b string) bool {
+	left, right := entities.SplitAuthorNames(a), entities.SplitAuthorNames(b)
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if left[i] != right[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeImportRecord 规范化出版日期并校验各字段的长度和格式
+func normalizeImportRecord(record *bookio.Record) error {
+	if !entities.IsValidPublishDate(record.PublishDate) {
+		normalized := entities.NormalizePublishDate(record.PublishDate)
+		if normalized == "" {
+			return fmt.Errorf("invalid %s %q", bookio.FieldPublishDate, record.PublishDate)
+		}
+		record.PublishDate = normalized
+	}
+
+	limits := []struct {
+		field string
+		max   int
+	}{
+		{bookio.FieldTitle, maxBookTextLength},
+		{bookio.FieldSubtitle, maxBookTextLength},
+		{bookio.FieldAuthor, maxBookTextLength},
+		{bookio.FieldPublisher, maxBookTextLength},
+		{bookio.FieldEdition, maxEditionLength},
+		{bookio.FieldDescription, maxDescriptionLength},
+	}
+	for _, limit := range limits {
+		if utf8.RuneCountInString(record.Get(limit.field)) > limit.max {
+			return fmt.Errorf("%s exceeds %d characters", limit.field, limit.max)
+		}
+	}
+	if record.CoverURL != "" {
+		u, err := url.Parse(record.CoverURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(record.CoverURL) > maxCoverURLLength {
+			return fmt.Errorf("invalid %s", bookio.FieldCoverURL)
+		}
+	}
+	return nil
+}
+
+// parseImportPageCount 解析页数，空值表示记录中没有页数
+func parseImportPageCount(text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(text)
+	if err != nil || count < 0 || count > maxPageCount {
+		return 0, fmt.Errorf("invalid %s %q", bookio.FieldPageCount, text)
+	}
+	return count, nil
+}
+
+// Export 按主键顺序分批读取全部图书并逐条写出，返回写出的记录数
+func (s *CatalogService) Export(ctx context.Context, w io.Writer, format bookio.Format, mapping bookio.Mapping) (int, error) {
+	writer, err := bookio.NewWriter(format, w, mapping)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+	}
+
+	count := 0
+	err = s.repo.ForEachBook(exportBatchSize, func(books []entities.Book) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, book := range books {
+			record := &bookio.Record{
+				ID:          book.ID,
+				ISBN:        book.ISBN,
+				Title:       book.Title,
+				Author:      strings.Join(entities.SplitAuthorNames(book.Author), "; "),
+				Publisher:   book.Publisher,
+				PublishDate: book.PublishDate,
+				CoverURL:    book.CoverURL,
+				Subtitle:    book.Subtitle,
+				Edition:     book.Edition,
+				Description: book.Description,
+			}
+			if book.PageCount > 0 {
+				record.PageCount = strconv.Itoa(book.PageCount)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+// CatalogMapping 在格式的默认映射上应用自定义映射
+func CatalogMapping(format bookio.Format, overrides map[string]string) (bookio.Mapping, error) {
+	mapping, err := bookio.DefaultMapping(format).WithOverrides(overrides)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidInput, err)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+type CategoryService struct {
+	repo repository.Repository
+}
+
+func NewCategoryService(repo repository.Repository) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+// Tree 返回完整的分类树
+func (s *CategoryService) Tree() ([]dto.CategoryResponse, error) {
+	categories, err := s.repo.ListCategories()
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToCategoryTree(categories), nil
+}
+
+func (s *CategoryService) CreateCategory(req *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	parent, err := s.findParent(req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category := &entities.Category{Name: req.Name}
+	if err := s.repo.CreateCategory(category, parent); err != nil {
+		return nil, err
+	}
+	return dto.ToCategoryResponse(category), nil
+}
+
+// UpdateCategory 重命名分类，ParentID 变化时把分类连同子树移动到新的父分类下
+func (s *CategoryService) UpdateCategory(id int, req *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	category, err := s.repo.GetCategory(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	parent, err := s.findParent(req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil && category.IsAncestorOf(parent) {
+		return nil, entities.ErrCategoryCycle
+	}
+
+	oldPath := category.Path
+	category.Name = req.Name
+	category.BuildPath(parent)
+	if err := s.repo.UpdateCategory(category, oldPath); err != nil {
+		return nil, err
+	}
+	return dto.ToCategoryResponse(category), nil
+}
+
+func (s *CategoryService) DeleteCategory(id int) error {
+	if _, err := s.repo.GetCategory(id); err != nil {
+		return errors.ErrNotFound
+	}
+	return s.repo.DeleteCategory(id)
+}
+
+func (s *CategoryService) findParent(parentID *uint) (*entities.Category, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	parent, err := s.repo.GetCategory(int(*parentID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return parent, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// CirculationService 负责馆藏副本管理以及借出、归还、续借
+type CirculationService struct {
+	repo        repository.Repository
+	memberships *MembershipService
+	publisher   events.Publisher
+	loanPeriod  time.Duration
+	maxRenewals int
+}
+
+func NewCirculationService(repo repository.Repository, memberships *MembershipService, cfg config.CirculationConfig, publisher events.Publisher) *CirculationService {
+	loanDays := cfg.LoanDays
+	if loanDays <= 0 {
+		loanDays = 14
+	}
+	return &CirculationService{
+		repo:        repo,
+		memberships: memberships,
+		publisher:   publisher,
+		loanPeriod:  time.Duration(loanDays) * 24 * time.Hour,
+		maxRenewals: cfg.MaxRenewals,
+	}
+}
+
+func (s *CirculationService) AddCopy(bookID int, req *dto.CreateBookCopyRequest) (*dto.BookCopyResponse, error) {
+	if _, err := s.repo.GetBook(bookID); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if _, err := s.repo.GetBookCopyByBarcode(req.Barcode); err == nil {
+		return nil, errors.ErrBarcodeAlreadyExists
+	}
+
+	if req.Condition == "" {
+		req.Condition = entities.CopyConditionGood
+	}
+	if !entities.IsValidCopyCondition(req.Condition) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	branch, err := s.copyBranch(req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookCopy := dto.ToBookCopyEntity(uint(bookID), req)
+	bookCopy.BranchID = branch.ID
+	if err := s.repo.CreateBookCopy(bookCopy); err != nil {
+		return nil, err
+	}
+	bookCopy.BranchName = branch.Name
+	s.publishAvailable(bookCopy)
+
+	return dto.ToBookCopyResponse(bookCopy), nil
+}
+
+func (s *CirculationService) ListCopies(bookID int) ([]dto.BookCopyResponse, error) {
+	if _, err := s.repo.GetBook(bookID); err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	copies, err := s.repo.ListBookCopies(bookID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToBookCopyResponseList(copies), nil
+}
+
+// UpdateCopy 修改副本品相或状态，借出中的副本只能通过归还流程改变状态
+func (s *CirculationService) UpdateCopy(id int, req *dto.UpdateBookCopyRequest) (*dto.BookCopyResponse, error) {
+	bookCopy, err := s.repo.GetBookCopy(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	if req.Condition != nil {
+		if !entities.IsValidCopyCondition(*req.Condition) {
+			return nil, errors.ErrInvalidInput
+		}
+		bookCopy.Condition = *req.Condition
+	}
+
+	becameAvailable := false
+	if req.Status != nil && *req.Status != bookCopy.Status {
+		if !entities.IsValidCopyStatus(*req.Status) {
+			return nil, errors.ErrInvalidInput
+		}
+		switch bookCopy.Status {
+		case entities.CopyStatusOnLoan:
+			return nil, entities.ErrCopyAlreadyOnLoan
+		case entities.CopyStatusOnHold:
+			return nil, entities.ErrCopyNotAvailable
+		case entities.CopyStatusInTransit:
+			return nil, entities.ErrCopyInTransit
+		}
+		bookCopy.Status = *req.Status
+		becameAvailable = bookCopy.IsAvailable()
+	}
+
+	if err := s.repo.UpdateBookCopy(bookCopy); err != nil {
+		return nil, err
+	}
+	if becameAvailable {
+		s.publishAvailable(bookCopy)
+	}
+	return dto.ToBookCopyResponse(bookCopy), nil
+}
+
+// Checkout 借出副本；普通用户只能为自己借书，馆员可以代读者办理
+func (s *CirculationService) Checkout(actorID uint, actorRole string, req *dto.CheckoutRequest) (*dto.LoanResponse, error) {
+	borrowerID := actorID
+	if req.UserID != 0 && req.UserID != actorID {
+		if !entities.IsStaffRole(actorRole) {
+			return nil, errors.ErrForbidden
+		}
+		if _, err := s.repo.GetUser(int(req.UserID)); err != nil {
+			return nil, errors.ErrNotFound
+		}
+		borrowerID = req.UserID
+	}
+	maxLoans, err := s.memberships.CheckBorrow(borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookCopy, err := s.findCopy(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loan := entities.NewLoan(bookCopy, borrowerID, now, s.loanPeriod)
+	if err := s.repo.CheckoutCopy(loan, maxLoans); err != nil {
+		return nil, err
+	}
+
+	logger.Info("copy checked out",
+		zap.Uint("loan_id", loan.ID),
+		zap.Uint("copy_id", loan.CopyID),
+		zap.Uint("user_id", loan.UserID),
+	)
+	return dto.ToLoanResponse(loan, now), nil
+}
+
+func (s *CirculationService) Return(actorID uint, actorRole string, loanID int) (*dto.LoanResponse, error) {
+	loan, err := s.getLoanFor(actorID, actorRole, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := loan.Return(now); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReturnLoan(loan); err != nil {
+		return nil, err
+	}
+
+	logger.Info("copy returned",
+		zap.Uint("loan_id", loan.ID),
+		zap.Uint("copy_id", loan.CopyID),
+		zap.Bool("overdue", now.After(loan.DueAt)),
+	)
+	s.publisher.Publish(events.CopyAvailable{CopyID: loan.CopyID, BookID: loan.BookID})
+	return dto.ToLoanResponse(loan, now), nil
+}
+
+func (s *CirculationService) Renew(actorID uint, actorRole string, loanID int) (*dto.LoanResponse, error) {
+	loan, err := s.getLoanFor(actorID, actorRole, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := loan.Renew(now, s.loanPeriod, s.maxRenewals); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenewLoan(loan); err != nil {
+		return nil, err
+	}
+
+	return dto.ToLoanResponse(loan, now), nil
+}
+
+func (s *CirculationService) ListUserLoans(userID uint, activeOnly bool) ([]dto.LoanResponse, error) {
+	loans, err := s.repo.ListLoansByUser(userID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToLoanResponseList(loans, time.Now()), nil
+}
+
+func (s *CirculationService) findCopy(req *dto.CheckoutRequest) (*entities.BookCopy, error) {
+	var (
+		bookCopy *entities.BookCopy
+		err      error
+	)
+	switch {
+	case req.CopyID != 0:
+		bookCopy, err = s.repo.GetBookCopy(int(req.CopyID))
+	case req.Barcode != "":
+		bookCopy, err = s.repo.GetBookCopyByBarcode(req.Barcode)
+	default:
+		return nil, errors.ErrInvalidInput
+	}
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return bookCopy, nil
+}
+
+// copyBranch 新副本所属的分馆，未指定时使用 ID 最小的分馆
+func (s *CirculationService) copyBranch(branchID uint) (*entities.Branch, error) {
+	if branchID != 0 {
+		branch, err := s.repo.GetBranch(int(branchID))
+		if err != nil {
+			return nil, errors.ErrNotFound
+		}
+		return branch, nil
+	}
+	branches, err := s.repo.ListBranches()
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, fmt.Errorf("%w: create a branch before adding copies", errors.ErrInvalidInput)
+	}
+	return &branches[0], nil
+}
+
+func (s *CirculationService) publishAvailable(bookCopy *entities.BookCopy) {
+	s.publisher.Publish(events.CopyAvailable{CopyID: bookCopy.ID, BookID: bookCopy.BookID})
+}
+
+// getLoanFor 读取借阅并校验操作者是借阅人本人或馆员
+func (s *CirculationService) getLoanFor(actorID uint, actorRole string, loanID int) (*entities.Loan, error) {
+	loan, err := s.repo.GetLoan(loanID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if loan.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return nil, errors.ErrForbidden
+	}
+	return loan, nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"regexp"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/domain/storage"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/imaging"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxCoverSize   = 5 << 20
+	coverThumbnailQuality = 85
+)
+
+// coverVersionPattern 封面版本为内容摘要的前 16 位十六进制
+var coverVersionPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// CoverService 负责图书封面的上传、下载、缩略图生成和读取。
+// 封面按 covers/{图书ID}/{版本}/{文件名} 保存，版本由原图内容决定，同一版本的文件不会再变化
+type CoverService struct {
+	repo    repository.Repository
+	store   storage.ObjectStorage
+	fetcher storage.Fetcher
+	maxSize int64
+}
+
+func NewCoverService(repo repository.Repository, store storage.ObjectStorage, fetcher storage.Fetcher, cfg config.CoverConfig) *CoverService {
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxCoverSize
+	}
+	return &CoverService{repo: repo, store: store, fetcher: fetcher, maxSize: maxSize}
+}
+
+// Upload 保存上传的封面原图并生成缩略图，替换图书原有的封面
+func (s *CoverService) Upload(ctx context.Context, bookID int, body io.Reader) (*dto.BookCoverResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	return s.save(ctx, book, data)
+}
+
+// Fetch 从 URL 下载封面，未指定 URL 时使用图书的 cover_url
+func (s *CoverService) Fetch(ctx context.Context, bookID int, req *dto.FetchCoverRequest) (*dto.BookCoverResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	source := req.URL
+	if source == "" {
+		source = book.CoverURL
+	}
+	if source == "" {
+		return nil, fmt.Errorf("%w: book has no cover_url", errors.ErrInvalidInput)
+	}
+
+	data, err := s.fetcher.Fetch(ctx, source, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return s.save(ctx, book, data)
+}
+
+// Delete 移除图书的封面
+func (s *CoverService) Delete(ctx context.Context, bookID int) error {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return errors.ErrNotFound
+	}
+	if !book.HasCover() {
+		return nil
+	}
+	if err := s.repo.SetBookCover(book.ID, "", ""); err != nil {
+		return err
+	}
+	s.removeFiles(ctx, book)
+	return nil
+}
+
+// Open 读取封面文件，版本或文件名不合法时按不存在处理
+func (s *CoverService) Open(ctx context.Context, bookID int, version, name string) (*storage.Object, error) {
+	if !coverVersionPattern.MatchString(version) || !isCoverFileName(name) {
+		return nil, errors.ErrNotFound
+	}
+	object, err := s.store.Get(ctx, coverKey(uint(bookID), version, name))
+	if err != nil {
+		if stderrors.Is(err, storage.ErrObjectNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// HandleBookDeleted 图书删除后清理其封面文件
+func (s *CoverService) HandleBookDeleted(event events.Event) error {
+	deleted, ok := event.(events.BookDeleted)
+	if !ok || !deleted.Book.HasCover() {
+		return nil
+	}
+	s.removeFiles(context.Background(), &deleted.Book)
+	return nil
+}
+
+// save 校验图片、保存原图和各尺寸缩略图，然后把图书指向新版本并清理旧版本的文件
+func (s *CoverService) save(ctx context.Context, book *entities.Book, data []byte) (*dto.BookCoverResponse, error) {
+	if int64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("%w: limit is %d bytes", entities.ErrCoverTooLarge, s.maxSize)
+	}
+
+	// 先只解析头部检查格式和尺寸，避免为超大图片分配内存
+	header, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, entities.ErrUnsupportedCoverImage
+	}
+	if _, ok := entities.CoverFormats[format]; !ok {
+		return nil, fmt.Errorf("%w: format %s", entities.ErrUnsupportedCoverImage, format)
+	}
+	if header.Width < entities.MinCoverDimension || header.Height < entities.MinCoverDimension ||
+		header.Width > entities.MaxCoverDimension || header.Height > entities.MaxCoverDimension {
+		return nil, fmt.Errorf("%w: %dx%d is outside %d-%d pixels", entities.ErrUnsupportedCoverImage,
+			header.Width, header.Height, entities.MinCoverDimension, entities.MaxCoverDimension)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, entities.ErrUnsupportedCoverImage
+	}
+
+	sum := sha256.Sum256(data)
+	previous := *book
+	book.CoverImage = hex.EncodeToString(sum[:])[:16]
+	book.CoverFormat = format
+	if book.CoverImage == previous.CoverImage {
+		return dto.ToBookCoverResponse(book), nil
+	}
+
+	if err := s.writeFiles(ctx, book, data, img); err != nil {
+		s.removeFiles(ctx, book)
+		return nil, err
+	}
+	if err := s.repo.SetBookCover(book.ID, book.CoverImage, book.CoverFormat); err != nil {
+		s.removeFiles(ctx, book)
+		return nil, err
+	}
+	if previous.HasCover() {
+		s.removeFiles(ctx, &previous)
+	}
+	return dto.ToBookCoverResponse(book), nil
+}
+
+// writeFiles 保存原图和全部缩略图
+func (s *CoverService) writeFiles(ctx context.Context, book *entities.Book, data []byte, img image.Image) error {
+	original := book.CoverFileName(entities.CoverOriginal)
+	if err := s.store.Put(ctx, coverKey(book.ID, book.CoverImage, original), bytes.NewReader(data), "image/"+book.CoverFormat); err != nil {
+		return err
+	}
+
+	for _, spec := range entities.CoverThumbnails {
+		thumbnail := imaging.Thumbnail(img, spec.MaxWidth, spec.MaxHeight, color.White)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: coverThumbnailQuality}); err != nil {
+			return err
+		}
+		key := coverKey(book.ID, book.CoverImage, book.CoverFileName(spec.Size))
+		if err := s.store.Put(ctx, key, &buf, "image/jpeg"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFiles 删除图书当前封面版本的全部文件，失败时只记录日志
+func (s *CoverService) removeFiles(ctx context.Context, book *entities.Book) {
+	names := []string{book.CoverFileName(entities.CoverOriginal)}
+	for _, spec := range entities.CoverThumbnails {
+		names = append(names, book.CoverFileName(spec.Size))
+	}
+	for _, name := range names {
+		if err := s.store.Delete(ctx, coverKey(book.ID, book.CoverImage, name)); err != nil {
+			logger.Warn("failed to delete cover file",
+				zap.Uint("book_id", book.ID),
+				zap.String("file", name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func coverKey(bookID uint, version, name string) string {
+	return fmt.Sprintf("covers/%d/%s/%s", bookID, version, name)
+}
+
+// isCoverFileName 文件名是否为某个缩略图或某种格式的原图
+func isCoverFileName(name string) bool {
+	for _, spec := range entities.CoverThumbnails {
+		if name == spec.Size+".jpg" {
+			return true
+		}
+	}
+	for _, extension := range entities.CoverFormats {
+		if name == entities.CoverOriginal+"."+extension {
+			return true
+		}
+	}
+	return false
+}
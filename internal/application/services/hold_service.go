@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// HoldService 负责预约排队、到书通知和预约架过期处理
+type HoldService struct {
+	repo        repository.Repository
+	memberships *MembershipService
+	publisher   events.Publisher
+	shelfPeriod time.Duration
+}
+
+func NewHoldService(repo repository.Repository, memberships *MembershipService, cfg config.CirculationConfig, publisher events.Publisher) *HoldService {
+	shelfDays := cfg.HoldShelfDays
+	if shelfDays <= 0 {
+		shelfDays = 7
+	}
+	return &HoldService{
+		repo:        repo,
+		memberships: memberships,
+		publisher:   publisher,
+		shelfPeriod: time.Duration(shelfDays) * 24 * time.Hour,
+	}
+}
+
+// PlaceHold 为书目排队；仍有可借副本时无需预约
+func (s *HoldService) PlaceHold(userID uint, bookID int) (*dto.HoldResponse, error) {
+	if _, err := s.repo.GetBook(bookID); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if err := s.memberships.CheckHold(userID); err != nil {
+		return nil, err
+	}
+
+	availability, err := s.repo.GetCopyAvailability([]uint{uint(bookID)})
+	if err != nil {
+		return nil, err
+	}
+	if availability[uint(bookID)].Available > 0 {
+		return nil, entities.ErrHoldNotNeeded
+	}
+
+	hold := entities.NewHold(uint(bookID), userID, time.Now())
+	if err := s.repo.PlaceHold(hold); err != nil {
+		return nil, err
+	}
+
+	logger.Info("hold placed", zap.Uint("hold_id", hold.ID), zap.Uint("book_id", hold.BookID), zap.Uint("user_id", userID))
+	return s.toResponse(hold)
+}
+
+// CancelHold 取消预约；已到书的预约取消后副本转给下一位
+func (s *HoldService) CancelHold(actorID uint, actorRole string, holdID int) (*dto.HoldResponse, error) {
+	hold, err := s.repo.GetHold(holdID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if hold.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return nil, errors.ErrForbidden
+	}
+
+	if err := s.close(hold, entities.HoldStatusCancelled); err != nil {
+		return nil, err
+	}
+	return dto.ToHoldResponse(hold), nil
+}
+
+func (s *HoldService) ListUserHolds(userID uint, activeOnly bool) ([]dto.HoldResponse, error) {
+	holds, err := s.repo.ListHoldsByUser(userID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.HoldResponse, 0, len(holds))
+	for i := range holds {
+		response, err := s.toResponse(&holds[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// PickupList 馆员视角的预约架清单，按取书期限排序
+func (s *HoldService) PickupList() ([]dto.PickupItemResponse, error) {
+	holds, err := s.repo.ListReadyHolds()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.PickupItemResponse, 0, len(holds))
+	for i := range holds {
+		item := dto.PickupItemResponse{HoldResponse: *dto.ToHoldResponse(&holds[i])}
+		if holds[i].CopyID != nil {
+			if bookCopy, err := s.repo.GetBookCopy(int(*holds[i].CopyID)); err == nil {
+				item.Barcode = bookCopy.Barcode
+			}
+		}
+		if book, err := s.repo.GetBook(int(holds[i].BookID)); err == nil {
+			item.BookTitle = book.Title
+		}
+		if user, err := s.repo.GetUser(int(holds[i].UserID)); err == nil {
+			item.UserName = user.Name
+			item.UserEmail = user.Email
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// HandleCopyAvailable 副本可借时分配给队首预约
+func (s *HoldService) HandleCopyAvailable(event events.Event) error {
+	e, ok := event.(events.CopyAvailable)
+	if !ok {
+		return nil
+	}
+
+	hold, err := s.repo.AssignCopyToNextHold(e.CopyID, time.Now(), s.shelfPeriod)
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		logger.Info("hold ready for pickup",
+			zap.Uint("hold_id", hold.ID),
+			zap.Uint("copy_id", e.CopyID),
+			zap.Uint("user_id", hold.UserID),
+			zap.Timep("expires_at", hold.ExpiresAt),
+		)
+	}
+	return nil
+}
+
+// ExpireHolds 定时任务：关闭超过取书期限的预约并释放副本
+func (s *HoldService) ExpireHolds(ctx context.Context) error {
+	holds, err := s.repo.ListExpiredHolds(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range holds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.close(&holds[i], entities.HoldStatusExpired); err != nil {
+			logger.Warn("failed to expire hold", zap.Uint("hold_id", holds[i].ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *HoldService) close(hold *entities.Hold, status string) error {
+	if err := hold.Close(status, time.Now()); err != nil {
+		return err
+	}
+	if err := s.repo.CloseHold(hold); err != nil {
+		return err
+	}
+
+	if hold.CopyID != nil {
+		s.publisher.Publish(events.CopyAvailable{CopyID: *hold.CopyID, BookID: hold.BookID})
+	}
+	return nil
+}
+
+func (s *HoldService) toResponse(hold *entities.Hold) (*dto.HoldResponse, error) {
+	response := dto.ToHoldResponse(hold)
+	if hold.Status == entities.HoldStatusWaiting {
+		position, err := s.repo.GetHoldQueuePosition(hold)
+		if err != nil {
+			return nil, err
+		}
+		response.QueuePosition = position
+	}
+	return response, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+	"github.com/azel-ko/final-ddd/internal/pkg/labelsheet"
+)
+
+const (
+	defaultLabelColumns = 3
+	defaultLabelRows    = 8
+)
+
+// LabelService 生成书脊和副本标签：ISBN 印 EAN-13，内部编号和副本条码印 Code 128 或 QR 码
+type LabelService struct {
+	repo repository.Repository
+}
+
+func NewLabelService(repo repository.Repository) *LabelService {
+	return &LabelService{repo: repo}
+}
+
+// BookBarcode 图书 ISBN 的 EAN-13 条码
+func (s *LabelService) BookBarcode(bookID int, query *dto.BarcodeImageQuery) (*dto.LabelFile, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	code, err := encodeLabel(barcode.KindEAN13, book.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	return renderLabel(code, query.Format, query.Scale, fmt.Sprintf("book-%d-isbn", book.ID))
+}
+
+// BookLabel 图书内部编号标签
+func (s *LabelService) BookLabel(bookID int, query *dto.LabelImageQuery) (*dto.LabelFile, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	code, err := encodeLabel(labelSymbology(query.Symbology), entities.BookLabelCode(book.ID))
+	if err != nil {
+		return nil, err
+	}
+	return renderLabel(code, query.Format, query.Scale, fmt.Sprintf("book-%d-label", book.ID))
+}
+
+// CopyLabel 副本条码标签，内容为副本登记的条码号
+func (s *LabelService) CopyLabel(copyID int, query *dto.LabelImageQuery) (*dto.LabelFile, error) {
+	bookCopy, err := s.repo.GetBookCopy(copyID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	code, err := encodeLabel(labelSymbology(query.Symbology), bookCopy.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	return renderLabel(code, query.Format, query.Scale, fmt.Sprintf("copy-%d-label", bookCopy.ID))
+}
+
+// Sheet 按请求中图书的顺序排版标签，输出 PDF
+func (s *LabelService) Sheet(req *dto.LabelSheetRequest) (*dto.LabelFile, error) {
+	symbology := req.Symbology
+	if symbology == "" {
+		symbology = barcode.KindEAN13
+	}
+
+	var labels []labelsheet.Label
+	for _, id := range req.BookIDs {
+		book, err := s.repo.GetBook(int(id))
+		if err != nil {
+			return nil, fmt.Errorf("%w: book %d", errors.ErrNotFound, id)
+		}
+
+		if req.PerCopy {
+			copies, err := s.repo.ListBookCopies(int(book.ID))
+			if err != nil {
+				return nil, err
+			}
+			for _, bookCopy := range copies {
+				// 副本条码号不是 EAN，ean13 时改用 Code 128
+				code, err := encodeLabel(labelSymbology(symbology), bookCopy.Barcode)
+				if err != nil {
+					return nil, err
+				}
+				labels = append(labels, labelsheet.Label{Code: code, Lines: []string{book.Title, bookCopy.Barcode}})
+			}
+			continue
+		}
+
+		content := entities.BookLabelCode(book.ID)
+		if symbology == barcode.KindEAN13 {
+			content = book.ISBN
+		}
+		code, err := encodeLabel(symbology, content)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, labelsheet.Label{Code: code, Lines: []string{book.Title, book.Author, code.Text}})
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("%w: the selected books have no copies", errors.ErrInvalidInput)
+	}
+
+	layout := labelsheet.Layout{Paper: labelsheet.PaperA4, Columns: req.Columns, Rows: req.Rows}
+	if req.Paper == "letter" {
+		layout.Paper = labelsheet.PaperLetter
+	}
+	if layout.Columns == 0 {
+		layout.Columns = defaultLabelColumns
+	}
+	if layout.Rows == 0 {
+		layout.Rows = defaultLabelRows
+	}
+
+	var buf bytes.Buffer
+	if err := labelsheet.Write(&buf, layout, labels); err != nil {
+		return nil, err
+	}
+	return &dto.LabelFile{ContentType: "application/pdf", Filename: "labels.pdf", Data: buf.Bytes()}, nil
+}
+
+// labelSymbology 内部编号只能用 Code 128 或 QR 码，默认 Code 128
+func labelSymbology(symbology string) string {
+	if symbology == barcode.KindQR {
+		return barcode.KindQR
+	}
+	return barcode.KindCode128
+}
+
+// encodeLabel 编码失败说明内容不适合该码制，按输入错误返回
+func encodeLabel(kind, content string) (*barcode.Barcode, error) {
+	code, err := barcode.Encode(kind, content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", errors.ErrInvalidInput, content, err)
+	}
+	return code, nil
+}
+
+// renderLabel 按请求的格式输出条码图片，默认 PNG
+func renderLabel(code *barcode.Barcode, format string, scale int, name string) (*dto.LabelFile, error) {
+	opts := barcode.RenderOptions{Scale: scale}
+	var buf bytes.Buffer
+	if format == "svg" {
+		if err := code.WriteSVG(&buf, opts); err != nil {
+			return nil, err
+		}
+		return &dto.LabelFile{ContentType: "image/svg+xml", Filename: name + ".svg", Data: buf.Bytes()}, nil
+	}
+	if err := png.Encode(&buf, code.Image(opts)); err != nil {
+		return nil, err
+	}
+	return &dto.LabelFile{ContentType: "image/png", Filename: name + ".png", Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// membershipExpiryBatchSize 每批取出的宽限期已过的会员资格数
+const membershipExpiryBatchSize = 200
+
+// MembershipService 会员计划和读者的会员资格。宽限期结束后定时任务把读者降为受限角色，续期后恢复；
+// 借阅、预约和电子书下载前由对应的服务调用 Check* 校验权益
+type MembershipService struct {
+	repo     repository.Repository
+	required bool
+}
+
+func NewMembershipService(repo repository.Repository, cfg config.MembershipConfig) *MembershipService {
+	return &MembershipService{repo: repo, required: cfg.Required}
+}
+
+// ListPlans 会员计划，只有馆员可以列出已停用的
+func (s *MembershipService) ListPlans(actorRole string, query *dto.MembershipPlanListQuery) ([]dto.MembershipPlanResponse, error) {
+	plans, err := s.repo.ListMembershipPlans(!(query.All && entities.IsStaffRole(actorRole)))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.MembershipPlanResponse, len(plans))
+	for i := range plans {
+		items[i] = dto.ToMembershipPlanResponse(&plans[i])
+	}
+	return items, nil
+}
+
+func (s *MembershipService) CreatePlan(req *dto.MembershipPlanRequest) (*dto.MembershipPlanResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	if _, err := s.repo.GetMembershipPlanByName(name); err == nil {
+		return nil, entities.ErrMembershipPlanNameTaken
+	}
+
+	plan := &entities.MembershipPlan{Active: true}
+	applyPlanRequest(plan, name, req)
+	active := plan.Active
+	if err := s.repo.CreateMembershipPlan(plan); err != nil {
+		return nil, err
+	}
+	if !active {
+		// 新建时 active 为零值会被写成列的默认值 true，停用需要再保存一次
+		plan.Active = false
+		if err := s.repo.UpdateMembershipPlan(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("membership plan created", zap.Uint("plan_id", plan.ID), zap.String("name", plan.Name))
+	response := dto.ToMembershipPlanResponse(plan)
+	return &response, nil
+}
+
+func (s *MembershipService) UpdatePlan(id int, req *dto.MembershipPlanRequest) (*dto.MembershipPlanResponse, error) {
+	plan, err := s.repo.GetMembershipPlan(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	if existing, err := s.repo.GetMembershipPlanByName(name); err == nil && existing.ID != plan.ID {
+		return nil, entities.ErrMembershipPlanNameTaken
+	}
+
+	applyPlanRequest(plan, name, req)
+	if err := s.repo.UpdateMembershipPlan(plan); err != nil {
+		return nil, err
+	}
+	response := dto.ToMembershipPlanResponse(plan)
+	return &response, nil
+}
+
+// DeletePlan 删除没有会员使用的计划，仍在使用的只能停用
+func (s *MembershipService) DeletePlan(id int) error {
+	plan, err := s.repo.GetMembershipPlan(id)
+	if err != nil {
+		return errors.ErrNotFound
+	}
+	return s.repo.DeleteMembershipPlan(plan.ID)
+}
+
+// Get 读者的会员资格，没有办理过时返回 ErrNotFound
+func (s *MembershipService) Get(userID uint) (*dto.MembershipResponse, error) {
+	membership, err := s.repo.GetMembershipByUser(userID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return s.toResponse(membership)
+}
+
+// List 会员资格列表，按到期时间排列，即将到期的在前
+func (s *MembershipService) List(page, pageSize int, query *dto.MembershipListQuery) (*dto.PaginatedMembershipResponse, error) {
+	now := time.Now()
+	filter := repository.MembershipFilter{PlanID: query.PlanID, Status: query.Status, Now: now}
+	offset := (page - 1) * pageSize
+	memberships, total, err := s.repo.ListMemberships(filter, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.repo.ListMembershipPlans(false)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*entities.MembershipPlan, len(plans))
+	for i := range plans {
+		byID[plans[i].ID] = &plans[i]
+	}
+	items := make([]dto.MembershipResponse, len(memberships))
+	for i := range memberships {
+		items[i] = dto.ToMembershipResponse(&memberships[i], byID[memberships[i].PlanID], now)
+	}
+	return &dto.PaginatedMembershipResponse{Items: items, Total: total}, nil
+}
+
+// Enroll 为读者办理会员，已有会员资格时返回 ErrMembershipExists
+func (s *MembershipService) Enroll(actorID, userID uint, req *dto.EnrollMembershipRequest) (*dto.MembershipResponse, error) {
+	if _, err := s.repo.GetUser(int(userID)); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if _, err := s.repo.GetMembershipByUser(userID); err == nil {
+		return nil, entities.ErrMembershipExists
+	}
+	plan, err := s.offeredPlan(req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	membership := entities.NewMembership(userID, plan, startsAt)
+	if err := s.repo.SaveMembership(membership, true); err != nil {
+		return nil, err
+	}
+
+	logger.Info("membership enrolled",
+		zap.Uint("membership_id", membership.ID),
+		zap.Uint("user_id", userID),
+		zap.Uint("plan_id", plan.ID),
+		zap.Uint("actor_id", actorID),
+		zap.Time("ends_at", membership.EndsAt),
+	)
+	return s.Get(userID)
+}
+
+// Renew 续期一期，可同时更换计划；被降为受限角色的读者恢复为普通读者
+func (s *MembershipService) Renew(actorID, userID uint, req *dto.RenewMembershipRequest) (*dto.MembershipResponse, error) {
+	membership, err := s.repo.GetMembershipByUser(userID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	planID := req.PlanID
+	if planID == 0 {
+		planID = membership.PlanID
+	}
+	plan, err := s.offeredPlan(planID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Renew(plan, time.Now())
+	if err := s.repo.SaveMembership(membership, true); err != nil {
+		return nil, err
+	}
+
+	logger.Info("membership renewed",
+		zap.Uint("membership_id", membership.ID),
+		zap.Uint("user_id", userID),
+		zap.Uint("plan_id", plan.ID),
+		zap.Uint("actor_id", actorID),
+		zap.Time("ends_at", membership.EndsAt),
+	)
+	return s.Get(userID)
+}
+
+// HandlePaymentCaptured 会员费扣款后为付款的读者办理会员，已有会员资格时按付款的计划续期。
+// 计划在付款期间被停用时办理失败，由馆员退款或手动办理
+func (s *MembershipService) HandlePaymentCaptured(event events.Event) error {
+	e, ok := event.(events.PaymentCaptured)
+	if !ok || e.Payment.Purpose != entities.PaymentPurposeMembership || e.Payment.PlanID == nil {
+		return nil
+	}
+
+	userID, planID := e.Payment.UserID, *e.Payment.PlanID
+	if _, err := s.repo.GetMembershipByUser(userID); err == nil {
+		_, err = s.Renew(userID, userID, &dto.RenewMembershipRequest{PlanID: planID})
+		return err
+	}
+	_, err := s.Enroll(userID, userID, &dto.EnrollMembershipRequest{PlanID: planID})
+	return err
+}
+
+// ExpireMemberships 定时任务：把宽限期已过的读者降为受限角色并发送站内通知
+func (s *MembershipService) ExpireMemberships(ctx context.Context) error {
+	now := time.Now()
+	downgraded := 0
+	// 按 ID 翻页，未能降级的会员资格仍满足查询条件，不能靠重新查询第一页推进
+	var lastID uint
+	for {
+		memberships, err := s.repo.ListLapsedMemberships(now, lastID, membershipExpiryBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range memberships {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			membership := &memberships[i]
+			lastID = membership.ID
+			ok, err := s.repo.DowngradeMembership(membership, now)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			downgraded++
+			notification := &entities.Notification{
+				UserID: membership.UserID,
+				Kind:   entities.NotificationKindMembership,
+				Title:  "Your membership has expired",
+				Body:   fmt.Sprintf("Your membership ended on %s. Renew it to borrow, place holds and download e-books again.", membership.EndsAt.Format("2006-01-02")),
+			}
+			if err := s.repo.CreateNotification(notification); err != nil {
+				logger.Warn("failed to notify expired member", zap.Uint("user_id", membership.UserID), zap.Error(err))
+			}
+		}
+		if len(memberships) < membershipExpiryBatchSize {
+			break
+		}
+	}
+	if downgraded > 0 {
+		logger.Info("expired memberships downgraded", zap.Int("count", downgraded))
+	}
+	return nil
+}
+
+// CheckBorrow 校验读者的会员资格允许借阅，返回计划的在借上限，0 表示不限。
+// 在借数量由 CheckoutCopy 在借出的事务内比对，以免并发借出超出上限
+func (s *MembershipService) CheckBorrow(userID uint) (int, error) {
+	plan, err := s.standing(userID)
+	if err != nil || plan == nil {
+		return 0, err
+	}
+	return plan.MaxLoans, nil
+}
+
+// CheckHold 校验读者可以预约
+func (s *MembershipService) CheckHold(userID uint) error {
+	_, err := s.standing(userID)
+	return err
+}
+
+// CheckDigitalAccess 校验读者可以借阅电子书
+func (s *MembershipService) CheckDigitalAccess(userID uint) error {
+	plan, err := s.standing(userID)
+	if err != nil {
+		return err
+	}
+	if plan != nil && !plan.DigitalAccess {
+		return entities.ErrDigitalAccessNotIncluded
+	}
+	return nil
+}
+
+// standing 读者当前所在的会员计划。馆员不受会员资格限制，返回 nil；
+// 未办理会员的读者在不强制要求会员时也返回 nil，即不限制
+func (s *MembershipService) standing(userID uint) (*entities.MembershipPlan, error) {
+	user, err := s.repo.GetUser(int(userID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if entities.IsStaffRole(user.Role) {
+		return nil, nil
+	}
+	if user.Role == entities.RoleRestricted {
+		return nil, entities.ErrMembershipRequired
+	}
+	membership, err := s.repo.GetMembershipByUser(userID)
+	if err != nil {
+		if s.required {
+			return nil, entities.ErrMembershipRequired
+		}
+		return nil, nil
+	}
+	// 宽限期已过但定时任务尚未降级时同样拒绝
+	if !membership.InGoodStanding(time.Now()) {
+		return nil, entities.ErrMembershipRequired
+	}
+	return s.repo.GetMembershipPlan(int(membership.PlanID))
+}
+
+// offeredPlan 读取仍在办理的计划
+func (s *MembershipService) offeredPlan(id uint) (*entities.MembershipPlan, error) {
+	plan, err := s.repo.GetMembershipPlan(int(id))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if !plan.Active {
+		return nil, entities.ErrMembershipPlanInactive
+	}
+	return plan, nil
+}
+
+func (s *MembershipService) toResponse(membership *entities.Membership) (*dto.MembershipResponse, error) {
+	// 计划不会在仍有会员使用时被删除，读取失败时只是不展示权益
+	plan, _ := s.repo.GetMembershipPlan(int(membership.PlanID))
+	response := dto.ToMembershipResponse(membership, plan, time.Now())
+	return &response, nil
+}
+
+func applyPlanRequest(plan *entities.MembershipPlan, name string, req *dto.MembershipPlanRequest) {
+	plan.Name = name
+	plan.Description = strings.TrimSpace(req.Description)
+	plan.DurationDays = req.DurationDays
+	plan.GraceDays = req.GraceDays
+	plan.MaxLoans = req.MaxLoans
+	plan.DigitalAccess = req.DigitalAccess
+	plan.Fee = req.Fee
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+}
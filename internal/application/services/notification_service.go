@@ -0,0 +1,45 @@
+package services
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+// NotificationService 读者的站内通知
+type NotificationService struct {
+	repo repository.Repository
+}
+
+func NewNotificationService(repo repository.Repository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+// List 用户的通知，最新的在前，同时给出全部未读的条数
+func (s *NotificationService) List(userID uint, page, pageSize int, query *dto.NotificationListQuery) (*dto.PaginatedNotificationResponse, error) {
+	offset := (page - 1) * pageSize
+	notifications, total, err := s.repo.ListNotifications(userID, query.Unread, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnreadNotifications(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.NotificationResponse, len(notifications))
+	for i := range notifications {
+		items[i] = dto.ToNotificationResponse(&notifications[i])
+	}
+	return &dto.PaginatedNotificationResponse{Items: items, Total: total, Unread: unread}, nil
+}
+
+// MarkRead 把指定的通知标记为已读，ids 为空时标记全部；不属于该用户或已读的通知被忽略
+func (s *NotificationService) MarkRead(userID uint, ids []uint) (*dto.MarkNotificationsReadResponse, error) {
+	marked, err := s.repo.MarkNotificationsRead(userID, ids, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkNotificationsReadResponse{Marked: marked}, nil
+}
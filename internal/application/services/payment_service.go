@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// PaymentService 在线支付和读者账户。所有金额按复式记账入账：读者应收款账户记录欠款，
+// 登记费用时借记应收款、贷记收入，扣款时借记渠道资金账户、贷记应收款，退款时反向冲回。
+// 付款人在渠道确认支付后由 webhook 通知授权，随即自动扣款；扣款和退款都带幂等键，重复调用只执行一次
+type PaymentService struct {
+	repo      repository.Repository
+	provider  payment.Provider
+	publisher events.Publisher
+	currency  string
+}
+
+func NewPaymentService(repo repository.Repository, provider payment.Provider, publisher events.Publisher, cfg config.PaymentConfig) *PaymentService {
+	currency := strings.ToUpper(cfg.Currency)
+	if currency == "" {
+		currency = "CNY"
+	}
+	return &PaymentService{repo: repo, provider: provider, publisher: publisher, currency: currency}
+}
+
+// CreateIntent 为读者发起一笔支付，返回的 client_secret 供前端向渠道确认支付
+func (s *PaymentService) CreateIntent(ctx context.Context, userID uint, req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error) {
+	p := &entities.Payment{
+		UserID:   userID,
+		Purpose:  req.Purpose,
+		Currency: s.currency,
+		Provider: s.provider.Name(),
+		Status:   entities.PaymentStatusPending,
+	}
+	description := "Account balance"
+	switch req.Purpose {
+	case entities.PaymentPurposeMembership:
+		if req.PlanID == 0 {
+			return nil, fmt.Errorf("%w: plan_id is required for membership payments", errors.ErrInvalidInput)
+		}
+		plan, err := s.repo.GetMembershipPlan(int(req.PlanID))
+		if err != nil {
+			return nil, errors.ErrNotFound
+		}
+		if !plan.Active {
+			return nil, entities.ErrMembershipPlanInactive
+		}
+		p.PlanID = &plan.ID
+		p.Amount = plan.Fee
+		description = "Membership: " + plan.Name
+	case entities.PaymentPurposeBalance:
+		account, err := s.userAccount(userID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Amount, err = s.repo.LedgerAccountBalance(account.ID); err != nil {
+			return nil, err
+		}
+	}
+	if p.Amount <= 0 {
+		return nil, entities.ErrNothingToPay
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, payment.IntentRequest{
+		Amount:      p.Amount,
+		Currency:    p.Currency,
+		Description: description,
+		Reference:   fmt.Sprintf("%s:user:%d", p.Purpose, userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.ProviderRef = intent.Ref
+	if err := s.repo.CreatePayment(p); err != nil {
+		return nil, err
+	}
+
+	logger.Info("payment intent created",
+		zap.Uint("payment_id", p.ID),
+		zap.Uint("user_id", userID),
+		zap.String("purpose", p.Purpose),
+		zap.Int64("amount", p.Amount),
+		zap.String("provider_ref", p.ProviderRef),
+	)
+	response := dto.ToPaymentResponse(p)
+	response.ClientSecret = intent.ClientSecret
+	return &response, nil
+}
+
+// Get 支付详情及其退款，读者只能查看自己的
+func (s *PaymentService) Get(actorID uint, actorRole string, id int) (*dto.PaymentResponse, error) {
+	p, err := s.repo.GetPayment(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if p.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return nil, errors.ErrNotFound
+	}
+	return s.toResponse(p)
+}
+
+// List 读者的支付记录，按创建时间倒序
+func (s *PaymentService) List(userID uint, page, pageSize int, query *dto.PaymentListQuery) (*dto.PaginatedPaymentResponse, error) {
+	offset := (page - 1) * pageSize
+	payments, total, err := s.repo.ListPayments(repository.PaymentFilter{UserID: userID, Status: query.Status}, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.PaymentResponse, len(payments))
+	for i := range payments {
+		items[i] = dto.ToPaymentResponse(&payments[i])
+	}
+	return &dto.PaginatedPaymentResponse{Items: items, Total: total}, nil
+}
+
+// HandleWebhook 校验并处理渠道的 webhook。重复投递的事件被忽略；处理失败时删除事件记录并返回错误，由渠道重新投递
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+	record := &entities.PaymentEvent{Provider: s.provider.Name(), EventID: event.ID, Type: event.Type}
+	recorded, err := s.repo.RecordPaymentEvent(record)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		logger.Info("duplicate payment webhook ignored", zap.String("event_id", event.ID))
+		return nil
+	}
+
+	if err := s.applyEvent(ctx, event); err != nil {
+		if forgetErr := s.repo.ForgetPaymentEvent(record); forgetErr != nil {
+			logger.Warn("failed to forget payment event", zap.String("event_id", event.ID), zap.Error(forgetErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PaymentService) applyEvent(ctx context.Context, event *payment.Event) error {
+	var status string
+	switch event.Type {
+	case payment.EventAuthorized:
+		status = entities.PaymentStatusAuthorized
+	case payment.EventFailed:
+		status = entities.PaymentStatusFailed
+	default:
+		return nil
+	}
+
+	p, err := s.repo.GetPaymentByProviderRef(s.provider.Name(), event.Ref)
+	if err != nil {
+		// 不是本系统发起的支付意向，重新投递也无法处理
+		logger.Warn("payment webhook for unknown intent", zap.String("event_id", event.ID), zap.String("provider_ref", event.Ref))
+		return nil
+	}
+	if p.Status == entities.PaymentStatusPending {
+		p.Status = status
+		if _, err := s.repo.UpdatePaymentStatus(p, entities.PaymentStatusPending); err != nil {
+			return err
+		}
+		if p, err = s.repo.GetPayment(int(p.ID)); err != nil {
+			return err
+		}
+		logger.Info("payment status changed", zap.Uint("payment_id", p.ID), zap.String("status", p.Status))
+	}
+	if event.Type == payment.EventAuthorized && p.Status == entities.PaymentStatusAuthorized {
+		_, err = s.capture(ctx, p)
+	}
+	return err
+}
+
+// Capture 对已授权的支付扣款并入账，通常在授权的 webhook 中自动完成，自动扣款失败时由馆员重试。
+// 已扣款的支付直接返回当前状态
+func (s *PaymentService) Capture(ctx context.Context, id int) (*dto.PaymentResponse, error) {
+	p, err := s.repo.GetPayment(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if p, err = s.capture(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.toResponse(p)
+}
+
+func (s *PaymentService) capture(ctx context.Context, p *entities.Payment) (*entities.Payment, error) {
+	switch p.Status {
+	case entities.PaymentStatusCaptured, entities.PaymentStatusRefunded:
+		return p, nil
+	case entities.PaymentStatusAuthorized:
+	default:
+		return nil, entities.ErrPaymentNotCapturable
+	}
+
+	// 同一笔支付的扣款始终使用同一个幂等键，并发或重试时渠道只扣一次
+	if err := s.provider.Capture(ctx, p.ProviderRef, p.Amount, fmt.Sprintf("capture-%d", p.ID)); err != nil {
+		return nil, err
+	}
+	posting, err := s.capturePosting(p)
+	if err != nil {
+		return nil, err
+	}
+	captured, err := s.repo.CapturePayment(p, posting)
+	if err != nil {
+		return nil, err
+	}
+	if !captured {
+		// 另一个请求已经入账
+		if p, err = s.repo.GetPayment(int(p.ID)); err != nil {
+			return nil, err
+		}
+		if p.Status != entities.PaymentStatusCaptured && p.Status != entities.PaymentStatusRefunded {
+			return nil, entities.ErrPaymentChanged
+		}
+		return p, nil
+	}
+
+	logger.Info("payment captured",
+		zap.Uint("payment_id", p.ID),
+		zap.Uint("user_id", p.UserID),
+		zap.String("purpose", p.Purpose),
+		zap.Int64("amount", p.Amount),
+	)
+	s.publisher.Publish(events.PaymentCaptured{Payment: *p})
+	return p, nil
+}
+
+// Refund 退回已扣款的全部或部分金额，并冲回对应的费用；同一幂等键的重复请求返回已有的结果。
+// 先在库中预留退款再调用渠道，并发的重复请求和超额退款在预留时被拒绝；渠道失败时撤销预留，
+// 渠道侧的幂等键与本次退款一一对应，用同一幂等键重试不会重复退款。退款不会撤销已办理的会员资格
+func (s *PaymentService) Refund(ctx context.Context, actorID uint, id int, idempotencyKey string, req *dto.RefundPaymentRequest) (*dto.PaymentResponse, error) {
+	p, err := s.repo.GetPayment(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if _, err := s.repo.GetPaymentRefundByKey(p.ID, idempotencyKey); err == nil {
+		return s.toResponse(p)
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = p.Refundable()
+	}
+	if amount <= 0 || amount > p.Refundable() {
+		return nil, entities.ErrPaymentNotRefundable
+	}
+
+	refund := &entities.PaymentRefund{
+		PaymentID:      p.ID,
+		IdempotencyKey: idempotencyKey,
+		Amount:         amount,
+		Reason:         strings.TrimSpace(req.Reason),
+		ActorID:        actorID,
+	}
+	reserved, err := s.repo.ReserveRefund(p, refund)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		// 同一幂等键的另一个请求已经预留
+		return s.toResponse(p)
+	}
+
+	refund.ProviderRef, err = s.provider.Refund(ctx, p.ProviderRef, amount, fmt.Sprintf("refund-%d-%s", p.ID, idempotencyKey))
+	if err != nil {
+		if releaseErr := s.repo.ReleaseRefund(refund); releaseErr != nil {
+			logger.Warn("failed to release refund reservation", zap.Uint("refund_id", refund.ID), zap.Error(releaseErr))
+		}
+		return nil, err
+	}
+	posting, err := s.refundPosting(p, actorID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CompleteRefund(p, refund, posting); err != nil {
+		// 渠道已经退款，退款保持 pending 并继续占用金额，需要人工入账
+		logger.Error("refund succeeded at the provider but could not be recorded",
+			zap.Uint("refund_id", refund.ID),
+			zap.String("provider_ref", refund.ProviderRef),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	logger.Info("payment refunded",
+		zap.Uint("payment_id", p.ID),
+		zap.Uint("refund_id", refund.ID),
+		zap.Int64("amount", amount),
+		zap.Uint("actor_id", actorID),
+	)
+	return s.toResponse(p)
+}
+
+// Charge 向读者登记一笔费用，计入其欠款
+func (s *PaymentService) Charge(actorID, userID uint, req *dto.ChargeRequest) (*dto.BalanceResponse, error) {
+	if _, err := s.repo.GetUser(int(userID)); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	receivable, err := s.userAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	revenue, err := s.systemAccount(entities.LedgerAccountChargeRevenue, "Fines and charges", entities.LedgerAccountRevenue)
+	if err != nil {
+		return nil, err
+	}
+	posting, err := entities.NewLedgerPosting(
+		entities.LedgerTransaction{
+			Kind:        entities.LedgerKindCharge,
+			Description: strings.TrimSpace(req.Description),
+			UserID:      &userID,
+			ActorID:     &actorID,
+		},
+		entities.LedgerLine{AccountID: receivable.ID, Amount: req.Amount},
+		entities.LedgerLine{AccountID: revenue.ID, Amount: -req.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.PostLedgerTransaction(posting); err != nil {
+		return nil, err
+	}
+
+	logger.Info("charge posted",
+		zap.Uint("transaction_id", posting.Transaction.ID),
+		zap.Uint("user_id", userID),
+		zap.Int64("amount", req.Amount),
+		zap.Uint("actor_id", actorID),
+	)
+	return s.Balance(userID)
+}
+
+// Balance 读者应收款账户的余额
+func (s *PaymentService) Balance(userID uint) (*dto.BalanceResponse, error) {
+	if _, err := s.repo.GetUser(int(userID)); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	account, err := s.userAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.repo.LedgerAccountBalance(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.BalanceResponse{UserID: userID, Currency: s.currency, Balance: balance}
+	if balance > 0 {
+		response.Outstanding = balance
+	} else {
+		response.Credit = -balance
+	}
+	return response, nil
+}
+
+// Transactions 读者应收款账户的流水，按时间倒序
+func (s *PaymentService) Transactions(userID uint, page, pageSize int) (*dto.PaginatedLedgerEntryResponse, error) {
+	if _, err := s.repo.GetUser(int(userID)); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	account, err := s.userAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	offset := (page - 1) * pageSize
+	entries, total, err := s.repo.ListLedgerEntries(account.ID, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.LedgerEntryResponse, len(entries))
+	for i := range entries {
+		items[i] = dto.ToLedgerEntryResponse(&entries[i])
+	}
+	return &dto.PaginatedLedgerEntryResponse{Items: items, Total: total}, nil
+}
+
+// capturePosting 扣款的凭证：会员费先计入欠款再由付款结清，结清欠款的付款直接冲减欠款
+func (s *PaymentService) capturePosting(p *entities.Payment) (*entities.LedgerPosting, error) {
+	receivable, err := s.userAccount(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	cash, err := s.cashAccount()
+	if err != nil {
+		return nil, err
+	}
+	transaction := entities.LedgerTransaction{
+		Kind:        entities.LedgerKindPayment,
+		Description: fmt.Sprintf("Payment #%d", p.ID),
+		UserID:      &p.UserID,
+		PaymentID:   &p.ID,
+	}
+	lines := []entities.LedgerLine{
+		{AccountID: cash.ID, Amount: p.Amount},
+		{AccountID: receivable.ID, Amount: -p.Amount},
+	}
+	if p.Purpose == entities.PaymentPurposeMembership {
+		revenue, err := s.systemAccount(entities.LedgerAccountMembershipRevenue, "Membership fees", entities.LedgerAccountRevenue)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Description = fmt.Sprintf("Membership fee, payment #%d", p.ID)
+		lines = append([]entities.LedgerLine{
+			{AccountID: receivable.ID, Amount: p.Amount},
+			{AccountID: revenue.ID, Amount: -p.Amount},
+		}, lines...)
+	}
+	return entities.NewLedgerPosting(transaction, lines...)
+}
+
+// refundPosting 退款的凭证：资金退回读者，同时把对应的费用冲减到退款账户，读者的余额不变
+func (s *PaymentService) refundPosting(p *entities.Payment, actorID uint, amount int64) (*entities.LedgerPosting, error) {
+	receivable, err := s.userAccount(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	cash, err := s.cashAccount()
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.systemAccount(entities.LedgerAccountRefunds, "Refunds", entities.LedgerAccountContra)
+	if err != nil {
+		return nil, err
+	}
+	return entities.NewLedgerPosting(
+		entities.LedgerTransaction{
+			Kind:        entities.LedgerKindRefund,
+			Description: fmt.Sprintf("Refund of payment #%d", p.ID),
+			UserID:      &p.UserID,
+			PaymentID:   &p.ID,
+			ActorID:     &actorID,
+		},
+		entities.LedgerLine{AccountID: receivable.ID, Amount: amount},
+		entities.LedgerLine{AccountID: cash.ID, Amount: -amount},
+		entities.LedgerLine{AccountID: refunds.ID, Amount: amount},
+		entities.LedgerLine{AccountID: receivable.ID, Amount: -amount},
+	)
+}
+
+func (s *PaymentService) userAccount(userID uint) (*entities.LedgerAccount, error) {
+	account := &entities.LedgerAccount{
+		Code:   entities.UserLedgerAccountCode(userID),
+		Name:   fmt.Sprintf("Receivable from user %d", userID),
+		Type:   entities.LedgerAccountAsset,
+		UserID: &userID,
+	}
+	if err := s.repo.EnsureLedgerAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *PaymentService) cashAccount() (*entities.LedgerAccount, error) {
+	name := s.provider.Name()
+	return s.systemAccount(entities.CashLedgerAccountCode(name), "Cash held by "+name, entities.LedgerAccountAsset)
+}
+
+func (s *PaymentService) systemAccount(code, name, accountType string) (*entities.LedgerAccount, error) {
+	account := &entities.LedgerAccount{Code: code, Name: name, Type: accountType}
+	if err := s.repo.EnsureLedgerAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *PaymentService) toResponse(p *entities.Payment) (*dto.PaymentResponse, error) {
+	refunds, err := s.repo.ListPaymentRefunds(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := dto.ToPaymentResponse(p)
+	for i := range refunds {
+		response.Refunds = append(response.Refunds, dto.ToPaymentRefundResponse(&refunds[i]))
+	}
+	return &response, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRecommendationWindow = 90 * 24 * time.Hour
+	defaultMinTogether          = 2
+	defaultSimilarPerBook       = 20
+	defaultMaxBooksPerUser      = 500
+	defaultSimilarLimit         = 10
+)
+
+// RecommendationService 记录读者与图书的交互，并据此推荐“看过这本书的读者也看过”的图书。
+// 相似度由后台任务定期全量计算；还没有足够交互数据的图书以同一作者的其他作品补足
+type RecommendationService struct {
+	repo            repository.Repository
+	window          time.Duration
+	minTogether     int
+	perBook         int
+	maxBooksPerUser int
+}
+
+func NewRecommendationService(repo repository.Repository, cfg config.RecommendationConfig) *RecommendationService {
+	s := &RecommendationService{
+		repo:            repo,
+		window:          cfg.Window,
+		minTogether:     cfg.MinTogether,
+		perBook:         cfg.PerBook,
+		maxBooksPerUser: cfg.MaxBooksPerUser,
+	}
+	if s.window <= 0 {
+		s.window = defaultRecommendationWindow
+	}
+	if s.minTogether <= 0 {
+		s.minTogether = defaultMinTogether
+	}
+	if s.perBook <= 0 {
+		s.perBook = defaultSimilarPerBook
+	}
+	if s.maxBooksPerUser <= 0 {
+		s.maxBooksPerUser = defaultMaxBooksPerUser
+	}
+	return s
+}
+
+// RecordInteraction 记录一次交互。失败只记录日志，不影响读者正在进行的请求
+func (s *RecommendationService) RecordInteraction(userID, bookID uint, kind entities.InteractionKind) {
+	interaction := &entities.BookInteraction{UserID: userID, BookID: bookID, Kind: kind, CreatedAt: time.Now()}
+	if err := s.repo.RecordInteraction(interaction); err != nil {
+		logger.Warn("failed to record book interaction",
+			zap.Uint("book_id", bookID),
+			zap.Uint("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
+
+// SimilarBooks 与图书相似的书，先取共同读者计算出的结果，不足时补充同一作者的作品
+func (s *RecommendationService) SimilarBooks(bookID, limit int) ([]dto.SimilarBookResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if limit <= 0 {
+		limit = defaultSimilarLimit
+	}
+
+	similarities, err := s.repo.ListSimilarBooks(book.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(similarities))
+	for i, similarity := range similarities {
+		ids[i] = similarity.SimilarBookID
+	}
+	books, err := s.repo.ListBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*entities.Book, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+
+	seen := map[uint]bool{book.ID: true}
+	responses := make([]dto.SimilarBookResponse, 0, limit)
+	for _, similarity := range similarities {
+		if similar, ok := byID[similarity.SimilarBookID]; ok {
+			seen[similar.ID] = true
+			responses = append(responses, dto.ToSimilarBookResponse(similar, similarity.Score, dto.SimilarReasonCoViewed))
+		}
+	}
+	if len(responses) < limit {
+		sameAuthor, err := s.sameAuthor(book, limit-len(responses), seen)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, sameAuthor...)
+	}
+	return responses, nil
+}
+
+// Recompute 用时间窗口内的交互记录重新计算全部图书的相似度，并清理窗口之外的记录
+func (s *RecommendationService) Recompute(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-s.window)
+	if _, err := s.repo.PruneInteractions(since); err != nil {
+		return err
+	}
+
+	counter := entities.NewCooccurrenceCounter()
+	var readers, skipped int
+	err := s.repo.ForEachReaderHistory(since, func(userID uint, bookIDs []uint) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(bookIDs) > s.maxBooksPerUser {
+			skipped++
+			return nil
+		}
+		readers++
+		counter.Add(bookIDs)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	similarities := counter.Similarities(s.minTogether, s.perBook, now)
+	if err := s.repo.ReplaceBookSimilarities(similarities); err != nil {
+		return err
+	}
+	logger.Info("book similarities recomputed",
+		zap.Int("readers", readers),
+		zap.Int("skipped_readers", skipped),
+		zap.Int("similarities", len(similarities)),
+	)
+	return nil
+}
+
+// sameAuthor 冷启动时的补充推荐：同一作者的其他作品，优先按关联的作者查找，没有关联时按作者文本匹配
+func (s *RecommendationService) sameAuthor(book *entities.Book, limit int, seen map[uint]bool) ([]dto.SimilarBookResponse, error) {
+	contributors, err := s.repo.ListBookContributors([]uint{book.ID})
+	if err != nil {
+		return nil, err
+	}
+	var filters []repository.BookFilter
+	for _, contributor := range contributors[book.ID] {
+		if contributor.Role == entities.AuthorRoleAuthor {
+			filters = append(filters, repository.BookFilter{AuthorID: contributor.AuthorID})
+		}
+	}
+	if len(filters) == 0 {
+		for _, name := range entities.SplitAuthorNames(book.Author) {
+			filters = append(filters, repository.BookFilter{Author: name})
+		}
+	}
+
+	var responses []dto.SimilarBookResponse
+	for _, filter := range filters {
+		// 多取 len(seen) 本，排除已推荐的书后仍可能凑满
+		books, _, err := s.repo.ListBooks(0, limit+len(seen), filter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range books {
+			if seen[books[i].ID] {
+				continue
+			}
+			seen[books[i].ID] = true
+			responses = append(responses, dto.ToSimilarBookResponse(&books[i], 0, dto.SimilarReasonSameAuthor))
+			if len(responses) == limit {
+				return responses, nil
+			}
+		}
+	}
+	return responses, nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxFlaggedWordsLength 与书评表 flagged_words 列的长度一致
+const maxFlaggedWordsLength = 255
+
+// ReviewService 负责书评的发表、修改、删除和审核。
+// 图书的评分汇总只统计已公开的书评，由仓储层随书评的状态变化增量维护
+type ReviewService struct {
+	repo            repository.Repository
+	filter          *entities.BannedWordFilter
+	requireApproval bool
+}
+
+func NewReviewService(repo repository.Repository, cfg config.ReviewConfig) *ReviewService {
+	return &ReviewService{
+		repo:            repo,
+		filter:          entities.NewBannedWordFilter(cfg.BannedWords),
+		requireApproval: cfg.RequireApproval,
+	}
+}
+
+// CreateReview 发表书评，每位读者对每本书只能发表一条
+func (s *ReviewService) CreateReview(userID uint, bookID int, req *dto.ReviewRequest) (*dto.ReviewResponse, error) {
+	if _, err := s.repo.GetBook(bookID); err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if !entities.IsValidRating(req.Rating) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	review := &entities.Review{
+		BookID: uint(bookID),
+		UserID: userID,
+		Rating: req.Rating,
+		Text:   strings.TrimSpace(req.Text),
+	}
+	s.screen(review)
+	if err := s.repo.CreateReview(review); err != nil {
+		return nil, err
+	}
+
+	logger.Info("review submitted",
+		zap.Uint("review_id", review.ID),
+		zap.Uint("book_id", review.BookID),
+		zap.String("status", review.Status),
+	)
+	return s.reload(review.ID)
+}
+
+// UpdateReview 作者修改自己的书评，修改后重新经过审核规则
+func (s *ReviewService) UpdateReview(actorID uint, reviewID int, req *dto.ReviewRequest) (*dto.ReviewResponse, error) {
+	review, err := s.repo.GetReview(reviewID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if review.UserID != actorID {
+		return nil, errors.ErrForbidden
+	}
+	if !entities.IsValidRating(req.Rating) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	previous := *review
+	review.Rating = req.Rating
+	review.Text = strings.TrimSpace(req.Text)
+	review.ModeratorID = nil
+	review.ModeratedAt = nil
+	review.ModerationNote = ""
+	s.screen(review)
+	if err := s.repo.UpdateReview(review, &previous); err != nil {
+		return nil, err
+	}
+	return s.reload(review.ID)
+}
+
+// DeleteReview 作者或馆员删除书评
+func (s *ReviewService) DeleteReview(actorID uint, actorRole string, reviewID int) error {
+	review, err := s.repo.GetReview(reviewID)
+	if err != nil {
+		return errors.ErrNotFound
+	}
+	if review.UserID != actorID && !entities.IsStaffRole(actorRole) {
+		return errors.ErrForbidden
+	}
+	return s.repo.DeleteReview(review)
+}
+
+// ListBookReviews 图书已公开的书评，最新的在前
+func (s *ReviewService) ListBookReviews(bookID, page, pageSize int) (*dto.PaginatedReviewResponse, error) {
+	if _, err := s.repo.GetBook(bookID); err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	offset := (page - 1) * pageSize
+	reviews, total, err := s.repo.ListBookReviews(uint(bookID), entities.ReviewStatusApproved, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedReviewResponse{
+		Items: dto.ToReviewResponseList(reviews),
+		Total: total,
+	}, nil
+}
+
+// ListUserReviews 读者自己的全部书评，包括待审核和被驳回的
+func (s *ReviewService) ListUserReviews(userID uint) ([]dto.ReviewResponse, error) {
+	reviews, err := s.repo.ListReviewsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToReviewResponseList(reviews), nil
+}
+
+// ModerationQueue 审核队列，默认列出待审核的书评
+func (s *ReviewService) ModerationQueue(query *dto.ReviewModerationQuery, page, pageSize int) (*dto.PaginatedReviewResponse, error) {
+	status := query.Status
+	if status == "" {
+		status = entities.ReviewStatusPending
+	}
+
+	offset := (page - 1) * pageSize
+	reviews, total, err := s.repo.ListReviewsByStatus(status, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedReviewResponse{
+		Items: dto.ToReviewResponseList(reviews),
+		Total: total,
+	}, nil
+}
+
+// ApproveReview 通过审核，书评公开并计入评分
+func (s *ReviewService) ApproveReview(moderatorID uint, reviewID int) (*dto.ReviewResponse, error) {
+	return s.moderate(moderatorID, reviewID, entities.ReviewStatusApproved, "")
+}
+
+// RejectReview 驳回书评；已公开的书评被驳回后从评分中扣除
+func (s *ReviewService) RejectReview(moderatorID uint, reviewID int, req *dto.RejectReviewRequest) (*dto.ReviewResponse, error) {
+	return s.moderate(moderatorID, reviewID, entities.ReviewStatusRejected, strings.TrimSpace(req.Note))
+}
+
+func (s *ReviewService) moderate(moderatorID uint, reviewID int, status, note string) (*dto.ReviewResponse, error) {
+	review, err := s.repo.GetReview(reviewID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	previous := *review
+	now := time.Now()
+	review.Status = status
+	review.ModeratorID = &moderatorID
+	review.ModeratedAt = &now
+	review.ModerationNote = note
+	if err := s.repo.UpdateReview(review, &previous); err != nil {
+		return nil, err
+	}
+
+	logger.Info("review moderated",
+		zap.Uint("review_id", review.ID),
+		zap.Uint("moderator_id", moderatorID),
+		zap.String("status", status),
+	)
+	return s.reload(review.ID)
+}
+
+// screen 按屏蔽词和审核配置决定书评的初始状态：命中屏蔽词或要求审核时待审核，否则直接公开
+func (s *ReviewService) screen(review *entities.Review) {
+	flagged := s.filter.Find(review.Text)
+	review.FlaggedWords = ""
+	for _, word := range flagged {
+		// 只记录放得下的屏蔽词，供审核时参考
+		if len(review.FlaggedWords)+len(word)+1 > maxFlaggedWordsLength {
+			break
+		}
+		if review.FlaggedWords != "" {
+			review.FlaggedWords += ","
+		}
+		review.FlaggedWords += word
+	}
+	if len(flagged) > 0 || s.requireApproval {
+		review.Status = entities.ReviewStatusPending
+		return
+	}
+	review.Status = entities.ReviewStatusApproved
+}
+
+// reload 重新读取书评以带出读者姓名和数据库生成的时间
+func (s *ReviewService) reload(reviewID uint) (*dto.ReviewResponse, error) {
+	review, err := s.repo.GetReview(int(reviewID))
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToReviewResponse(review), nil
+}
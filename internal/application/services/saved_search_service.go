@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/mail"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSavedSearchMaxResults = 20
+	defaultSavedSearchMaxPerUser = 20
+	// savedSearchBatchSize 每批取出的到期检索数
+	savedSearchBatchSize = 200
+)
+
+// SavedSearchService 读者保存的图书检索。后台任务按各检索的频率检查上次检查之后新入藏的图书，
+// 有符合条件的就发站内通知；开启了邮件摘要的检索，同一读者的结果汇总成一封邮件发送
+type SavedSearchService struct {
+	repo       repository.Repository
+	books      *BookService
+	mailer     mail.Mailer
+	maxResults int
+	maxPerUser int
+}
+
+func NewSavedSearchService(repo repository.Repository, books *BookService, mailer mail.Mailer, cfg config.SavedSearchConfig) *SavedSearchService {
+	s := &SavedSearchService{repo: repo, books: books, mailer: mailer, maxResults: cfg.MaxResults, maxPerUser: cfg.MaxPerUser}
+	if s.maxResults <= 0 {
+		s.maxResults = defaultSavedSearchMaxResults
+	}
+	if s.maxPerUser <= 0 {
+		s.maxPerUser = defaultSavedSearchMaxPerUser
+	}
+	return s
+}
+
+// List 用户保存的全部检索
+func (s *SavedSearchService) List(userID uint) ([]dto.SavedSearchResponse, error) {
+	searches, err := s.repo.ListSavedSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.SavedSearchResponse, len(searches))
+	for i := range searches {
+		response, err := s.toResponse(&searches[i])
+		if err != nil {
+			return nil, err
+		}
+		items[i] = *response
+	}
+	return items, nil
+}
+
+// Create 保存检索，名称在同一用户内不能重复（不区分大小写）。只提醒保存之后入藏的图书
+func (s *SavedSearchService) Create(userID uint, req *dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	searches, err := s.repo.ListSavedSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(searches) >= s.maxPerUser {
+		return nil, entities.ErrSavedSearchLimit
+	}
+	if savedSearchNameTaken(searches, name, 0) {
+		return nil, entities.ErrSavedSearchNameTaken
+	}
+	criteria, err := s.validateCriteria(req.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	lastBookID, err := s.repo.MaxBookID()
+	if err != nil {
+		return nil, err
+	}
+
+	search := &entities.SavedSearch{
+		UserID:      userID,
+		Name:        name,
+		Frequency:   req.Frequency,
+		EmailDigest: req.EmailDigest,
+		LastBookID:  lastBookID,
+	}
+	search.SetCriteria(criteria)
+	search.Schedule(time.Now())
+	if err := s.repo.CreateSavedSearch(search); err != nil {
+		return nil, err
+	}
+
+	logger.Info("saved search created",
+		zap.Uint("saved_search_id", search.ID),
+		zap.Uint("user_id", userID),
+		zap.String("frequency", search.Frequency),
+	)
+	return s.toResponse(search)
+}
+
+func (s *SavedSearchService) Get(userID uint, id int) (*dto.SavedSearchResponse, error) {
+	search, err := s.ownSavedSearch(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(search)
+}
+
+// Update 修改检索。条件改变后从此刻起重新计算新书，频率改变后从此刻起重新安排下次检查
+func (s *SavedSearchService) Update(userID uint, id int, req *dto.SavedSearchRequest) (*dto.SavedSearchResponse, error) {
+	search, err := s.ownSavedSearch(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	searches, err := s.repo.ListSavedSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	if savedSearchNameTaken(searches, name, search.ID) {
+		return nil, entities.ErrSavedSearchNameTaken
+	}
+	criteria, err := s.validateCriteria(req.Criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := search.Criteria
+	search.SetCriteria(criteria)
+	if search.Criteria != previous {
+		if search.LastBookID, err = s.repo.MaxBookID(); err != nil {
+			return nil, err
+		}
+	}
+	if search.Frequency != req.Frequency {
+		search.Frequency = req.Frequency
+		search.Schedule(time.Now())
+	}
+	search.Name = name
+	search.EmailDigest = req.EmailDigest
+	if err := s.repo.UpdateSavedSearch(search); err != nil {
+		return nil, err
+	}
+	return s.toResponse(search)
+}
+
+func (s *SavedSearchService) Delete(userID uint, id int) error {
+	search, err := s.ownSavedSearch(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteSavedSearch(search.ID)
+}
+
+// Books 按保存的条件列出全部符合的图书，最新入藏的在前
+func (s *SavedSearchService) Books(userID uint, id, page, pageSize int) (*dto.PaginatedBookResponse, error) {
+	search, err := s.ownSavedSearch(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := search.DecodeCriteria()
+	if err != nil {
+		return nil, err
+	}
+	query := dto.ToBookListQuery(criteria)
+	query.Sort = repository.BookSortAdded
+	return s.books.ListBooks(page, pageSize, query)
+}
+
+// savedSearchDigest 一位读者本次要通过邮件收到的各检索结果
+type savedSearchDigest struct {
+	userID   uint
+	sections []string
+}
+
+// Run 定时任务：检查到期的检索在上次检查之后新入藏的图书，有结果的发送通知和邮件摘要。
+// 每次检查都截止到任务开始时的最大图书 ID，之后入藏的图书留给下一次检查
+func (s *SavedSearchService) Run(ctx context.Context) error {
+	throughID, err := s.repo.MaxBookID()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	digests := make(map[uint]*savedSearchDigest)
+	var order []uint
+	checked, notified := 0, 0
+	for {
+		searches, err := s.repo.ListDueSavedSearches(now, savedSearchBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range searches {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			search := &searches[i]
+			section, err := s.check(search, throughID)
+			if err != nil {
+				logger.Warn("failed to check saved search", zap.Uint("saved_search_id", search.ID), zap.Error(err))
+			}
+			if err := s.repo.MarkSavedSearchRun(search.ID, max(search.LastBookID, throughID), now, now.Add(search.Period())); err != nil {
+				return err
+			}
+			checked++
+			if section == "" {
+				continue
+			}
+			notified++
+			if search.EmailDigest {
+				digest, ok := digests[search.UserID]
+				if !ok {
+					digest = &savedSearchDigest{userID: search.UserID}
+					digests[search.UserID] = digest
+					order = append(order, search.UserID)
+				}
+				digest.sections = append(digest.sections, section)
+			}
+		}
+		if len(searches) < savedSearchBatchSize {
+			break
+		}
+	}
+
+	for _, userID := range order {
+		s.sendDigest(ctx, digests[userID])
+	}
+	if checked > 0 {
+		logger.Info("saved searches checked",
+			zap.Int("checked", checked),
+			zap.Int("notified", notified),
+			zap.Int("digests", len(order)),
+		)
+	}
+	return nil
+}
+
+// check 查找检索在 (LastBookID, throughID] 内符合条件的图书，有结果时创建站内通知，
+// 返回用于邮件摘要的一段文本；没有结果时返回空串。条件失效（如分类已删除）时返回错误，本次跳过
+func (s *SavedSearchService) check(search *entities.SavedSearch, throughID uint) (string, error) {
+	if search.LastBookID >= throughID {
+		return "", nil
+	}
+	criteria, err := search.DecodeCriteria()
+	if err != nil {
+		return "", err
+	}
+	filter, err := s.books.buildFilter(dto.ToBookListQuery(criteria))
+	if err != nil {
+		return "", err
+	}
+	filter.AfterID = search.LastBookID
+	filter.ThroughID = throughID
+	books, total, err := s.repo.ListBooks(0, s.maxResults, filter)
+	if err != nil {
+		return "", err
+	}
+	if total == 0 {
+		return "", nil
+	}
+
+	title := fmt.Sprintf("%d new book(s) match your saved search %q", total, search.Name)
+	var body strings.Builder
+	data := entities.SavedSearchNotificationData{SavedSearchID: search.ID, Total: total}
+	for i := range books {
+		fmt.Fprintf(&body, "- %s by %s\n", books[i].Title, books[i].Author)
+		data.BookIDs = append(data.BookIDs, books[i].ID)
+	}
+	if more := total - int64(len(books)); more > 0 {
+		fmt.Fprintf(&body, "and %d more\n", more)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	notification := &entities.Notification{
+		UserID: search.UserID,
+		Kind:   entities.NotificationKindSavedSearch,
+		Title:  title,
+		Body:   body.String(),
+		Data:   string(encoded),
+	}
+	if err := s.repo.CreateNotification(notification); err != nil {
+		return "", err
+	}
+	return title + "\n" + body.String(), nil
+}
+
+// sendDigest 把一位读者各检索的结果合成一封邮件发送，发送失败只记录日志，站内通知仍然有效
+func (s *SavedSearchService) sendDigest(ctx context.Context, digest *savedSearchDigest) {
+	user, err := s.repo.GetUser(int(digest.userID))
+	if err != nil {
+		logger.Warn("saved search digest recipient not found", zap.Uint("user_id", digest.userID))
+		return
+	}
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "New books matching your saved searches",
+		Body:    fmt.Sprintf("Hello %s,\n\n%s", user.Name, strings.Join(digest.sections, "\n")),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		logger.Warn("failed to send saved search digest", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// validateCriteria 规范化条件并按图书列表的规则校验，分类、分馆须存在
+func (s *SavedSearchService) validateCriteria(req dto.SavedSearchCriteria) (entities.SavedSearchCriteria, error) {
+	criteria := req.ToEntity()
+	criteria.Tags = normalizeTags(criteria.Tags)
+	if _, err := s.books.buildFilter(dto.ToBookListQuery(criteria)); err != nil {
+		return criteria, err
+	}
+	return criteria, nil
+}
+
+// ownSavedSearch 读取检索并校验归属，不属于该用户时按不存在处理
+func (s *SavedSearchService) ownSavedSearch(userID uint, id int) (*entities.SavedSearch, error) {
+	search, err := s.repo.GetSavedSearch(id)
+	if err != nil || search.UserID != userID {
+		return nil, errors.ErrNotFound
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) toResponse(search *entities.SavedSearch) (*dto.SavedSearchResponse, error) {
+	criteria, err := search.DecodeCriteria()
+	if err != nil {
+		return nil, err
+	}
+	response := dto.ToSavedSearchResponse(search, criteria)
+	return &response, nil
+}
+
+// savedSearchNameTaken 名称是否已被该用户的其他检索使用，不区分大小写
+func savedSearchNameTaken(searches []entities.SavedSearch, name string, exceptID uint) bool {
+	for _, search := range searches {
+		if search.ID != exceptID && strings.EqualFold(search.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+// shareTokenBytes 分享令牌的随机字节数，编码后为 32 位十六进制
+const shareTokenBytes = 16
+
+// ShelfService 负责读者的书架和阅读进度。
+// 三个默认书架表示阅读状态，放到其中一个上会从另外两个上移走，并同步开始、读完日期
+type ShelfService struct {
+	repo repository.Repository
+}
+
+func NewShelfService(repo repository.Repository) *ShelfService {
+	return &ShelfService{repo: repo}
+}
+
+// ListShelves 用户的全部书架，首次访问时创建默认书架
+func (s *ShelfService) ListShelves(userID uint) ([]dto.ShelfResponse, error) {
+	shelves, err := s.ensureDefaults(userID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToShelfResponseList(shelves), nil
+}
+
+// CreateShelf 创建自定义书架，同一用户的书架名称不能重复（不区分大小写）
+func (s *ShelfService) CreateShelf(userID uint, req *dto.ShelfRequest) (*dto.ShelfResponse, error) {
+	shelves, err := s.ensureDefaults(userID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	if nameTaken(shelves, name, 0) {
+		return nil, entities.ErrShelfNameTaken
+	}
+
+	shelf := &entities.Shelf{UserID: userID, Name: name, Kind: entities.ShelfKindCustom}
+	if err := s.repo.CreateShelf(shelf); err != nil {
+		return nil, err
+	}
+	return dto.ToShelfResponse(shelf), nil
+}
+
+// RenameShelf 重命名自定义书架
+func (s *ShelfService) RenameShelf(userID uint, shelfID int, req *dto.ShelfRequest) (*dto.ShelfResponse, error) {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.IsDefault() {
+		return nil, entities.ErrDefaultShelf
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	shelves, err := s.repo.ListShelves(userID)
+	if err != nil {
+		return nil, err
+	}
+	if nameTaken(shelves, name, shelf.ID) {
+		return nil, entities.ErrShelfNameTaken
+	}
+
+	shelf.Name = name
+	if err := s.repo.UpdateShelf(shelf); err != nil {
+		return nil, err
+	}
+	return dto.ToShelfResponse(shelf), nil
+}
+
+// DeleteShelf 删除自定义书架，书架上的图书一并移除，阅读进度保留
+func (s *ShelfService) DeleteShelf(userID uint, shelfID int) error {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return err
+	}
+	if shelf.IsDefault() {
+		return entities.ErrDefaultShelf
+	}
+	return s.repo.DeleteShelf(shelf.ID)
+}
+
+// ShareShelf 为书架生成分享链接，已分享的书架保持原链接
+func (s *ShelfService) ShareShelf(userID uint, shelfID int) (*dto.ShelfResponse, error) {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.ShareToken != nil {
+		return dto.ToShelfResponse(shelf), nil
+	}
+
+	buf := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+	shelf.ShareToken = &token
+	if err := s.repo.UpdateShelf(shelf); err != nil {
+		return nil, err
+	}
+	return dto.ToShelfResponse(shelf), nil
+}
+
+// UnshareShelf 取消分享，原链接随即失效
+func (s *ShelfService) UnshareShelf(userID uint, shelfID int) (*dto.ShelfResponse, error) {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.ShareToken == nil {
+		return dto.ToShelfResponse(shelf), nil
+	}
+	shelf.ShareToken = nil
+	if err := s.repo.UpdateShelf(shelf); err != nil {
+		return nil, err
+	}
+	return dto.ToShelfResponse(shelf), nil
+}
+
+// ListShelfBooks 书架上的图书及其阅读进度，最近加入的在前
+func (s *ShelfService) ListShelfBooks(userID uint, shelfID, page, pageSize int) (*dto.PaginatedShelfItemResponse, error) {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	items, total, err := s.repo.ListShelfItems(shelf.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.itemResponses(items, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedShelfItemResponse{Items: responses, Total: total}, nil
+}
+
+// SharedShelf 通过分享令牌查看书架，不公开阅读进度
+func (s *ShelfService) SharedShelf(token string, page, pageSize int) (*dto.SharedShelfResponse, error) {
+	shelf, err := s.repo.GetShelfByShareToken(token)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	owner, err := s.repo.GetUser(int(shelf.UserID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	items, total, err := s.repo.ListShelfItems(shelf.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.itemResponses(items, shelf.UserID, false)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SharedShelfResponse{
+		Name:      shelf.Name,
+		Kind:      shelf.Kind,
+		OwnerName: owner.Name,
+		Items:     responses,
+		Total:     total,
+	}, nil
+}
+
+// AddBook 把图书放上书架。放到默认书架上时从其他默认书架移走，
+// 并按书架记录开始阅读或读完的日期
+func (s *ShelfService) AddBook(userID uint, shelfID int, req *dto.AddShelfBookRequest) (*dto.ShelfItemResponse, error) {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.repo.GetBook(int(req.BookID))
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	var progress *entities.ReadingProgress
+	if shelf.IsDefault() {
+		if progress, err = s.moveToStatus(userID, book.ID, shelf.Kind); err != nil {
+			return nil, err
+		}
+	} else {
+		item := &entities.ShelfItem{ShelfID: shelf.ID, BookID: book.ID, UserID: userID, AddedAt: time.Now()}
+		if err := s.repo.AddShelfItem(item, nil); err != nil {
+			return nil, err
+		}
+		if progress, err = s.loadProgress(userID, book.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	response := &dto.ShelfItemResponse{Book: dto.ToShelfBookResponse(book), AddedAt: time.Now()}
+	if progress != nil {
+		response.Progress = dto.ToReadingProgressResponse(progress)
+	}
+	return response, nil
+}
+
+// RemoveBook 把图书从书架上拿下
+func (s *ShelfService) RemoveBook(userID uint, shelfID, bookID int) error {
+	shelf, err := s.ownShelf(userID, shelfID)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveShelfItem(shelf.ID, uint(bookID))
+}
+
+// UpdateProgress 更新阅读进度。有进度的书移到“在读”，读完（100% 或给出完成日期）的书移到“已读”
+func (s *ShelfService) UpdateProgress(userID uint, bookID int, req *dto.ReadingProgressRequest) (*dto.ReadingProgressResponse, error) {
+	book, err := s.repo.GetBook(bookID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	progress, err := s.loadProgress(userID, book.ID)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		progress = &entities.ReadingProgress{UserID: userID, BookID: book.ID}
+	}
+
+	startedAt, err := parseReadingDate(req.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	finishedAt, err := parseReadingDate(req.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt != nil && finishedAt != nil && finishedAt.Before(*startedAt) {
+		return nil, errors.ErrInvalidInput
+	}
+
+	if req.CurrentPage != nil || req.TotalPages != nil {
+		current := progress.CurrentPage
+		if req.CurrentPage != nil {
+			current = *req.CurrentPage
+		}
+		total := 0
+		if req.TotalPages != nil {
+			total = *req.TotalPages
+		}
+		progress.SetPages(current, total)
+	}
+	if req.Percent != nil {
+		progress.Percent = *req.Percent
+		if progress.TotalPages > 0 {
+			progress.CurrentPage = int(float64(progress.TotalPages) * *req.Percent / 100)
+		}
+	}
+	if startedAt != nil {
+		progress.StartedAt = startedAt
+	}
+
+	// 只改总页数或开始日期时不改变阅读状态
+	advanced := req.CurrentPage != nil || req.Percent != nil
+	now := time.Now()
+	switch {
+	case finishedAt != nil || (advanced && progress.Percent >= 100) || (!advanced && progress.FinishedAt != nil):
+		if finishedAt != nil {
+			progress.FinishedAt = finishedAt
+		}
+		progress.Finish(now)
+		err = s.placeOnStatusShelf(userID, book.ID, entities.ShelfKindRead)
+	case advanced && (progress.Percent > 0 || progress.CurrentPage > 0):
+		if progress.FinishedAt != nil {
+			// 读完后再次记录进度视为重读
+			progress.Start(now)
+		} else if progress.StartedAt == nil {
+			progress.StartedAt = &now
+		}
+		err = s.placeOnStatusShelf(userID, book.ID, entities.ShelfKindReading)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveReadingProgress(progress); err != nil {
+		return nil, err
+	}
+	return dto.ToReadingProgressResponse(progress), nil
+}
+
+// Stats 某一年的阅读统计，year 为 0 时统计今年
+func (s *ShelfService) Stats(userID uint, year int) (*dto.ReadingStatsResponse, error) {
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	shelves, err := s.ensureDefaults(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	finished, err := s.repo.ListFinishedReading(userID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	var reading int64
+	if shelf := shelfOfKind(shelves, entities.ShelfKindReading); shelf != nil {
+		reading = shelf.BookCount
+	}
+	return dto.ToReadingStatsResponse(entities.SummarizeReading(year, finished, reading)), nil
+}
+
+// moveToStatus 把图书放到表示某个阅读状态的默认书架上，并相应记录开始或读完日期
+func (s *ShelfService) moveToStatus(userID, bookID uint, kind string) (*entities.ReadingProgress, error) {
+	if err := s.placeOnStatusShelf(userID, bookID, kind); err != nil {
+		return nil, err
+	}
+	progress, err := s.loadProgress(userID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch kind {
+	case entities.ShelfKindReading:
+		if progress == nil {
+			progress = &entities.ReadingProgress{UserID: userID, BookID: bookID}
+		}
+		progress.Start(now)
+	case entities.ShelfKindRead:
+		if progress == nil {
+			progress = &entities.ReadingProgress{UserID: userID, BookID: bookID}
+		}
+		progress.Finish(now)
+	default:
+		return progress, nil
+	}
+	if err := s.repo.SaveReadingProgress(progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// placeOnStatusShelf 把图书放到某个默认书架上，并从其他默认书架移走
+func (s *ShelfService) placeOnStatusShelf(userID, bookID uint, kind string) error {
+	shelves, err := s.ensureDefaults(userID)
+	if err != nil {
+		return err
+	}
+	target := shelfOfKind(shelves, kind)
+	if target == nil {
+		return errors.ErrNotFound
+	}
+	var exclusive []uint
+	for _, shelf := range shelves {
+		if shelf.IsDefault() {
+			exclusive = append(exclusive, shelf.ID)
+		}
+	}
+	item := &entities.ShelfItem{ShelfID: target.ID, BookID: bookID, UserID: userID, AddedAt: time.Now()}
+	return s.repo.AddShelfItem(item, exclusive)
+}
+
+// ensureDefaults 补齐用户缺少的默认书架，返回用户的全部书架
+func (s *ShelfService) ensureDefaults(userID uint) ([]entities.Shelf, error) {
+	shelves, err := s.repo.ListShelves(userID)
+	if err != nil {
+		return nil, err
+	}
+	var missing []entities.Shelf
+	for _, shelf := range entities.DefaultShelves {
+		if shelfOfKind(shelves, shelf.Kind) == nil {
+			shelf.UserID = userID
+			missing = append(missing, shelf)
+		}
+	}
+	if len(missing) == 0 {
+		return shelves, nil
+	}
+	if err := s.repo.EnsureShelves(missing); err != nil {
+		return nil, err
+	}
+	return s.repo.ListShelves(userID)
+}
+
+// ownShelf 读取当前用户的书架，他人的书架按不存在处理
+func (s *ShelfService) ownShelf(userID uint, shelfID int) (*entities.Shelf, error) {
+	shelf, err := s.repo.GetShelf(shelfID)
+	if err != nil || shelf.UserID != userID {
+		return nil, errors.ErrNotFound
+	}
+	return shelf, nil
+}
+
+func (s *ShelfService) loadProgress(userID, bookID uint) (*entities.ReadingProgress, error) {
+	records, err := s.repo.GetReadingProgress(userID, []uint{bookID})
+	if err != nil {
+		return nil, err
+	}
+	progress, ok := records[bookID]
+	if !ok {
+		return nil, nil
+	}
+	return &progress, nil
+}
+
+// itemResponses 补全书架条目的图书信息，withProgress 为 true 时附带阅读进度
+func (s *ShelfService) itemResponses(items []entities.ShelfItem, userID uint, withProgress bool) ([]dto.ShelfItemResponse, error) {
+	bookIDs := make([]uint, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	books, err := s.repo.ListBooksByIDs(bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*entities.Book, len(books))
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+	progress := map[uint]entities.ReadingProgress{}
+	if withProgress {
+		if progress, err = s.repo.GetReadingProgress(userID, bookIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := make([]dto.ShelfItemResponse, 0, len(items))
+	for _, item := range items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			continue
+		}
+		response := dto.ShelfItemResponse{Book: dto.ToShelfBookResponse(book), AddedAt: item.AddedAt}
+		if record, ok := progress[item.BookID]; ok {
+			response.Progress = dto.ToReadingProgressResponse(&record)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func shelfOfKind(shelves []entities.Shelf, kind string) *entities.Shelf {
+	for i := range shelves {
+		if shelves[i].Kind == kind {
+			return &shelves[i]
+		}
+	}
+	return nil
+}
+
+// nameTaken 名称是否已被用户的其他书架使用
+func nameTaken(shelves []entities.Shelf, name string, exceptID uint) bool {
+	for _, shelf := range shelves {
+		if shelf.ID != exceptID && strings.EqualFold(shelf.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseReadingDate 解析 YYYY-MM-DD 格式的日期，按本地时区的零点计
+func parseReadingDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", *value, time.Local)
+	if err != nil {
+		return nil, errors.ErrInvalidInput
+	}
+	return &date, nil
+}
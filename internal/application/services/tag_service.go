@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+)
+
+type TagService struct {
+	repo repository.Repository
+}
+
+func NewTagService(repo repository.Repository) *TagService {
+	return &TagService{repo: repo}
+}
+
+// ListTags 列出标签及使用次数，prefix 用于输入联想
+func (s *TagService) ListTags(prefix string) ([]dto.FacetResponse, error) {
+	tags, err := s.repo.ListTags(entities.NormalizeTag(prefix))
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToFacetResponseList(tags), nil
+}
+
+// RenameTag 重命名标签；新名称已存在时两个标签合并
+func (s *TagService) RenameTag(id int, req *dto.RenameTagRequest) (*dto.FacetResponse, error) {
+	tag, err := s.repo.GetTag(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	name := entities.NormalizeTag(req.Name)
+	if name == "" {
+		return nil, errors.ErrInvalidInput
+	}
+
+	renamed, err := s.repo.RenameTag(tag, name)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.FacetResponse{ID: renamed.ID, Name: renamed.Name}, nil
+}
+
+func (s *TagService) DeleteTag(id int) error {
+	if _, err := s.repo.GetTag(id); err != nil {
+		return errors.ErrNotFound
+	}
+	return s.repo.DeleteTag(id)
+}
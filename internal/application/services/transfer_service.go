@@ -0,0 +1,125 @@
+package services
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TransferService 馆际调拨：申请后副本留在调出馆，发出后在途不可借，调入馆签收后归属调入馆并恢复可借
+type TransferService struct {
+	repo      repository.Repository
+	publisher events.Publisher
+}
+
+func NewTransferService(repo repository.Repository, publisher events.Publisher) *TransferService {
+	return &TransferService{repo: repo, publisher: publisher}
+}
+
+// RequestTransfer 申请调拨。借出中的副本也可以申请，归还后再发出
+func (s *TransferService) RequestTransfer(actorID uint, req *dto.CreateTransferRequest) (*dto.TransferResponse, error) {
+	var (
+		bookCopy *entities.BookCopy
+		err      error
+	)
+	if req.CopyID != 0 {
+		bookCopy, err = s.repo.GetBookCopy(int(req.CopyID))
+	} else {
+		bookCopy, err = s.repo.GetBookCopyByBarcode(req.Barcode)
+	}
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	if _, err := s.repo.GetBranch(int(req.ToBranchID)); err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	switch {
+	case bookCopy.BranchID == req.ToBranchID:
+		return nil, entities.ErrTransferSameBranch
+	case bookCopy.Status == entities.CopyStatusInTransit:
+		return nil, entities.ErrCopyInTransit
+	case bookCopy.Status == entities.CopyStatusLost || bookCopy.Status == entities.CopyStatusWithdrawn:
+		return nil, entities.ErrCopyNotAvailable
+	}
+
+	transfer := &entities.BranchTransfer{
+		CopyID:       bookCopy.ID,
+		FromBranchID: bookCopy.BranchID,
+		ToBranchID:   req.ToBranchID,
+		Status:       entities.TransferStatusRequested,
+		Note:         req.Note,
+		RequestedBy:  actorID,
+	}
+	if err := s.repo.CreateBranchTransfer(transfer); err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(int(transfer.ID))
+}
+
+func (s *TransferService) ListTransfers(page, pageSize int, query *dto.TransferListQuery) (*dto.PaginatedTransferResponse, error) {
+	filter := repository.BranchTransferFilter{BranchID: query.BranchID, CopyID: query.CopyID, Status: query.Status}
+	offset := (page - 1) * pageSize
+	transfers, total, err := s.repo.ListBranchTransfers(filter, offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedTransferResponse{Items: dto.ToTransferResponseList(transfers), Total: total}, nil
+}
+
+func (s *TransferService) GetTransfer(id int) (*dto.TransferResponse, error) {
+	transfer, err := s.repo.GetBranchTransfer(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	response := dto.ToTransferResponse(transfer)
+	return &response, nil
+}
+
+// Ship 调出馆发出副本，副本必须在馆且可借
+func (s *TransferService) Ship(id int) (*dto.TransferResponse, error) {
+	return s.transition(id, entities.TransferStatusInTransit)
+}
+
+// Receive 调入馆签收，副本恢复可借后按调入馆的预约队列分配
+func (s *TransferService) Receive(id int) (*dto.TransferResponse, error) {
+	response, err := s.transition(id, entities.TransferStatusReceived)
+	if err != nil {
+		return nil, err
+	}
+	s.publisher.Publish(events.CopyAvailable{CopyID: response.CopyID, BookID: response.BookID})
+	return response, nil
+}
+
+// Cancel 撤销尚未发出的调拨
+func (s *TransferService) Cancel(id int) (*dto.TransferResponse, error) {
+	return s.transition(id, entities.TransferStatusCancelled)
+}
+
+func (s *TransferService) transition(id int, to string) (*dto.TransferResponse, error) {
+	transfer, err := s.repo.GetBranchTransfer(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	previousStatus := transfer.Status
+	if err := transfer.Transition(to, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBranchTransfer(transfer, previousStatus); err != nil {
+		return nil, err
+	}
+
+	logger.Info("branch transfer status changed",
+		zap.Uint("transfer_id", transfer.ID),
+		zap.Uint("copy_id", transfer.CopyID),
+		zap.String("from", previousStatus),
+		zap.String("to", to),
+	)
+	return s.GetTransfer(int(transfer.ID))
+}
@@ -1,8 +1,11 @@
 package services
 
 import (
+	"time"
+
 	"github.com/azel-ko/final-ddd/internal/application/dto"
 	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
 	"github.com/azel-ko/final-ddd/internal/domain/repository"
 )
 
@@ -66,7 +69,7 @@ func (s *UserService) GetSelf(userID uint) (*dto.UserProfileResponse, error) {
 	if err != nil {
 		return nil, errors.ErrNotFound
 	}
-	return dto.ToUserProfileResponse(user), nil
+	return s.profile(user), nil
 }
 
 func (s *UserService) UpdateSelf(userID uint, req *dto.UpdateUserProfileRequest) (*dto.UserProfileResponse, error) {
@@ -99,5 +102,16 @@ func (s *UserService) UpdateSelf(userID uint, req *dto.UpdateUserProfileRequest)
 		return nil, err // Could be a generic error like errors.ErrDatabase
 	}
 
-	return dto.ToUserProfileResponse(user), nil
+	return s.profile(user), nil
+}
+
+// profile 个人资料，附带会员资格的状态和权益
+func (s *UserService) profile(user *entities.User) *dto.UserProfileResponse {
+	response := dto.ToUserProfileResponse(user)
+	if membership, err := s.repo.GetMembershipByUser(user.ID); err == nil {
+		plan, _ := s.repo.GetMembershipPlan(int(membership.PlanID))
+		status := dto.ToMembershipResponse(membership, plan, time.Now())
+		response.Membership = &status
+	}
+	return response
 }
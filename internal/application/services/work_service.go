@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WorkService 负责作品及其版本的归并：把同一本书的不同版本合并为一个作品，或把误归入的版本拆分出去
+type WorkService struct {
+	repo repository.Repository
+}
+
+func NewWorkService(repo repository.Repository) *WorkService {
+	return &WorkService{repo: repo}
+}
+
+func (s *WorkService) ListWorks(page, pageSize int, query *dto.WorkListQuery) (*dto.PaginatedWorkResponse, error) {
+	offset := (page - 1) * pageSize
+	works, total, err := s.repo.ListWorks(offset, pageSize, strings.TrimSpace(query.Title))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PaginatedWorkResponse{Items: dto.ToWorkResponseList(works), Total: total}, nil
+}
+
+// GetWork 作品及其全部版本
+func (s *WorkService) GetWork(id int) (*dto.WorkDetailResponse, error) {
+	work, err := s.repo.GetWork(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	return s.detail(work)
+}
+
+func (s *WorkService) UpdateWork(id int, req *dto.UpdateWorkRequest) (*dto.WorkDetailResponse, error) {
+	work, err := s.repo.GetWork(id)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, errors.ErrInvalidInput
+	}
+	work.Title = title
+	if err := s.repo.UpdateWork(work); err != nil {
+		return nil, err
+	}
+	return s.detail(work)
+}
+
+// MergeWorks 把其他作品的全部版本并入目标作品，被并入的作品随之删除
+func (s *WorkService) MergeWorks(actorID uint, targetID int, req *dto.MergeWorksRequest) (*dto.WorkDetailResponse, error) {
+	target, err := s.repo.GetWork(targetID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+
+	seen := make(map[uint]bool)
+	var sourceIDs []uint
+	for _, id := range req.WorkIDs {
+		if id == target.ID {
+			return nil, entities.ErrWorkMergeSelf
+		}
+		if seen[id] {
+			continue
+		}
+		if _, err := s.repo.GetWork(int(id)); err != nil {
+			return nil, fmt.Errorf("%w: work %d does not exist", errors.ErrNotFound, id)
+		}
+		seen[id] = true
+		sourceIDs = append(sourceIDs, id)
+	}
+	if err := s.repo.MergeWorks(target.ID, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	logger.Info("works merged",
+		zap.Uint("work_id", target.ID),
+		zap.Uints("merged_work_ids", sourceIDs),
+		zap.Uint("actor_id", actorID),
+	)
+	return s.GetWork(int(target.ID))
+}
+
+// SplitWork 把指定的版本从作品中拆出，组成一个新作品；原作品至少要保留一个版本
+func (s *WorkService) SplitWork(actorID uint, workID int, req *dto.SplitWorkRequest) (*dto.WorkDetailResponse, error) {
+	source, err := s.repo.GetWork(workID)
+	if err != nil {
+		return nil, errors.ErrNotFound
+	}
+	editions, err := s.repo.ListWorkEditions(source.ID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*entities.Book, len(editions))
+	for i := range editions {
+		byID[editions[i].ID] = &editions[i]
+	}
+
+	seen := make(map[uint]bool)
+	var bookIDs []uint
+	for _, id := range req.BookIDs {
+		if seen[id] {
+			continue
+		}
+		if byID[id] == nil {
+			return nil, fmt.Errorf("%w: book %d", entities.ErrEditionNotInWork, id)
+		}
+		seen[id] = true
+		bookIDs = append(bookIDs, id)
+	}
+	if len(bookIDs) >= len(editions) {
+		return nil, entities.ErrWorkSplitAll
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = byID[bookIDs[0]].Title
+	}
+	work := &entities.Work{Title: title}
+	if err := s.repo.SplitWork(source.ID, work, bookIDs); err != nil {
+		return nil, err
+	}
+
+	logger.Info("work split",
+		zap.Uint("work_id", source.ID),
+		zap.Uint("new_work_id", work.ID),
+		zap.Uints("book_ids", bookIDs),
+		zap.Uint("actor_id", actorID),
+	)
+	return s.GetWork(int(work.ID))
+}
+
+func (s *WorkService) detail(work *entities.Work) (*dto.WorkDetailResponse, error) {
+	editions, err := s.repo.ListWorkEditions(work.ID)
+	if err != nil {
+		return nil, err
+	}
+	work.EditionCount = int64(len(editions))
+	return dto.ToWorkDetailResponse(work, editions), nil
+}
@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 采购状态：读者提交的荐购经馆员审核后进入采购流程
+const (
+	AcquisitionStatusSuggested = "suggested" // 读者已提交，等待审核
+	AcquisitionStatusApproved  = "approved"  // 同意采购，尚未下单
+	AcquisitionStatusRejected  = "rejected"
+	AcquisitionStatusOrdered   = "ordered"  // 已向供应商下单
+	AcquisitionStatusReceived  = "received" // 已到货并编目入藏
+	AcquisitionStatusCancelled = "cancelled"
+)
+
+// acquisitionTransitions 每个状态允许转入的状态，未列出的状态为终态
+var acquisitionTransitions = map[string][]string{
+	AcquisitionStatusSuggested: {AcquisitionStatusApproved, AcquisitionStatusRejected, AcquisitionStatusCancelled},
+	AcquisitionStatusApproved:  {AcquisitionStatusOrdered, AcquisitionStatusCancelled},
+	AcquisitionStatusOrdered:   {AcquisitionStatusReceived, AcquisitionStatusCancelled},
+}
+
+var (
+	ErrInvalidAcquisitionTransition = errors.New("acquisition cannot move to the requested status")
+	ErrAcquisitionChanged           = errors.New("acquisition was modified by another request")
+	ErrAcquisitionAlreadySuggested  = errors.New("this title has already been suggested")
+	ErrBookAlreadyInCatalog         = errors.New("the library already owns this title")
+)
+
+// Acquisition 一条荐购或采购记录，从读者建议一直跟踪到到货入藏。
+// 馆员也可以直接提交，由自己审核后下单
+type Acquisition struct {
+	ID     uint   `gorm:"primarykey"`
+	UserID uint   `gorm:"not null;index"` // 提交人
+	ISBN   string `gorm:"size:20;not null;default:'';index"`
+	Title  string `gorm:"size:255;not null;default:''"`
+	Author string `gorm:"size:255;not null;default:''"`
+	Note   string `gorm:"size:1000;not null;default:''"` // 提交人说明
+	Status string `gorm:"size:20;not null;index"`
+
+	Vendor    string `gorm:"size:255;not null;default:''"`
+	Quantity  int    `gorm:"not null;default:0"`
+	CostCents int64  `gorm:"not null;default:0"` // 总价，以最小货币单位计
+	Currency  string `gorm:"size:3;not null;default:''"`
+	BookID    *uint  `gorm:"index"` // 到货后入藏的图书
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	UserName string `gorm:"->;-:migration"` // 查询时关联提交人姓名，只读
+}
+
+// AcquisitionEvent 采购状态变更的历史记录
+type AcquisitionEvent struct {
+	ID            uint      `gorm:"primarykey"`
+	AcquisitionID uint      `gorm:"not null;index"`
+	FromStatus    string    `gorm:"size:20;not null;default:''"` // 提交时为空
+	ToStatus      string    `gorm:"size:20;not null"`
+	ActorID       uint      `gorm:"not null"`
+	Note          string    `gorm:"size:1000;not null;default:''"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+
+	ActorName string `gorm:"->;-:migration"`
+}
+
+// OpenAcquisitionStatuses 仍在流程中的状态
+func OpenAcquisitionStatuses() []string {
+	return []string{AcquisitionStatusSuggested, AcquisitionStatusApproved, AcquisitionStatusOrdered}
+}
+
+// CanTransition 当前状态能否转入 to
+func (a *Acquisition) CanTransition(to string) bool {
+	for _, next := range acquisitionTransitions[a.Status] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition 转入 to 状态并返回对应的历史记录，不允许的转换返回 ErrInvalidAcquisitionTransition
+func (a *Acquisition) Transition(to string, actorID uint, note string) (*AcquisitionEvent, error) {
+	if !a.CanTransition(to) {
+		return nil, ErrInvalidAcquisitionTransition
+	}
+	event := &AcquisitionEvent{AcquisitionID: a.ID, FromStatus: a.Status, ToStatus: to, ActorID: actorID, Note: note}
+	a.Status = to
+	return event, nil
+}
@@ -0,0 +1,130 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// 作者在图书中的角色
+const (
+	AuthorRoleAuthor     = "author"
+	AuthorRoleEditor     = "editor"
+	AuthorRoleTranslator = "translator"
+)
+
+var ErrAuthorInUse = errors.New("author is still linked to books")
+
+// Author 作者
+type Author struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"size:255;not null;index"`
+	SortName  string    `gorm:"size:255;not null;index"`
+	Bio       string    `gorm:"type:text"`
+	ISNI      string    `gorm:"size:32"`
+	ORCID     string    `gorm:"size:32"`
+	VIAF      string    `gorm:"size:32"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// BookAuthor 图书与作者的多对多关联
+type BookAuthor struct {
+	BookID   uint   `gorm:"primaryKey"`
+	AuthorID uint   `gorm:"primaryKey;index"`
+	Role     string `gorm:"primaryKey;size:20"`
+	Position int    `gorm:"not null;default:0"`
+}
+
+// Contributor 图书的一位责任者（作者、编者或译者）
+type Contributor struct {
+	AuthorID uint
+	Name     string
+	Role     string
+	Position int
+}
+
+// IsValidAuthorRole 检查责任者角色是否合法
+func IsValidAuthorRole(role string) bool {
+	switch role {
+	case AuthorRoleAuthor, AuthorRoleEditor, AuthorRoleTranslator:
+		return true
+	}
+	return false
+}
+
+// SplitAuthorNames 把自由文本的作者字段拆成多个姓名。
+// 分号、&、" and "、顿号总是分隔符；逗号只有在每一段都像完整姓名（包含空格）时才拆分，
+// 以免把 "Salinger, J.D." 这类倒置姓名拆开。
+func SplitAuthorNames(text string) []string {
+	replacer := strings.NewReplacer(";", "\x00", "&", "\x00", " and ", "\x00", "、", "\x00", "；", "\x00")
+	var names []string
+	for _, part := range strings.Split(replacer.Replace(text), "\x00") {
+		names = append(names, splitOnCommas(part)...)
+	}
+	return names
+}
+
+func splitOnCommas(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	parts := strings.Split(text, ",")
+	if len(parts) > 1 {
+		for _, part := range parts {
+			if !strings.Contains(strings.TrimSpace(part), " ") {
+				return []string{text}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			names = append(names, part)
+		}
+	}
+	return names
+}
+
+// DeriveSortName 由姓名生成排序名，"George Orwell" -> "Orwell, George"；已是倒置形式或单名时原样返回
+func DeriveSortName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, ",") {
+		return name
+	}
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return name
+	}
+
+	// 姓氏前的冠词并入姓氏，如 "Ursula K. Le Guin" -> "Le Guin, Ursula K."
+	split := len(fields) - 1
+	for split > 1 && surnameParticles[strings.ToLower(fields[split-1])] {
+		split--
+	}
+	return strings.Join(fields[split:], " ") + ", " + strings.Join(fields[:split], " ")
+}
+
+var surnameParticles = map[string]bool{
+	"da": true, "de": true, "del": true, "della": true, "der": true, "di": true, "du": true,
+	"la": true, "le": true, "van": true, "von": true, "den": true, "ter": true, "dos": true,
+}
+
+// Byline 由责任者列表生成图书上显示的作者字符串，优先使用作者，其次编者
+func Byline(contributors []Contributor) string {
+	for _, role := range []string{AuthorRoleAuthor, AuthorRoleEditor, AuthorRoleTranslator} {
+		var names []string
+		for _, c := range contributors {
+			if c.Role == role {
+				names = append(names, c.Name)
+			}
+		}
+		if len(names) > 0 {
+			return strings.Join(names, "; ")
+		}
+	}
+	return ""
+}
@@ -1,8 +1,97 @@
 package entities
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
 type Book struct {
-	ID     uint   `gorm:"primarykey"`
-	Title  string `gorm:"size:255;not null"`
-	Author string `gorm:"size:255;not null"`
-	ISBN   string `gorm:"size:20;not null;unique"`
+	ID       uint   `gorm:"primarykey"`
+	Title    string `gorm:"size:255;not null"`
+	Subtitle string `gorm:"size:255;not null;default:''"`
+	Author   string `gorm:"size:255;not null"`
+	ISBN     string `gorm:"size:20;not null;unique"`
+
+	Publisher   string `gorm:"size:255;not null;default:''"`
+	PublishDate string `gorm:"size:10;not null;default:''"` // YYYY、YYYY-MM 或 YYYY-MM-DD
+	CoverURL    string `gorm:"size:512;not null;default:''"`
+	PageCount   int    `gorm:"not null;default:0"`           // 0 表示未知
+	Edition     string `gorm:"size:100;not null;default:''"` // 版次说明，例如 "2nd edition"、"修订版"
+	Description string `gorm:"type:text"`
+
+	// 版本信息：同一作品的不同版本通过 WorkID 归为一组
+	WorkID   uint   `gorm:"not null;default:0;index"`
+	Format   string `gorm:"size:20;not null;default:''"` // 装帧形式，BookFormat* 之一
+	Language string `gorm:"size:3;not null;default:''"`  // ISO 639 语言代码
+
+	CoverImage  string `gorm:"size:32;not null;default:''"` // 已上传封面的版本（内容摘要），为空表示没有上传封面
+	CoverFormat string `gorm:"size:8;not null;default:''"`  // 封面原图格式：jpeg、png 或 gif
+
+	// 已公开书评的评分汇总，只由书评的增删改在数据库中增量维护，保存图书时不会写入
+	RatingCount   int64   `gorm:"->"`
+	RatingSum     int64   `gorm:"->"`
+	RatingAverage float64 `gorm:"->"`
+
+	EditionCount int64 `gorm:"->;-:migration"` // 按作品合并列出时统计的同一作品的版本数，只读
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// publishDateLayouts 出版日期允许的精度：年、年月、年月日
+var publishDateLayouts = []string{"2006", "2006-01", "2006-01-02"}
+
+// IsValidPublishDate 出版日期为空或为 YYYY、YYYY-MM、YYYY-MM-DD 之一
+func IsValidPublishDate(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, layout := range publishDateLayouts {
+		if len(value) != len(layout) {
+			continue
+		}
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// publishDateInputLayouts 外部数据中常见的出版日期写法及其对应的精度
+var publishDateInputLayouts = []struct {
+	layout string
+	format string
+}{
+	{"2006-01-02", "2006-01-02"},
+	{"January 2, 2006", "2006-01-02"},
+	{"Jan 2, 2006", "2006-01-02"},
+	{"2 January 2006", "2006-01-02"},
+	{"2006-01", "2006-01"},
+	{"January 2006", "2006-01"},
+	{"Jan 2006", "2006-01"},
+	{"2006", "2006"},
+}
+
+var yearPattern = regexp.MustCompile(`(?:^|\D)(1[5-9]\d\d|20\d\d)(?:\D|$)`)
+
+// NormalizePublishDate 把外部数据中的出版日期转换为 YYYY、YYYY-MM 或 YYYY-MM-DD，
+// 无法识别的写法（如 "c1990."）只保留其中的年份，没有年份时返回空串
+func NormalizePublishDate(raw string) string {
+	raw = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "."))
+	for _, candidate := range publishDateInputLayouts {
+		if t, err := time.Parse(candidate.layout, raw); err == nil {
+			return t.Format(candidate.format)
+		}
+	}
+	if match := yearPattern.FindStringSubmatch(raw); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// BookLabelCode 图书的内部标签编码，印在书脊标签的 Code 128 或 QR 码中
+func BookLabelCode(id uint) string {
+	return fmt.Sprintf("BK%06d", id)
 }
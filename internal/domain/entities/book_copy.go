@@ -0,0 +1,65 @@
+package entities
+
+import "time"
+
+// 馆藏副本状态
+const (
+	CopyStatusAvailable = "available"
+	CopyStatusOnLoan    = "on_loan"
+	CopyStatusOnHold    = "on_hold_shelf" // 已分配给预约读者，等待取书
+	CopyStatusInTransit = "in_transit"    // 正在分馆间调拨
+	CopyStatusLost      = "lost"
+	CopyStatusWithdrawn = "withdrawn"
+)
+
+// 馆藏副本品相
+const (
+	CopyConditionNew     = "new"
+	CopyConditionGood    = "good"
+	CopyConditionFair    = "fair"
+	CopyConditionPoor    = "poor"
+	CopyConditionDamaged = "damaged"
+)
+
+// BookCopy 图书的一个实体副本，是借阅的最小单位
+type BookCopy struct {
+	ID        uint      `gorm:"primarykey"`
+	BookID    uint      `gorm:"not null;index"`
+	BranchID  uint      `gorm:"not null;default:0;index"` // 所在分馆
+	Barcode   string    `gorm:"size:64;not null;unique"`
+	Condition string    `gorm:"size:20;not null"`
+	Status    string    `gorm:"size:20;not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	BranchName string `gorm:"->;-:migration"` // 查询时关联的分馆名称，只读
+}
+
+// IsAvailable 副本是否可以被借出
+func (c *BookCopy) IsAvailable() bool {
+	return c.Status == CopyStatusAvailable
+}
+
+// CopyAvailability 某本图书的副本统计
+type CopyAvailability struct {
+	Total     int64
+	Available int64
+}
+
+// IsValidCopyCondition 检查品相取值是否合法
+func IsValidCopyCondition(condition string) bool {
+	switch condition {
+	case CopyConditionNew, CopyConditionGood, CopyConditionFair, CopyConditionPoor, CopyConditionDamaged:
+		return true
+	}
+	return false
+}
+
+// IsValidCopyStatus 检查可由馆员直接设置的副本状态是否合法
+func IsValidCopyStatus(status string) bool {
+	switch status {
+	case CopyStatusAvailable, CopyStatusLost, CopyStatusWithdrawn:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package entities
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 疑似重复图书的审核状态
+const (
+	DuplicateStatusPending   = "pending"   // 等待管理员审核
+	DuplicateStatusMerged    = "merged"    // 已合并
+	DuplicateStatusDismissed = "dismissed" // 确认不是重复，后续检测不会再次加入队列
+)
+
+// 判定为疑似重复的依据
+const (
+	DuplicateReasonISBN    = "isbn"    // ISBN-10 与 ISBN-13 换算后相同
+	DuplicateReasonSimilar = "similar" // 书名和作者相似
+)
+
+var (
+	ErrBookMergeSelf     = errors.New("cannot merge a book into itself")
+	ErrDuplicateResolved = errors.New("duplicate candidate has already been resolved")
+)
+
+// BookDuplicate 一对疑似重复的图书，BookID 总是两者中较小的 ID
+type BookDuplicate struct {
+	ID          uint    `gorm:"primarykey"`
+	BookID      uint    `gorm:"not null;uniqueIndex:idx_book_duplicates_pair"`
+	OtherBookID uint    `gorm:"not null;uniqueIndex:idx_book_duplicates_pair;index"`
+	Reason      string  `gorm:"size:20;not null"`
+	Score       float64 `gorm:"not null"` // 0 到 1，ISBN 相同时为 1
+	Status      string  `gorm:"size:20;not null;index"`
+	ResolvedBy  *uint   // 审核的管理员
+	ResolvedAt  *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// Pair 以 (较小 ID, 较大 ID) 表示的图书组合
+func (d *BookDuplicate) Pair() [2]uint {
+	return [2]uint{d.BookID, d.OtherBookID}
+}
+
+// Contains 图书是否为这对组合之一
+func (d *BookDuplicate) Contains(bookID uint) bool {
+	return d.BookID == bookID || d.OtherBookID == bookID
+}
+
+// Resolve 结束审核
+func (d *BookDuplicate) Resolve(status string, actorID uint, now time.Time) error {
+	if d.Status != DuplicateStatusPending {
+		return ErrDuplicateResolved
+	}
+	d.Status = status
+	d.ResolvedBy = &actorID
+	d.ResolvedAt = &now
+	return nil
+}
+
+// BookRedirect 已合并图书的原 ID 指向保留下来的图书，旧链接据此继续可用
+type BookRedirect struct {
+	BookID       uint      `gorm:"primaryKey;autoIncrement:false"`
+	TargetBookID uint      `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+const (
+	// titleWeight 综合得分中书名相似度的权重，其余为作者相似度
+	titleWeight = 0.7
+	// maxGramPostings 出现在太多图书中的三元组（如 " th"）几乎不能区分图书，不用于挑选比较对象
+	maxGramPostings = 1000
+)
+
+// duplicateKey 参与比较的图书特征
+type duplicateKey struct {
+	id     uint
+	workID uint
+	title  map[string]struct{}
+	author map[string]struct{}
+}
+
+// DuplicateFinder 在全部图书中查找疑似重复：ISBN 规范化后相同的直接认定，
+// 其余以书名、作者的三元组相似度（Jaccard 系数）加权打分。
+// 只比较至少有一个书名三元组相同的组合，避免两两比较全部图书
+type DuplicateFinder struct {
+	keys   []duplicateKey
+	byISBN map[ISBN][]int
+	byGram map[string][]int
+}
+
+func NewDuplicateFinder() *DuplicateFinder {
+	return &DuplicateFinder{byISBN: make(map[ISBN][]int), byGram: make(map[string][]int)}
+}
+
+// Add 加入一本图书；ISBN 无法通过校验时只按书名和作者比较
+func (f *DuplicateFinder) Add(book *Book) {
+	key := duplicateKey{
+		id:     book.ID,
+		workID: book.WorkID,
+		title:  Trigrams(NormalizeForMatch(book.Title)),
+		author: Trigrams(NormalizeForMatch(book.Author)),
+	}
+	index := len(f.keys)
+	if isbn, err := ParseISBN(book.ISBN); err == nil {
+		f.byISBN[isbn] = append(f.byISBN[isbn], index)
+	}
+	for gram := range key.title {
+		f.byGram[gram] = append(f.byGram[gram], index)
+	}
+	f.keys = append(f.keys, key)
+}
+
+// Candidates 得分不低于 threshold 的组合，按得分从高到低排列。
+// 已归入同一作品的图书是有意区分的不同版本，不作为候选
+func (f *DuplicateFinder) Candidates(threshold float64) []BookDuplicate {
+	found := make(map[[2]uint]BookDuplicate)
+	add := func(i, j int, reason string, score float64) {
+		a, b := f.keys[i].id, f.keys[j].id
+		if a > b {
+			a, b = b, a
+		}
+		if _, ok := found[[2]uint{a, b}]; ok {
+			return
+		}
+		found[[2]uint{a, b}] = BookDuplicate{BookID: a, OtherBookID: b, Reason: reason, Score: score, Status: DuplicateStatusPending}
+	}
+
+	for _, group := range f.byISBN {
+		for x, i := range group {
+			for _, j := range group[x+1:] {
+				add(i, j, DuplicateReasonISBN, 1)
+			}
+		}
+	}
+
+	for i := range f.keys {
+		compared := make(map[int]bool)
+		for gram := range f.keys[i].title {
+			postings := f.byGram[gram]
+			if len(postings) > maxGramPostings {
+				continue
+			}
+			for _, j := range postings {
+				if j <= i || compared[j] {
+					continue
+				}
+				compared[j] = true
+				a, b := &f.keys[i], &f.keys[j]
+				if a.workID != 0 && a.workID == b.workID {
+					continue
+				}
+				if score := similarity(a, b); score >= threshold {
+					add(i, j, DuplicateReasonSimilar, score)
+				}
+			}
+		}
+	}
+
+	candidates := make([]BookDuplicate, 0, len(found))
+	for _, candidate := range found {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].BookID != candidates[j].BookID {
+			return candidates[i].BookID < candidates[j].BookID
+		}
+		return candidates[i].OtherBookID < candidates[j].OtherBookID
+	})
+	return candidates
+}
+
+// similarity 书名和作者相似度的加权和，保留两位小数；有一方没有作者时只看书名
+func similarity(a, b *duplicateKey) float64 {
+	score := jaccard(a.title, b.title)
+	if len(a.author) > 0 && len(b.author) > 0 {
+		score = titleWeight*score + (1-titleWeight)*jaccard(a.author, b.author)
+	}
+	return float64(int(score*100+0.5)) / 100
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for gram := range a {
+		if _, ok := b[gram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// leadingArticles 比较书名时忽略的英文冠词
+var leadingArticles = []string{"the ", "a ", "an "}
+
+// NormalizeForMatch 转为小写，标点和空白统一为单个空格，并去掉开头的冠词，
+// 使 "The Catcher in the Rye." 与 "catcher in the rye" 得到相同的结果
+func NormalizeForMatch(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			sb.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	normalized := sb.String()
+	for _, article := range leadingArticles {
+		if strings.HasPrefix(normalized, article) && len(normalized) > len(article) {
+			return normalized[len(article):]
+		}
+	}
+	return normalized
+}
+
+// Trigrams 按词切分三元组，与 PostgreSQL pg_trgm 相同：每个词前补两个空格、后补一个空格
+func Trigrams(normalized string) map[string]struct{} {
+	grams := make(map[string]struct{})
+	for _, word := range strings.Fields(normalized) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return grams
+}
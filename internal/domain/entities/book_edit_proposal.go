@@ -0,0 +1,163 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// 修改建议的状态
+const (
+	EditProposalStatusPending   = "pending"
+	EditProposalStatusApproved  = "approved"
+	EditProposalStatusRejected  = "rejected"
+	EditProposalStatusWithdrawn = "withdrawn" // 提交人在审核前撤回
+)
+
+var (
+	ErrEditProposalEmpty    = errors.New("proposal does not change any field")
+	ErrEditProposalResolved = errors.New("proposal has already been reviewed or withdrawn")
+	// ErrEditProposalConflict 提交建议后图书的相关字段又被修改过，需要驳回后重新提交
+	ErrEditProposalConflict = errors.New("book was changed after the proposal was submitted")
+)
+
+// ProposableBookFields 读者可以提议修改的字段。ISBN、责任者、封面地址影响查重和关联，只能由馆员直接编辑
+var ProposableBookFields = []string{
+	"title", "subtitle", "publisher", "publish_date", "page_count", "edition", "description", "format", "language",
+}
+
+// BookEditProposal 读者对图书著录提出的修改建议，经馆员审核通过后写入图书。
+// Changes 中每个字段的 From 为提交时图书的值，用于发现提交后图书又被修改的冲突
+type BookEditProposal struct {
+	ID         uint   `gorm:"primarykey"`
+	BookID     uint   `gorm:"not null;index"`
+	UserID     uint   `gorm:"not null;index"` // 提交人
+	Status     string `gorm:"size:20;not null;index"`
+	Changes    string `gorm:"type:text;not null"`            // []FieldChange 的 JSON
+	Note       string `gorm:"size:1000;not null;default:''"` // 提交人说明
+	ReviewerID *uint  `gorm:"index"`
+	ReviewNote string `gorm:"size:1000;not null;default:''"` // 审核意见，驳回时必填
+	ReviewedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+
+	UserName     string `gorm:"->;-:migration"` // 查询时关联的提交人姓名，只读
+	ReviewerName string `gorm:"->;-:migration"`
+	BookTitle    string `gorm:"->;-:migration"` // 图书当前的书名
+}
+
+// NewBookEditProposal 比较图书当前值与提议的值，只保留有变化的字段；没有任何变化时返回 ErrEditProposalEmpty。
+// proposed 的键为 ProposableBookFields 之一，其余键被忽略
+func NewBookEditProposal(book *Book, userID uint, proposed map[string]string, note string) (*BookEditProposal, error) {
+	var changes []FieldChange
+	for _, field := range ProposableBookFields {
+		value, ok := proposed[field]
+		if !ok {
+			continue
+		}
+		if current := BookFieldValue(book, field); current != value {
+			changes = append(changes, FieldChange{Field: field, From: current, To: value})
+		}
+	}
+	if len(changes) == 0 {
+		return nil, ErrEditProposalEmpty
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	return &BookEditProposal{
+		BookID:  book.ID,
+		UserID:  userID,
+		Status:  EditProposalStatusPending,
+		Changes: string(data),
+		Note:    note,
+	}, nil
+}
+
+// DecodeChanges 解析提议修改的字段
+func (p *BookEditProposal) DecodeChanges() ([]FieldChange, error) {
+	var changes []FieldChange
+	err := json.Unmarshal([]byte(p.Changes), &changes)
+	return changes, err
+}
+
+// Resolve 记录审核或撤回的结果
+func (p *BookEditProposal) Resolve(status string, actorID uint, note string, now time.Time) {
+	p.Status = status
+	p.ReviewerID = &actorID
+	p.ReviewNote = note
+	p.ReviewedAt = &now
+}
+
+// EditConflicts 返回提交建议后又被修改过的字段，From 为提交时的值，To 为图书现在的值。
+// 图书现在的值已经等于提议的值时不算冲突
+func EditConflicts(book *Book, changes []FieldChange) []FieldChange {
+	var conflicts []FieldChange
+	for _, change := range changes {
+		current := BookFieldValue(book, change.Field)
+		if current != change.From && current != change.To {
+			conflicts = append(conflicts, FieldChange{Field: change.Field, From: change.From, To: current})
+		}
+	}
+	return conflicts
+}
+
+// ApplyEditChanges 把提议的值写入图书，返回需要更新的列及其新值
+func ApplyEditChanges(book *Book, changes []FieldChange) map[string]interface{} {
+	columns := make(map[string]interface{}, len(changes))
+	for _, change := range changes {
+		switch change.Field {
+		case "title":
+			book.Title = change.To
+		case "subtitle":
+			book.Subtitle = change.To
+		case "publisher":
+			book.Publisher = change.To
+		case "publish_date":
+			book.PublishDate = change.To
+		case "page_count":
+			book.PageCount, _ = strconv.Atoi(change.To)
+			columns[change.Field] = book.PageCount
+			continue
+		case "edition":
+			book.Edition = change.To
+		case "description":
+			book.Description = change.To
+		case "format":
+			book.Format = change.To
+		case "language":
+			book.Language = change.To
+		default:
+			continue
+		}
+		columns[change.Field] = change.To
+	}
+	return columns
+}
+
+// BookFieldValue 以文本形式返回图书可提议修改的字段的值，未知字段返回空串
+func BookFieldValue(book *Book, field string) string {
+	switch field {
+	case "title":
+		return book.Title
+	case "subtitle":
+		return book.Subtitle
+	case "publisher":
+		return book.Publisher
+	case "publish_date":
+		return book.PublishDate
+	case "page_count":
+		return strconv.Itoa(book.PageCount)
+	case "edition":
+		return book.Edition
+	case "description":
+		return book.Description
+	case "format":
+		return book.Format
+	case "language":
+		return book.Language
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnsupportedBookFile 文件不是可识别的 EPUB 或 PDF
+	ErrUnsupportedBookFile = errors.New("unsupported e-book file, expected EPUB or PDF")
+	// ErrBookFileTooLarge 电子书文件超过大小上限
+	ErrBookFileTooLarge = errors.New("e-book file too large")
+	// ErrDownloadLimitReached 同一本书同时有效的下载授权已达上限
+	ErrDownloadLimitReached = errors.New("all digital copies of this title are in use, try again later")
+	// ErrDownloadLinkInvalid 下载链接签名不正确、已过期或授权已不存在
+	ErrDownloadLinkInvalid = errors.New("download link is invalid or has expired")
+)
+
+// BookFile 图书的一个电子书文件，内容保存在对象存储中
+type BookFile struct {
+	ID         uint      `gorm:"primarykey"`
+	BookID     uint      `gorm:"not null;index"`
+	Format     string    `gorm:"size:10;not null"`  // epub 或 pdf
+	FileName   string    `gorm:"size:255;not null"` // 下载时建议的文件名
+	StorageKey string    `gorm:"size:255;not null"`
+	Size       int64     `gorm:"not null"`
+	SHA256     string    `gorm:"size:64;not null"`
+	UploadedBy uint      `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// DownloadGrant 发给读者的一次下载授权，过期前占用该书的一个并发名额
+type DownloadGrant struct {
+	ID        uint      `gorm:"primarykey"`
+	FileID    uint      `gorm:"not null;index"`
+	BookID    uint      `gorm:"not null;index:idx_download_grants_book_expires"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_download_grants_book_expires"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// IsActive 授权是否尚未过期
+func (g *DownloadGrant) IsActive(now time.Time) bool {
+	return now.Before(g.ExpiresAt)
+}
@@ -0,0 +1,140 @@
+package entities
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 图书修订的动作
+const (
+	BookRevisionCreate   = "create"
+	BookRevisionUpdate   = "update"
+	BookRevisionDelete   = "delete"
+	BookRevisionRevert   = "revert"
+	BookRevisionBaseline = "baseline" // 开始记录历史前图书已有的状态，首次修改前自动补记
+	BookRevisionMerge    = "merge"    // 合并到另一本图书，快照为合并前的状态
+)
+
+// BookRevision 图书的一个不可变修订，保存操作后的完整快照。
+// 图书删除后修订仍然保留，可据此恢复
+type BookRevision struct {
+	ID           uint      `gorm:"primarykey"`
+	BookID       uint      `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Revision     int       `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"` // 同一本书内从 1 递增
+	Action       string    `gorm:"size:20;not null"`
+	ActorID      *uint     `gorm:"index"` // 为空表示系统操作，例如命令行导入
+	RevertedFrom *int      // 恢复操作所依据的修订号
+	Snapshot     string    `gorm:"type:text;not null"` // BookSnapshot 的 JSON
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+
+	ActorName string `gorm:"->;-:migration"`
+}
+
+// SnapshotContributor 快照中的一位责任者
+type SnapshotContributor struct {
+	AuthorID uint   `json:"author_id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+// BookSnapshot 图书可编辑字段的完整快照。所属作品和封面不在其中：
+// 它们由作品合并拆分和封面上传单独维护，不随修订恢复
+type BookSnapshot struct {
+	Title        string                `json:"title"`
+	Subtitle     string                `json:"subtitle"`
+	Author       string                `json:"author"`
+	ISBN         string                `json:"isbn"`
+	Publisher    string                `json:"publisher"`
+	PublishDate  string                `json:"publish_date"`
+	CoverURL     string                `json:"cover_url"`
+	PageCount    int                   `json:"page_count"`
+	Edition      string                `json:"edition"`
+	Description  string                `json:"description"`
+	Format       string                `json:"format"`
+	Language     string                `json:"language"`
+	Contributors []SnapshotContributor `json:"contributors"`
+}
+
+// NewBookSnapshot 由图书及其责任者生成快照
+func NewBookSnapshot(book *Book, contributors []Contributor) BookSnapshot {
+	snapshot := BookSnapshot{
+		Title:        book.Title,
+		Subtitle:     book.Subtitle,
+		Author:       book.Author,
+		ISBN:         book.ISBN,
+		Publisher:    book.Publisher,
+		PublishDate:  book.PublishDate,
+		CoverURL:     book.CoverURL,
+		PageCount:    book.PageCount,
+		Edition:      book.Edition,
+		Description:  book.Description,
+		Format:       book.Format,
+		Language:     book.Language,
+		Contributors: make([]SnapshotContributor, len(contributors)),
+	}
+	for i, contributor := range contributors {
+		snapshot.Contributors[i] = SnapshotContributor{AuthorID: contributor.AuthorID, Name: contributor.Name, Role: contributor.Role}
+	}
+	return snapshot
+}
+
+// Encode 序列化快照，用于写入 BookRevision.Snapshot
+func (s BookSnapshot) Encode() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// DecodeSnapshot 解析修订保存的快照
+func (r *BookRevision) DecodeSnapshot() (BookSnapshot, error) {
+	var snapshot BookSnapshot
+	err := json.Unmarshal([]byte(r.Snapshot), &snapshot)
+	return snapshot, err
+}
+
+// FieldChange 两个快照之间一个字段的变化
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Diff 按字段比较两个快照，返回从 s 到 other 发生变化的字段，责任者列表作为一个字段比较
+func (s BookSnapshot) Diff(other BookSnapshot) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", s.Title, other.Title},
+		{"subtitle", s.Subtitle, other.Subtitle},
+		{"author", s.Author, other.Author},
+		{"isbn", s.ISBN, other.ISBN},
+		{"publisher", s.Publisher, other.Publisher},
+		{"publish_date", s.PublishDate, other.PublishDate},
+		{"cover_url", s.CoverURL, other.CoverURL},
+		{"page_count", strconv.Itoa(s.PageCount), strconv.Itoa(other.PageCount)},
+		{"edition", s.Edition, other.Edition},
+		{"description", s.Description, other.Description},
+		{"format", s.Format, other.Format},
+		{"language", s.Language, other.Language},
+		{"contributors", s.contributorList(), other.contributorList()},
+	}
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	return changes
+}
+
+// contributorList 把责任者列表格式化为便于比较和展示的文本，例如 "Jane Doe (author); John Roe (translator)"
+func (s BookSnapshot) contributorList() string {
+	parts := make([]string, len(s.Contributors))
+	for i, contributor := range s.Contributors {
+		parts[i] = contributor.Name + " (" + contributor.Role + ")"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 全文检索的高亮标记，各方言的检索实现统一使用
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// maxSearchTerms 单次检索最多使用的检索词数量
+const maxSearchTerms = 8
+
+// BookSearchHit 全文检索命中的图书及其相关度和高亮片段
+type BookSearchHit struct {
+	Book
+	Rank          float64 // 越大越相关；退化为模糊匹配时为 0
+	TitleSnippet  string
+	AuthorSnippet string
+}
+
+// SearchTerms 把检索语句拆分为小写检索词，去掉标点和重复项
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		terms = append(terms, field)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// Highlight 用高亮标记包裹文本中出现的检索词，不区分大小写
+func Highlight(text string, terms []string) string {
+	if len(terms) == 0 || text == "" {
+		return text
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		return HighlightStart + match + HighlightStop
+	})
+}
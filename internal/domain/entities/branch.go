@@ -0,0 +1,129 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrBranchNameTaken     = errors.New("a branch with this name already exists")
+	ErrBranchInUse         = errors.New("branch still has holdings or open transfers")
+	ErrInvalidTimezone     = errors.New("unknown timezone")
+	ErrInvalidOpeningHours = errors.New("invalid opening hours")
+)
+
+// Branch 图书馆的一个分馆，馆藏副本归属于某个分馆
+type Branch struct {
+	ID       uint   `gorm:"primarykey"`
+	Name     string `gorm:"size:100;not null;unique"`
+	Address  string `gorm:"size:500;not null;default:''"`
+	Timezone string `gorm:"size:64;not null"` // IANA 时区名，开放时间按分馆当地时间计算
+	// OpeningHours 每周开放时段的 JSON，见 OpeningPeriod
+	OpeningHours string `gorm:"type:text"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// OpeningPeriod 每周某天的一个开放时段，Open、Close 为当地时间 HH:MM，不支持跨午夜
+type OpeningPeriod struct {
+	Weekday time.Weekday `json:"weekday"` // 0 为星期日
+	Open    string       `json:"open"`
+	Close   string       `json:"close"`
+}
+
+// minutes 时段起止对应当天的分钟数
+func (p OpeningPeriod) minutes() (int, int, bool) {
+	open, err := time.Parse("15:04", p.Open)
+	if err != nil {
+		return 0, 0, false
+	}
+	closing, err := time.Parse("15:04", p.Close)
+	if err != nil {
+		return 0, 0, false
+	}
+	return open.Hour()*60 + open.Minute(), closing.Hour()*60 + closing.Minute(), true
+}
+
+// Location 分馆时区，调用前时区已在保存时校验
+func (b *Branch) Location() *time.Location {
+	location, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// SetTimezone 校验并设置时区
+func (b *Branch) SetTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	b.Timezone = name
+	return nil
+}
+
+// SetOpeningHours 校验并保存开放时段：同一天的时段不能重叠，结束时间必须晚于开始时间
+func (b *Branch) SetOpeningHours(periods []OpeningPeriod) error {
+	sorted := append([]OpeningPeriod(nil), periods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weekday != sorted[j].Weekday {
+			return sorted[i].Weekday < sorted[j].Weekday
+		}
+		return sorted[i].Open < sorted[j].Open
+	})
+
+	lastClose := map[time.Weekday]int{}
+	for _, period := range sorted {
+		open, closing, ok := period.minutes()
+		if !ok || period.Weekday < time.Sunday || period.Weekday > time.Saturday || closing <= open {
+			return ErrInvalidOpeningHours
+		}
+		if last, seen := lastClose[period.Weekday]; seen && open < last {
+			return ErrInvalidOpeningHours
+		}
+		lastClose[period.Weekday] = closing
+	}
+
+	data, err := json.Marshal(sorted)
+	if err != nil {
+		return err
+	}
+	b.OpeningHours = string(data)
+	return nil
+}
+
+// Periods 解析保存的开放时段，没有设置时返回空
+func (b *Branch) Periods() []OpeningPeriod {
+	var periods []OpeningPeriod
+	if b.OpeningHours != "" {
+		_ = json.Unmarshal([]byte(b.OpeningHours), &periods)
+	}
+	return periods
+}
+
+// IsOpenAt 按分馆当地时间判断 t 时刻是否在开放时段内
+func (b *Branch) IsOpenAt(t time.Time) bool {
+	local := t.In(b.Location())
+	now := local.Hour()*60 + local.Minute()
+	for _, period := range b.Periods() {
+		open, closing, ok := period.minutes()
+		if ok && period.Weekday == local.Weekday() && now >= open && now < closing {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchAvailability 某本图书在一个分馆的副本统计
+type BranchAvailability struct {
+	BranchID   uint
+	BranchName string
+	Total      int64
+	Available  int64
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 馆际调拨状态
+const (
+	TransferStatusRequested = "requested"  // 已申请，副本仍在调出馆
+	TransferStatusInTransit = "in_transit" // 已发出，副本在途
+	TransferStatusReceived  = "received"   // 调入馆已签收，副本归属调入馆
+	TransferStatusCancelled = "cancelled"
+)
+
+// transferTransitions 每个状态允许转入的状态；在途的副本只能签收，未列出的状态为终态
+var transferTransitions = map[string][]string{
+	TransferStatusRequested: {TransferStatusInTransit, TransferStatusCancelled},
+	TransferStatusInTransit: {TransferStatusReceived},
+}
+
+var (
+	ErrInvalidTransferTransition = errors.New("transfer cannot move to the requested status")
+	ErrTransferChanged           = errors.New("transfer was modified by another request")
+	ErrTransferAlreadyOpen       = errors.New("copy already has an open transfer")
+	ErrTransferSameBranch        = errors.New("copy is already at the destination branch")
+	ErrCopyInTransit             = errors.New("copy is in transit between branches")
+)
+
+// BranchTransfer 把一个副本从一个分馆调拨到另一个分馆，记录申请、发出和签收的时间
+type BranchTransfer struct {
+	ID           uint   `gorm:"primarykey"`
+	CopyID       uint   `gorm:"not null;index"`
+	FromBranchID uint   `gorm:"not null;index"`
+	ToBranchID   uint   `gorm:"not null;index"`
+	Status       string `gorm:"size:20;not null;index"`
+	Note         string `gorm:"size:1000;not null;default:''"`
+	RequestedBy  uint   `gorm:"not null"`
+
+	ShippedAt  *time.Time
+	ReceivedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+
+	// 查询时关联的副本和分馆信息，只读
+	BookID         uint   `gorm:"->;-:migration"`
+	Barcode        string `gorm:"->;-:migration"`
+	FromBranchName string `gorm:"->;-:migration"`
+	ToBranchName   string `gorm:"->;-:migration"`
+}
+
+// OpenTransferStatuses 尚未结束的调拨状态，同一副本同时只能有一个
+func OpenTransferStatuses() []string {
+	return []string{TransferStatusRequested, TransferStatusInTransit}
+}
+
+// Transition 转入 to 状态并记录对应的时间，不允许的转换返回 ErrInvalidTransferTransition
+func (t *BranchTransfer) Transition(to string, now time.Time) error {
+	for _, next := range transferTransitions[t.Status] {
+		if next != to {
+			continue
+		}
+		switch to {
+		case TransferStatusInTransit:
+			t.ShippedAt = &now
+		case TransferStatusReceived:
+			t.ReceivedAt = &now
+		}
+		t.Status = to
+		return nil
+	}
+	return ErrInvalidTransferTransition
+}
@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCategoryHasChildren = errors.New("category still has child categories")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its descendants")
+)
+
+// Category 主题分类，使用物化路径表示树结构，如 "/1/5/12/"
+type Category struct {
+	ID        uint      `gorm:"primarykey"`
+	ParentID  *uint     `gorm:"index"`
+	Name      string    `gorm:"size:255;not null"`
+	Path      string    `gorm:"size:255;not null;index"`
+	Depth     int       `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// BookCategory 图书与分类的关联
+type BookCategory struct {
+	BookID     uint `gorm:"primaryKey"`
+	CategoryID uint `gorm:"primaryKey;index"`
+}
+
+// Tag 自由标签，名称统一为小写
+type Tag struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"size:64;not null;unique"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// BookTag 图书与标签的关联
+type BookTag struct {
+	BookID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey;index"`
+}
+
+// FacetCount 分面统计中的一项
+type FacetCount struct {
+	ID    uint
+	Name  string
+	Count int64
+}
+
+// BookFacets 图书列表的分面统计
+type BookFacets struct {
+	Categories []FacetCount
+	Tags       []FacetCount
+}
+
+// BuildPath 根据父分类的路径生成本分类的路径
+func (c *Category) BuildPath(parent *Category) {
+	if parent == nil {
+		c.ParentID = nil
+		c.Path = "/" + strconv.FormatUint(uint64(c.ID), 10) + "/"
+		c.Depth = 0
+		return
+	}
+	c.ParentID = &parent.ID
+	c.Path = parent.Path + strconv.FormatUint(uint64(c.ID), 10) + "/"
+	c.Depth = parent.Depth + 1
+}
+
+// IsAncestorOf 判断本分类是否为另一分类的祖先（或其本身）
+func (c *Category) IsAncestorOf(other *Category) bool {
+	return strings.HasPrefix(other.Path, c.Path)
+}
+
+// NormalizeTag 统一标签格式：去掉首尾空白、合并连续空白并转为小写
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,58 @@
+package entities
+
+import "errors"
+
+var (
+	// ErrUnsupportedCoverImage 封面不是可识别的 JPEG、PNG 或 GIF 图片，或尺寸不在允许范围内
+	ErrUnsupportedCoverImage = errors.New("unsupported cover image")
+	// ErrCoverTooLarge 封面文件超过大小上限
+	ErrCoverTooLarge = errors.New("cover image too large")
+)
+
+const (
+	CoverSizeSmall  = "small"
+	CoverSizeMedium = "medium"
+	CoverSizeLarge  = "large"
+	// CoverOriginal 上传的原图，按原格式保存
+	CoverOriginal = "original"
+)
+
+// CoverThumbnail 缩略图规格，图片按原宽高比缩放到 MaxWidth×MaxHeight 以内
+type CoverThumbnail struct {
+	Size      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// CoverThumbnails 每张封面生成的缩略图
+var CoverThumbnails = []CoverThumbnail{
+	{Size: CoverSizeSmall, MaxWidth: 96, MaxHeight: 144},
+	{Size: CoverSizeMedium, MaxWidth: 240, MaxHeight: 360},
+	{Size: CoverSizeLarge, MaxWidth: 480, MaxHeight: 720},
+}
+
+// CoverFormats 封面原图允许的格式及保存时使用的扩展名
+var CoverFormats = map[string]string{
+	"jpeg": "jpg",
+	"png":  "png",
+	"gif":  "gif",
+}
+
+// 封面原图允许的宽高范围（像素）
+const (
+	MinCoverDimension = 32
+	MaxCoverDimension = 8000
+)
+
+// HasCover 图书是否有已上传的封面
+func (b *Book) HasCover() bool {
+	return b.CoverImage != ""
+}
+
+// CoverFileName 封面某个尺寸的文件名：缩略图统一为 JPEG，原图保留上传时的格式
+func (b *Book) CoverFileName(size string) string {
+	if size == CoverOriginal {
+		return CoverOriginal + "." + CoverFormats[b.CoverFormat]
+	}
+	return size + ".jpg"
+}
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 预约状态
+const (
+	HoldStatusWaiting   = "waiting"   // 排队中
+	HoldStatusReady     = "ready"     // 已分配副本，等待取书
+	HoldStatusFulfilled = "fulfilled" // 已借出
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired" // 超过取书期限
+)
+
+var (
+	ErrHoldAlreadyExists = errors.New("an active hold already exists for this book")
+	ErrHoldNotActive     = errors.New("hold is not active")
+	ErrHoldNotNeeded     = errors.New("copies are available for checkout")
+)
+
+// Hold 读者对某个书目的预约，按 ID 先后形成 FIFO 队列
+type Hold struct {
+	ID        uint      `gorm:"primarykey"`
+	BookID    uint      `gorm:"not null;index"`
+	UserID    uint      `gorm:"not null;index"`
+	CopyID    *uint     `gorm:"index"`
+	Status    string    `gorm:"size:20;not null;index"`
+	PlacedAt  time.Time `gorm:"not null"`
+	ReadyAt   *time.Time
+	ExpiresAt *time.Time `gorm:"index"`
+	ClosedAt  *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// NewHold 创建排队中的预约
+func NewHold(bookID, userID uint, now time.Time) *Hold {
+	return &Hold{
+		BookID:   bookID,
+		UserID:   userID,
+		Status:   HoldStatusWaiting,
+		PlacedAt: now,
+	}
+}
+
+// IsActive 预约是否仍在排队或等待取书
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldStatusWaiting || h.Status == HoldStatusReady
+}
+
+// MarkReady 为预约分配副本并开始计算取书期限
+func (h *Hold) MarkReady(copyID uint, now time.Time, shelfPeriod time.Duration) {
+	expiresAt := now.Add(shelfPeriod)
+	h.CopyID = &copyID
+	h.Status = HoldStatusReady
+	h.ReadyAt = &now
+	h.ExpiresAt = &expiresAt
+}
+
+// Close 以给定状态结束预约
+func (h *Hold) Close(status string, now time.Time) error {
+	if !h.IsActive() {
+		return ErrHoldNotActive
+	}
+	h.Status = status
+	h.ClosedAt = &now
+	return nil
+}
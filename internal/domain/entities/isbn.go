@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidISBN = errors.New("invalid ISBN")
+
+// ISBN 规范化后的 ISBN，统一为不带分隔符的 13 位数字
+type ISBN string
+
+// ParseISBN 去掉连字符和空格后校验 ISBN-10 或 ISBN-13 的校验位，ISBN-10 转换为 ISBN-13；
+// 13 位的只接受 978、979 开头的，其余 EAN-13 是普通商品条码
+func ParseISBN(raw string) (ISBN, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t':
+			return -1
+		case 'x':
+			return 'X'
+		}
+		return r
+	}, raw)
+
+	switch len(digits) {
+	case 10:
+		if !isValidISBN10(digits) {
+			return "", ErrInvalidISBN
+		}
+		body := "978" + digits[:9]
+		return ISBN(body + isbn13CheckDigit(body)), nil
+	case 13:
+		if !isAllDigits(digits) || isbn13CheckDigit(digits[:12]) != digits[12:] {
+			return "", ErrInvalidISBN
+		}
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", ErrInvalidISBN
+		}
+		return ISBN(digits), nil
+	}
+	return "", ErrInvalidISBN
+}
+
+func (i ISBN) String() string {
+	return string(i)
+}
+
+// ISBN10 返回对应的 ISBN-10，只有 978 前缀的 ISBN 才有对应的 ISBN-10
+func (i ISBN) ISBN10() (string, bool) {
+	s := string(i)
+	if len(s) != 13 || !strings.HasPrefix(s, "978") {
+		return "", false
+	}
+	body := s[3:12]
+	sum := 0
+	for k := 0; k < 9; k++ {
+		sum += int(body[k]-'0') * (10 - k)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+	return body + string(rune('0'+check)), true
+}
+
+// isValidISBN10 ISBN-10 各位按 10 到 1 加权求和须能被 11 整除，末位 X 表示 10
+func isValidISBN10(s string) bool {
+	sum := 0
+	for k := 0; k < 10; k++ {
+		var value int
+		switch {
+		case s[k] >= '0' && s[k] <= '9':
+			value = int(s[k] - '0')
+		case s[k] == 'X' && k == 9:
+			value = 10
+		default:
+			return false
+		}
+		sum += value * (10 - k)
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit 计算 12 位数字的 ISBN-13 校验位，各位交替按 1 和 3 加权
+func isbn13CheckDigit(body string) string {
+	sum := 0
+	for k := 0; k < 12; k++ {
+		weight := 1
+		if k%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[k]-'0') * weight
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
+
+func isAllDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 账户类型
+const (
+	LedgerAccountAsset   = "asset"   // 资金账户、读者应收款
+	LedgerAccountRevenue = "revenue" // 会员费、罚款等收入
+	LedgerAccountContra  = "contra"  // 收入的抵减，例如退款
+)
+
+// 系统账户的编码，读者应收款账户的编码见 UserLedgerAccountCode
+const (
+	LedgerAccountMembershipRevenue = "revenue:membership"
+	LedgerAccountChargeRevenue     = "revenue:charges"
+	LedgerAccountRefunds           = "contra:refunds"
+)
+
+// 记账凭证的类型
+const (
+	LedgerKindCharge  = "charge"  // 馆员登记的罚款、赔偿等费用
+	LedgerKindPayment = "payment" // 在线支付扣款
+	LedgerKindRefund  = "refund"  // 退款
+)
+
+// ErrLedgerUnbalanced 凭证的借贷金额不相等或没有分录
+var ErrLedgerUnbalanced = errors.New("ledger transaction is not balanced")
+
+// LedgerAccount 复式记账的账户。余额为全部分录金额之和，借方为正、贷方为负
+type LedgerAccount struct {
+	ID        uint      `gorm:"primarykey"`
+	Code      string    `gorm:"size:100;not null;unique"`
+	Name      string    `gorm:"size:255;not null"`
+	Type      string    `gorm:"size:20;not null"`
+	UserID    *uint     `gorm:"index"` // 读者应收款账户所属的读者
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LedgerTransaction 一张记账凭证，其下分录的金额之和为零
+type LedgerTransaction struct {
+	ID          uint      `gorm:"primarykey"`
+	Kind        string    `gorm:"size:20;not null"`
+	Description string    `gorm:"size:255;not null"`
+	UserID      *uint     `gorm:"index"` // 相关的读者
+	PaymentID   *uint     `gorm:"index"`
+	ActorID     *uint     // 登记费用、发起退款的馆员
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// LedgerEntry 凭证中的一条分录，Amount 借方为正、贷方为负，以分为单位
+type LedgerEntry struct {
+	ID            uint      `gorm:"primarykey"`
+	TransactionID uint      `gorm:"not null;index"`
+	AccountID     uint      `gorm:"not null;index"`
+	Amount        int64     `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+
+	Kind        string `gorm:"->;-:migration"` // 查询时关联的凭证类型和摘要，只读
+	Description string `gorm:"->;-:migration"`
+	PaymentID   *uint  `gorm:"->;-:migration"`
+}
+
+// LedgerLine 编制凭证时的一行：账户及其借（正）贷（负）金额
+type LedgerLine struct {
+	AccountID uint
+	Amount    int64
+}
+
+// LedgerPosting 待入账的凭证及其分录
+type LedgerPosting struct {
+	Transaction LedgerTransaction
+	Entries     []LedgerEntry
+}
+
+// NewLedgerPosting 编制凭证，分录金额之和不为零或没有分录时返回 ErrLedgerUnbalanced。金额为零的行被忽略
+func NewLedgerPosting(transaction LedgerTransaction, lines ...LedgerLine) (*LedgerPosting, error) {
+	posting := &LedgerPosting{Transaction: transaction}
+	var sum int64
+	for _, line := range lines {
+		if line.Amount == 0 {
+			continue
+		}
+		sum += line.Amount
+		posting.Entries = append(posting.Entries, LedgerEntry{AccountID: line.AccountID, Amount: line.Amount})
+	}
+	if sum != 0 || len(posting.Entries) == 0 {
+		return nil, ErrLedgerUnbalanced
+	}
+	return posting, nil
+}
+
+// UserLedgerAccountCode 读者应收款账户的编码。余额为正表示读者欠款，为负表示预付
+func UserLedgerAccountCode(userID uint) string {
+	return fmt.Sprintf("receivable:user:%d", userID)
+}
+
+// CashLedgerAccountCode 支付渠道资金账户的编码
+func CashLedgerAccountCode(provider string) string {
+	return "cash:" + provider
+}
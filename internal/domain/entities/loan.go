@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrLoanNotActive     = errors.New("loan is not active")
+	ErrRenewLimitReached = errors.New("renew limit reached")
+	ErrLoanOverdue       = errors.New("overdue loans cannot be renewed")
+	ErrCopyNotAvailable  = errors.New("copy is not available")
+	ErrCopyAlreadyOnLoan = errors.New("copy already has an active loan")
+)
+
+// Loan 借阅聚合根，记录一个副本被某个用户借出的全过程
+type Loan struct {
+	ID           uint       `gorm:"primarykey"`
+	CopyID       uint       `gorm:"not null;index"`
+	BookID       uint       `gorm:"not null;index"`
+	UserID       uint       `gorm:"not null;index"`
+	CheckedOutAt time.Time  `gorm:"not null"`
+	DueAt        time.Time  `gorm:"not null;index"`
+	ReturnedAt   *time.Time `gorm:"index"`
+	RenewCount   int        `gorm:"not null;default:0"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+}
+
+// NewLoan 创建一笔新的借阅
+func NewLoan(bookCopy *BookCopy, userID uint, now time.Time, period time.Duration) *Loan {
+	return &Loan{
+		CopyID:       bookCopy.ID,
+		BookID:       bookCopy.BookID,
+		UserID:       userID,
+		CheckedOutAt: now,
+		DueAt:        now.Add(period),
+	}
+}
+
+// IsActive 借阅是否尚未归还
+func (l *Loan) IsActive() bool {
+	return l.ReturnedAt == nil
+}
+
+// IsOverdue 借阅在给定时间是否已逾期
+func (l *Loan) IsOverdue(now time.Time) bool {
+	return l.IsActive() && now.After(l.DueAt)
+}
+
+// Renew 续借，从当前到期日顺延一个借期
+func (l *Loan) Renew(now time.Time, period time.Duration, maxRenewals int) error {
+	if !l.IsActive() {
+		return ErrLoanNotActive
+	}
+	if l.IsOverdue(now) {
+		return ErrLoanOverdue
+	}
+	if l.RenewCount >= maxRenewals {
+		return ErrRenewLimitReached
+	}
+	l.RenewCount++
+	l.DueAt = l.DueAt.Add(period)
+	return nil
+}
+
+// Return 归还
+func (l *Loan) Return(now time.Time) error {
+	if !l.IsActive() {
+		return ErrLoanNotActive
+	}
+	l.ReturnedAt = &now
+	return nil
+}
@@ -0,0 +1,111 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 会员资格的状态，由有效期和宽限期推算
+const (
+	MembershipStatusActive  = "active"
+	MembershipStatusGrace   = "grace"   // 已到期但仍在宽限期内，权益照常
+	MembershipStatusExpired = "expired" // 宽限期已过，读者被降为受限角色
+)
+
+var (
+	// ErrMembershipRequired 读者没有有效的会员资格或已被降为受限角色
+	ErrMembershipRequired = errors.New("an active membership is required")
+	// ErrLoanLimitReached 在借数量已达会员计划的上限
+	ErrLoanLimitReached = errors.New("loan limit of the membership plan reached")
+	// ErrDigitalAccessNotIncluded 会员计划不含电子书借阅
+	ErrDigitalAccessNotIncluded = errors.New("membership plan does not include digital access")
+	// ErrMembershipPlanInactive 计划已停用，不能再办理或续期
+	ErrMembershipPlanInactive  = errors.New("membership plan is no longer offered")
+	ErrMembershipPlanNameTaken = errors.New("a membership plan with this name already exists")
+	// ErrMembershipExists 读者已有会员资格，应续期而不是重新办理
+	ErrMembershipExists = errors.New("user already has a membership, renew it instead")
+	// ErrMembershipPlanInUse 仍有会员使用的计划不能删除，可以停用
+	ErrMembershipPlanInUse = errors.New("membership plan is in use")
+)
+
+// MembershipPlan 会员计划，规定有效期、宽限期和权益
+type MembershipPlan struct {
+	ID            uint      `gorm:"primarykey"`
+	Name          string    `gorm:"size:100;not null;unique"`
+	Description   string    `gorm:"size:1000;not null;default:''"`
+	DurationDays  int       `gorm:"not null"`           // 每次办理或续期的有效天数
+	GraceDays     int       `gorm:"not null;default:0"` // 到期后权益保留的天数，之后降为受限角色
+	MaxLoans      int       `gorm:"not null;default:0"` // 同时在借的副本数上限，0 表示不限
+	DigitalAccess bool      `gorm:"not null;default:false"`
+	Fee           int64     `gorm:"not null;default:0"` // 每期费用，以分为单位
+	Active        bool      `gorm:"not null;default:true"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// Duration 一期的时长
+func (p *MembershipPlan) Duration() time.Duration {
+	return time.Duration(p.DurationDays) * 24 * time.Hour
+}
+
+// Membership 读者的会员资格，每位读者一条，续期时延长有效期。
+// 宽限期结束后由定时任务降为受限角色并记录 DowngradedAt，续期后恢复
+type Membership struct {
+	ID           uint       `gorm:"primarykey"`
+	UserID       uint       `gorm:"not null;unique"`
+	PlanID       uint       `gorm:"not null;index"`
+	StartsAt     time.Time  `gorm:"not null"`
+	EndsAt       time.Time  `gorm:"not null"`
+	GraceEndsAt  time.Time  `gorm:"not null;index"`
+	RenewedAt    *time.Time // 最近一次续期的时间
+	DowngradedAt *time.Time // 宽限期结束后被降为受限角色的时间，续期后清空
+	Version      int        `gorm:"not null;default:0"` // 每次保存或降级时加一，降级前据此判断期间是否续期过
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
+
+	PlanName string `gorm:"->;-:migration"` // 查询时关联的计划名称，只读
+	UserName string `gorm:"->;-:migration"`
+}
+
+// NewMembership 从 startsAt 开始按计划办理一期会员
+func NewMembership(userID uint, plan *MembershipPlan, startsAt time.Time) *Membership {
+	m := &Membership{UserID: userID, StartsAt: startsAt}
+	m.setPeriod(plan, startsAt)
+	return m
+}
+
+// Renew 续期一期，可以同时更换计划。宽限期结束前续期从原到期日顺延，之后从 now 重新开始
+func (m *Membership) Renew(plan *MembershipPlan, now time.Time) {
+	from := m.EndsAt
+	if !now.Before(m.GraceEndsAt) {
+		m.StartsAt = now
+		from = now
+	}
+	m.setPeriod(plan, from)
+	m.RenewedAt = &now
+	m.DowngradedAt = nil
+}
+
+func (m *Membership) setPeriod(plan *MembershipPlan, from time.Time) {
+	m.PlanID = plan.ID
+	m.PlanName = plan.Name
+	m.EndsAt = from.Add(plan.Duration())
+	m.GraceEndsAt = m.EndsAt.Add(time.Duration(plan.GraceDays) * 24 * time.Hour)
+}
+
+// Status 会员资格在 now 时的状态
+func (m *Membership) Status(now time.Time) string {
+	switch {
+	case now.Before(m.EndsAt):
+		return MembershipStatusActive
+	case now.Before(m.GraceEndsAt):
+		return MembershipStatusGrace
+	default:
+		return MembershipStatusExpired
+	}
+}
+
+// InGoodStanding 有效期或宽限期内，享有计划的权益
+func (m *Membership) InGoodStanding(now time.Time) bool {
+	return m.Status(now) != MembershipStatusExpired
+}
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// 通知类型
+const (
+	NotificationKindSavedSearch = "saved_search" // 保存的检索有新入藏的图书
+	NotificationKindMembership  = "membership"   // 会员资格过期被降为受限角色
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    uint       `gorm:"not null;index"`
+	Kind      string     `gorm:"size:30;not null"`
+	Title     string     `gorm:"size:255;not null"`
+	Body      string     `gorm:"type:text"`
+	Data      string     `gorm:"type:text"` // 与类型相关的 JSON，例如保存的检索 ID 和图书 ID 列表
+	ReadAt    *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// SavedSearchNotificationData 保存的检索通知的附加数据
+type SavedSearchNotificationData struct {
+	SavedSearchID uint   `json:"saved_search_id"`
+	BookIDs       []uint `json:"book_ids"` // 通知中列出的图书，最多为配置的条数
+	Total         int64  `json:"total"`    // 本次检查符合条件的新书总数
+}
@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 支付的用途
+const (
+	PaymentPurposeMembership = "membership" // 办理或续期会员，扣款后按 PlanID 办理
+	PaymentPurposeBalance    = "balance"    // 结清应收款账户的欠款
+)
+
+// 支付的状态
+const (
+	PaymentStatusPending    = "pending"    // 已创建支付意向，等待付款人确认
+	PaymentStatusAuthorized = "authorized" // 付款人已确认，等待扣款
+	PaymentStatusCaptured   = "captured"   // 已扣款并入账
+	PaymentStatusRefunded   = "refunded"   // 已全额退款
+	PaymentStatusFailed     = "failed"
+)
+
+var (
+	// ErrNothingToPay 应收款账户没有欠款，或会员计划免费
+	ErrNothingToPay = errors.New("there is nothing to pay")
+	// ErrPaymentNotCapturable 支付尚未授权或已失败，不能扣款
+	ErrPaymentNotCapturable = errors.New("payment has not been authorized")
+	// ErrPaymentNotRefundable 支付尚未扣款，或退款金额超出可退余额
+	ErrPaymentNotRefundable = errors.New("payment cannot be refunded for this amount")
+	// ErrPaymentChanged 支付在处理期间被并发修改，可以重试
+	ErrPaymentChanged = errors.New("payment was changed concurrently, please retry")
+)
+
+// Payment 通过支付渠道收取的一笔款项。扣款时入账，退款可以分多次
+type Payment struct {
+	ID             uint   `gorm:"primarykey"`
+	UserID         uint   `gorm:"not null;index"`
+	Purpose        string `gorm:"size:20;not null"`
+	PlanID         *uint  // 用途为会员时办理的计划
+	Amount         int64  `gorm:"not null"` // 以分为单位
+	Currency       string `gorm:"size:3;not null"`
+	Provider       string `gorm:"size:30;not null"`
+	ProviderRef    string `gorm:"size:100;not null;uniqueIndex"` // 渠道侧的支付意向编号
+	Status         string `gorm:"size:20;not null;index"`
+	RefundedAmount int64  `gorm:"not null;default:0"`
+	CapturedAt     *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// Refundable 还可以退回的金额
+func (p *Payment) Refundable() int64 {
+	if p.Status != PaymentStatusCaptured {
+		return 0
+	}
+	return p.Amount - p.RefundedAmount
+}
+
+// 退款的状态
+const (
+	RefundStatusPending   = "pending"   // 已预留金额，正在等待渠道退款
+	RefundStatusSucceeded = "succeeded" // 渠道已退款并入账
+)
+
+// PaymentRefund 一次退款。同一笔支付内幂等键唯一，重复提交同一幂等键时返回已有的退款。
+// 调用渠道前先以 pending 状态写入并预留金额，渠道退款成功后改为 succeeded 并入账
+type PaymentRefund struct {
+	ID             uint      `gorm:"primarykey"`
+	PaymentID      uint      `gorm:"not null;uniqueIndex:idx_payment_refunds_key"`
+	IdempotencyKey string    `gorm:"size:100;not null;uniqueIndex:idx_payment_refunds_key"`
+	Amount         int64     `gorm:"not null"`
+	Reason         string    `gorm:"size:255;not null;default:''"`
+	Status         string    `gorm:"size:20;not null;default:'succeeded'"`
+	ProviderRef    string    `gorm:"size:100;not null"` // 渠道退款成功前为空
+	ActorID        uint      `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// PaymentEvent 已处理的 webhook 事件，用于忽略渠道的重复投递
+type PaymentEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	Provider  string    `gorm:"size:30;not null;uniqueIndex:idx_payment_events_provider_event"`
+	EventID   string    `gorm:"size:100;not null;uniqueIndex:idx_payment_events_provider_event"`
+	Type      string    `gorm:"size:50;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// InteractionKind 读者与图书的交互类型
+type InteractionKind uint8
+
+const (
+	InteractionView       InteractionKind = 1 // 查看图书详情
+	InteractionISBNLookup InteractionKind = 2 // 按 ISBN 查到了馆藏中的图书
+)
+
+// BookInteraction 一次读者与图书的交互，只保留计算推荐所需的字段
+type BookInteraction struct {
+	ID        uint64          `gorm:"primarykey"`
+	UserID    uint            `gorm:"not null;index:idx_book_interactions_user_book"`
+	BookID    uint            `gorm:"not null;index:idx_book_interactions_user_book;index"`
+	Kind      InteractionKind `gorm:"not null"`
+	CreatedAt time.Time       `gorm:"not null;index"`
+}
+
+// BookSimilarity 预先计算的图书相似度，每本书只保存得分最高的若干本
+type BookSimilarity struct {
+	BookID        uint      `gorm:"primaryKey"`
+	SimilarBookID uint      `gorm:"primaryKey;index"`
+	Score         float64   `gorm:"not null"`
+	Together      int       `gorm:"not null"` // 同时看过两本书的读者数
+	ComputedAt    time.Time `gorm:"not null"`
+}
+
+// CooccurrenceCounter 统计图书两两被同一读者看过的次数
+type CooccurrenceCounter struct {
+	readers  map[uint]int
+	together map[[2]uint]int
+}
+
+func NewCooccurrenceCounter() *CooccurrenceCounter {
+	return &CooccurrenceCounter{readers: make(map[uint]int), together: make(map[[2]uint]int)}
+}
+
+// Add 记录一位读者看过的全部图书，bookIDs 不能重复
+func (c *CooccurrenceCounter) Add(bookIDs []uint) {
+	for i, a := range bookIDs {
+		c.readers[a]++
+		for _, b := range bookIDs[i+1:] {
+			if a > b {
+				c.together[[2]uint{b, a}]++
+			} else {
+				c.together[[2]uint{a, b}]++
+			}
+		}
+	}
+}
+
+// Similarities 以余弦相似度（共同读者数 / √(两书读者数之积)）为每本书挑出最相似的 perBook 本，
+// 共同读者少于 minTogether 的组合视为偶然，不计入
+func (c *CooccurrenceCounter) Similarities(minTogether, perBook int, now time.Time) []BookSimilarity {
+	byBook := make(map[uint][]BookSimilarity)
+	for pair, count := range c.together {
+		if count < minTogether {
+			continue
+		}
+		score := float64(count) / math.Sqrt(float64(c.readers[pair[0]])*float64(c.readers[pair[1]]))
+		byBook[pair[0]] = append(byBook[pair[0]], BookSimilarity{BookID: pair[0], SimilarBookID: pair[1], Score: score, Together: count, ComputedAt: now})
+		byBook[pair[1]] = append(byBook[pair[1]], BookSimilarity{BookID: pair[1], SimilarBookID: pair[0], Score: score, Together: count, ComputedAt: now})
+	}
+
+	var result []BookSimilarity
+	for _, similar := range byBook {
+		sort.Slice(similar, func(i, j int) bool {
+			if similar[i].Score != similar[j].Score {
+				return similar[i].Score > similar[j].Score
+			}
+			return similar[i].SimilarBookID < similar[j].SimilarBookID
+		})
+		if len(similar) > perBook {
+			similar = similar[:perBook]
+		}
+		result = append(result, similar...)
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 书评状态
+const (
+	ReviewStatusPending  = "pending"  // 等待审核
+	ReviewStatusApproved = "approved" // 已公开，计入评分
+	ReviewStatusRejected = "rejected"
+)
+
+// 评分范围（星级）
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+var (
+	ErrReviewAlreadyExists = errors.New("you have already reviewed this book")
+	ErrReviewChanged       = errors.New("review was modified by another request")
+)
+
+// Review 读者对图书的评分和书评，每位读者对每本书只有一条
+type Review struct {
+	ID     uint   `gorm:"primarykey"`
+	BookID uint   `gorm:"not null;uniqueIndex:idx_reviews_book_user"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_reviews_book_user;index"`
+	Rating int    `gorm:"not null"`
+	Text   string `gorm:"type:text;not null"`
+	Status string `gorm:"size:20;not null;index"`
+
+	FlaggedWords   string `gorm:"size:255;not null;default:''"` // 命中的屏蔽词，逗号分隔
+	ModeratorID    *uint  // 最近一次审核的管理员
+	ModeratedAt    *time.Time
+	ModerationNote string `gorm:"size:255;not null;default:''"` // 驳回原因等审核备注
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	UserName string `gorm:"->;-:migration"` // 查询时关联读者姓名，只读
+}
+
+// IsValidRating 评分是否在允许的星级范围内
+func IsValidRating(rating int) bool {
+	return rating >= MinRating && rating <= MaxRating
+}
+
+// RatingContribution 书评计入图书评分的条数和分值，只有已公开的书评计入
+func (r *Review) RatingContribution() (count, sum int) {
+	if r.Status != ReviewStatusApproved {
+		return 0, 0
+	}
+	return 1, r.Rating
+}
+
+// BannedWordFilter 屏蔽词过滤器，不区分大小写。
+// 以空格分词的文字（拉丁字母、数字等）按整词匹配，中日文等不分词的文字按子串匹配
+type BannedWordFilter struct {
+	words []string
+}
+
+func NewBannedWordFilter(words []string) *BannedWordFilter {
+	filter := &BannedWordFilter{}
+	seen := make(map[string]bool)
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		filter.words = append(filter.words, word)
+	}
+	return filter
+}
+
+// Find 返回文本中出现的屏蔽词，按配置顺序排列
+func (f *BannedWordFilter) Find(text string) []string {
+	text = strings.ToLower(text)
+	var found []string
+	for _, word := range f.words {
+		if containsWord(text, word) {
+			found = append(found, word)
+		}
+	}
+	return found
+}
+
+// containsWord 在 text 中查找 word，词首尾若是分词文字则要求两侧不是字母或数字
+func containsWord(text, word string) bool {
+	first, _ := utf8.DecodeRuneInString(word)
+	last, _ := utf8.DecodeLastRuneInString(word)
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], word)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(word)
+
+		before, size := utf8.DecodeLastRuneInString(text[:start])
+		leftOK := size == 0 || !needsBoundary(first) || !isWordRune(before)
+		after, size := utf8.DecodeRuneInString(text[end:])
+		rightOK := size == 0 || !needsBoundary(last) || !isWordRune(after)
+		if leftOK && rightOK {
+			return true
+		}
+		_, size = utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// needsBoundary 该字符所属的文字是否以空格分词
+func needsBoundary(r rune) bool {
+	return isWordRune(r) && !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
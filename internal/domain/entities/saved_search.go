@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 保存的检索的提醒频率
+const (
+	SavedSearchHourly = "hourly"
+	SavedSearchDaily  = "daily"
+	SavedSearchWeekly = "weekly"
+)
+
+var savedSearchPeriods = map[string]time.Duration{
+	SavedSearchHourly: time.Hour,
+	SavedSearchDaily:  24 * time.Hour,
+	SavedSearchWeekly: 7 * 24 * time.Hour,
+}
+
+var (
+	ErrSavedSearchNameTaken = errors.New("a saved search with this name already exists")
+	ErrSavedSearchLimit     = errors.New("saved search limit reached")
+)
+
+// IsValidSavedSearchFrequency 频率是否为 hourly、daily、weekly 之一
+func IsValidSavedSearchFrequency(frequency string) bool {
+	_, ok := savedSearchPeriods[frequency]
+	return ok
+}
+
+// SavedSearchCriteria 保存的图书列表筛选条件，字段含义与图书列表的同名查询参数相同
+type SavedSearchCriteria struct {
+	Title         string   `json:"title,omitempty"`
+	Author        string   `json:"author,omitempty"`
+	AuthorID      uint     `json:"author_id,omitempty"`
+	CategoryID    uint     `json:"category_id,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Publisher     string   `json:"publisher,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	Format        string   `json:"format,omitempty"`
+	BranchID      uint     `json:"branch_id,omitempty"`
+	PublishedFrom int      `json:"published_from,omitempty"`
+	PublishedTo   int      `json:"published_to,omitempty"`
+	MinPages      int      `json:"min_pages,omitempty"`
+	MaxPages      int      `json:"max_pages,omitempty"`
+}
+
+// SavedSearch 用户保存的图书检索。后台任务按频率检查上次检查之后新入藏的图书，
+// 有符合条件的就给用户发通知，开启了邮件摘要的同时汇总到邮件中
+type SavedSearch struct {
+	ID          uint   `gorm:"primarykey"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_saved_searches_user_name"`
+	Name        string `gorm:"size:100;not null;uniqueIndex:idx_saved_searches_user_name"`
+	Criteria    string `gorm:"type:text;not null"` // SavedSearchCriteria 的 JSON
+	Frequency   string `gorm:"size:20;not null"`
+	EmailDigest bool   `gorm:"not null;default:false"`
+
+	LastBookID uint       `gorm:"not null;default:0"` // 已检查过的最大图书 ID，之后入藏的图书 ID 更大
+	NextRunAt  time.Time  `gorm:"not null;index"`
+	LastRunAt  *time.Time // 最近一次检查的时间，尚未检查过时为空
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// DecodeCriteria 解析保存的筛选条件
+func (s *SavedSearch) DecodeCriteria() (SavedSearchCriteria, error) {
+	var criteria SavedSearchCriteria
+	err := json.Unmarshal([]byte(s.Criteria), &criteria)
+	return criteria, err
+}
+
+// SetCriteria 保存筛选条件
+func (s *SavedSearch) SetCriteria(criteria SavedSearchCriteria) {
+	data, _ := json.Marshal(criteria)
+	s.Criteria = string(data)
+}
+
+// Period 两次检查的间隔
+func (s *SavedSearch) Period() time.Duration {
+	if period, ok := savedSearchPeriods[s.Frequency]; ok {
+		return period
+	}
+	return savedSearchPeriods[SavedSearchDaily]
+}
+
+// Schedule 以 now 为起点安排下一次检查
+func (s *SavedSearch) Schedule(now time.Time) {
+	s.NextRunAt = now.Add(s.Period())
+}
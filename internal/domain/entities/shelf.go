@@ -0,0 +1,138 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// 书架类型：三个默认书架表示阅读状态，一本书同时只在其中一个上；自定义书架不限
+const (
+	ShelfKindWantToRead = "want_to_read"
+	ShelfKindReading    = "reading"
+	ShelfKindRead       = "read"
+	ShelfKindCustom     = "custom"
+)
+
+var (
+	ErrShelfNameTaken = errors.New("a shelf with this name already exists")
+	ErrDefaultShelf   = errors.New("default shelves cannot be renamed or deleted")
+)
+
+// DefaultShelves 每位用户都有的默认书架，首次访问书架时创建
+var DefaultShelves = []Shelf{
+	{Kind: ShelfKindWantToRead, Name: "Want to Read"},
+	{Kind: ShelfKindReading, Name: "Currently Reading"},
+	{Kind: ShelfKindRead, Name: "Read"},
+}
+
+// Shelf 用户的书架。设置了 ShareToken 的书架可以通过分享链接公开查看
+type Shelf struct {
+	ID         uint      `gorm:"primarykey"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_shelves_user_name"`
+	Name       string    `gorm:"size:100;not null;uniqueIndex:idx_shelves_user_name"`
+	Kind       string    `gorm:"size:20;not null"`
+	ShareToken *string   `gorm:"size:64;uniqueIndex"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+
+	BookCount int64 `gorm:"->;-:migration"` // 查询时统计的图书数量，只读
+}
+
+// IsDefault 是否为表示阅读状态的默认书架
+func (s *Shelf) IsDefault() bool {
+	return s.Kind != ShelfKindCustom
+}
+
+// ShelfItem 书架上的一本书
+type ShelfItem struct {
+	ID      uint      `gorm:"primarykey"`
+	ShelfID uint      `gorm:"not null;uniqueIndex:idx_shelf_items_shelf_book"`
+	BookID  uint      `gorm:"not null;uniqueIndex:idx_shelf_items_shelf_book;index"`
+	UserID  uint      `gorm:"not null;index"`
+	AddedAt time.Time `gorm:"not null"`
+}
+
+// ReadingProgress 用户阅读某本书的进度，与书在哪个书架上无关
+type ReadingProgress struct {
+	UserID      uint    `gorm:"primaryKey"`
+	BookID      uint    `gorm:"primaryKey;index"`
+	CurrentPage int     `gorm:"not null;default:0"`
+	TotalPages  int     `gorm:"not null;default:0"`
+	Percent     float64 `gorm:"not null;default:0"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time `gorm:"index"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (ReadingProgress) TableName() string {
+	return "reading_progress"
+}
+
+// Start 开始阅读：记录开始日期，清除上一次读完的记录
+func (p *ReadingProgress) Start(now time.Time) {
+	if p.StartedAt == nil || p.FinishedAt != nil {
+		p.StartedAt = &now
+	}
+	p.FinishedAt = nil
+	if p.Percent >= 100 {
+		p.CurrentPage, p.Percent = 0, 0
+	}
+}
+
+// Finish 读完：进度记为 100%，没有开始日期时以完成时间为准
+func (p *ReadingProgress) Finish(now time.Time) {
+	if p.FinishedAt == nil {
+		p.FinishedAt = &now
+	}
+	if p.StartedAt == nil {
+		p.StartedAt = p.FinishedAt
+	}
+	p.Percent = 100
+	if p.TotalPages > 0 {
+		p.CurrentPage = p.TotalPages
+	}
+}
+
+// SetPages 按页数更新进度，总页数已知时同时计算百分比
+func (p *ReadingProgress) SetPages(current, total int) {
+	if total > 0 {
+		p.TotalPages = total
+	}
+	p.CurrentPage = current
+	if p.TotalPages > 0 {
+		p.Percent = float64(min(p.CurrentPage, p.TotalPages)) * 100 / float64(p.TotalPages)
+	}
+}
+
+// ReadingStats 某一年的阅读统计
+type ReadingStats struct {
+	Year              int
+	BooksFinished     int
+	PagesRead         int
+	FinishedByMonth   [12]int
+	AverageDaysToRead float64
+	CurrentlyReading  int64
+}
+
+// SummarizeReading 统计某一年读完的书；finished 为该年内完成的阅读记录
+func SummarizeReading(year int, finished []ReadingProgress, currentlyReading int64) *ReadingStats {
+	stats := &ReadingStats{Year: year, CurrentlyReading: currentlyReading}
+	var totalDays float64
+	var timed int
+	for _, progress := range finished {
+		if progress.FinishedAt == nil || progress.FinishedAt.Year() != year {
+			continue
+		}
+		stats.BooksFinished++
+		stats.PagesRead += progress.TotalPages
+		stats.FinishedByMonth[progress.FinishedAt.Month()-1]++
+		if progress.StartedAt != nil && !progress.StartedAt.After(*progress.FinishedAt) {
+			totalDays += progress.FinishedAt.Sub(*progress.StartedAt).Hours() / 24
+			timed++
+		}
+	}
+	if timed > 0 {
+		stats.AverageDaysToRead = totalDays / float64(timed)
+	}
+	return stats
+}
@@ -2,6 +2,19 @@ package entities
 
 import "time"
 
+// 用户角色
+const (
+	RoleAdmin      = "admin"
+	RoleLibrarian  = "librarian"
+	RoleUser       = "user"
+	RoleRestricted = "restricted" // 会员资格过期的读者，不能借阅、预约和下载电子书，续期后恢复为 user
+)
+
+// IsStaffRole 判断角色是否为馆员或管理员
+func IsStaffRole(role string) bool {
+	return role == RoleAdmin || role == RoleLibrarian
+}
+
 type User struct {
 	ID       uint      `gorm:"primarykey"`
 	Name     string    `gorm:"size:255;not null"`
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// 图书版本的装帧形式
+const (
+	BookFormatHardcover = "hardcover"
+	BookFormatPaperback = "paperback"
+	BookFormatEbook     = "ebook"
+	BookFormatAudiobook = "audiobook"
+)
+
+var (
+	ErrWorkMergeSelf    = errors.New("cannot merge a work into itself")
+	ErrWorkSplitAll     = errors.New("a split must leave at least one edition in the original work")
+	ErrEditionNotInWork = errors.New("book is not an edition of this work")
+)
+
+// Work 作品：同一本书的精装、平装、译本等版本各有自己的 ISBN，
+// 它们作为不同的 Book 记录归入同一个作品。每本图书都属于且只属于一个作品
+type Work struct {
+	ID        uint      `gorm:"primarykey"`
+	Title     string    `gorm:"size:255;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	EditionCount int64 `gorm:"->;-:migration"` // 查询时统计的版本数量，只读
+}
+
+// languageCodePattern ISO 639-1（两个字母）或 ISO 639-2/3（三个字母）的小写语言代码
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// IsValidBookFormat 装帧形式为空或为已知的取值之一
+func IsValidBookFormat(format string) bool {
+	switch format {
+	case "", BookFormatHardcover, BookFormatPaperback, BookFormatEbook, BookFormatAudiobook:
+		return true
+	}
+	return false
+}
+
+// IsValidLanguageCode 语言为空或为 ISO 639 语言代码
+func IsValidLanguageCode(code string) bool {
+	return code == "" || languageCodePattern.MatchString(code)
+}
@@ -0,0 +1,44 @@
+package events
+
+import "github.com/azel-ko/final-ddd/internal/domain/entities"
+
+// Event 领域事件
+type Event interface {
+	EventName() string
+}
+
+// Handler 事件处理函数
+type Handler func(event Event) error
+
+// Publisher 事件发布者，由基础设施层实现
+type Publisher interface {
+	Publish(event Event)
+}
+
+const (
+	CopyAvailableEvent   = "copy.available"
+	BookDeletedEvent     = "book.deleted"
+	PaymentCapturedEvent = "payment.captured"
+)
+
+// CopyAvailable 某个副本重新变为可借（新增、归还、预约过期等）
+type CopyAvailable struct {
+	CopyID uint
+	BookID uint
+}
+
+func (e CopyAvailable) EventName() string { return CopyAvailableEvent }
+
+// BookDeleted 图书已被删除，Book 为删除前的记录，供清理封面等附属资源
+type BookDeleted struct {
+	Book entities.Book
+}
+
+func (e BookDeleted) EventName() string { return BookDeletedEvent }
+
+// PaymentCaptured 一笔支付已扣款并入账，供按用途办理会员等后续处理
+type PaymentCaptured struct {
+	Payment entities.Payment
+}
+
+func (e PaymentCaptured) EventName() string { return PaymentCapturedEvent }
@@ -0,0 +1,15 @@
+package mail
+
+import "context"
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 发送邮件，由基础设施层实现
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
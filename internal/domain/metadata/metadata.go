@@ -0,0 +1,31 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+var (
+	// ErrNotFound 外部书目中没有该 ISBN 的记录
+	ErrNotFound = errors.New("no metadata found for ISBN")
+	// ErrUnavailable 外部书目服务不可用（超时、网络错误、非预期响应等）
+	ErrUnavailable = errors.New("metadata provider unavailable")
+)
+
+// BookMetadata 外部书目返回的图书信息，PublishDate 为 YYYY、YYYY-MM 或 YYYY-MM-DD
+type BookMetadata struct {
+	ISBN        string   `json:"isbn"`
+	Title       string   `json:"title"`
+	Authors     []string `json:"authors"`
+	Publisher   string   `json:"publisher"`
+	PublishDate string   `json:"publish_date"`
+	CoverURL    string   `json:"cover_url"`
+	Source      string   `json:"source"`
+}
+
+// MetadataProvider 按 ISBN 查询外部书目，由基础设施层实现
+type MetadataProvider interface {
+	LookupISBN(ctx context.Context, isbn entities.ISBN) (*BookMetadata, error)
+}
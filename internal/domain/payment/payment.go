@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrInvalidSignature webhook 签名不正确或已过期
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownIntent 支付渠道中不存在该支付意向
+	ErrUnknownIntent = errors.New("unknown payment intent")
+	// ErrProviderDeclined 支付渠道拒绝了扣款或退款（金额超出、尚未授权等）
+	ErrProviderDeclined = errors.New("payment provider declined the request")
+)
+
+// webhook 事件类型
+const (
+	EventAuthorized = "payment.authorized" // 付款人已确认支付，可以扣款
+	EventFailed     = "payment.failed"     // 付款人支付失败或放弃
+)
+
+// IntentRequest 创建支付意向的参数，金额以分为单位
+type IntentRequest struct {
+	Amount      int64
+	Currency    string
+	Description string
+	Reference   string // 本系统的业务引用（用途和读者），渠道侧用于对账
+}
+
+// Intent 支付渠道返回的支付意向
+type Intent struct {
+	Ref          string // 渠道侧的支付意向编号
+	ClientSecret string // 前端向渠道确认支付时使用
+}
+
+// Event 解析并校验过签名的 webhook 事件
+type Event struct {
+	ID   string // 渠道侧的事件编号，同一事件可能重复投递
+	Type string // Event* 之一，其他类型被忽略
+	Ref  string // 支付意向编号
+}
+
+// Provider 支付渠道，由基础设施层实现。扣款和退款都带幂等键，
+// 相同的幂等键重复调用时渠道只执行一次并返回相同的结果
+type Provider interface {
+	// Name 渠道名称，用于记录支付所属的渠道和资金账户
+	Name() string
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture 从已授权的支付意向扣款
+	Capture(ctx context.Context, ref string, amount int64, idempotencyKey string) error
+	// Refund 退回已扣款的全部或部分金额，返回渠道侧的退款编号
+	Refund(ctx context.Context, ref string, amount int64, idempotencyKey string) (string, error)
+	// ParseWebhook 校验签名并解析 webhook 请求体，签名不正确时返回 ErrInvalidSignature
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
@@ -1,8 +1,18 @@
 package repository
 
-import "github.com/azel-ko/final-ddd/internal/domain/entities"
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
 
 type Repository interface {
+	BookSearch
+
+	// Transaction 在一个数据库事务内执行 fn，fn 中通过 repo 的读写都在该事务内，返回错误时全部回滚。
+	// 仓储方法自身开启的事务在其中成为保存点
+	Transaction(fn func(repo Repository) error) error
+
 	// User operations
 	CreateUser(user *entities.User) error
 	GetUser(id int) (*entities.User, error)
@@ -17,5 +27,385 @@ type Repository interface {
 	UpdateBook(book *entities.Book) error
 	DeleteBook(id int) error
 	GetBookByISBN(isbn string) (*entities.Book, error)
-	ListBooks(offset, limit int, title, author string) ([]entities.Book, int64, error)
+	ListBooks(offset, limit int, filter BookFilter) ([]entities.Book, int64, error)
+	// ForEachBook 按主键顺序分批遍历全部图书，用于导出等流式处理
+	ForEachBook(batchSize int, fn func(books []entities.Book) error) error
+	// ListBooksByIDs 批量读取图书，不存在的 ID 被忽略，结果不保证顺序
+	ListBooksByIDs(ids []uint) ([]entities.Book, error)
+	// SetBookCover 只更新图书的封面版本和格式，image 为空表示移除封面
+	SetBookCover(bookID uint, image, format string) error
+
+	// Work operations
+	GetWork(id int) (*entities.Work, error)
+	UpdateWork(work *entities.Work) error
+	// ListWorks 按标题列出作品及其版本数量，title 非空时模糊匹配
+	ListWorks(offset, limit int, title string) ([]entities.Work, int64, error)
+	// ListWorkEditions 按出版日期列出作品的全部版本
+	ListWorkEditions(workID uint) ([]entities.Book, error)
+	// MergeWorks 在一个事务内把 sourceIDs 作品的全部版本移到目标作品，并删除这些作品
+	MergeWorks(targetID uint, sourceIDs []uint) error
+	// SplitWork 在一个事务内创建新作品并把 bookIDs 从原作品移入；
+	// 图书已不属于原作品时返回 entities.ErrEditionNotInWork，原作品会被移空时返回 entities.ErrWorkSplitAll
+	SplitWork(sourceID uint, work *entities.Work, bookIDs []uint) error
+
+	// Book copy operations
+	CreateBookCopy(bookCopy *entities.BookCopy) error
+	GetBookCopy(id int) (*entities.BookCopy, error)
+	GetBookCopyByBarcode(barcode string) (*entities.BookCopy, error)
+	UpdateBookCopy(bookCopy *entities.BookCopy) error
+	ListBookCopies(bookID int) ([]entities.BookCopy, error)
+	GetCopyAvailability(bookIDs []uint) (map[uint]entities.CopyAvailability, error)
+	// GetBranchAvailability 按分馆统计每本图书的副本（不含已剔除的），分馆按 ID 排序
+	GetBranchAvailability(bookIDs []uint) (map[uint][]entities.BranchAvailability, error)
+
+	// Loan operations
+	// CheckoutCopy 在一个事务内锁定副本、校验其可借并创建借阅；maxLoans 大于 0 时先锁定读者，
+	// 在借数量已达上限则返回 ErrLoanLimitReached
+	CheckoutCopy(loan *entities.Loan, maxLoans int) error
+	// ReturnLoan 在一个事务内保存归还信息并将副本置为可借
+	ReturnLoan(loan *entities.Loan) error
+	GetLoan(id int) (*entities.Loan, error)
+	// RenewLoan 仅在借阅仍未归还且续借次数未被并发修改时更新到期日
+	RenewLoan(loan *entities.Loan) error
+	ListLoansByUser(userID uint, activeOnly bool) ([]entities.Loan, error)
+
+	// Hold operations
+	// PlaceHold 在一个事务内锁定书目并校验同一读者没有重复的有效预约
+	PlaceHold(hold *entities.Hold) error
+	GetHold(id int) (*entities.Hold, error)
+	// CloseHold 结束预约；若预约已分配副本，同时把副本放回可借状态
+	CloseHold(hold *entities.Hold) error
+	// AssignCopyToNextHold 将可借副本分配给该书目排在最前面的预约，没有排队预约时返回 nil
+	AssignCopyToNextHold(copyID uint, now time.Time, shelfPeriod time.Duration) (*entities.Hold, error)
+	GetHoldQueuePosition(hold *entities.Hold) (int64, error)
+	ListHoldsByUser(userID uint, activeOnly bool) ([]entities.Hold, error)
+	ListReadyHolds() ([]entities.Hold, error)
+	ListExpiredHolds(now time.Time) ([]entities.Hold, error)
+
+	// Author operations
+	CreateAuthor(author *entities.Author) error
+	GetAuthor(id int) (*entities.Author, error)
+	UpdateAuthor(author *entities.Author) error
+	// DeleteAuthor 删除作者，仍关联图书时返回 entities.ErrAuthorInUse
+	DeleteAuthor(id int) error
+	ListAuthors(offset, limit int, name string) ([]entities.Author, int64, error)
+	FindAuthorByName(name string) (*entities.Author, error)
+	// SetBookAuthors 在一个事务内替换图书的全部责任者，并同步图书上的作者文本
+	SetBookAuthors(bookID uint, links []entities.BookAuthor, byline string) error
+	ListBookContributors(bookIDs []uint) (map[uint][]entities.Contributor, error)
+
+	// Category operations
+	// CreateCategory 创建分类并根据父分类生成物化路径，parent 为 nil 时创建顶级分类
+	CreateCategory(category *entities.Category, parent *entities.Category) error
+	GetCategory(id int) (*entities.Category, error)
+	// UpdateCategory 保存分类；路径变化（移动）时同时改写整棵子树的路径和深度
+	UpdateCategory(category *entities.Category, oldPath string) error
+	// DeleteCategory 删除分类及其图书关联，仍有子分类时返回 entities.ErrCategoryHasChildren
+	DeleteCategory(id int) error
+	ListCategories() ([]entities.Category, error)
+	SetBookCategories(bookID uint, categoryIDs []uint) error
+	ListBookCategories(bookIDs []uint) (map[uint][]entities.Category, error)
+
+	// Tag operations
+	// ListTags 列出标签及其使用次数
+	ListTags(prefix string) ([]entities.FacetCount, error)
+	GetTag(id int) (*entities.Tag, error)
+	// RenameTag 重命名标签，目标名称已存在时把图书合并到已有标签并删除原标签
+	RenameTag(tag *entities.Tag, name string) (*entities.Tag, error)
+	DeleteTag(id int) error
+	// SetBookTags 替换图书的标签，不存在的标签会被创建
+	SetBookTags(bookID uint, names []string) error
+	ListBookTags(bookIDs []uint) (map[uint][]string, error)
+
+	// BookFacets 统计满足筛选条件的图书在各分类和标签下的数量
+	BookFacets(filter BookFilter) (*entities.BookFacets, error)
+
+	// Review operations
+	// CreateReview 创建书评并同步图书评分，该读者已评价过此书时返回 entities.ErrReviewAlreadyExists
+	CreateReview(review *entities.Review) error
+	GetReview(id int) (*entities.Review, error)
+	// UpdateReview 保存书评并按前后差值增量更新图书评分；
+	// previous 为读取时的状态，若期间已被其他请求修改则返回 entities.ErrReviewChanged
+	UpdateReview(review *entities.Review, previous *entities.Review) error
+	// DeleteReview 删除书评并从图书评分中扣除，同样以读取时的状态做并发检查
+	DeleteReview(review *entities.Review) error
+	// ListBookReviews 按时间倒序列出图书指定状态的书评
+	ListBookReviews(bookID uint, status string, offset, limit int) ([]entities.Review, int64, error)
+	ListReviewsByUser(userID uint) ([]entities.Review, error)
+	// ListReviewsByStatus 审核队列：命中屏蔽词的排在前面，其余按提交先后排列
+	ListReviewsByStatus(status string, offset, limit int) ([]entities.Review, int64, error)
+
+	// Shelf operations
+	// EnsureShelves 创建用户尚未拥有的书架（按名称判断），已存在的保持不变
+	EnsureShelves(shelves []entities.Shelf) error
+	CreateShelf(shelf *entities.Shelf) error
+	GetShelf(id int) (*entities.Shelf, error)
+	GetShelfByShareToken(token string) (*entities.Shelf, error)
+	UpdateShelf(shelf *entities.Shelf) error
+	// DeleteShelf 删除书架及其上的图书（阅读进度保留）
+	DeleteShelf(id uint) error
+	// ListShelves 列出用户的书架及各自的图书数量，默认书架在前
+	ListShelves(userID uint) ([]entities.Shelf, error)
+	// AddShelfItem 把图书放上书架；exclusiveShelfIDs 中其他书架上的同一本书会被移走，用于在阅读状态间移动
+	AddShelfItem(item *entities.ShelfItem, exclusiveShelfIDs []uint) error
+	RemoveShelfItem(shelfID, bookID uint) error
+	// ListShelfItems 按加入时间倒序列出书架上的图书
+	ListShelfItems(shelfID uint, offset, limit int) ([]entities.ShelfItem, int64, error)
+	GetReadingProgress(userID uint, bookIDs []uint) (map[uint]entities.ReadingProgress, error)
+	SaveReadingProgress(progress *entities.ReadingProgress) error
+	// ListFinishedReading 列出用户在 [from, to) 内读完的记录
+	ListFinishedReading(userID uint, from, to time.Time) ([]entities.ReadingProgress, error)
+
+	// Recommendation operations
+	RecordInteraction(interaction *entities.BookInteraction) error
+	// PruneInteractions 删除 before 之前的交互记录，返回删除的条数
+	PruneInteractions(before time.Time) (int64, error)
+	// ForEachReaderHistory 按读者逐个回调其自 since 以来交互过的图书（已去重）
+	ForEachReaderHistory(since time.Time, fn func(userID uint, bookIDs []uint) error) error
+	// ReplaceBookSimilarities 在一个事务内用新的计算结果替换全部相似度
+	ReplaceBookSimilarities(similarities []entities.BookSimilarity) error
+	// ListSimilarBooks 按相似度从高到低列出与图书相似的书
+	ListSimilarBooks(bookID uint, limit int) ([]entities.BookSimilarity, error)
+
+	// Book revision operations
+	// AddBookRevision 以该书当前最大修订号加一写入修订，并回填 revision.Revision
+	AddBookRevision(revision *entities.BookRevision) error
+	GetBookRevision(bookID uint, revision int) (*entities.BookRevision, error)
+	// ListBookRevisions 按修订号倒序列出图书的修订，图书已删除时同样可查
+	ListBookRevisions(bookID uint, offset, limit int) ([]entities.BookRevision, int64, error)
+
+	// Book duplicate operations
+	// SyncBookDuplicates 在一个事务内用最新的检测结果更新审核队列：新出现的组合加入队列，
+	// 仍在队列中的组合更新得分，不再满足条件的待审核组合移出队列；已合并或已忽略的组合保持不变。返回新加入的数量
+	SyncBookDuplicates(candidates []entities.BookDuplicate) (int, error)
+	GetBookDuplicate(id int) (*entities.BookDuplicate, error)
+	// ListBookDuplicates 按得分从高到低列出指定状态的组合
+	ListBookDuplicates(status string, offset, limit int) ([]entities.BookDuplicate, int64, error)
+	// ResolveBookDuplicate 忽略一对组合；已被其他请求处理时返回 entities.ErrDuplicateResolved
+	ResolveBookDuplicate(duplicate *entities.BookDuplicate) error
+	// MergeBooks 在一个事务内把 duplicateID 的副本、借阅、预约、书评、书架、分类、标签等记录改到 survivorID，
+	// 删除 duplicateID 并留下指向 survivorID 的重定向。两本书都有的记录（如同一读者的书评）保留 survivorID 的。
+	// duplicate 不为空时同时把这对组合标记为已合并，已被其他请求处理时返回 entities.ErrDuplicateResolved。
+	// 同一读者在两本书上的预约只保留一个，因此放回可借状态的副本 ID 一并返回
+	MergeBooks(survivorID, duplicateID uint, duplicate *entities.BookDuplicate) ([]uint, error)
+	// GetBookRedirect 查找已合并图书指向的图书
+	GetBookRedirect(bookID uint) (*entities.BookRedirect, error)
+
+	// Acquisition operations
+	// CreateAcquisition 在一个事务内创建采购记录及其第一条历史
+	CreateAcquisition(acquisition *entities.Acquisition, event *entities.AcquisitionEvent) error
+	GetAcquisition(id int) (*entities.Acquisition, error)
+	// UpdateAcquisition 保存采购记录并追加历史；读取后状态已被其他请求改变时返回 entities.ErrAcquisitionChanged
+	UpdateAcquisition(acquisition *entities.Acquisition, previousStatus string, event *entities.AcquisitionEvent) error
+	// ListAcquisitions 按提交时间倒序列出采购记录
+	ListAcquisitions(filter AcquisitionFilter, offset, limit int) ([]entities.Acquisition, int64, error)
+	// FindOpenAcquisitionByISBN 查找同一 ISBN 仍在流程中的采购，用于避免重复荐购
+	FindOpenAcquisitionByISBN(isbn string) (*entities.Acquisition, error)
+	// ListAcquisitionEvents 按时间顺序列出采购的状态历史
+	ListAcquisitionEvents(acquisitionID uint) ([]entities.AcquisitionEvent, error)
+
+	// Branch operations
+	CreateBranch(branch *entities.Branch) error
+	GetBranch(id int) (*entities.Branch, error)
+	GetBranchByName(name string) (*entities.Branch, error)
+	UpdateBranch(branch *entities.Branch) error
+	// DeleteBranch 删除分馆；仍有副本或未结束的调拨时返回 entities.ErrBranchInUse
+	DeleteBranch(id uint) error
+	// ListBranches 按 ID 顺序列出全部分馆
+	ListBranches() ([]entities.Branch, error)
+
+	// Branch transfer operations
+	// CreateBranchTransfer 在一个事务内锁定副本并创建调拨；副本已有未结束的调拨时返回 entities.ErrTransferAlreadyOpen
+	CreateBranchTransfer(transfer *entities.BranchTransfer) error
+	GetBranchTransfer(id int) (*entities.BranchTransfer, error)
+	// UpdateBranchTransfer 保存调拨状态并同步副本：发出时副本由可借转为在途，签收时副本归入调入馆并恢复可借。
+	// 读取后状态已被其他请求改变时返回 entities.ErrTransferChanged，发出时副本不可借返回 entities.ErrCopyNotAvailable
+	UpdateBranchTransfer(transfer *entities.BranchTransfer, previousStatus string) error
+	// ListBranchTransfers 按申请时间倒序列出调拨
+	ListBranchTransfers(filter BranchTransferFilter, offset, limit int) ([]entities.BranchTransfer, int64, error)
+
+	// Book file operations
+	CreateBookFile(file *entities.BookFile) error
+	GetBookFile(id int) (*entities.BookFile, error)
+	// ListBookFiles 按上传顺序列出图书的电子书文件
+	ListBookFiles(bookID uint) ([]entities.BookFile, error)
+	// DeleteBookFile 删除文件记录及其下载授权
+	DeleteBookFile(id uint) error
+	// CreateDownloadGrant 在一个事务内锁定书目行并创建下载授权；
+	// 该书其他读者未过期的授权已达 limit 时返回 entities.ErrDownloadLimitReached，limit 不大于 0 表示不限
+	CreateDownloadGrant(grant *entities.DownloadGrant, limit int) error
+	GetDownloadGrant(id int) (*entities.DownloadGrant, error)
+	// FindActiveDownloadGrant 查找读者对文件在 now 时仍有效的授权，有多个时取最晚过期的
+	FindActiveDownloadGrant(fileID, userID uint, now time.Time) (*entities.DownloadGrant, error)
+	// PruneDownloadGrants 删除 before 之前过期的授权，返回删除的条数
+	PruneDownloadGrants(before time.Time) (int64, error)
+
+	// Book edit proposal operations
+	CreateBookEditProposal(proposal *entities.BookEditProposal) error
+	GetBookEditProposal(id int) (*entities.BookEditProposal, error)
+	// ListBookEditProposals 按提交时间倒序列出修改建议
+	ListBookEditProposals(filter BookEditProposalFilter, offset, limit int) ([]entities.BookEditProposal, int64, error)
+	// ResolveBookEditProposal 保存驳回或撤回的结果，仅在建议仍待审核时生效，否则返回 entities.ErrEditProposalResolved
+	ResolveBookEditProposal(proposal *entities.BookEditProposal) error
+	// ApplyBookEditProposal 在一个事务内锁定图书、检查冲突、写入提议的字段并把建议标记为已通过。
+	// 相关字段在提交后被修改过时返回 entities.ErrEditProposalConflict，建议已被处理时返回 entities.ErrEditProposalResolved。
+	// 成功时返回修改后的图书
+	ApplyBookEditProposal(proposal *entities.BookEditProposal) (*entities.Book, error)
+
+	// Saved search operations
+	CreateSavedSearch(search *entities.SavedSearch) error
+	GetSavedSearch(id int) (*entities.SavedSearch, error)
+	UpdateSavedSearch(search *entities.SavedSearch) error
+	DeleteSavedSearch(id uint) error
+	// ListSavedSearches 按创建时间列出用户保存的检索
+	ListSavedSearches(userID uint) ([]entities.SavedSearch, error)
+	// ListDueSavedSearches 列出 NextRunAt 不晚于 now 的检索，最早到期的在前
+	ListDueSavedSearches(now time.Time, limit int) ([]entities.SavedSearch, error)
+	// MarkSavedSearchRun 记录一次检查：已检查到的最大图书 ID、检查时间和下次检查时间
+	MarkSavedSearchRun(id uint, lastBookID uint, lastRunAt, nextRunAt time.Time) error
+	// MaxBookID 当前最大的图书 ID，没有图书时为 0
+	MaxBookID() (uint, error)
+
+	// Notification operations
+	CreateNotification(notification *entities.Notification) error
+	// ListNotifications 按创建时间倒序列出用户的通知，unreadOnly 为 true 时只列出未读的
+	ListNotifications(userID uint, unreadOnly bool, offset, limit int) ([]entities.Notification, int64, error)
+	CountUnreadNotifications(userID uint) (int64, error)
+	// MarkNotificationsRead 把用户的通知标记为已读，ids 为空时标记全部，返回实际标记的条数
+	MarkNotificationsRead(userID uint, ids []uint, now time.Time) (int64, error)
+
+	// Membership operations
+	CreateMembershipPlan(plan *entities.MembershipPlan) error
+	GetMembershipPlan(id int) (*entities.MembershipPlan, error)
+	GetMembershipPlanByName(name string) (*entities.MembershipPlan, error)
+	UpdateMembershipPlan(plan *entities.MembershipPlan) error
+	// DeleteMembershipPlan 删除计划，仍有会员资格使用时返回 entities.ErrMembershipPlanInUse
+	DeleteMembershipPlan(id uint) error
+	// ListMembershipPlans 按 ID 列出计划，activeOnly 为 true 时只列出仍在办理的
+	ListMembershipPlans(activeOnly bool) ([]entities.MembershipPlan, error)
+	GetMembershipByUser(userID uint) (*entities.Membership, error)
+	// SaveMembership 创建或更新会员资格；restoreRole 为 true 时在同一事务内把受限角色的读者恢复为 user
+	SaveMembership(membership *entities.Membership, restoreRole bool) error
+	// ListMemberships 按到期时间列出会员资格
+	ListMemberships(filter MembershipFilter, offset, limit int) ([]entities.Membership, int64, error)
+	// ListLapsedMemberships 按 ID 顺序列出 ID 大于 afterID、宽限期在 now 之前结束且尚未降级的会员资格
+	ListLapsedMemberships(now time.Time, afterID uint, limit int) ([]entities.Membership, error)
+	// DowngradeMembership 在一个事务内记录降级时间，并把角色仍为 user 的读者降为受限角色（馆员不受影响）；
+	// 会员资格已降级，或读取后被保存过（版本号已变化）时不做任何修改并返回 false
+	DowngradeMembership(membership *entities.Membership, now time.Time) (bool, error)
+
+	// Ledger operations
+	// EnsureLedgerAccount 按编码读取账户，不存在时创建，account 被回填为库中的记录
+	EnsureLedgerAccount(account *entities.LedgerAccount) error
+	// PostLedgerTransaction 在一个事务内写入凭证及其分录
+	PostLedgerTransaction(posting *entities.LedgerPosting) error
+	// LedgerAccountBalance 账户全部分录金额之和
+	LedgerAccountBalance(accountID uint) (int64, error)
+	// ListLedgerEntries 按时间倒序列出账户的分录，并关联凭证的类型和摘要
+	ListLedgerEntries(accountID uint, offset, limit int) ([]entities.LedgerEntry, int64, error)
+
+	// Payment operations
+	CreatePayment(payment *entities.Payment) error
+	GetPayment(id int) (*entities.Payment, error)
+	GetPaymentByProviderRef(provider, ref string) (*entities.Payment, error)
+	ListPayments(filter PaymentFilter, offset, limit int) ([]entities.Payment, int64, error)
+	// UpdatePaymentStatus 仅当支付仍处于 from 状态时改为 payment.Status，返回是否更新
+	UpdatePaymentStatus(payment *entities.Payment, from string) (bool, error)
+	// CapturePayment 在一个事务内把已授权的支付标记为已扣款并入账；支付已不在授权状态时不做修改并返回 false
+	CapturePayment(payment *entities.Payment, posting *entities.LedgerPosting) (bool, error)
+	// ReserveRefund 在一个事务内写入 pending 状态的退款并从可退余额中预留其金额。
+	// 同一幂等键的退款已存在时不做修改并返回 false；可退余额不足时返回 entities.ErrPaymentNotRefundable
+	ReserveRefund(payment *entities.Payment, refund *entities.PaymentRefund) (bool, error)
+	// CompleteRefund 在一个事务内把预留的退款标记为成功、全额退完时把支付标记为已退款并入账，payment 被回填为最新状态
+	CompleteRefund(payment *entities.Payment, refund *entities.PaymentRefund, posting *entities.LedgerPosting) error
+	// ReleaseRefund 渠道退款失败时删除预留的退款并退回其金额，之后可以用同一幂等键重试
+	ReleaseRefund(refund *entities.PaymentRefund) error
+	GetPaymentRefundByKey(paymentID uint, key string) (*entities.PaymentRefund, error)
+	ListPaymentRefunds(paymentID uint) ([]entities.PaymentRefund, error)
+	// RecordPaymentEvent 记录已处理的 webhook 事件，同一渠道的事件编号已存在时返回 false
+	RecordPaymentEvent(event *entities.PaymentEvent) (bool, error)
+	// ForgetPaymentEvent 删除事件记录，处理失败时调用以便渠道重新投递
+	ForgetPaymentEvent(event *entities.PaymentEvent) error
+}
+
+// AcquisitionFilter ListAcquisitions 的筛选条件，零值字段表示不按该字段过滤
+type AcquisitionFilter struct {
+	UserID uint
+	Status string
+}
+
+// BookEditProposalFilter ListBookEditProposals 的筛选条件，零值字段表示不按该字段过滤
+type BookEditProposalFilter struct {
+	BookID uint
+	UserID uint
+	Status string
+}
+
+// MembershipFilter ListMemberships 的筛选条件，零值字段表示不按该字段过滤
+type MembershipFilter struct {
+	PlanID uint
+	Status string    // entities.MembershipStatus* 之一，按 Now 时的状态筛选
+	Now    time.Time // Status 非空时必填
+}
+
+// PaymentFilter ListPayments 的筛选条件，零值字段表示不按该字段过滤
+type PaymentFilter struct {
+	UserID uint
+	Status string
+}
+
+// BranchTransferFilter ListBranchTransfers 的筛选条件，零值字段表示不按该字段过滤
+type BranchTransferFilter struct {
+	BranchID uint // 调出或调入该分馆
+	CopyID   uint
+	Status   string
 }
+
+// BookSearch 图书全文检索能力，各方言基于自身的全文索引实现
+type BookSearch interface {
+	// SearchBooks 检索书名或作者中包含全部检索词（按前缀匹配）的图书，按相关度排序；
+	// 全文索引不可用时退化为模糊匹配
+	// collapseEditions 为 true 时每个作品只返回一行，规则同 BookFilter.CollapseEditions
+	SearchBooks(terms []string, collapseEditions bool, offset, limit int) ([]entities.BookSearchHit, int64, error)
+}
+
+// BookFilter ListBooks 的筛选条件，零值字段表示不按该字段过滤
+type BookFilter struct {
+	Title    string
+	Author   string // 按作者姓名模糊匹配
+	AuthorID uint   // 按关联的作者精确匹配
+
+	CategoryPath string   // 分类的物化路径，匹配该分类及其所有子分类
+	Tags         []string // 需同时具备的标签（已规范化）
+
+	Publisher string // 按出版社模糊匹配
+	Language  string // ISO 639 语言代码，精确匹配
+	Format    string // 装帧形式，精确匹配
+
+	BranchID uint // 只列出在该分馆有副本（不含已剔除的）的图书
+
+	// 只列出 ID 在 (AfterID, ThroughID] 内的图书，0 表示不限，用于找出两次检查之间新入藏的图书
+	AfterID   uint
+	ThroughID uint
+
+	// 出版年份范围（含两端），0 表示不限；限定年份时不含出版日期未知的图书
+	PublishedFrom int
+	PublishedTo   int
+	// 页数范围（含两端），0 表示不限；限定页数时不含页数未知的图书
+	MinPages int
+	MaxPages int
+
+	Sort string // 排序方式，BookSort* 之一，为空时按 ID 排序
+
+	// CollapseEditions 为 true 时每个作品只返回一行（满足条件的版本中 ID 最小的一本），并统计版本数
+	CollapseEditions bool
+}
+
+// ListBooks 支持的排序方式
+const (
+	BookSortRating    = "rating"    // 平均评分从高到低，评分相同时评价多的在前
+	BookSortTitle     = "title"     // 书名、副标题按字母顺序
+	BookSortPublished = "published" // 出版日期从新到旧，日期未知的在后
+	BookSortPages     = "pages"     // 页数从少到多，页数未知的在后
+	BookSortAdded     = "added"     // 入藏时间从新到旧
+)
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrObjectNotFound 对象不存在
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrInvalidKey 对象键为空、为绝对路径或包含 ".." 等无法安全映射到存储位置的片段
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrFetchFailed 下载远程资源失败（网络错误、超时、非 2xx 响应等）
+	ErrFetchFailed = errors.New("failed to fetch remote resource")
+	// ErrHostNotAllowed 远程地址指向回环、内网等不允许访问的主机
+	ErrHostNotAllowed = errors.New("remote host not allowed")
+)
+
+// Object 读取到的对象，使用完毕后需要关闭 Body
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// ObjectStorage 按键存取二进制对象（封面图片等），键使用 "/" 分隔，由基础设施层实现
+type ObjectStorage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get 读取对象，不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// Fetcher 下载远程资源，最多读取 limit+1 字节，调用方据此判断内容是否超出上限
+type Fetcher interface {
+	Fetch(ctx context.Context, rawURL string, limit int64) ([]byte, error)
+}
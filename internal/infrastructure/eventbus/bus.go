@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Bus 进程内的同步事件总线，处理函数按订阅顺序依次执行
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]events.Handler
+}
+
+func New() *Bus {
+	return &Bus{handlers: make(map[string][]events.Handler)}
+}
+
+// Subscribe 订阅指定名称的事件
+func (b *Bus) Subscribe(name string, handler events.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish 发布事件，处理函数的错误只记录日志，不影响发布方
+func (b *Bus) Publish(event events.Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			logger.Error("event handler failed",
+				zap.String("event", event.EventName()),
+				zap.Error(err),
+			)
+		}
+	}
+}
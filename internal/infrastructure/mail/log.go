@@ -0,0 +1,21 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/azel-ko/final-ddd/internal/domain/mail"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// LogMailer 不真正发送，只把邮件写入日志，用于开发环境和未配置 SMTP 的部署
+type LogMailer struct{}
+
+func (m *LogMailer) Send(_ context.Context, msg mail.Message) error {
+	logger.Info("mail",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/azel-ko/final-ddd/internal/domain/mail"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+// NewMailer 根据配置创建邮件发送器，支持 log（默认，只写日志）和 smtp
+func NewMailer(cfg config.MailConfig) (mail.Mailer, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &LogMailer{}, nil
+	case "smtp":
+		if cfg.Host == "" || cfg.From == "" {
+			return nil, fmt.Errorf("smtp mailer requires host and from")
+		}
+		return NewSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Cache 缓存读写，*cache.RedisCache 满足该接口
+type Cache interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// cachedEntry 缓存条目，Found 为 false 表示外部书目中没有该 ISBN
+type cachedEntry struct {
+	Found    bool                   `json:"found"`
+	Metadata *metadata.BookMetadata `json:"metadata,omitempty"`
+}
+
+// CachedProvider 为书目查询加上缓存：命中记录和"没有记录"都会缓存，
+// 服务不可用的错误不缓存；缓存本身出错时直接查询外部书目
+type CachedProvider struct {
+	provider    metadata.MetadataProvider
+	cache       Cache
+	ttl         time.Duration
+	notFoundTTL time.Duration
+}
+
+func NewCachedProvider(provider metadata.MetadataProvider, cache Cache, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		provider:    provider,
+		cache:       cache,
+		ttl:         ttl,
+		notFoundTTL: ttl / 24,
+	}
+}
+
+func (p *CachedProvider) LookupISBN(ctx context.Context, isbn entities.ISBN) (*metadata.BookMetadata, error) {
+	key := "metadata:isbn:" + isbn.String()
+
+	var entry cachedEntry
+	if err := p.cache.Get(ctx, key, &entry); err == nil {
+		if !entry.Found || entry.Metadata == nil {
+			return nil, metadata.ErrNotFound
+		}
+		return entry.Metadata, nil
+	}
+
+	record, err := p.provider.LookupISBN(ctx, isbn)
+	switch {
+	case err == nil:
+		entry = cachedEntry{Found: true, Metadata: record}
+		p.store(ctx, key, entry, p.ttl)
+	case errors.Is(err, metadata.ErrNotFound):
+		p.store(ctx, key, cachedEntry{Found: false}, p.notFoundTTL)
+	}
+	return record, err
+}
+
+func (p *CachedProvider) store(ctx context.Context, key string, entry cachedEntry, ttl time.Duration) {
+	if err := p.cache.Set(ctx, key, entry, ttl); err != nil {
+		logger.Warn("failed to cache book metadata", zap.String("key", key), zap.Error(err))
+	}
+}
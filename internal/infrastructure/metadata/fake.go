@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+)
+
+const fakeSource = "fake"
+
+// FakeProvider 内存中的书目，用于本地开发和测试，不访问网络
+type FakeProvider struct {
+	records map[entities.ISBN]metadata.BookMetadata
+}
+
+// NewFakeProvider 使用给定的记录创建书目，记录的 ISBN 会先规范化，无效的记录被忽略
+func NewFakeProvider(records ...metadata.BookMetadata) *FakeProvider {
+	p := &FakeProvider{records: make(map[entities.ISBN]metadata.BookMetadata)}
+	for _, record := range records {
+		isbn, err := entities.ParseISBN(record.ISBN)
+		if err != nil {
+			continue
+		}
+		record.ISBN = isbn.String()
+		if record.Source == "" {
+			record.Source = fakeSource
+		}
+		p.records[isbn] = record
+	}
+	return p
+}
+
+func (p *FakeProvider) LookupISBN(ctx context.Context, isbn entities.ISBN) (*metadata.BookMetadata, error) {
+	record, ok := p.records[isbn]
+	if !ok {
+		return nil, metadata.ErrNotFound
+	}
+	return &record, nil
+}
+
+// SampleRecords 与初始数据中的图书对应的示例书目
+func SampleRecords() []metadata.BookMetadata {
+	return []metadata.BookMetadata{
+		{ISBN: "9780316769488", Title: "The Catcher in the Rye", Authors: []string{"J.D. Salinger"}, Publisher: "Little, Brown and Company", PublishDate: "1951-07-16"},
+		{ISBN: "9780061120084", Title: "To Kill a Mockingbird", Authors: []string{"Harper Lee"}, Publisher: "Harper Perennial Modern Classics", PublishDate: "2006-05-23"},
+		{ISBN: "9780451524935", Title: "1984", Authors: []string{"George Orwell"}, Publisher: "Signet Classic", PublishDate: "1961-01"},
+		{ISBN: "9780060853983", Title: "Good Omens", Authors: []string{"Neil Gaiman", "Terry Pratchett"}, Publisher: "William Morrow", PublishDate: "2006"},
+	}
+}
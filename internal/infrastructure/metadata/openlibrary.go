@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+)
+
+const (
+	DefaultOpenLibraryURL = "https://openlibrary.org"
+	openLibrarySource     = "openlibrary"
+)
+
+// OpenLibraryProvider 通过 Open Library Books API（jscmd=data）查询图书信息
+type OpenLibraryProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOpenLibraryProvider(baseURL string, timeout time.Duration) *OpenLibraryProvider {
+	if baseURL == "" {
+		baseURL = DefaultOpenLibraryURL
+	}
+	return &OpenLibraryProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type openLibraryBook struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Authors  []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Publishers []struct {
+		Name string `json:"name"`
+	} `json:"publishers"`
+	PublishDate string `json:"publish_date"`
+	Cover       struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *OpenLibraryProvider) LookupISBN(ctx context.Context, isbn entities.ISBN) (*metadata.BookMetadata, error) {
+	bibkey := "ISBN:" + isbn.String()
+	query := url.Values{
+		"bibkeys": {bibkey},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", metadata.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", metadata.ErrUnavailable, resp.StatusCode)
+	}
+
+	// 没有记录时返回空对象
+	var result map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", metadata.ErrUnavailable, err)
+	}
+	book, ok := result[bibkey]
+	if !ok || book.Title == "" {
+		return nil, metadata.ErrNotFound
+	}
+
+	record := &metadata.BookMetadata{
+		ISBN:        isbn.String(),
+		Title:       book.Title,
+		PublishDate: entities.NormalizePublishDate(book.PublishDate),
+		Source:      openLibrarySource,
+	}
+	if book.Subtitle != "" {
+		record.Title += ": " + book.Subtitle
+	}
+	for _, author := range book.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			record.Authors = append(record.Authors, name)
+		}
+	}
+	if len(book.Publishers) > 0 {
+		record.Publisher = book.Publishers[0].Name
+	}
+	switch {
+	case book.Cover.Large != "":
+		record.CoverURL = book.Cover.Large
+	case book.Cover.Medium != "":
+		record.CoverURL = book.Cover.Medium
+	default:
+		record.CoverURL = book.Cover.Small
+	}
+	return record, nil
+}
@@ -0,0 +1,41 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// NewProvider 根据配置创建书目查询；未配置或无法识别的 provider 关闭外部查询
+func NewProvider(cfg config.MetadataConfig, cache Cache) metadata.MetadataProvider {
+	var provider metadata.MetadataProvider
+	switch cfg.Provider {
+	case "openlibrary":
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		provider = NewOpenLibraryProvider(cfg.BaseURL, timeout)
+	case "fake":
+		provider = NewFakeProvider(SampleRecords()...)
+	default:
+		return disabledProvider{}
+	}
+
+	if cfg.CacheTTL > 0 && cache != nil {
+		provider = NewCachedProvider(provider, cache, cfg.CacheTTL)
+	}
+	return provider
+}
+
+// disabledProvider 关闭外部查询时使用，所有查询都返回不可用
+type disabledProvider struct{}
+
+func (disabledProvider) LookupISBN(ctx context.Context, isbn entities.ISBN) (*metadata.BookMetadata, error) {
+	return nil, metadata.ErrUnavailable
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CirculationTablesMigration 创建馆藏副本和借阅表的迁移
+type CirculationTablesMigration struct{}
+
+func (m *CirculationTablesMigration) ID() string {
+	return "003_create_circulation_tables"
+}
+
+func (m *CirculationTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookCopy{}, &Loan{})
+}
+
+func (m *CirculationTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&Loan{}, &BookCopy{})
+}
+
+// BookCopy 定义馆藏副本表的结构
+type BookCopy struct {
+	ID        uint      `gorm:"primarykey"`
+	BookID    uint      `gorm:"not null;index"`
+	Barcode   string    `gorm:"size:64;not null;unique"`
+	Condition string    `gorm:"size:20;not null"`
+	Status    string    `gorm:"size:20;not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// Loan 定义借阅表的结构
+type Loan struct {
+	ID           uint       `gorm:"primarykey"`
+	CopyID       uint       `gorm:"not null;index"`
+	BookID       uint       `gorm:"not null;index"`
+	UserID       uint       `gorm:"not null;index"`
+	CheckedOutAt time.Time  `gorm:"not null"`
+	DueAt        time.Time  `gorm:"not null;index"`
+	ReturnedAt   *time.Time `gorm:"index"`
+	RenewCount   int        `gorm:"not null;default:0"`
+	CreatedAt    time.Time  `gorm:"not null"`
+	UpdatedAt    time.Time  `gorm:"not null"`
+}
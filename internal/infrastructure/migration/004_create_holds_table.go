@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HoldTableMigration 创建预约表的迁移
+type HoldTableMigration struct{}
+
+func (m *HoldTableMigration) ID() string {
+	return "004_create_holds_table"
+}
+
+func (m *HoldTableMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&Hold{})
+}
+
+func (m *HoldTableMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&Hold{})
+}
+
+// Hold 定义预约表的结构
+type Hold struct {
+	ID        uint      `gorm:"primarykey"`
+	BookID    uint      `gorm:"not null;index"`
+	UserID    uint      `gorm:"not null;index"`
+	CopyID    *uint     `gorm:"index"`
+	Status    string    `gorm:"size:20;not null;index"`
+	PlacedAt  time.Time `gorm:"not null"`
+	ReadyAt   *time.Time
+	ExpiresAt *time.Time `gorm:"index"`
+	ClosedAt  *time.Time
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
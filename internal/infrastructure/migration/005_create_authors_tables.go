@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// AuthorTablesMigration 创建作者表及图书-作者关联表，并把已有图书的作者文本拆分为作者记录
+type AuthorTablesMigration struct{}
+
+func (m *AuthorTablesMigration) ID() string {
+	return "005_create_authors_tables"
+}
+
+func (m *AuthorTablesMigration) Up(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Author{}, &BookAuthor{}); err != nil {
+		return err
+	}
+
+	var books []Book
+	if err := db.Select("id", "author").Find(&books).Error; err != nil {
+		return err
+	}
+
+	// 同名作者视为同一人
+	authorIDs := make(map[string]uint)
+	for _, book := range books {
+		// 作者文本中同一人重复出现时只关联一次，否则关联表主键冲突
+		seen := make(map[uint]bool)
+		for _, name := range entities.SplitAuthorNames(book.Author) {
+			authorID, ok := authorIDs[name]
+			if !ok {
+				author := Author{
+					Name:      name,
+					SortName:  entities.DeriveSortName(name),
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}
+				if err := db.Create(&author).Error; err != nil {
+					return err
+				}
+				authorID = author.ID
+				authorIDs[name] = authorID
+			}
+
+			if seen[authorID] {
+				continue
+			}
+			seen[authorID] = true
+
+			link := BookAuthor{BookID: book.ID, AuthorID: authorID, Role: entities.AuthorRoleAuthor, Position: len(seen) - 1}
+			if err := db.Create(&link).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *AuthorTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookAuthor{}, &Author{})
+}
+
+// Author 定义作者表的结构
+type Author struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"size:255;not null;index"`
+	SortName  string    `gorm:"size:255;not null;index"`
+	Bio       string    `gorm:"type:text"`
+	ISNI      string    `gorm:"size:32"`
+	ORCID     string    `gorm:"size:32"`
+	VIAF      string    `gorm:"size:32"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// BookAuthor 定义图书-作者关联表的结构
+type BookAuthor struct {
+	BookID   uint   `gorm:"primaryKey"`
+	AuthorID uint   `gorm:"primaryKey;index"`
+	Role     string `gorm:"primaryKey;size:20"`
+	Position int    `gorm:"not null;default:0"`
+}
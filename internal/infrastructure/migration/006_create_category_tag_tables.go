@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CategoryTagTablesMigration 创建分类树、标签及其与图书关联的表
+type CategoryTagTablesMigration struct{}
+
+func (m *CategoryTagTablesMigration) ID() string {
+	return "006_create_category_tag_tables"
+}
+
+func (m *CategoryTagTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&Category{}, &BookCategory{}, &Tag{}, &BookTag{})
+}
+
+func (m *CategoryTagTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookTag{}, &Tag{}, &BookCategory{}, &Category{})
+}
+
+// Category 定义分类表的结构
+type Category struct {
+	ID        uint      `gorm:"primarykey"`
+	ParentID  *uint     `gorm:"index"`
+	Name      string    `gorm:"size:255;not null"`
+	Path      string    `gorm:"size:255;not null;index"`
+	Depth     int       `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// BookCategory 定义图书-分类关联表的结构
+type BookCategory struct {
+	BookID     uint `gorm:"primaryKey"`
+	CategoryID uint `gorm:"primaryKey;index"`
+}
+
+// Tag 定义标签表的结构
+type Tag struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"size:64;not null;unique"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// BookTag 定义图书-标签关联表的结构
+type BookTag struct {
+	BookID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey;index"`
+}
@@ -0,0 +1,93 @@
+package migration
+
+import (
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// BookSearchIndexMigration 按数据库方言为图书的书名和作者建立全文索引：
+// Postgres 使用 tsvector 生成列加 GIN 索引，MySQL 使用 FULLTEXT 索引，
+// SQLite 使用 FTS5 外部内容表并通过触发器与 books 表保持同步
+type BookSearchIndexMigration struct{}
+
+func (m *BookSearchIndexMigration) ID() string {
+	return "007_create_book_search_index"
+}
+
+func (m *BookSearchIndexMigration) Up(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return execAll(db,
+			`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(author, '')), 'B')
+			) STORED`,
+			`CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN (search_vector)`,
+		)
+	case "mysql":
+		if db.Migrator().HasIndex("books", "idx_books_fulltext") {
+			return nil
+		}
+		return db.Exec(`CREATE FULLTEXT INDEX idx_books_fulltext ON books (title, author)`).Error
+	case "sqlite":
+		// FTS5 需要以 sqlite_fts5 构建标签编译驱动，未启用时检索退化为模糊匹配
+		var enabled bool
+		if err := db.Raw(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled).Error; err != nil {
+			return err
+		}
+		if !enabled {
+			logger.Warn("SQLite is built without FTS5, book search falls back to LIKE")
+			return nil
+		}
+		return execAll(db,
+			`CREATE VIRTUAL TABLE IF NOT EXISTS books_fts USING fts5(
+				title, author, content='books', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+			)`,
+			`CREATE TRIGGER IF NOT EXISTS books_fts_ai AFTER INSERT ON books BEGIN
+				INSERT INTO books_fts(rowid, title, author) VALUES (new.id, new.title, new.author);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS books_fts_ad AFTER DELETE ON books BEGIN
+				INSERT INTO books_fts(books_fts, rowid, title, author) VALUES ('delete', old.id, old.title, old.author);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS books_fts_au AFTER UPDATE ON books BEGIN
+				INSERT INTO books_fts(books_fts, rowid, title, author) VALUES ('delete', old.id, old.title, old.author);
+				INSERT INTO books_fts(rowid, title, author) VALUES (new.id, new.title, new.author);
+			END`,
+			`INSERT INTO books_fts(books_fts) VALUES ('rebuild')`,
+		)
+	}
+	return nil
+}
+
+func (m *BookSearchIndexMigration) Down(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return execAll(db,
+			`DROP INDEX IF EXISTS idx_books_search_vector`,
+			`ALTER TABLE books DROP COLUMN IF EXISTS search_vector`,
+		)
+	case "mysql":
+		if !db.Migrator().HasIndex("books", "idx_books_fulltext") {
+			return nil
+		}
+		return db.Exec(`DROP INDEX idx_books_fulltext ON books`).Error
+	case "sqlite":
+		return execAll(db,
+			`DROP TRIGGER IF EXISTS books_fts_ai`,
+			`DROP TRIGGER IF EXISTS books_fts_ad`,
+			`DROP TRIGGER IF EXISTS books_fts_au`,
+			`DROP TABLE IF EXISTS books_fts`,
+		)
+	}
+	return nil
+}
+
+// execAll 依次执行多条 SQL 语句，遇到错误立即返回
+func execAll(db *gorm.DB, statements ...string) error {
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NormalizeISBNMigration 把已有图书的 ISBN 规范化为不带分隔符的 ISBN-13。
+// 无法通过校验的 ISBN 保持原样；多本图书规范化后得到同一个 ISBN 时视为冲突，
+// 这些图书都不修改，由管理员合并或更正后再处理。两种情况都会记录到日志
+type NormalizeISBNMigration struct{}
+
+func (m *NormalizeISBNMigration) ID() string {
+	return "008_normalize_isbn"
+}
+
+func (m *NormalizeISBNMigration) Up(db *gorm.DB) error {
+	var books []Book
+	if err := db.Select("id", "isbn").Order("id").Find(&books).Error; err != nil {
+		return err
+	}
+
+	groups := make(map[entities.ISBN][]Book)
+	var order []entities.ISBN
+	for _, book := range books {
+		isbn, err := entities.ParseISBN(book.ISBN)
+		if err != nil {
+			logger.Warn("book has an invalid ISBN, left unchanged",
+				zap.Uint("bookID", book.ID), zap.String("isbn", book.ISBN))
+			continue
+		}
+		if _, ok := groups[isbn]; !ok {
+			order = append(order, isbn)
+		}
+		groups[isbn] = append(groups[isbn], book)
+	}
+
+	normalized, conflicts := 0, 0
+	for _, isbn := range order {
+		group := groups[isbn]
+		if len(group) > 1 {
+			conflicts++
+			ids := make([]uint, len(group))
+			values := make([]string, len(group))
+			for i, book := range group {
+				ids[i] = book.ID
+				values[i] = book.ISBN
+			}
+			logger.Warn("books share the same ISBN after normalisation, left unchanged",
+				zap.String("isbn", isbn.String()), zap.Uints("bookIDs", ids), zap.Strings("values", values))
+			continue
+		}
+
+		book := group[0]
+		if book.ISBN == isbn.String() {
+			continue
+		}
+		if err := db.Model(&Book{}).Where("id = ?", book.ID).Update("isbn", isbn.String()).Error; err != nil {
+			return err
+		}
+		normalized++
+	}
+
+	logger.Info("ISBN normalisation finished",
+		zap.Int("normalized", normalized), zap.Int("conflicts", conflicts))
+	return nil
+}
+
+// Down 规范化无法还原，回滚时不做任何处理
+func (m *NormalizeISBNMigration) Down(db *gorm.DB) error {
+	return nil
+}
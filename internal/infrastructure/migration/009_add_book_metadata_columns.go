@@ -0,0 +1,47 @@
+package migration
+
+import "gorm.io/gorm"
+
+// BookMetadataColumnsMigration 为图书表增加出版社、出版日期和封面地址列
+type BookMetadataColumnsMigration struct{}
+
+func (m *BookMetadataColumnsMigration) ID() string {
+	return "009_add_book_metadata_columns"
+}
+
+var bookMetadataColumns = []string{"Publisher", "PublishDate", "CoverURL"}
+
+func (m *BookMetadataColumnsMigration) Up(db *gorm.DB) error {
+	for _, column := range bookMetadataColumns {
+		if db.Migrator().HasColumn(&BookMetadata{}, column) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(&BookMetadata{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *BookMetadataColumnsMigration) Down(db *gorm.DB) error {
+	for _, column := range bookMetadataColumns {
+		if !db.Migrator().HasColumn(&BookMetadata{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&BookMetadata{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BookMetadata 图书表中本次新增的列
+type BookMetadata struct {
+	Publisher   string `gorm:"size:255;not null;default:''"`
+	PublishDate string `gorm:"size:10;not null;default:''"`
+	CoverURL    string `gorm:"size:512;not null;default:''"`
+}
+
+func (BookMetadata) TableName() string {
+	return "books"
+}
@@ -0,0 +1,46 @@
+package migration
+
+import "gorm.io/gorm"
+
+// BookCoverColumnsMigration 为图书表增加已上传封面的版本和格式列
+type BookCoverColumnsMigration struct{}
+
+func (m *BookCoverColumnsMigration) ID() string {
+	return "010_add_book_cover_columns"
+}
+
+var bookCoverColumns = []string{"CoverImage", "CoverFormat"}
+
+func (m *BookCoverColumnsMigration) Up(db *gorm.DB) error {
+	for _, column := range bookCoverColumns {
+		if db.Migrator().HasColumn(&BookCover{}, column) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(&BookCover{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *BookCoverColumnsMigration) Down(db *gorm.DB) error {
+	for _, column := range bookCoverColumns {
+		if !db.Migrator().HasColumn(&BookCover{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&BookCover{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BookCover 图书表中本次新增的列
+type BookCover struct {
+	CoverImage  string `gorm:"size:32;not null;default:''"`
+	CoverFormat string `gorm:"size:8;not null;default:''"`
+}
+
+func (BookCover) TableName() string {
+	return "books"
+}
@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReviewTableMigration 创建书评表，并为图书表增加评分汇总列
+type ReviewTableMigration struct{}
+
+func (m *ReviewTableMigration) ID() string {
+	return "011_create_reviews_table"
+}
+
+var bookRatingColumns = []string{"RatingCount", "RatingSum", "RatingAverage"}
+
+func (m *ReviewTableMigration) Up(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Review{}); err != nil {
+		return err
+	}
+	for _, column := range bookRatingColumns {
+		if db.Migrator().HasColumn(&BookRating{}, column) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(&BookRating{}, column); err != nil {
+			return err
+		}
+	}
+	if !db.Migrator().HasIndex(&BookRating{}, "idx_books_rating_average") {
+		return db.Migrator().CreateIndex(&BookRating{}, "idx_books_rating_average")
+	}
+	return nil
+}
+
+func (m *ReviewTableMigration) Down(db *gorm.DB) error {
+	for _, column := range bookRatingColumns {
+		if !db.Migrator().HasColumn(&BookRating{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&BookRating{}, column); err != nil {
+			return err
+		}
+	}
+	return db.Migrator().DropTable(&Review{})
+}
+
+// Review 定义书评表的结构
+type Review struct {
+	ID             uint   `gorm:"primarykey"`
+	BookID         uint   `gorm:"not null;uniqueIndex:idx_reviews_book_user"`
+	UserID         uint   `gorm:"not null;uniqueIndex:idx_reviews_book_user;index"`
+	Rating         int    `gorm:"not null"`
+	Text           string `gorm:"type:text;not null"`
+	Status         string `gorm:"size:20;not null;index"`
+	FlaggedWords   string `gorm:"size:255;not null;default:''"`
+	ModeratorID    *uint
+	ModeratedAt    *time.Time
+	ModerationNote string    `gorm:"size:255;not null;default:''"`
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+// BookRating 图书表中本次新增的评分汇总列
+type BookRating struct {
+	RatingCount   int64   `gorm:"not null;default:0"`
+	RatingSum     int64   `gorm:"not null;default:0"`
+	RatingAverage float64 `gorm:"not null;default:0;index:idx_books_rating_average"`
+}
+
+func (BookRating) TableName() string {
+	return "books"
+}
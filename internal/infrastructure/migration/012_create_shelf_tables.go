@@ -0,0 +1,58 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShelfTablesMigration 创建书架、书架图书和阅读进度表
+type ShelfTablesMigration struct{}
+
+func (m *ShelfTablesMigration) ID() string {
+	return "012_create_shelf_tables"
+}
+
+func (m *ShelfTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&Shelf{}, &ShelfItem{}, &ReadingProgress{})
+}
+
+func (m *ShelfTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&ReadingProgress{}, &ShelfItem{}, &Shelf{})
+}
+
+// Shelf 定义书架表的结构
+type Shelf struct {
+	ID         uint      `gorm:"primarykey"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_shelves_user_name"`
+	Name       string    `gorm:"size:100;not null;uniqueIndex:idx_shelves_user_name"`
+	Kind       string    `gorm:"size:20;not null"`
+	ShareToken *string   `gorm:"size:64;uniqueIndex"`
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+// ShelfItem 定义书架图书表的结构
+type ShelfItem struct {
+	ID      uint      `gorm:"primarykey"`
+	ShelfID uint      `gorm:"not null;uniqueIndex:idx_shelf_items_shelf_book"`
+	BookID  uint      `gorm:"not null;uniqueIndex:idx_shelf_items_shelf_book;index"`
+	UserID  uint      `gorm:"not null;index"`
+	AddedAt time.Time `gorm:"not null"`
+}
+
+// ReadingProgress 定义阅读进度表的结构
+type ReadingProgress struct {
+	UserID      uint    `gorm:"primaryKey"`
+	BookID      uint    `gorm:"primaryKey;index"`
+	CurrentPage int     `gorm:"not null;default:0"`
+	TotalPages  int     `gorm:"not null;default:0"`
+	Percent     float64 `gorm:"not null;default:0"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time `gorm:"index"`
+	UpdatedAt   time.Time  `gorm:"not null"`
+}
+
+func (ReadingProgress) TableName() string {
+	return "reading_progress"
+}
@@ -0,0 +1,40 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecommendationTablesMigration 创建图书交互记录和图书相似度表
+type RecommendationTablesMigration struct{}
+
+func (m *RecommendationTablesMigration) ID() string {
+	return "013_create_recommendation_tables"
+}
+
+func (m *RecommendationTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookInteraction{}, &BookSimilarity{})
+}
+
+func (m *RecommendationTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookSimilarity{}, &BookInteraction{})
+}
+
+// BookInteraction 定义图书交互记录表的结构
+type BookInteraction struct {
+	ID        uint64    `gorm:"primarykey"`
+	UserID    uint      `gorm:"not null;index:idx_book_interactions_user_book"`
+	BookID    uint      `gorm:"not null;index:idx_book_interactions_user_book;index"`
+	Kind      uint8     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// BookSimilarity 定义图书相似度表的结构
+type BookSimilarity struct {
+	BookID        uint      `gorm:"primaryKey"`
+	SimilarBookID uint      `gorm:"primaryKey;index"`
+	Score         float64   `gorm:"not null"`
+	Together      int       `gorm:"not null"`
+	ComputedAt    time.Time `gorm:"not null"`
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorksTableMigration 创建作品表，为图书表增加作品、装帧形式和语言列，
+// 并为已有的每本图书各建一个同名作品
+type WorksTableMigration struct{}
+
+func (m *WorksTableMigration) ID() string {
+	return "014_create_works_table"
+}
+
+var bookEditionColumns = []string{"WorkID", "Format", "Language"}
+
+// workBackfillBatchSize 回填作品时每批处理的图书数量
+const workBackfillBatchSize = 500
+
+func (m *WorksTableMigration) Up(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Work{}); err != nil {
+		return err
+	}
+	for _, column := range bookEditionColumns {
+		if db.Migrator().HasColumn(&BookEdition{}, column) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(&BookEdition{}, column); err != nil {
+			return err
+		}
+	}
+	if !db.Migrator().HasIndex(&BookEdition{}, "idx_books_work_id") {
+		if err := db.Migrator().CreateIndex(&BookEdition{}, "idx_books_work_id"); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for {
+			var books []Book
+			if err := tx.Select("id", "title").Where("work_id = 0").Order("id").Limit(workBackfillBatchSize).Find(&books).Error; err != nil {
+				return err
+			}
+			if len(books) == 0 {
+				return nil
+			}
+			for _, book := range books {
+				work := Work{Title: book.Title, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+				if err := tx.Create(&work).Error; err != nil {
+					return err
+				}
+				if err := tx.Table("books").Where("id = ?", book.ID).Update("work_id", work.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+func (m *WorksTableMigration) Down(db *gorm.DB) error {
+	for _, column := range bookEditionColumns {
+		if !db.Migrator().HasColumn(&BookEdition{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&BookEdition{}, column); err != nil {
+			return err
+		}
+	}
+	return db.Migrator().DropTable(&Work{})
+}
+
+// Work 定义作品表的结构
+type Work struct {
+	ID        uint      `gorm:"primarykey"`
+	Title     string    `gorm:"size:255;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// BookEdition 图书表中本次新增的版本信息列
+type BookEdition struct {
+	WorkID   uint   `gorm:"not null;default:0;index:idx_books_work_id"`
+	Format   string `gorm:"size:20;not null;default:''"`
+	Language string `gorm:"size:3;not null;default:''"`
+}
+
+func (BookEdition) TableName() string {
+	return "books"
+}
@@ -0,0 +1,34 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookRevisionsTableMigration 创建图书修订历史表
+type BookRevisionsTableMigration struct{}
+
+func (m *BookRevisionsTableMigration) ID() string {
+	return "015_create_book_revisions_table"
+}
+
+func (m *BookRevisionsTableMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookRevision{})
+}
+
+func (m *BookRevisionsTableMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookRevision{})
+}
+
+// BookRevision 定义图书修订表的结构
+type BookRevision struct {
+	ID           uint   `gorm:"primarykey"`
+	BookID       uint   `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Revision     int    `gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Action       string `gorm:"size:20;not null"`
+	ActorID      *uint  `gorm:"index"`
+	RevertedFrom *int
+	Snapshot     string    `gorm:"type:text;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
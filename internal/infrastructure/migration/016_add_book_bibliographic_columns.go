@@ -0,0 +1,77 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookBibliographicColumnsMigration 为图书表增加副标题、页数、版次和简介列。
+// 创建时间和更新时间列在建表时就已存在，这里只在缺失时补上
+type BookBibliographicColumnsMigration struct{}
+
+func (m *BookBibliographicColumnsMigration) ID() string {
+	return "016_add_book_bibliographic_columns"
+}
+
+var bookBibliographicColumns = []string{"Subtitle", "PageCount", "Edition", "Description"}
+
+// bookTimestampColumns 建表迁移已创建，回滚本迁移时保留
+var bookTimestampColumns = []string{"CreatedAt", "UpdatedAt"}
+
+var bookSortIndexes = []string{"idx_books_publish_date", "idx_books_created_at"}
+
+func (m *BookBibliographicColumnsMigration) Up(db *gorm.DB) error {
+	for _, column := range append(bookBibliographicColumns, bookTimestampColumns...) {
+		if db.Migrator().HasColumn(&BookBibliographic{}, column) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(&BookBibliographic{}, column); err != nil {
+			return err
+		}
+	}
+	for _, index := range bookSortIndexes {
+		if db.Migrator().HasIndex(&BookBibliographic{}, index) {
+			continue
+		}
+		if err := db.Migrator().CreateIndex(&BookBibliographic{}, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *BookBibliographicColumnsMigration) Down(db *gorm.DB) error {
+	for _, index := range bookSortIndexes {
+		if !db.Migrator().HasIndex(&BookBibliographic{}, index) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(&BookBibliographic{}, index); err != nil {
+			return err
+		}
+	}
+	for _, column := range bookBibliographicColumns {
+		if !db.Migrator().HasColumn(&BookBibliographic{}, column) {
+			continue
+		}
+		if err := db.Migrator().DropColumn(&BookBibliographic{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BookBibliographic 图书表中本次新增的列，以及用于排序的索引
+type BookBibliographic struct {
+	Subtitle    string    `gorm:"size:255;not null;default:''"`
+	PublishDate string    `gorm:"size:10;not null;default:'';index:idx_books_publish_date"`
+	PageCount   int       `gorm:"not null;default:0"`
+	Edition     string    `gorm:"size:100;not null;default:''"`
+	Description string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_books_created_at"`
+	UpdatedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (BookBibliographic) TableName() string {
+	return "books"
+}
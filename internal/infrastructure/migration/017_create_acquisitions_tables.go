@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AcquisitionTablesMigration 创建荐购采购表及其状态历史表
+type AcquisitionTablesMigration struct{}
+
+func (m *AcquisitionTablesMigration) ID() string {
+	return "017_create_acquisitions_tables"
+}
+
+func (m *AcquisitionTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&Acquisition{}, &AcquisitionEvent{})
+}
+
+func (m *AcquisitionTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&AcquisitionEvent{}, &Acquisition{})
+}
+
+// Acquisition 定义荐购采购表的结构
+type Acquisition struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"not null;index"`
+	ISBN      string    `gorm:"size:20;not null;default:'';index"`
+	Title     string    `gorm:"size:255;not null;default:''"`
+	Author    string    `gorm:"size:255;not null;default:''"`
+	Note      string    `gorm:"size:1000;not null;default:''"`
+	Status    string    `gorm:"size:20;not null;index"`
+	Vendor    string    `gorm:"size:255;not null;default:''"`
+	Quantity  int       `gorm:"not null;default:0"`
+	CostCents int64     `gorm:"not null;default:0"`
+	Currency  string    `gorm:"size:3;not null;default:''"`
+	BookID    *uint     `gorm:"index"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// AcquisitionEvent 定义采购状态历史表的结构
+type AcquisitionEvent struct {
+	ID            uint      `gorm:"primarykey"`
+	AcquisitionID uint      `gorm:"not null;index"`
+	FromStatus    string    `gorm:"size:20;not null;default:''"`
+	ToStatus      string    `gorm:"size:20;not null"`
+	ActorID       uint      `gorm:"not null"`
+	Note          string    `gorm:"size:1000;not null;default:''"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
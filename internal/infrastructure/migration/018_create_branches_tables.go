@@ -0,0 +1,93 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BranchTablesMigration 创建分馆表和馆际调拨表，为副本表增加所在分馆列。
+// 没有任何分馆时建一个默认的总馆，已有副本都归入总馆
+type BranchTablesMigration struct{}
+
+func (m *BranchTablesMigration) ID() string {
+	return "018_create_branches_tables"
+}
+
+// defaultBranchName 迁移时创建的默认分馆
+const defaultBranchName = "Main Library"
+
+func (m *BranchTablesMigration) Up(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Branch{}, &BranchTransfer{}); err != nil {
+		return err
+	}
+	if !db.Migrator().HasColumn(&CopyBranch{}, "BranchID") {
+		if err := db.Migrator().AddColumn(&CopyBranch{}, "BranchID"); err != nil {
+			return err
+		}
+	}
+	if !db.Migrator().HasIndex(&CopyBranch{}, "idx_book_copies_branch_id") {
+		if err := db.Migrator().CreateIndex(&CopyBranch{}, "idx_book_copies_branch_id"); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Branch{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		branch := Branch{Name: defaultBranchName, Timezone: "UTC", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := tx.Create(&branch).Error; err != nil {
+			return err
+		}
+		return tx.Model(&CopyBranch{}).Where("branch_id = 0").Update("branch_id", branch.ID).Error
+	})
+}
+
+func (m *BranchTablesMigration) Down(db *gorm.DB) error {
+	if db.Migrator().HasColumn(&CopyBranch{}, "BranchID") {
+		if err := db.Migrator().DropColumn(&CopyBranch{}, "BranchID"); err != nil {
+			return err
+		}
+	}
+	return db.Migrator().DropTable(&BranchTransfer{}, &Branch{})
+}
+
+// Branch 定义分馆表的结构
+type Branch struct {
+	ID           uint      `gorm:"primarykey"`
+	Name         string    `gorm:"size:100;not null;unique"`
+	Address      string    `gorm:"size:500;not null;default:''"`
+	Timezone     string    `gorm:"size:64;not null"`
+	OpeningHours string    `gorm:"type:text"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// BranchTransfer 定义馆际调拨表的结构
+type BranchTransfer struct {
+	ID           uint   `gorm:"primarykey"`
+	CopyID       uint   `gorm:"not null;index"`
+	FromBranchID uint   `gorm:"not null;index"`
+	ToBranchID   uint   `gorm:"not null;index"`
+	Status       string `gorm:"size:20;not null;index"`
+	Note         string `gorm:"size:1000;not null;default:''"`
+	RequestedBy  uint   `gorm:"not null"`
+	ShippedAt    *time.Time
+	ReceivedAt   *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+// CopyBranch 副本表中新增的分馆列
+type CopyBranch struct {
+	BranchID uint `gorm:"not null;default:0;index"`
+}
+
+func (CopyBranch) TableName() string {
+	return "book_copies"
+}
@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookDuplicateTablesMigration 创建疑似重复图书的审核队列和已合并图书的重定向表
+type BookDuplicateTablesMigration struct{}
+
+func (m *BookDuplicateTablesMigration) ID() string {
+	return "019_create_book_duplicates_tables"
+}
+
+func (m *BookDuplicateTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookDuplicate{}, &BookRedirect{})
+}
+
+func (m *BookDuplicateTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookRedirect{}, &BookDuplicate{})
+}
+
+// BookDuplicate 定义疑似重复图书表的结构
+type BookDuplicate struct {
+	ID          uint    `gorm:"primarykey"`
+	BookID      uint    `gorm:"not null;uniqueIndex:idx_book_duplicates_pair"`
+	OtherBookID uint    `gorm:"not null;uniqueIndex:idx_book_duplicates_pair;index"`
+	Reason      string  `gorm:"size:20;not null"`
+	Score       float64 `gorm:"not null"`
+	Status      string  `gorm:"size:20;not null;index"`
+	ResolvedBy  *uint
+	ResolvedAt  *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// BookRedirect 定义图书重定向表的结构
+type BookRedirect struct {
+	BookID       uint      `gorm:"primaryKey;autoIncrement:false"`
+	TargetBookID uint      `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
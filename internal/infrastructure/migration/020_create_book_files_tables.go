@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookFileTablesMigration 创建电子书文件表和下载授权表
+type BookFileTablesMigration struct{}
+
+func (m *BookFileTablesMigration) ID() string {
+	return "020_create_book_files_tables"
+}
+
+func (m *BookFileTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookFile{}, &DownloadGrant{})
+}
+
+func (m *BookFileTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&DownloadGrant{}, &BookFile{})
+}
+
+// BookFile 定义电子书文件表的结构
+type BookFile struct {
+	ID         uint      `gorm:"primarykey"`
+	BookID     uint      `gorm:"not null;index"`
+	Format     string    `gorm:"size:10;not null"`
+	FileName   string    `gorm:"size:255;not null"`
+	StorageKey string    `gorm:"size:255;not null"`
+	Size       int64     `gorm:"not null"`
+	SHA256     string    `gorm:"size:64;not null"`
+	UploadedBy uint      `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+// DownloadGrant 定义下载授权表的结构
+type DownloadGrant struct {
+	ID        uint      `gorm:"primarykey"`
+	FileID    uint      `gorm:"not null;index"`
+	BookID    uint      `gorm:"not null;index:idx_download_grants_book_expires"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_download_grants_book_expires"`
+	CreatedAt time.Time `gorm:"not null"`
+}
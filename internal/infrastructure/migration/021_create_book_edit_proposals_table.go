@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookEditProposalTableMigration 创建图书修改建议表
+type BookEditProposalTableMigration struct{}
+
+func (m *BookEditProposalTableMigration) ID() string {
+	return "021_create_book_edit_proposals_table"
+}
+
+func (m *BookEditProposalTableMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&BookEditProposal{})
+}
+
+func (m *BookEditProposalTableMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&BookEditProposal{})
+}
+
+// BookEditProposal 定义图书修改建议表的结构
+type BookEditProposal struct {
+	ID         uint   `gorm:"primarykey"`
+	BookID     uint   `gorm:"not null;index"`
+	UserID     uint   `gorm:"not null;index"`
+	Status     string `gorm:"size:20;not null;index"`
+	Changes    string `gorm:"type:text;not null"`
+	Note       string `gorm:"size:1000;not null;default:''"`
+	ReviewerID *uint  `gorm:"index"`
+	ReviewNote string `gorm:"size:1000;not null;default:''"`
+	ReviewedAt *time.Time
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
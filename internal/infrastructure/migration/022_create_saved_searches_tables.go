@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedSearchTablesMigration 创建保存的检索表和站内通知表
+type SavedSearchTablesMigration struct{}
+
+func (m *SavedSearchTablesMigration) ID() string {
+	return "022_create_saved_searches_tables"
+}
+
+func (m *SavedSearchTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&SavedSearch{}, &Notification{})
+}
+
+func (m *SavedSearchTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&Notification{}, &SavedSearch{})
+}
+
+// SavedSearch 定义保存的检索表的结构
+type SavedSearch struct {
+	ID          uint      `gorm:"primarykey"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_saved_searches_user_name"`
+	Name        string    `gorm:"size:100;not null;uniqueIndex:idx_saved_searches_user_name"`
+	Criteria    string    `gorm:"type:text;not null"`
+	Frequency   string    `gorm:"size:20;not null"`
+	EmailDigest bool      `gorm:"not null;default:false"`
+	LastBookID  uint      `gorm:"not null;default:0"`
+	NextRunAt   time.Time `gorm:"not null;index"`
+	LastRunAt   *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// Notification 定义站内通知表的结构
+type Notification struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    uint       `gorm:"not null;index"`
+	Kind      string     `gorm:"size:30;not null"`
+	Title     string     `gorm:"size:255;not null"`
+	Body      string     `gorm:"type:text"`
+	Data      string     `gorm:"type:text"`
+	ReadAt    *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"not null"`
+}
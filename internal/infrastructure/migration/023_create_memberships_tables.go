@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MembershipTablesMigration 创建会员计划表和会员资格表
+type MembershipTablesMigration struct{}
+
+func (m *MembershipTablesMigration) ID() string {
+	return "023_create_memberships_tables"
+}
+
+func (m *MembershipTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&MembershipPlan{}, &Membership{})
+}
+
+func (m *MembershipTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&Membership{}, &MembershipPlan{})
+}
+
+// MembershipPlan 定义会员计划表的结构
+type MembershipPlan struct {
+	ID            uint      `gorm:"primarykey"`
+	Name          string    `gorm:"size:100;not null;unique"`
+	Description   string    `gorm:"size:1000;not null;default:''"`
+	DurationDays  int       `gorm:"not null"`
+	GraceDays     int       `gorm:"not null;default:0"`
+	MaxLoans      int       `gorm:"not null;default:0"`
+	DigitalAccess bool      `gorm:"not null;default:false"`
+	Fee           int64     `gorm:"not null;default:0"`
+	Active        bool      `gorm:"not null;default:true"`
+	CreatedAt     time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
+
+// Membership 定义会员资格表的结构
+type Membership struct {
+	ID           uint      `gorm:"primarykey"`
+	UserID       uint      `gorm:"not null;unique"`
+	PlanID       uint      `gorm:"not null;index"`
+	StartsAt     time.Time `gorm:"not null"`
+	EndsAt       time.Time `gorm:"not null"`
+	GraceEndsAt  time.Time `gorm:"not null;index"`
+	RenewedAt    *time.Time
+	DowngradedAt *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentLedgerTablesMigration 创建复式记账的账户、凭证、分录表以及支付相关的表
+type PaymentLedgerTablesMigration struct{}
+
+func (m *PaymentLedgerTablesMigration) ID() string {
+	return "024_create_payments_ledger_tables"
+}
+
+func (m *PaymentLedgerTablesMigration) Up(db *gorm.DB) error {
+	return db.AutoMigrate(&LedgerAccount{}, &LedgerTransaction{}, &LedgerEntry{},
+		&Payment{}, &PaymentRefund{}, &PaymentEvent{})
+}
+
+func (m *PaymentLedgerTablesMigration) Down(db *gorm.DB) error {
+	return db.Migrator().DropTable(&PaymentEvent{}, &PaymentRefund{}, &Payment{},
+		&LedgerEntry{}, &LedgerTransaction{}, &LedgerAccount{})
+}
+
+// LedgerAccount 定义账户表的结构
+type LedgerAccount struct {
+	ID        uint      `gorm:"primarykey"`
+	Code      string    `gorm:"size:100;not null;unique"`
+	Name      string    `gorm:"size:255;not null"`
+	Type      string    `gorm:"size:20;not null"`
+	UserID    *uint     `gorm:"index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// LedgerTransaction 定义记账凭证表的结构
+type LedgerTransaction struct {
+	ID          uint   `gorm:"primarykey"`
+	Kind        string `gorm:"size:20;not null"`
+	Description string `gorm:"size:255;not null"`
+	UserID      *uint  `gorm:"index"`
+	PaymentID   *uint  `gorm:"index"`
+	ActorID     *uint
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// LedgerEntry 定义分录表的结构
+type LedgerEntry struct {
+	ID            uint      `gorm:"primarykey"`
+	TransactionID uint      `gorm:"not null;index"`
+	AccountID     uint      `gorm:"not null;index"`
+	Amount        int64     `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+// Payment 定义支付表的结构
+type Payment struct {
+	ID             uint   `gorm:"primarykey"`
+	UserID         uint   `gorm:"not null;index"`
+	Purpose        string `gorm:"size:20;not null"`
+	PlanID         *uint
+	Amount         int64  `gorm:"not null"`
+	Currency       string `gorm:"size:3;not null"`
+	Provider       string `gorm:"size:30;not null"`
+	ProviderRef    string `gorm:"size:100;not null;uniqueIndex"`
+	Status         string `gorm:"size:20;not null;index"`
+	RefundedAmount int64  `gorm:"not null;default:0"`
+	CapturedAt     *time.Time
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+// PaymentRefund 定义退款表的结构
+type PaymentRefund struct {
+	ID             uint      `gorm:"primarykey"`
+	PaymentID      uint      `gorm:"not null;uniqueIndex:idx_payment_refunds_key"`
+	IdempotencyKey string    `gorm:"size:100;not null;uniqueIndex:idx_payment_refunds_key"`
+	Amount         int64     `gorm:"not null"`
+	Reason         string    `gorm:"size:255;not null;default:''"`
+	ProviderRef    string    `gorm:"size:100;not null"`
+	ActorID        uint      `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// PaymentEvent 定义已处理 webhook 事件表的结构
+type PaymentEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	Provider  string    `gorm:"size:30;not null;uniqueIndex:idx_payment_events_provider_event"`
+	EventID   string    `gorm:"size:100;not null;uniqueIndex:idx_payment_events_provider_event"`
+	Type      string    `gorm:"size:50;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,34 @@
+package migration
+
+import "gorm.io/gorm"
+
+// PaymentRefundStatusMigration 为退款表增加状态列，退款在调用渠道前先以 pending 状态预留。
+// 已有的退款都是渠道成功后才写入的，默认值为 succeeded
+type PaymentRefundStatusMigration struct{}
+
+func (m *PaymentRefundStatusMigration) ID() string {
+	return "025_add_payment_refund_status"
+}
+
+func (m *PaymentRefundStatusMigration) Up(db *gorm.DB) error {
+	if db.Migrator().HasColumn(&PaymentRefundStatus{}, "Status") {
+		return nil
+	}
+	return db.Migrator().AddColumn(&PaymentRefundStatus{}, "Status")
+}
+
+func (m *PaymentRefundStatusMigration) Down(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&PaymentRefundStatus{}, "Status") {
+		return nil
+	}
+	return db.Migrator().DropColumn(&PaymentRefundStatus{}, "Status")
+}
+
+// PaymentRefundStatus 退款表中本次新增的列
+type PaymentRefundStatus struct {
+	Status string `gorm:"size:20;not null;default:'succeeded'"`
+}
+
+func (PaymentRefundStatus) TableName() string {
+	return "payment_refunds"
+}
@@ -0,0 +1,34 @@
+package migration
+
+import "gorm.io/gorm"
+
+// MembershipVersionMigration 为会员资格表增加版本号，降级时比对版本号而不是宽限期结束时间，
+// 后者经过数据库往返后精度和时区可能变化，相等比较会失败
+type MembershipVersionMigration struct{}
+
+func (m *MembershipVersionMigration) ID() string {
+	return "026_add_membership_version"
+}
+
+func (m *MembershipVersionMigration) Up(db *gorm.DB) error {
+	if db.Migrator().HasColumn(&MembershipVersion{}, "Version") {
+		return nil
+	}
+	return db.Migrator().AddColumn(&MembershipVersion{}, "Version")
+}
+
+func (m *MembershipVersionMigration) Down(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&MembershipVersion{}, "Version") {
+		return nil
+	}
+	return db.Migrator().DropColumn(&MembershipVersion{}, "Version")
+}
+
+// MembershipVersion 会员资格表中本次新增的列
+type MembershipVersion struct {
+	Version int `gorm:"not null;default:0"`
+}
+
+func (MembershipVersion) TableName() string {
+	return "memberships"
+}
@@ -9,5 +9,29 @@ import (
 func RegisterMigrations(migrator *baseMigration.Migrator) {
 	migrator.AddMigration(&UserTableMigration{})
 	migrator.AddMigration(&BookTableMigration{})
+	migrator.AddMigration(&CirculationTablesMigration{})
+	migrator.AddMigration(&HoldTableMigration{})
+	migrator.AddMigration(&AuthorTablesMigration{})
+	migrator.AddMigration(&CategoryTagTablesMigration{})
+	migrator.AddMigration(&BookSearchIndexMigration{})
+	migrator.AddMigration(&NormalizeISBNMigration{})
+	migrator.AddMigration(&BookMetadataColumnsMigration{})
+	migrator.AddMigration(&BookCoverColumnsMigration{})
+	migrator.AddMigration(&ReviewTableMigration{})
+	migrator.AddMigration(&ShelfTablesMigration{})
+	migrator.AddMigration(&RecommendationTablesMigration{})
+	migrator.AddMigration(&WorksTableMigration{})
+	migrator.AddMigration(&BookRevisionsTableMigration{})
+	migrator.AddMigration(&BookBibliographicColumnsMigration{})
+	migrator.AddMigration(&AcquisitionTablesMigration{})
+	migrator.AddMigration(&BranchTablesMigration{})
+	migrator.AddMigration(&BookDuplicateTablesMigration{})
+	migrator.AddMigration(&BookFileTablesMigration{})
+	migrator.AddMigration(&BookEditProposalTableMigration{})
+	migrator.AddMigration(&SavedSearchTablesMigration{})
+	migrator.AddMigration(&MembershipTablesMigration{})
+	migrator.AddMigration(&PaymentLedgerTablesMigration{})
+	migrator.AddMigration(&PaymentRefundStatusMigration{})
+	migrator.AddMigration(&MembershipVersionMigration{})
 	// 在这里添加新的迁移
 }
@@ -0,0 +1,154 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+)
+
+// FakeProvider 进程内模拟的支付渠道，用于开发和测试。支付意向保存在内存中，
+// 通过 Authorize、Fail 生成与真实渠道格式相同的已签名 webhook 请求体来模拟付款人的操作
+type FakeProvider struct {
+	secret string
+
+	mu      sync.Mutex
+	intents map[string]*fakeIntent
+	results map[string]fakeResult // 按幂等键记录扣款、退款的结果
+}
+
+type fakeIntent struct {
+	amount     int64
+	authorized bool
+	captured   int64
+	refunded   int64
+}
+
+type fakeResult struct {
+	refundRef string
+	err       error
+}
+
+// fakeWebhook fake 渠道的 webhook 请求体
+type fakeWebhook struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Ref  string `json:"intent"`
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:  secret,
+		intents: make(map[string]*fakeIntent),
+		results: make(map[string]fakeResult),
+	}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+func (p *FakeProvider) CreateIntent(_ context.Context, req payment.IntentRequest) (*payment.Intent, error) {
+	if req.Amount <= 0 {
+		return nil, payment.ErrProviderDeclined
+	}
+	ref := "fake_pi_" + randomHex(8)
+	p.mu.Lock()
+	p.intents[ref] = &fakeIntent{amount: req.Amount}
+	p.mu.Unlock()
+	return &payment.Intent{Ref: ref, ClientSecret: ref + "_secret_" + randomHex(8)}, nil
+}
+
+func (p *FakeProvider) Capture(_ context.Context, ref string, amount int64, idempotencyKey string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if result, ok := p.results[idempotencyKey]; ok {
+		return result.err
+	}
+	intent, ok := p.intents[ref]
+	var err error
+	switch {
+	case !ok:
+		err = payment.ErrUnknownIntent
+	case !intent.authorized || intent.captured > 0 || amount <= 0 || amount > intent.amount:
+		err = payment.ErrProviderDeclined
+	default:
+		intent.captured = amount
+	}
+	p.results[idempotencyKey] = fakeResult{err: err}
+	return err
+}
+
+func (p *FakeProvider) Refund(_ context.Context, ref string, amount int64, idempotencyKey string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if result, ok := p.results[idempotencyKey]; ok {
+		return result.refundRef, result.err
+	}
+	intent, ok := p.intents[ref]
+	var result fakeResult
+	switch {
+	case !ok:
+		result.err = payment.ErrUnknownIntent
+	case amount <= 0 || amount > intent.captured-intent.refunded:
+		result.err = payment.ErrProviderDeclined
+	default:
+		intent.refunded += amount
+		result.refundRef = "fake_re_" + randomHex(8)
+	}
+	p.results[idempotencyKey] = result
+	return result.refundRef, result.err
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, signature string) (*payment.Event, error) {
+	if err := VerifySignature(p.secret, payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+	var body fakeWebhook
+	if err := json.Unmarshal(payload, &body); err != nil || body.ID == "" {
+		return nil, fmt.Errorf("%w: malformed payload", payment.ErrInvalidSignature)
+	}
+	return &payment.Event{ID: body.ID, Type: body.Type, Ref: body.Ref}, nil
+}
+
+// Authorize 模拟付款人确认支付，返回渠道随后投递的 webhook 请求体及其签名
+func (p *FakeProvider) Authorize(ref string) ([]byte, string, error) {
+	p.mu.Lock()
+	intent, ok := p.intents[ref]
+	if ok {
+		intent.authorized = true
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, "", payment.ErrUnknownIntent
+	}
+	return p.webhook(payment.EventAuthorized, ref)
+}
+
+// Fail 模拟付款人支付失败，返回对应的 webhook 请求体及其签名
+func (p *FakeProvider) Fail(ref string) ([]byte, string, error) {
+	p.mu.Lock()
+	_, ok := p.intents[ref]
+	p.mu.Unlock()
+	if !ok {
+		return nil, "", payment.ErrUnknownIntent
+	}
+	return p.webhook(payment.EventFailed, ref)
+}
+
+func (p *FakeProvider) webhook(eventType, ref string) ([]byte, string, error) {
+	payload, err := json.Marshal(fakeWebhook{ID: "fake_evt_" + randomHex(8), Type: eventType, Ref: ref})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, SignPayload(p.secret, payload, time.Now()), nil
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
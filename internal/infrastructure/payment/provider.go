@@ -0,0 +1,21 @@
+package payment
+
+import (
+	"fmt"
+
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+// NewProvider 根据配置创建支付渠道，目前支持 fake（默认，用于开发和测试）
+func NewProvider(cfg config.PaymentConfig) (payment.Provider, error) {
+	switch cfg.Provider {
+	case "", "fake":
+		if cfg.WebhookSecret == "" {
+			return nil, fmt.Errorf("payments.webhook_secret is required")
+		}
+		return NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+)
+
+// signatureTolerance webhook 签名时间戳与当前时间允许的最大偏差，防止重放
+const signatureTolerance = 5 * time.Minute
+
+// SignPayload 生成 webhook 签名，格式为 "t=<unix 秒>,v1=<hex(HMAC-SHA256(secret, t + "." + payload))>"
+func SignPayload(secret string, payload []byte, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(computeSignature(secret, t, payload))
+}
+
+// VerifySignature 校验 SignPayload 生成的签名及其时间戳
+func VerifySignature(secret string, payload []byte, header string, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return payment.ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > signatureTolerance || skew < -signatureTolerance {
+		return payment.ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(expected, computeSignature(secret, t, payload)) {
+		return payment.ErrInvalidSignature
+	}
+	return nil
+}
+
+func computeSignature(secret, t string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+func (r *mysqlRepository) CreateAuthor(author *entities.Author) error {
+	return r.db.Create(author).Error
+}
+
+func (r *mysqlRepository) GetAuthor(id int) (*entities.Author, error) {
+	var author entities.Author
+	if err := r.db.First(&author, id).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (r *mysqlRepository) UpdateAuthor(author *entities.Author) error {
+	return r.db.Save(author).Error
+}
+
+func (r *mysqlRepository) DeleteAuthor(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var linked int64
+		if err := tx.Model(&entities.BookAuthor{}).Where("author_id = ?", id).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return entities.ErrAuthorInUse
+		}
+		return tx.Delete(&entities.Author{}, id).Error
+	})
+}
+
+func (r *mysqlRepository) ListAuthors(offset, limit int, name string) ([]entities.Author, int64, error) {
+	var authors []entities.Author
+	var total int64
+
+	query := r.db.Model(&entities.Author{})
+	if name != "" {
+		query = query.Where("name "+likeOperator+" ?", "%"+name+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("sort_name").Offset(offset).Limit(limit).Find(&authors).Error; err != nil {
+		return nil, 0, err
+	}
+	return authors, total, nil
+}
+
+func (r *mysqlRepository) FindAuthorByName(name string) (*entities.Author, error) {
+	var author entities.Author
+	if err := r.db.Where("name = ?", name).Order("id").First(&author).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (r *mysqlRepository) SetBookAuthors(bookID uint, links []entities.BookAuthor, byline string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&entities.BookAuthor{}).Error; err != nil {
+			return err
+		}
+		if len(links) > 0 {
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entities.Book{}).Where("id = ?", bookID).Update("author", byline).Error
+	})
+}
+
+func (r *mysqlRepository) ListBookContributors(bookIDs []uint) (map[uint][]entities.Contributor, error) {
+	result := make(map[uint][]entities.Contributor, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		BookID   uint
+		AuthorID uint
+		Name     string
+		Role     string
+		Position int
+	}
+	err := r.db.Table("book_authors").
+		Select("book_authors.book_id, book_authors.author_id, authors.name, book_authors.role, book_authors.position").
+		Joins("JOIN authors ON authors.id = book_authors.author_id").
+		Where("book_authors.book_id IN ?", bookIDs).
+		Order("book_authors.book_id, book_authors.position").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.BookID] = append(result[row.BookID], entities.Contributor{
+			AuthorID: row.AuthorID,
+			Name:     row.Name,
+			Role:     row.Role,
+			Position: row.Position,
+		})
+	}
+	return result, nil
+}
@@ -1,9 +1,13 @@
 package mysql
 
-import "github.com/azel-ko/final-ddd/internal/domain/entities"
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+)
 
 func (r *mysqlRepository) CreateBook(book *entities.Book) error {
-	return r.db.Create(book).Error
+	return createBookWithWork(r.db, book)
 }
 func (r *mysqlRepository) GetBook(id int) (*entities.Book, error) {
 	var book entities.Book
@@ -13,27 +17,23 @@ func (r *mysqlRepository) GetBook(id int) (*entities.Book, error) {
 	return &book, nil
 }
 
-func (r *mysqlRepository) ListBooks(offset, limit int, title, author string) ([]entities.Book, int64, error) {
+func (r *mysqlRepository) ListBooks(offset, limit int, filter repository.BookFilter) ([]entities.Book, int64, error) {
 	var books []entities.Book
 	var total int64
 
-	query := r.db.Model(&entities.Book{})
-	countQuery := r.db.Model(&entities.Book{})
-
-	if title != "" {
-		query = query.Where("title LIKE ?", "%"+title+"%")
-		countQuery = countQuery.Where("title LIKE ?", "%"+title+"%")
-	}
-	if author != "" {
-		query = query.Where("author LIKE ?", "%"+author+"%")
-		countQuery = countQuery.Where("author LIKE ?", "%"+author+"%")
+	query := applyBookFilter(r.db.Model(&entities.Book{}), filter)
+	countQuery := applyBookFilter(r.db.Model(&entities.Book{}), filter)
+	if filter.CollapseEditions {
+		query = collapseEditions(query, applyBookFilter(r.db.Model(&entities.Book{}), filter)).
+			Select("books.*, " + editionCountColumn)
+		countQuery = collapseEditions(countQuery, applyBookFilter(r.db.Model(&entities.Book{}), filter))
 	}
 
 	if err := countQuery.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := query.Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if err := orderBooks(query, filter.Sort).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -43,7 +43,25 @@ func (r *mysqlRepository) UpdateBook(book *entities.Book) error {
 	return r.db.Save(book).Error
 }
 func (r *mysqlRepository) DeleteBook(id int) error {
-	return r.db.Delete(&entities.Book{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 先删除图书的关联记录
+		for _, link := range []interface{}{&entities.BookAuthor{}, &entities.BookCategory{}, &entities.BookTag{}, &entities.Review{}, &entities.ShelfItem{}, &entities.ReadingProgress{}, &entities.BookInteraction{}} {
+			if err := tx.Where("book_id = ?", id).Delete(link).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("book_id = ? OR similar_book_id = ?", id, id).Delete(&entities.BookSimilarity{}).Error; err != nil {
+			return err
+		}
+		var workID uint
+		if err := tx.Model(&entities.Book{}).Where("id = ?", id).Pluck("work_id", &workID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entities.Book{}, id).Error; err != nil {
+			return err
+		}
+		return deleteEmptyWork(tx, workID)
+	})
 }
 
 func (r *mysqlRepository) GetBookByISBN(isbn string) (*entities.Book, error) {
@@ -0,0 +1,8 @@
+package mysql
+
+import "github.com/azel-ko/final-ddd/internal/domain/entities"
+
+func (r *mysqlRepository) SetBookCover(bookID uint, image, format string) error {
+	return r.db.Model(&entities.Book{}).Where("id = ?", bookID).
+		Updates(map[string]interface{}{"cover_image": image, "cover_format": format}).Error
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *mysqlRepository) CreateBookFile(file *entities.BookFile) error {
+	return r.db.Create(file).Error
+}
+
+func (r *mysqlRepository) GetBookFile(id int) (*entities.BookFile, error) {
+	var file entities.BookFile
+	if err := r.db.First(&file, id).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *mysqlRepository) ListBookFiles(bookID uint) ([]entities.BookFile, error) {
+	var files []entities.BookFile
+	if err := r.db.Where("book_id = ?", bookID).Order("id").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *mysqlRepository) DeleteBookFile(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id = ?", id).Delete(&entities.DownloadGrant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.BookFile{}, id).Error
+	})
+}
+
+func (r *mysqlRepository) CreateDownloadGrant(grant *entities.DownloadGrant, limit int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定书目行，使同一书目的授权串行化，并发请求不会同时占用最后一个名额
+		var book entities.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, grant.BookID).Error; err != nil {
+			return err
+		}
+
+		if limit > 0 {
+			// 同一读者的多个授权只占一个名额
+			var readers int64
+			err := tx.Model(&entities.DownloadGrant{}).
+				Where("book_id = ? AND user_id <> ? AND expires_at > ?", grant.BookID, grant.UserID, grant.CreatedAt).
+				Distinct("user_id").
+				Count(&readers).Error
+			if err != nil {
+				return err
+			}
+			if readers >= int64(limit) {
+				return entities.ErrDownloadLimitReached
+			}
+		}
+
+		return tx.Create(grant).Error
+	})
+}
+
+func (r *mysqlRepository) GetDownloadGrant(id int) (*entities.DownloadGrant, error) {
+	var grant entities.DownloadGrant
+	if err := r.db.First(&grant, id).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *mysqlRepository) FindActiveDownloadGrant(fileID, userID uint, now time.Time) (*entities.DownloadGrant, error) {
+	var grant entities.DownloadGrant
+	err := r.db.Where("file_id = ? AND user_id = ? AND expires_at > ?", fileID, userID, now).
+		Order("expires_at DESC").
+		First(&grant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *mysqlRepository) PruneDownloadGrants(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&entities.DownloadGrant{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,4 @@
+package mysql
+
+// likeOperator 不区分大小写的模糊匹配运算符
+const likeOperator = "LIKE"
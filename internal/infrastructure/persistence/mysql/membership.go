@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+const membershipColumns = "memberships.*, membership_plans.name AS plan_name, users.name AS user_name"
+
+func (r *mysqlRepository) membershipQuery() *gorm.DB {
+	return r.db.Model(&entities.Membership{}).
+		Select(membershipColumns).
+		Joins("LEFT JOIN membership_plans ON membership_plans.id = memberships.plan_id").
+		Joins("LEFT JOIN users ON users.id = memberships.user_id")
+}
+
+func (r *mysqlRepository) CreateMembershipPlan(plan *entities.MembershipPlan) error {
+	return r.db.Create(plan).Error
+}
+
+func (r *mysqlRepository) GetMembershipPlan(id int) (*entities.MembershipPlan, error) {
+	var plan entities.MembershipPlan
+	if err := r.db.First(&plan, id).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *mysqlRepository) GetMembershipPlanByName(name string) (*entities.MembershipPlan, error) {
+	var plan entities.MembershipPlan
+	if err := r.db.Where("name = ?", name).First(&plan).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *mysqlRepository) UpdateMembershipPlan(plan *entities.MembershipPlan) error {
+	return r.db.Save(plan).Error
+}
+
+func (r *mysqlRepository) DeleteMembershipPlan(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entities.Membership{}).Where("plan_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return entities.ErrMembershipPlanInUse
+		}
+		return tx.Delete(&entities.MembershipPlan{}, id).Error
+	})
+}
+
+func (r *mysqlRepository) ListMembershipPlans(activeOnly bool) ([]entities.MembershipPlan, error) {
+	query := r.db.Order("id")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var plans []entities.MembershipPlan
+	err := query.Find(&plans).Error
+	return plans, err
+}
+
+func (r *mysqlRepository) GetMembershipByUser(userID uint) (*entities.Membership, error) {
+	var membership entities.Membership
+	if err := r.membershipQuery().Where("memberships.user_id = ?", userID).First(&membership).Error; err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *mysqlRepository) SaveMembership(membership *entities.Membership, restoreRole bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		membership.Version++
+		if err := tx.Save(membership).Error; err != nil {
+			return err
+		}
+		if !restoreRole {
+			return nil
+		}
+		return tx.Model(&entities.User{}).
+			Where("id = ? AND role = ?", membership.UserID, entities.RoleRestricted).
+			Update("role", entities.RoleUser).Error
+	})
+}
+
+func (r *mysqlRepository) ListMemberships(filter repository.MembershipFilter, offset, limit int) ([]entities.Membership, int64, error) {
+	apply := func(query *gorm.DB) *gorm.DB {
+		if filter.PlanID != 0 {
+			query = query.Where("memberships.plan_id = ?", filter.PlanID)
+		}
+		switch filter.Status {
+		case entities.MembershipStatusActive:
+			query = query.Where("memberships.ends_at > ?", filter.Now)
+		case entities.MembershipStatusGrace:
+			query = query.Where("memberships.ends_at <= ? AND memberships.grace_ends_at > ?", filter.Now, filter.Now)
+		case entities.MembershipStatusExpired:
+			query = query.Where("memberships.grace_ends_at <= ?", filter.Now)
+		}
+		return query
+	}
+
+	var total int64
+	if err := apply(r.db.Model(&entities.Membership{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var memberships []entities.Membership
+	err := apply(r.membershipQuery()).
+		Order("memberships.ends_at").Order("memberships.id").
+		Offset(offset).Limit(limit).
+		Find(&memberships).Error
+	return memberships, total, err
+}
+
+func (r *mysqlRepository) ListLapsedMemberships(now time.Time, afterID uint, limit int) ([]entities.Membership, error) {
+	var memberships []entities.Membership
+	err := r.db.Where("id > ? AND grace_ends_at <= ? AND downgraded_at IS NULL", afterID, now).
+		Order("id").
+		Limit(limit).
+		Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *mysqlRepository) DowngradeMembership(membership *entities.Membership, now time.Time) (bool, error) {
+	downgraded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 同时比对读取时的版本号，期间续期过的会员资格不会被降级
+		result := tx.Model(&entities.Membership{}).
+			Where("id = ? AND downgraded_at IS NULL AND version = ? AND grace_ends_at <= ?", membership.ID, membership.Version, now).
+			Updates(map[string]interface{}{
+				"downgraded_at": now,
+				"version":       gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		downgraded = true
+		return tx.Model(&entities.User{}).
+			Where("id = ? AND role = ?", membership.UserID, entities.RoleUser).
+			Update("role", entities.RoleRestricted).Error
+	})
+	if err == nil && downgraded {
+		membership.DowngradedAt = &now
+		membership.Version++
+	}
+	return downgraded, err
+}
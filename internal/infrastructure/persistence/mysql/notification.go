@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+func (r *mysqlRepository) CreateNotification(notification *entities.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *mysqlRepository) ListNotifications(userID uint, unreadOnly bool, offset, limit int) ([]entities.Notification, int64, error) {
+	query := r.db.Model(&entities.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []entities.Notification
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *mysqlRepository) CountUnreadNotifications(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *mysqlRepository) MarkNotificationsRead(userID uint, ids []uint, now time.Time) (int64, error) {
+	query := r.db.Model(&entities.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read_at", now)
+	return result.RowsAffected, result.Error
+}
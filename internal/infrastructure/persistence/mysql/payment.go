@@ -0,0 +1,162 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *mysqlRepository) CreatePayment(payment *entities.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *mysqlRepository) GetPayment(id int) (*entities.Payment, error) {
+	var payment entities.Payment
+	if err := r.db.First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *mysqlRepository) GetPaymentByProviderRef(provider, ref string) (*entities.Payment, error) {
+	var payment entities.Payment
+	if err := r.db.Where("provider = ? AND provider_ref = ?", provider, ref).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *mysqlRepository) ListPayments(filter repository.PaymentFilter, offset, limit int) ([]entities.Payment, int64, error) {
+	query := r.db.Model(&entities.Payment{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var payments []entities.Payment
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&payments).Error
+	return payments, total, err
+}
+
+func (r *mysqlRepository) UpdatePaymentStatus(payment *entities.Payment, from string) (bool, error) {
+	result := r.db.Model(&entities.Payment{}).
+		Where("id = ? AND status = ?", payment.ID, from).
+		Update("status", payment.Status)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mysqlRepository) CapturePayment(payment *entities.Payment, posting *entities.LedgerPosting) (bool, error) {
+	captured := false
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, entities.PaymentStatusAuthorized).
+			Updates(map[string]interface{}{"status": entities.PaymentStatusCaptured, "captured_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		captured = true
+		return postLedgerTransaction(tx, posting)
+	})
+	if err == nil && captured {
+		payment.Status = entities.PaymentStatusCaptured
+		payment.CapturedAt = &now
+	}
+	return captured, err
+}
+
+func (r *mysqlRepository) ReserveRefund(payment *entities.Payment, refund *entities.PaymentRefund) (bool, error) {
+	reserved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 唯一索引保证同一幂等键只有一个请求能写入，并发的重复请求在这里落空
+		refund.Status = entities.RefundStatusPending
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(refund)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		// 在条件中校验可退余额，并发的退款不会超过扣款金额
+		result = tx.Model(&entities.Payment{}).
+			Where("id = ? AND status = ? AND refunded_amount + ? <= amount", payment.ID, entities.PaymentStatusCaptured, refund.Amount).
+			Update("refunded_amount", gorm.Expr("refunded_amount + ?", refund.Amount))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrPaymentNotRefundable
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+func (r *mysqlRepository) CompleteRefund(payment *entities.Payment, refund *entities.PaymentRefund, posting *entities.LedgerPosting) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(refund).
+			Updates(map[string]interface{}{"status": entities.RefundStatusSucceeded, "provider_ref": refund.ProviderRef}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.Payment{}).
+			Where("id = ? AND refunded_amount = amount", payment.ID).
+			Update("status", entities.PaymentStatusRefunded).Error; err != nil {
+			return err
+		}
+		return postLedgerTransaction(tx, posting)
+	})
+	if err != nil {
+		return err
+	}
+	refund.Status = entities.RefundStatusSucceeded
+	return r.db.First(payment, payment.ID).Error
+}
+
+func (r *mysqlRepository) ReleaseRefund(refund *entities.PaymentRefund) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND status = ?", refund.ID, entities.RefundStatusPending).Delete(&entities.PaymentRefund{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&entities.Payment{}).
+			Where("id = ?", refund.PaymentID).
+			Update("refunded_amount", gorm.Expr("refunded_amount - ?", refund.Amount)).Error
+	})
+}
+
+func (r *mysqlRepository) GetPaymentRefundByKey(paymentID uint, key string) (*entities.PaymentRefund, error) {
+	var refund entities.PaymentRefund
+	if err := r.db.Where("payment_id = ? AND idempotency_key = ?", paymentID, key).First(&refund).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *mysqlRepository) ListPaymentRefunds(paymentID uint) ([]entities.PaymentRefund, error) {
+	var refunds []entities.PaymentRefund
+	err := r.db.Where("payment_id = ?", paymentID).Order("id").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *mysqlRepository) RecordPaymentEvent(event *entities.PaymentEvent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mysqlRepository) ForgetPaymentEvent(event *entities.PaymentEvent) error {
+	return r.db.Where("provider = ? AND event_id = ?", event.Provider, event.EventID).
+		Delete(&entities.PaymentEvent{}).Error
+}
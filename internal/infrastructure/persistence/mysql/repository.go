@@ -13,3 +13,9 @@ type mysqlRepository struct {
 func NewMySQLRepository(db *gorm.DB) repository.Repository {
 	return &mysqlRepository{db: db}
 }
+
+func (r *mysqlRepository) Transaction(fn func(repo repository.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&mysqlRepository{db: tx})
+	})
+}
@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reviewColumns 书评查询同时带出读者姓名
+const reviewColumns = "reviews.*, users.name AS user_name"
+
+func (r *mysqlRepository) reviewQuery() *gorm.DB {
+	return r.db.Model(&entities.Review{}).Select(reviewColumns).Joins("LEFT JOIN users ON users.id = reviews.user_id")
+}
+
+func (r *mysqlRepository) CreateReview(review *entities.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定图书行，使同一本书的书评创建和评分更新串行化
+		var book entities.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, review.BookID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&entities.Review{}).Where("book_id = ? AND user_id = ?", review.BookID, review.UserID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return entities.ErrReviewAlreadyExists
+		}
+
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		count, sum := review.RatingContribution()
+		return adjustBookRating(tx, review.BookID, count, sum)
+	})
+}
+
+func (r *mysqlRepository) GetReview(id int) (*entities.Review, error) {
+	var review entities.Review
+	if err := r.reviewQuery().Where("reviews.id = ?", id).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *mysqlRepository) UpdateReview(review *entities.Review, previous *entities.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		review.UpdatedAt = time.Now()
+		result := tx.Model(&entities.Review{}).
+			Where("id = ? AND status = ? AND rating = ?", previous.ID, previous.Status, previous.Rating).
+			Updates(map[string]interface{}{
+				"rating":          review.Rating,
+				"text":            review.Text,
+				"status":          review.Status,
+				"flagged_words":   review.FlaggedWords,
+				"moderator_id":    review.ModeratorID,
+				"moderated_at":    review.ModeratedAt,
+				"moderation_note": review.ModerationNote,
+				"updated_at":      review.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrReviewChanged
+		}
+
+		count, sum := review.RatingContribution()
+		previousCount, previousSum := previous.RatingContribution()
+		return adjustBookRating(tx, review.BookID, count-previousCount, sum-previousSum)
+	})
+}
+
+func (r *mysqlRepository) DeleteReview(review *entities.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND status = ? AND rating = ?", review.ID, review.Status, review.Rating).
+			Delete(&entities.Review{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrReviewChanged
+		}
+
+		count, sum := review.RatingContribution()
+		return adjustBookRating(tx, review.BookID, -count, -sum)
+	})
+}
+
+func (r *mysqlRepository) ListBookReviews(bookID uint, status string, offset, limit int) ([]entities.Review, int64, error) {
+	var reviews []entities.Review
+	var total int64
+
+	if err := r.db.Model(&entities.Review{}).Where("book_id = ? AND status = ?", bookID, status).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.reviewQuery().
+		Where("reviews.book_id = ? AND reviews.status = ?", bookID, status).
+		Order("reviews.created_at DESC, reviews.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+func (r *mysqlRepository) ListReviewsByUser(userID uint) ([]entities.Review, error) {
+	var reviews []entities.Review
+	err := r.reviewQuery().
+		Where("reviews.user_id = ?", userID).
+		Order("reviews.created_at DESC, reviews.id DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *mysqlRepository) ListReviewsByStatus(status string, offset, limit int) ([]entities.Review, int64, error) {
+	var reviews []entities.Review
+	var total int64
+
+	if err := r.db.Model(&entities.Review{}).Where("status = ?", status).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.reviewQuery().
+		Where("reviews.status = ?", status).
+		Order("CASE WHEN reviews.flagged_words <> '' THEN 0 ELSE 1 END, reviews.id").
+		Offset(offset).Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// adjustBookRating 按差值增量更新图书的评分条数、总分和平均分。
+// 平均分放在第一个赋值：MySQL 按从左到右的顺序使用已更新的列值，这样各方言都基于更新前的值计算
+func adjustBookRating(tx *gorm.DB, bookID uint, count, sum int) error {
+	if count == 0 && sum == 0 {
+		return nil
+	}
+	return tx.Exec(`UPDATE books SET
+		rating_average = CASE WHEN rating_count + ? > 0 THEN (rating_sum + ?) * 1.0 / (rating_count + ?) ELSE 0 END,
+		rating_count = rating_count + ?,
+		rating_sum = rating_sum + ?
+		WHERE id = ?`, count, sum, count, count, sum, bookID).Error
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+const acquisitionColumns = "acquisitions.*, users.name AS user_name"
+
+func (r *postgresRepository) acquisitionQuery() *gorm.DB {
+	return r.db.Model(&entities.Acquisition{}).Select(acquisitionColumns).Joins("LEFT JOIN users ON users.id = acquisitions.user_id")
+}
+
+func (r *postgresRepository) CreateAcquisition(acquisition *entities.Acquisition, event *entities.AcquisitionEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(acquisition).Error; err != nil {
+			return err
+		}
+		event.AcquisitionID = acquisition.ID
+		return tx.Create(event).Error
+	})
+}
+
+func (r *postgresRepository) GetAcquisition(id int) (*entities.Acquisition, error) {
+	var acquisition entities.Acquisition
+	if err := r.acquisitionQuery().Where("acquisitions.id = ?", id).First(&acquisition).Error; err != nil {
+		return nil, err
+	}
+	return &acquisition, nil
+}
+
+func (r *postgresRepository) UpdateAcquisition(acquisition *entities.Acquisition, previousStatus string, event *entities.AcquisitionEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		acquisition.UpdatedAt = time.Now()
+		result := tx.Model(&entities.Acquisition{}).
+			Where("id = ? AND status = ?", acquisition.ID, previousStatus).
+			Updates(map[string]interface{}{
+				"isbn":       acquisition.ISBN,
+				"title":      acquisition.Title,
+				"author":     acquisition.Author,
+				"status":     acquisition.Status,
+				"vendor":     acquisition.Vendor,
+				"quantity":   acquisition.Quantity,
+				"cost_cents": acquisition.CostCents,
+				"currency":   acquisition.Currency,
+				"book_id":    acquisition.BookID,
+				"updated_at": acquisition.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrAcquisitionChanged
+		}
+		return tx.Create(event).Error
+	})
+}
+
+func (r *postgresRepository) ListAcquisitions(filter repository.AcquisitionFilter, offset, limit int) ([]entities.Acquisition, int64, error) {
+	apply := func(query *gorm.DB) *gorm.DB {
+		if filter.UserID != 0 {
+			query = query.Where("acquisitions.user_id = ?", filter.UserID)
+		}
+		if filter.Status != "" {
+			query = query.Where("acquisitions.status = ?", filter.Status)
+		}
+		return query
+	}
+
+	var total int64
+	if err := apply(r.db.Model(&entities.Acquisition{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var acquisitions []entities.Acquisition
+	err := apply(r.acquisitionQuery()).
+		Order("acquisitions.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&acquisitions).Error
+	return acquisitions, total, err
+}
+
+func (r *postgresRepository) FindOpenAcquisitionByISBN(isbn string) (*entities.Acquisition, error) {
+	var acquisition entities.Acquisition
+	err := r.db.Where("isbn = ? AND status IN ?", isbn, entities.OpenAcquisitionStatuses()).
+		Order("id").
+		First(&acquisition).Error
+	if err != nil {
+		return nil, err
+	}
+	return &acquisition, nil
+}
+
+func (r *postgresRepository) ListAcquisitionEvents(acquisitionID uint) ([]entities.AcquisitionEvent, error) {
+	var events []entities.AcquisitionEvent
+	err := r.db.Model(&entities.AcquisitionEvent{}).
+		Select("acquisition_events.*, users.name AS actor_name").
+		Joins("LEFT JOIN users ON users.id = acquisition_events.actor_id").
+		Where("acquisition_events.acquisition_id = ?", acquisitionID).
+		Order("acquisition_events.id").
+		Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+func (r *postgresRepository) ForEachBook(batchSize int, fn func(books []entities.Book) error) error {
+	var books []entities.Book
+	return r.db.Model(&entities.Book{}).FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(books)
+	}).Error
+}
+
+func (r *postgresRepository) ListBooksByIDs(ids []uint) ([]entities.Book, error) {
+	var books []entities.Book
+	if len(ids) == 0 {
+		return books, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&books).Error
+	return books, err
+}
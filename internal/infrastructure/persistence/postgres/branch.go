@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *postgresRepository) CreateBranch(branch *entities.Branch) error {
+	return r.db.Create(branch).Error
+}
+
+func (r *postgresRepository) GetBranch(id int) (*entities.Branch, error) {
+	var branch entities.Branch
+	if err := r.db.First(&branch, id).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *postgresRepository) GetBranchByName(name string) (*entities.Branch, error) {
+	var branch entities.Branch
+	if err := r.db.Where("name = ?", name).First(&branch).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *postgresRepository) UpdateBranch(branch *entities.Branch) error {
+	return r.db.Save(branch).Error
+}
+
+func (r *postgresRepository) DeleteBranch(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var copies int64
+		if err := tx.Model(&entities.BookCopy{}).Where("branch_id = ?", id).Count(&copies).Error; err != nil {
+			return err
+		}
+		var transfers int64
+		err := tx.Model(&entities.BranchTransfer{}).
+			Where("(from_branch_id = ? OR to_branch_id = ?) AND status IN ?", id, id, entities.OpenTransferStatuses()).
+			Count(&transfers).Error
+		if err != nil {
+			return err
+		}
+		if copies > 0 || transfers > 0 {
+			return entities.ErrBranchInUse
+		}
+		return tx.Delete(&entities.Branch{}, id).Error
+	})
+}
+
+func (r *postgresRepository) ListBranches() ([]entities.Branch, error) {
+	var branches []entities.Branch
+	if err := r.db.Order("id").Find(&branches).Error; err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+const branchTransferColumns = "branch_transfers.*, book_copies.book_id, book_copies.barcode, " +
+	"from_branch.name AS from_branch_name, to_branch.name AS to_branch_name"
+
+func (r *postgresRepository) branchTransferQuery() *gorm.DB {
+	return r.db.Model(&entities.BranchTransfer{}).
+		Select(branchTransferColumns).
+		Joins("LEFT JOIN book_copies ON book_copies.id = branch_transfers.copy_id").
+		Joins("LEFT JOIN branches from_branch ON from_branch.id = branch_transfers.from_branch_id").
+		Joins("LEFT JOIN branches to_branch ON to_branch.id = branch_transfers.to_branch_id")
+}
+
+func (r *postgresRepository) CreateBranchTransfer(transfer *entities.BranchTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bookCopy entities.BookCopy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, transfer.CopyID).Error; err != nil {
+			return err
+		}
+		var open int64
+		err := tx.Model(&entities.BranchTransfer{}).
+			Where("copy_id = ? AND status IN ?", transfer.CopyID, entities.OpenTransferStatuses()).
+			Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return entities.ErrTransferAlreadyOpen
+		}
+		return tx.Create(transfer).Error
+	})
+}
+
+func (r *postgresRepository) GetBranchTransfer(id int) (*entities.BranchTransfer, error) {
+	var transfer entities.BranchTransfer
+	if err := r.branchTransferQuery().Where("branch_transfers.id = ?", id).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *postgresRepository) UpdateBranchTransfer(transfer *entities.BranchTransfer, previousStatus string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		transfer.UpdatedAt = time.Now()
+		result := tx.Model(&entities.BranchTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, previousStatus).
+			Updates(map[string]interface{}{
+				"status":      transfer.Status,
+				"shipped_at":  transfer.ShippedAt,
+				"received_at": transfer.ReceivedAt,
+				"updated_at":  transfer.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrTransferChanged
+		}
+
+		switch transfer.Status {
+		case entities.TransferStatusInTransit:
+			result = tx.Model(&entities.BookCopy{}).
+				Where("id = ? AND branch_id = ? AND status = ?", transfer.CopyID, transfer.FromBranchID, entities.CopyStatusAvailable).
+				Update("status", entities.CopyStatusInTransit)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return entities.ErrCopyNotAvailable
+			}
+		case entities.TransferStatusReceived:
+			return tx.Model(&entities.BookCopy{}).
+				Where("id = ? AND status = ?", transfer.CopyID, entities.CopyStatusInTransit).
+				Updates(map[string]interface{}{
+					"branch_id": transfer.ToBranchID,
+					"status":    entities.CopyStatusAvailable,
+				}).Error
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) ListBranchTransfers(filter repository.BranchTransferFilter, offset, limit int) ([]entities.BranchTransfer, int64, error) {
+	apply := func(query *gorm.DB) *gorm.DB {
+		if filter.BranchID != 0 {
+			query = query.Where("(branch_transfers.from_branch_id = ? OR branch_transfers.to_branch_id = ?)", filter.BranchID, filter.BranchID)
+		}
+		if filter.CopyID != 0 {
+			query = query.Where("branch_transfers.copy_id = ?", filter.CopyID)
+		}
+		if filter.Status != "" {
+			query = query.Where("branch_transfers.status = ?", filter.Status)
+		}
+		return query
+	}
+
+	var total int64
+	if err := apply(r.db.Model(&entities.BranchTransfer{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var transfers []entities.BranchTransfer
+	err := apply(r.branchTransferQuery()).
+		Order("branch_transfers.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&transfers).Error
+	return transfers, total, err
+}
@@ -0,0 +1,229 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const bookCopyColumns = "book_copies.*, branches.name AS branch_name"
+
+func (r *postgresRepository) bookCopyQuery() *gorm.DB {
+	return r.db.Model(&entities.BookCopy{}).Select(bookCopyColumns).Joins("LEFT JOIN branches ON branches.id = book_copies.branch_id")
+}
+
+func (r *postgresRepository) CreateBookCopy(bookCopy *entities.BookCopy) error {
+	return r.db.Create(bookCopy).Error
+}
+
+func (r *postgresRepository) GetBookCopy(id int) (*entities.BookCopy, error) {
+	var bookCopy entities.BookCopy
+	if err := r.bookCopyQuery().Where("book_copies.id = ?", id).First(&bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *postgresRepository) GetBookCopyByBarcode(barcode string) (*entities.BookCopy, error) {
+	var bookCopy entities.BookCopy
+	if err := r.bookCopyQuery().Where("book_copies.barcode = ?", barcode).First(&bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *postgresRepository) UpdateBookCopy(bookCopy *entities.BookCopy) error {
+	return r.db.Save(bookCopy).Error
+}
+
+func (r *postgresRepository) ListBookCopies(bookID int) ([]entities.BookCopy, error) {
+	var copies []entities.BookCopy
+	if err := r.bookCopyQuery().Where("book_copies.book_id = ?", bookID).Order("book_copies.id").Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+func (r *postgresRepository) GetCopyAvailability(bookIDs []uint) (map[uint]entities.CopyAvailability, error) {
+	result := make(map[uint]entities.CopyAvailability, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		BookID    uint
+		Total     int64
+		Available int64
+	}
+	err := r.db.Model(&entities.BookCopy{}).
+		Select("book_id, COUNT(*) AS total, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS available", entities.CopyStatusAvailable).
+		Where("book_id IN ? AND status <> ?", bookIDs, entities.CopyStatusWithdrawn).
+		Group("book_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.BookID] = entities.CopyAvailability{Total: row.Total, Available: row.Available}
+	}
+	return result, nil
+}
+
+func (r *postgresRepository) GetBranchAvailability(bookIDs []uint) (map[uint][]entities.BranchAvailability, error) {
+	result := make(map[uint][]entities.BranchAvailability, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		BookID     uint
+		BranchID   uint
+		BranchName string
+		Total      int64
+		Available  int64
+	}
+	err := r.db.Model(&entities.BookCopy{}).
+		Select("book_copies.book_id, book_copies.branch_id, branches.name AS branch_name, COUNT(*) AS total, SUM(CASE WHEN book_copies.status = ? THEN 1 ELSE 0 END) AS available", entities.CopyStatusAvailable).
+		Joins("LEFT JOIN branches ON branches.id = book_copies.branch_id").
+		Where("book_copies.book_id IN ? AND book_copies.status <> ?", bookIDs, entities.CopyStatusWithdrawn).
+		Group("book_copies.book_id, book_copies.branch_id, branches.name").
+		Order("book_copies.branch_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.BookID] = append(result[row.BookID], entities.BranchAvailability{
+			BranchID:   row.BranchID,
+			BranchName: row.BranchName,
+			Total:      row.Total,
+			Available:  row.Available,
+		})
+	}
+	return result, nil
+}
+
+func (r *postgresRepository) CheckoutCopy(loan *entities.Loan, maxLoans int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if maxLoans > 0 {
+			// 先锁定读者，同一读者的并发借出在这里排队，之后统计的在借数量不会过时
+			var user entities.User
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, loan.UserID).Error; err != nil {
+				return err
+			}
+			var loans int64
+			if err := tx.Model(&entities.Loan{}).Where("user_id = ? AND returned_at IS NULL", loan.UserID).Count(&loans).Error; err != nil {
+				return err
+			}
+			if loans >= int64(maxLoans) {
+				return entities.ErrLoanLimitReached
+			}
+		}
+
+		var bookCopy entities.BookCopy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, loan.CopyID).Error; err != nil {
+			return err
+		}
+		if err := r.claimHeldCopy(tx, &bookCopy, loan.UserID); err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&entities.Loan{}).Where("copy_id = ? AND returned_at IS NULL", bookCopy.ID).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return entities.ErrCopyAlreadyOnLoan
+		}
+
+		if err := tx.Create(loan).Error; err != nil {
+			return err
+		}
+		return tx.Model(&bookCopy).Update("status", entities.CopyStatusOnLoan).Error
+	})
+}
+
+// claimHeldCopy 校验副本可借；预约架上的副本只能由预约读者借出，并同时完成该预约
+func (r *postgresRepository) claimHeldCopy(tx *gorm.DB, bookCopy *entities.BookCopy, userID uint) error {
+	if bookCopy.IsAvailable() {
+		return nil
+	}
+	if bookCopy.Status != entities.CopyStatusOnHold {
+		return entities.ErrCopyNotAvailable
+	}
+
+	result := tx.Model(&entities.Hold{}).
+		Where("copy_id = ? AND user_id = ? AND status = ?", bookCopy.ID, userID, entities.HoldStatusReady).
+		Updates(map[string]interface{}{
+			"status":    entities.HoldStatusFulfilled,
+			"closed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrCopyNotAvailable
+	}
+	return nil
+}
+
+func (r *postgresRepository) ReturnLoan(loan *entities.Loan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bookCopy entities.BookCopy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, loan.CopyID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&entities.Loan{}).
+			Where("id = ? AND returned_at IS NULL", loan.ID).
+			Update("returned_at", loan.ReturnedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrLoanNotActive
+		}
+
+		return tx.Model(&bookCopy).Update("status", entities.CopyStatusAvailable).Error
+	})
+}
+
+func (r *postgresRepository) GetLoan(id int) (*entities.Loan, error) {
+	var loan entities.Loan
+	if err := r.db.First(&loan, id).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *postgresRepository) RenewLoan(loan *entities.Loan) error {
+	result := r.db.Model(&entities.Loan{}).
+		Where("id = ? AND returned_at IS NULL AND renew_count = ?", loan.ID, loan.RenewCount-1).
+		Updates(map[string]interface{}{
+			"due_at":      loan.DueAt,
+			"renew_count": loan.RenewCount,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrLoanNotActive
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListLoansByUser(userID uint, activeOnly bool) ([]entities.Loan, error) {
+	var loans []entities.Loan
+	query := r.db.Where("user_id = ?", userID)
+	if activeOnly {
+		query = query.Where("returned_at IS NULL")
+	}
+	if err := query.Order("checked_out_at DESC").Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
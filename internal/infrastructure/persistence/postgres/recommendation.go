@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// similarityBatchSize 写入相似度时每批插入的行数
+const similarityBatchSize = 500
+
+func (r *postgresRepository) RecordInteraction(interaction *entities.BookInteraction) error {
+	return r.db.Create(interaction).Error
+}
+
+func (r *postgresRepository) PruneInteractions(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&entities.BookInteraction{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *postgresRepository) ForEachReaderHistory(since time.Time, fn func(userID uint, bookIDs []uint) error) error {
+	rows, err := r.db.Model(&entities.BookInteraction{}).
+		Select("DISTINCT user_id, book_id").
+		Where("created_at >= ?", since).
+		Order("user_id, book_id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current uint
+	var bookIDs []uint
+	for rows.Next() {
+		var userID, bookID uint
+		if err := rows.Scan(&userID, &bookID); err != nil {
+			return err
+		}
+		if userID != current && len(bookIDs) > 0 {
+			if err := fn(current, bookIDs); err != nil {
+				return err
+			}
+			bookIDs = nil
+		}
+		current = userID
+		bookIDs = append(bookIDs, bookID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(bookIDs) > 0 {
+		return fn(current, bookIDs)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ReplaceBookSimilarities(similarities []entities.BookSimilarity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entities.BookSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(similarities, similarityBatchSize).Error
+	})
+}
+
+func (r *postgresRepository) ListSimilarBooks(bookID uint, limit int) ([]entities.BookSimilarity, error) {
+	var similarities []entities.BookSimilarity
+	err := r.db.Where("book_id = ?", bookID).
+		Order("score DESC, similar_book_id").
+		Limit(limit).
+		Find(&similarities).Error
+	return similarities, err
+}
@@ -15,6 +15,12 @@ func NewPostgresRepository(db *gorm.DB) repository.Repository {
 	return &postgresRepository{db: db}
 }
 
+func (r *postgresRepository) Transaction(fn func(repo repository.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&postgresRepository{db: tx})
+	})
+}
+
 func (r *postgresRepository) CreateUser(user *entities.User) error {
 	return r.db.Create(user).Error
 }
@@ -46,7 +52,7 @@ func (r *postgresRepository) GetUserByEmail(email string) (*entities.User, error
 }
 
 func (r *postgresRepository) CreateBook(book *entities.Book) error {
-	return r.db.Create(book).Error
+	return createBookWithWork(r.db, book)
 }
 
 func (r *postgresRepository) GetBook(id int) (*entities.Book, error) {
@@ -62,7 +68,25 @@ func (r *postgresRepository) UpdateBook(book *entities.Book) error {
 }
 
 func (r *postgresRepository) DeleteBook(id int) error {
-	return r.db.Delete(&entities.Book{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 先删除图书的关联记录
+		for _, link := range []interface{}{&entities.BookAuthor{}, &entities.BookCategory{}, &entities.BookTag{}, &entities.Review{}, &entities.ShelfItem{}, &entities.ReadingProgress{}, &entities.BookInteraction{}} {
+			if err := tx.Where("book_id = ?", id).Delete(link).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("book_id = ? OR similar_book_id = ?", id, id).Delete(&entities.BookSimilarity{}).Error; err != nil {
+			return err
+		}
+		var workID uint
+		if err := tx.Model(&entities.Book{}).Where("id = ?", id).Pluck("work_id", &workID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entities.Book{}, id).Error; err != nil {
+			return err
+		}
+		return deleteEmptyWork(tx, workID)
+	})
 }
 
 func (r *postgresRepository) GetBookByISBN(isbn string) (*entities.Book, error) {
@@ -73,25 +97,23 @@ func (r *postgresRepository) GetBookByISBN(isbn string) (*entities.Book, error)
 	return &book, nil
 }
 
-func (r *postgresRepository) ListBooks(offset, limit int, title, author string) ([]entities.Book, int64, error) {
+func (r *postgresRepository) ListBooks(offset, limit int, filter repository.BookFilter) ([]entities.Book, int64, error) {
 	var books []entities.Book
 	var total int64
 	
-	query := r.db.Model(&entities.Book{})
-	
-	if title != "" {
-		query = query.Where("title ILIKE ?", "%"+title+"%")
-	}
-	
-	if author != "" {
-		query = query.Where("author ILIKE ?", "%"+author+"%")
+	query := applyBookFilter(r.db.Model(&entities.Book{}), filter)
+	if filter.CollapseEditions {
+		query = collapseEditions(query, applyBookFilter(r.db.Model(&entities.Book{}), filter))
 	}
 	
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	
-	if err := query.Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+	if filter.CollapseEditions {
+		query = query.Select("books.*, " + editionCountColumn)
+	}
+	if err := orderBooks(query, filter.Sort).Offset(offset).Limit(limit).Find(&books).Error; err != nil {
 		return nil, 0, err
 	}
 	
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+func (r *postgresRepository) CreateSavedSearch(search *entities.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *postgresRepository) GetSavedSearch(id int) (*entities.SavedSearch, error) {
+	var search entities.SavedSearch
+	if err := r.db.First(&search, id).Error; err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *postgresRepository) UpdateSavedSearch(search *entities.SavedSearch) error {
+	return r.db.Save(search).Error
+}
+
+func (r *postgresRepository) DeleteSavedSearch(id uint) error {
+	return r.db.Delete(&entities.SavedSearch{}, id).Error
+}
+
+func (r *postgresRepository) ListSavedSearches(userID uint) ([]entities.SavedSearch, error) {
+	var searches []entities.SavedSearch
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&searches).Error
+	return searches, err
+}
+
+func (r *postgresRepository) ListDueSavedSearches(now time.Time, limit int) ([]entities.SavedSearch, error) {
+	var searches []entities.SavedSearch
+	err := r.db.Where("next_run_at <= ?", now).
+		Order("next_run_at").Order("id").
+		Limit(limit).
+		Find(&searches).Error
+	return searches, err
+}
+
+func (r *postgresRepository) MarkSavedSearchRun(id uint, lastBookID uint, lastRunAt, nextRunAt time.Time) error {
+	return r.db.Model(&entities.SavedSearch{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_book_id": lastBookID,
+		"last_run_at":  lastRunAt,
+		"next_run_at":  nextRunAt,
+	}).Error
+}
+
+func (r *postgresRepository) MaxBookID() (uint, error) {
+	var max uint
+	err := r.db.Model(&entities.Book{}).Select("COALESCE(MAX(id), 0)").Scan(&max).Error
+	return max, err
+}
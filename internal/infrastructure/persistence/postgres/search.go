@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// headlineOptions ts_headline 的选项，书名和作者都较短，整段高亮
+const headlineOptions = "StartSel=" + entities.HighlightStart + ", StopSel=" + entities.HighlightStop + ", HighlightAll=true"
+
+// SearchBooks 基于 search_vector 生成列的检索，书名权重高于作者，按 ts_rank_cd 排序
+func (r *postgresRepository) SearchBooks(terms []string, collapse bool, offset, limit int) ([]entities.BookSearchHit, int64, error) {
+	if len(terms) == 0 {
+		return []entities.BookSearchHit{}, 0, nil
+	}
+	if !r.db.Migrator().HasColumn("books", "search_vector") {
+		return r.searchBooksLike(terms, collapse, offset, limit)
+	}
+
+	// 检索词只含字母和数字，可以直接拼成 tsquery；每个词按前缀匹配
+	prefixed := make([]string, len(terms))
+	for i, term := range terms {
+		prefixed[i] = term + ":*"
+	}
+	tsquery := strings.Join(prefixed, " & ")
+
+	countQuery := r.db.Model(&entities.Book{}).Where("books.search_vector @@ to_tsquery('simple', ?)", tsquery)
+	editionColumn, collapseCondition := "", ""
+	if collapse {
+		countQuery = collapseEditions(countQuery,
+			r.db.Model(&entities.Book{}).Where("books.search_vector @@ to_tsquery('simple', ?)", tsquery))
+		editionColumn = ", " + editionCountColumn
+		collapseCondition = " AND books.id IN (SELECT MIN(b.id) FROM books b WHERE b.search_vector @@ q.query GROUP BY b.work_id)"
+	}
+
+	var total int64
+	if err := countQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []entities.BookSearchHit
+	if err := r.db.Raw(`SELECT books.*,
+			ts_rank_cd(books.search_vector, q.query) AS rank,
+			ts_headline('simple', books.title, q.query, ?) AS title_snippet,
+			ts_headline('simple', books.author, q.query, ?) AS author_snippet`+editionColumn+`
+		FROM books, to_tsquery('simple', ?) AS q(query)
+		WHERE books.search_vector @@ q.query`+collapseCondition+`
+		ORDER BY rank DESC, books.id
+		OFFSET ? LIMIT ?`, headlineOptions, headlineOptions, tsquery, offset, limit).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
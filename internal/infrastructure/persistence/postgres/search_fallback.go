@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// searchBooksLike 全文索引不可用时的检索：每个检索词都须出现在书名或作者中，
+// 书名命中第一个检索词的排在前面，高亮片段在内存中生成
+func (r *postgresRepository) searchBooksLike(terms []string, collapse bool, offset, limit int) ([]entities.BookSearchHit, int64, error) {
+	var books []entities.Book
+	var total int64
+
+	match := func(query *gorm.DB) *gorm.DB {
+		for _, term := range terms {
+			pattern := "%" + term + "%"
+			query = query.Where("books.title "+likeOperator+" ? OR books.author "+likeOperator+" ?", pattern, pattern)
+		}
+		return query
+	}
+	where := func(query *gorm.DB) *gorm.DB {
+		query = match(query)
+		if collapse {
+			query = collapseEditions(query, match(r.db.Model(&entities.Book{})))
+		}
+		return query
+	}
+
+	if err := where(r.db.Model(&entities.Book{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	titleFirst := clause.OrderBy{Expression: clause.Expr{
+		SQL:  "CASE WHEN books.title " + likeOperator + " ? THEN 0 ELSE 1 END",
+		Vars: []interface{}{"%" + terms[0] + "%"},
+	}}
+	selection := "books.*"
+	if collapse {
+		selection += ", " + editionCountColumn
+	}
+	if err := where(r.db.Model(&entities.Book{})).Select(selection).Order(titleFirst).Order("books.title").
+		Offset(offset).Limit(limit).Find(&books).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]entities.BookSearchHit, len(books))
+	for i, book := range books {
+		hits[i] = entities.BookSearchHit{
+			Book:          book,
+			TitleSnippet:  entities.Highlight(book.Title, terms),
+			AuthorSnippet: entities.Highlight(book.Author, terms),
+		}
+	}
+	return hits, total, nil
+}
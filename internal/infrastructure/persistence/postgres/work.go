@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+// workColumns 作品查询同时统计版本数量
+const workColumns = "works.*, (SELECT COUNT(*) FROM books b WHERE b.work_id = works.id) AS edition_count"
+
+// editionCountColumn 图书查询时统计同一作品的版本数量
+const editionCountColumn = "(SELECT COUNT(*) FROM books e WHERE e.work_id = books.work_id) AS edition_count"
+
+// createBookWithWork 创建图书；没有指定作品时同时创建一个同名的作品
+func createBookWithWork(db *gorm.DB, book *entities.Book) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if book.WorkID == 0 {
+			work := &entities.Work{Title: book.Title}
+			if err := tx.Create(work).Error; err != nil {
+				return err
+			}
+			book.WorkID = work.ID
+		}
+		return tx.Create(book).Error
+	})
+}
+
+// deleteEmptyWork 删除已经没有版本的作品
+func deleteEmptyWork(tx *gorm.DB, workID uint) error {
+	return tx.Where("id = ? AND NOT EXISTS (SELECT 1 FROM books WHERE books.work_id = works.id)", workID).
+		Delete(&entities.Work{}).Error
+}
+
+// collapseEditions 每个作品只保留满足筛选条件的图书中 ID 最小的一本，并统计作品的版本数
+func collapseEditions(query *gorm.DB, representatives *gorm.DB) *gorm.DB {
+	return query.Where("books.id IN (?)", representatives.Select("MIN(books.id)").Group("books.work_id"))
+}
+
+func (r *postgresRepository) GetWork(id int) (*entities.Work, error) {
+	var work entities.Work
+	if err := r.db.Model(&entities.Work{}).Select(workColumns).Where("works.id = ?", id).First(&work).Error; err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *postgresRepository) UpdateWork(work *entities.Work) error {
+	return r.db.Save(work).Error
+}
+
+func (r *postgresRepository) ListWorks(offset, limit int, title string) ([]entities.Work, int64, error) {
+	var works []entities.Work
+	var total int64
+
+	query := r.db.Model(&entities.Work{})
+	if title != "" {
+		query = query.Where("works.title "+likeOperator+" ?", "%"+title+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Select(workColumns).Order("works.title, works.id").Offset(offset).Limit(limit).Find(&works).Error; err != nil {
+		return nil, 0, err
+	}
+	return works, total, nil
+}
+
+func (r *postgresRepository) ListWorkEditions(workID uint) ([]entities.Book, error) {
+	var books []entities.Book
+	err := r.db.Where("work_id = ?", workID).Order("publish_date, id").Find(&books).Error
+	return books, err
+}
+
+func (r *postgresRepository) MergeWorks(targetID uint, sourceIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Book{}).Where("work_id IN ?", sourceIDs).Update("work_id", targetID).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", sourceIDs).Delete(&entities.Work{}).Error
+	})
+}
+
+func (r *postgresRepository) SplitWork(sourceID uint, work *entities.Work, bookIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(work).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&entities.Book{}).Where("id IN ? AND work_id = ?", bookIDs, sourceID).Update("work_id", work.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 期间有版本被移走时放弃拆分
+		if result.RowsAffected != int64(len(bookIDs)) {
+			return entities.ErrEditionNotInWork
+		}
+		var remaining int64
+		if err := tx.Model(&entities.Book{}).Where("work_id = ?", sourceID).Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return entities.ErrWorkSplitAll
+		}
+		return nil
+	})
+}
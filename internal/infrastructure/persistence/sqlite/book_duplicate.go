@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+func (r *sqliteRepository) SyncBookDuplicates(candidates []entities.BookDuplicate) (int, error) {
+	added := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []entities.BookDuplicate
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+		byPair := make(map[[2]uint]*entities.BookDuplicate, len(existing))
+		for i := range existing {
+			byPair[existing[i].Pair()] = &existing[i]
+		}
+
+		found := make(map[[2]uint]bool, len(candidates))
+		for i := range candidates {
+			candidate := &candidates[i]
+			found[candidate.Pair()] = true
+			current, ok := byPair[candidate.Pair()]
+			if !ok {
+				if err := tx.Create(candidate).Error; err != nil {
+					return err
+				}
+				added++
+				continue
+			}
+			if current.Status == entities.DuplicateStatusPending && (current.Score != candidate.Score || current.Reason != candidate.Reason) {
+				err := tx.Model(current).Updates(map[string]interface{}{"reason": candidate.Reason, "score": candidate.Score}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		var stale []uint
+		for _, duplicate := range existing {
+			if duplicate.Status == entities.DuplicateStatusPending && !found[duplicate.Pair()] {
+				stale = append(stale, duplicate.ID)
+			}
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", stale).Delete(&entities.BookDuplicate{}).Error
+	})
+	return added, err
+}
+
+func (r *sqliteRepository) GetBookDuplicate(id int) (*entities.BookDuplicate, error) {
+	var duplicate entities.BookDuplicate
+	if err := r.db.First(&duplicate, id).Error; err != nil {
+		return nil, err
+	}
+	return &duplicate, nil
+}
+
+func (r *sqliteRepository) ListBookDuplicates(status string, offset, limit int) ([]entities.BookDuplicate, int64, error) {
+	var duplicates []entities.BookDuplicate
+	var total int64
+
+	query := r.db.Model(&entities.BookDuplicate{}).Where("status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("score DESC, id").Offset(offset).Limit(limit).Find(&duplicates).Error; err != nil {
+		return nil, 0, err
+	}
+	return duplicates, total, nil
+}
+
+func (r *sqliteRepository) ResolveBookDuplicate(duplicate *entities.BookDuplicate) error {
+	return resolveBookDuplicate(r.db, duplicate)
+}
+
+// resolveBookDuplicate 保存审核结果，仅在组合仍待审核时生效
+func resolveBookDuplicate(tx *gorm.DB, duplicate *entities.BookDuplicate) error {
+	result := tx.Model(&entities.BookDuplicate{}).
+		Where("id = ? AND status = ?", duplicate.ID, entities.DuplicateStatusPending).
+		Updates(map[string]interface{}{
+			"status":      duplicate.Status,
+			"resolved_by": duplicate.ResolvedBy,
+			"resolved_at": duplicate.ResolvedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrDuplicateResolved
+	}
+	return nil
+}
+
+func (r *sqliteRepository) MergeBooks(survivorID, duplicateID uint, duplicate *entities.BookDuplicate) ([]uint, error) {
+	var released []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if duplicate != nil {
+			if err := resolveBookDuplicate(tx, duplicate); err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []interface{}{&entities.BookCopy{}, &entities.Loan{}, &entities.Acquisition{}, &entities.BookInteraction{}, &entities.BookFile{}, &entities.DownloadGrant{}, &entities.BookEditProposal{}} {
+			if err := tx.Model(model).Where("book_id = ?", duplicateID).Update("book_id", survivorID).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		if released, err = mergeHolds(tx, survivorID, duplicateID); err != nil {
+			return err
+		}
+
+		links := []struct {
+			model interface{}
+			key   string
+		}{
+			{&entities.BookCategory{}, "category_id"},
+			{&entities.BookTag{}, "tag_id"},
+			{&entities.Review{}, "user_id"},
+			{&entities.ShelfItem{}, "shelf_id"},
+			{&entities.ReadingProgress{}, "user_id"},
+		}
+		for _, link := range links {
+			if err := moveBookLinks(tx, link.model, link.key, survivorID, duplicateID); err != nil {
+				return err
+			}
+		}
+		if err := recomputeBookRating(tx, survivorID); err != nil {
+			return err
+		}
+		// 责任者以保留的图书为准，相似度由下次计算重新生成
+		if err := tx.Where("book_id = ?", duplicateID).Delete(&entities.BookAuthor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ? OR similar_book_id = ?", duplicateID, duplicateID).Delete(&entities.BookSimilarity{}).Error; err != nil {
+			return err
+		}
+		// 涉及被合并图书的其他待审核组合已失去意义
+		err = tx.Where("(book_id = ? OR other_book_id = ?) AND status = ?", duplicateID, duplicateID, entities.DuplicateStatusPending).
+			Delete(&entities.BookDuplicate{}).Error
+		if err != nil {
+			return err
+		}
+
+		// 之前合并到 duplicateID 的图书改为直接指向 survivorID，重定向不会形成链
+		if err := tx.Model(&entities.BookRedirect{}).Where("target_book_id = ?", duplicateID).Update("target_book_id", survivorID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&entities.BookRedirect{BookID: duplicateID, TargetBookID: survivorID}).Error; err != nil {
+			return err
+		}
+
+		var workID uint
+		if err := tx.Model(&entities.Book{}).Where("id = ?", duplicateID).Pluck("work_id", &workID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entities.Book{}, duplicateID).Error; err != nil {
+			return err
+		}
+		return deleteEmptyWork(tx, workID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// mergeHolds 把预约改到保留的图书。读者在两本书上都有预约时只保留一个：已到书的优先，
+// 都在排队时保留保留图书上的；两个都已到书时取消被合并图书上的，其副本放回可借状态并返回，
+// 由调用方重新分配给队首的预约
+func mergeHolds(tx *gorm.DB, survivorID, duplicateID uint) ([]uint, error) {
+	active := []string{entities.HoldStatusWaiting, entities.HoldStatusReady}
+	var survivorHolds []entities.Hold
+	if err := tx.Where("book_id = ? AND status IN ?", survivorID, active).Find(&survivorHolds).Error; err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]*entities.Hold, len(survivorHolds))
+	holders := make([]uint, 0, len(survivorHolds))
+	for i := range survivorHolds {
+		byUser[survivorHolds[i].UserID] = &survivorHolds[i]
+		holders = append(holders, survivorHolds[i].UserID)
+	}
+
+	var cancelled, released []uint
+	if len(holders) > 0 {
+		var duplicateHolds []entities.Hold
+		if err := tx.Where("book_id = ? AND status IN ? AND user_id IN ?", duplicateID, active, holders).Find(&duplicateHolds).Error; err != nil {
+			return nil, err
+		}
+		for _, hold := range duplicateHolds {
+			kept := byUser[hold.UserID]
+			switch {
+			case hold.Status == entities.HoldStatusWaiting:
+				cancelled = append(cancelled, hold.ID)
+			case kept.Status == entities.HoldStatusWaiting:
+				cancelled = append(cancelled, kept.ID)
+			default:
+				cancelled = append(cancelled, hold.ID)
+				if hold.CopyID != nil {
+					released = append(released, *hold.CopyID)
+				}
+			}
+		}
+	}
+
+	if len(cancelled) > 0 {
+		err := tx.Model(&entities.Hold{}).
+			Where("id IN ?", cancelled).
+			Updates(map[string]interface{}{
+				"status":    entities.HoldStatusCancelled,
+				"closed_at": time.Now(),
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(released) > 0 {
+		err := tx.Model(&entities.BookCopy{}).
+			Where("id IN ? AND status = ?", released, entities.CopyStatusOnHold).
+			Update("status", entities.CopyStatusAvailable).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(&entities.Hold{}).Where("book_id = ?", duplicateID).Update("book_id", survivorID).Error; err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// moveBookLinks 把关联记录从 duplicateID 改到 survivorID；survivorID 已有相同 key 的记录时删除 duplicateID 的那条
+func moveBookLinks(tx *gorm.DB, model interface{}, key string, survivorID, duplicateID uint) error {
+	var existing []uint
+	if err := tx.Model(model).Where("book_id = ?", survivorID).Pluck(key, &existing).Error; err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		if err := tx.Where("book_id = ? AND "+key+" IN ?", duplicateID, existing).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(model).Where("book_id = ?", duplicateID).Update("book_id", survivorID).Error
+}
+
+// recomputeBookRating 按已公开的书评重新汇总图书评分
+func recomputeBookRating(tx *gorm.DB, bookID uint) error {
+	return tx.Exec(`UPDATE books SET
+		rating_count = (SELECT COUNT(*) FROM reviews WHERE reviews.book_id = books.id AND reviews.status = ?),
+		rating_sum = (SELECT COALESCE(SUM(reviews.rating), 0) FROM reviews WHERE reviews.book_id = books.id AND reviews.status = ?),
+		rating_average = (SELECT COALESCE(AVG(reviews.rating * 1.0), 0) FROM reviews WHERE reviews.book_id = books.id AND reviews.status = ?)
+		WHERE id = ?`, entities.ReviewStatusApproved, entities.ReviewStatusApproved, entities.ReviewStatusApproved, bookID).Error
+}
+
+func (r *sqliteRepository) GetBookRedirect(bookID uint) (*entities.BookRedirect, error) {
+	var redirect entities.BookRedirect
+	if err := r.db.Where("book_id = ?", bookID).First(&redirect).Error; err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
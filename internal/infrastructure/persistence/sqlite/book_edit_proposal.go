@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const bookEditProposalColumns = "book_edit_proposals.*, users.name AS user_name, reviewers.name AS reviewer_name, books.title AS book_title"
+
+func (r *sqliteRepository) bookEditProposalQuery() *gorm.DB {
+	return r.db.Model(&entities.BookEditProposal{}).
+		Select(bookEditProposalColumns).
+		Joins("LEFT JOIN users ON users.id = book_edit_proposals.user_id").
+		Joins("LEFT JOIN users AS reviewers ON reviewers.id = book_edit_proposals.reviewer_id").
+		Joins("LEFT JOIN books ON books.id = book_edit_proposals.book_id")
+}
+
+func (r *sqliteRepository) CreateBookEditProposal(proposal *entities.BookEditProposal) error {
+	return r.db.Create(proposal).Error
+}
+
+func (r *sqliteRepository) GetBookEditProposal(id int) (*entities.BookEditProposal, error) {
+	var proposal entities.BookEditProposal
+	if err := r.bookEditProposalQuery().Where("book_edit_proposals.id = ?", id).First(&proposal).Error; err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+func (r *sqliteRepository) ListBookEditProposals(filter repository.BookEditProposalFilter, offset, limit int) ([]entities.BookEditProposal, int64, error) {
+	apply := func(query *gorm.DB) *gorm.DB {
+		if filter.BookID != 0 {
+			query = query.Where("book_edit_proposals.book_id = ?", filter.BookID)
+		}
+		if filter.UserID != 0 {
+			query = query.Where("book_edit_proposals.user_id = ?", filter.UserID)
+		}
+		if filter.Status != "" {
+			query = query.Where("book_edit_proposals.status = ?", filter.Status)
+		}
+		return query
+	}
+
+	var total int64
+	if err := apply(r.db.Model(&entities.BookEditProposal{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var proposals []entities.BookEditProposal
+	err := apply(r.bookEditProposalQuery()).
+		Order("book_edit_proposals.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&proposals).Error
+	return proposals, total, err
+}
+
+func (r *sqliteRepository) ResolveBookEditProposal(proposal *entities.BookEditProposal) error {
+	return resolveBookEditProposal(r.db, proposal)
+}
+
+// resolveBookEditProposal 保存审核结果，仅在建议仍待审核时生效
+func resolveBookEditProposal(tx *gorm.DB, proposal *entities.BookEditProposal) error {
+	result := tx.Model(&entities.BookEditProposal{}).
+		Where("id = ? AND status = ?", proposal.ID, entities.EditProposalStatusPending).
+		Updates(map[string]interface{}{
+			"status":      proposal.Status,
+			"reviewer_id": proposal.ReviewerID,
+			"review_note": proposal.ReviewNote,
+			"reviewed_at": proposal.ReviewedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entities.ErrEditProposalResolved
+	}
+	return nil
+}
+
+func (r *sqliteRepository) ApplyBookEditProposal(proposal *entities.BookEditProposal) (*entities.Book, error) {
+	changes, err := proposal.DecodeChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var book entities.Book
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定图书行，检查冲突和写入之间图书不会再被修改
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, proposal.BookID).Error; err != nil {
+			return err
+		}
+		if len(entities.EditConflicts(&book, changes)) > 0 {
+			return entities.ErrEditProposalConflict
+		}
+		if err := resolveBookEditProposal(tx, proposal); err != nil {
+			return err
+		}
+		return tx.Model(&book).Updates(entities.ApplyEditChanges(&book, changes)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// applyBookFilter 将 BookFilter 转换为查询条件
+func applyBookFilter(query *gorm.DB, filter repository.BookFilter) *gorm.DB {
+	if filter.Title != "" {
+		query = query.Where("books.title "+likeOperator+" ?", "%"+filter.Title+"%")
+	}
+	if filter.Author != "" {
+		pattern := "%" + filter.Author + "%"
+		query = query.Where("books.author "+likeOperator+" ? OR EXISTS (SELECT 1 FROM book_authors ba JOIN authors a ON a.id = ba.author_id WHERE ba.book_id = books.id AND a.name "+likeOperator+" ?)", pattern, pattern)
+	}
+	if filter.AuthorID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = books.id AND ba.author_id = ?)", filter.AuthorID)
+	}
+	if filter.CategoryPath != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM book_categories bc JOIN categories c ON c.id = bc.category_id WHERE bc.book_id = books.id AND c.path LIKE ?)", filter.CategoryPath+"%")
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("EXISTS (SELECT 1 FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.book_id = books.id AND t.name = ?)", tag)
+	}
+	if filter.Publisher != "" {
+		query = query.Where("books.publisher "+likeOperator+" ?", "%"+filter.Publisher+"%")
+	}
+	if filter.Language != "" {
+		query = query.Where("books.language = ?", filter.Language)
+	}
+	if filter.Format != "" {
+		query = query.Where("books.format = ?", filter.Format)
+	}
+	if filter.BranchID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM book_copies cp WHERE cp.book_id = books.id AND cp.branch_id = ? AND cp.status <> ?)", filter.BranchID, entities.CopyStatusWithdrawn)
+	}
+	if filter.AfterID != 0 {
+		query = query.Where("books.id > ?", filter.AfterID)
+	}
+	if filter.ThroughID != 0 {
+		query = query.Where("books.id <= ?", filter.ThroughID)
+	}
+	// 出版日期以 YYYY 开头，按字符串比较即可按年份筛选；空日期小于任何年份
+	if filter.PublishedFrom != 0 {
+		query = query.Where("books.publish_date >= ?", fmt.Sprintf("%04d", filter.PublishedFrom))
+	}
+	if filter.PublishedTo != 0 {
+		query = query.Where("books.publish_date <> '' AND books.publish_date < ?", fmt.Sprintf("%04d", filter.PublishedTo+1))
+	}
+	if filter.MinPages != 0 {
+		query = query.Where("books.page_count >= ?", filter.MinPages)
+	}
+	if filter.MaxPages != 0 {
+		query = query.Where("books.page_count > 0 AND books.page_count <= ?", filter.MaxPages)
+	}
+	return query
+}
+
+// orderBooks 按 BookFilter.Sort 排序，主键作为最后的排序键保证分页稳定
+func orderBooks(query *gorm.DB, sort string) *gorm.DB {
+	switch sort {
+	case repository.BookSortRating:
+		query = query.Order("books.rating_average DESC").Order("books.rating_count DESC")
+	case repository.BookSortTitle:
+		query = query.Order("books.title").Order("books.subtitle")
+	case repository.BookSortPublished:
+		query = query.Order("books.publish_date DESC")
+	case repository.BookSortPages:
+		query = query.Order("books.page_count = 0").Order("books.page_count")
+	case repository.BookSortAdded:
+		query = query.Order("books.created_at DESC")
+	}
+	return query.Order("books.id")
+}
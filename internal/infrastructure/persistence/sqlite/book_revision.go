@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+)
+
+const bookRevisionColumns = "book_revisions.*, users.name AS actor_name"
+
+func (r *sqliteRepository) bookRevisions() *gorm.DB {
+	return r.db.Model(&entities.BookRevision{}).Select(bookRevisionColumns).Joins("LEFT JOIN users ON users.id = book_revisions.actor_id")
+}
+
+func (r *sqliteRepository) AddBookRevision(revision *entities.BookRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&entities.BookRevision{}).
+			Select("COALESCE(MAX(revision), 0)").
+			Where("book_id = ?", revision.BookID).
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		// 并发写入同一本书时唯一索引会拒绝重复的修订号
+		revision.Revision = latest + 1
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *sqliteRepository) GetBookRevision(bookID uint, revision int) (*entities.BookRevision, error) {
+	var result entities.BookRevision
+	if err := r.bookRevisions().
+		Where("book_revisions.book_id = ? AND book_revisions.revision = ?", bookID, revision).
+		First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (r *sqliteRepository) ListBookRevisions(bookID uint, offset, limit int) ([]entities.BookRevision, int64, error) {
+	var total int64
+	if err := r.db.Model(&entities.BookRevision{}).Where("book_id = ?", bookID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []entities.BookRevision
+	err := r.bookRevisions().
+		Where("book_revisions.book_id = ?", bookID).
+		Order("book_revisions.revision DESC").
+		Offset(offset).Limit(limit).
+		Find(&revisions).Error
+	return revisions, total, err
+}
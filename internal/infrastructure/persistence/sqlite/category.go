@@ -0,0 +1,279 @@
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+func (r *sqliteRepository) CreateCategory(category *entities.Category, parent *entities.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 路径依赖自增 ID，先写入占位路径再补全
+		category.Path = "/"
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		category.BuildPath(parent)
+		return tx.Model(category).Updates(map[string]interface{}{
+			"parent_id": category.ParentID,
+			"path":      category.Path,
+			"depth":     category.Depth,
+		}).Error
+	})
+}
+
+func (r *sqliteRepository) GetCategory(id int) (*entities.Category, error) {
+	var category entities.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *sqliteRepository) UpdateCategory(category *entities.Category, oldPath string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		if oldPath == category.Path {
+			return nil
+		}
+
+		var descendants []entities.Category
+		if err := tx.Where("path LIKE ? AND id <> ?", oldPath+"%", category.ID).Find(&descendants).Error; err != nil {
+			return err
+		}
+		for _, descendant := range descendants {
+			path := category.Path + strings.TrimPrefix(descendant.Path, oldPath)
+			depth := strings.Count(path, "/") - 2
+			if err := tx.Model(&descendant).Updates(map[string]interface{}{"path": path, "depth": depth}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqliteRepository) DeleteCategory(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&entities.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return entities.ErrCategoryHasChildren
+		}
+		if err := tx.Where("category_id = ?", id).Delete(&entities.BookCategory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Category{}, id).Error
+	})
+}
+
+func (r *sqliteRepository) ListCategories() ([]entities.Category, error) {
+	var categories []entities.Category
+	if err := r.db.Order("path").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *sqliteRepository) SetBookCategories(bookID uint, categoryIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&entities.BookCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+
+		links := make([]entities.BookCategory, len(categoryIDs))
+		for i, categoryID := range categoryIDs {
+			links[i] = entities.BookCategory{BookID: bookID, CategoryID: categoryID}
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+func (r *sqliteRepository) ListBookCategories(bookIDs []uint) (map[uint][]entities.Category, error) {
+	result := make(map[uint][]entities.Category, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		BookID uint
+		entities.Category
+	}
+	err := r.db.Table("book_categories").
+		Select("book_categories.book_id, categories.*").
+		Joins("JOIN categories ON categories.id = book_categories.category_id").
+		Where("book_categories.book_id IN ?", bookIDs).
+		Order("categories.path").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.BookID] = append(result[row.BookID], row.Category)
+	}
+	return result, nil
+}
+
+func (r *sqliteRepository) ListTags(prefix string) ([]entities.FacetCount, error) {
+	var tags []entities.FacetCount
+	query := r.db.Table("tags").
+		Select("tags.id AS id, tags.name AS name, COUNT(book_tags.book_id) AS count").
+		Joins("LEFT JOIN book_tags ON book_tags.tag_id = tags.id").
+		Group("tags.id, tags.name").
+		Order("tags.name")
+	if prefix != "" {
+		query = query.Where("tags.name LIKE ?", prefix+"%")
+	}
+	if err := query.Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *sqliteRepository) GetTag(id int) (*entities.Tag, error) {
+	var tag entities.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *sqliteRepository) RenameTag(tag *entities.Tag, name string) (*entities.Tag, error) {
+	result := tag
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing entities.Tag
+		err := tx.Where("name = ? AND id <> ?", name, tag.ID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag.Name = name
+			return tx.Model(tag).Update("name", name).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		// 合并：把原标签的图书挂到已有标签上
+		var bookIDs []uint
+		if err := tx.Model(&entities.BookTag{}).Where("tag_id = ?", tag.ID).Pluck("book_id", &bookIDs).Error; err != nil {
+			return err
+		}
+		var taken []uint
+		if err := tx.Model(&entities.BookTag{}).Where("tag_id = ?", existing.ID).Pluck("book_id", &taken).Error; err != nil {
+			return err
+		}
+		skip := make(map[uint]bool, len(taken))
+		for _, id := range taken {
+			skip[id] = true
+		}
+		for _, bookID := range bookIDs {
+			if skip[bookID] {
+				continue
+			}
+			if err := tx.Create(&entities.BookTag{BookID: bookID, TagID: existing.ID}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&entities.BookTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entities.Tag{}, tag.ID).Error; err != nil {
+			return err
+		}
+		result = &existing
+		return nil
+	})
+	return result, err
+}
+
+func (r *sqliteRepository) DeleteTag(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&entities.BookTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Tag{}, id).Error
+	})
+}
+
+func (r *sqliteRepository) SetBookTags(bookID uint, names []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&entities.BookTag{}).Error; err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			var tag entities.Tag
+			if err := tx.Where(entities.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&entities.BookTag{BookID: bookID, TagID: tag.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqliteRepository) ListBookTags(bookIDs []uint) (map[uint][]string, error) {
+	result := make(map[uint][]string, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		BookID uint
+		Name   string
+	}
+	err := r.db.Table("book_tags").
+		Select("book_tags.book_id, tags.name").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("book_tags.book_id IN ?", bookIDs).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.BookID] = append(result[row.BookID], row.Name)
+	}
+	return result, nil
+}
+
+func (r *sqliteRepository) BookFacets(filter repository.BookFilter) (*entities.BookFacets, error) {
+	matching := applyBookFilter(r.db.Model(&entities.Book{}), filter).Select("books.id")
+	facets := &entities.BookFacets{}
+
+	err := r.db.Table("book_categories").
+		Select("categories.id AS id, categories.name AS name, COUNT(DISTINCT book_categories.book_id) AS count").
+		Joins("JOIN categories ON categories.id = book_categories.category_id").
+		Where("book_categories.book_id IN (?)", matching).
+		Group("categories.id, categories.name").
+		Order("count DESC, categories.name").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Table("book_tags").
+		Select("tags.id AS id, tags.name AS name, COUNT(DISTINCT book_tags.book_id) AS count").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("book_tags.book_id IN (?)", matching).
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name").
+		Limit(50).
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
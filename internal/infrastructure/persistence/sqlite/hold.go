@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *sqliteRepository) PlaceHold(hold *entities.Hold) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定书目行，使同一书目的预约串行化
+		var book entities.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, hold.BookID).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		err := tx.Model(&entities.Hold{}).
+			Where("book_id = ? AND user_id = ? AND status IN ?", hold.BookID, hold.UserID,
+				[]string{entities.HoldStatusWaiting, entities.HoldStatusReady}).
+			Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return entities.ErrHoldAlreadyExists
+		}
+
+		return tx.Create(hold).Error
+	})
+}
+
+func (r *sqliteRepository) GetHold(id int) (*entities.Hold, error) {
+	var hold entities.Hold
+	if err := r.db.First(&hold, id).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *sqliteRepository) CloseHold(hold *entities.Hold) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Hold{}).
+			Where("id = ? AND status IN ?", hold.ID, []string{entities.HoldStatusWaiting, entities.HoldStatusReady}).
+			Updates(map[string]interface{}{
+				"status":    hold.Status,
+				"closed_at": hold.ClosedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entities.ErrHoldNotActive
+		}
+
+		if hold.CopyID == nil {
+			return nil
+		}
+		return tx.Model(&entities.BookCopy{}).
+			Where("id = ? AND status = ?", *hold.CopyID, entities.CopyStatusOnHold).
+			Update("status", entities.CopyStatusAvailable).Error
+	})
+}
+
+func (r *sqliteRepository) AssignCopyToNextHold(copyID uint, now time.Time, shelfPeriod time.Duration) (*entities.Hold, error) {
+	var assigned *entities.Hold
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var bookCopy entities.BookCopy
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, copyID).Error; err != nil {
+			return err
+		}
+		if !bookCopy.IsAvailable() {
+			return nil
+		}
+
+		var hold entities.Hold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("book_id = ? AND status = ?", bookCopy.BookID, entities.HoldStatusWaiting).
+			Order("id").
+			First(&hold).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		hold.MarkReady(bookCopy.ID, now, shelfPeriod)
+		if err := tx.Save(&hold).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&bookCopy).Update("status", entities.CopyStatusOnHold).Error; err != nil {
+			return err
+		}
+		assigned = &hold
+		return nil
+	})
+	return assigned, err
+}
+
+func (r *sqliteRepository) GetHoldQueuePosition(hold *entities.Hold) (int64, error) {
+	var position int64
+	err := r.db.Model(&entities.Hold{}).
+		Where("book_id = ? AND status = ? AND id <= ?", hold.BookID, entities.HoldStatusWaiting, hold.ID).
+		Count(&position).Error
+	return position, err
+}
+
+func (r *sqliteRepository) ListHoldsByUser(userID uint, activeOnly bool) ([]entities.Hold, error) {
+	var holds []entities.Hold
+	query := r.db.Where("user_id = ?", userID)
+	if activeOnly {
+		query = query.Where("status IN ?", []string{entities.HoldStatusWaiting, entities.HoldStatusReady})
+	}
+	if err := query.Order("id DESC").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *sqliteRepository) ListReadyHolds() ([]entities.Hold, error) {
+	var holds []entities.Hold
+	if err := r.db.Where("status = ?", entities.HoldStatusReady).Order("expires_at").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *sqliteRepository) ListExpiredHolds(now time.Time) ([]entities.Hold, error) {
+	var holds []entities.Hold
+	err := r.db.Where("status = ? AND expires_at < ?", entities.HoldStatusReady, now).Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *sqliteRepository) EnsureLedgerAccount(account *entities.LedgerAccount) error {
+	// 并发创建同一账户时只有一条插入成功，之后统一按编码读回
+	created := *account
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+		return err
+	}
+	var existing entities.LedgerAccount
+	if err := r.db.Where("code = ?", account.Code).First(&existing).Error; err != nil {
+		return err
+	}
+	*account = existing
+	return nil
+}
+
+func (r *sqliteRepository) PostLedgerTransaction(posting *entities.LedgerPosting) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return postLedgerTransaction(tx, posting)
+	})
+}
+
+func postLedgerTransaction(tx *gorm.DB, posting *entities.LedgerPosting) error {
+	if err := tx.Create(&posting.Transaction).Error; err != nil {
+		return err
+	}
+	for i := range posting.Entries {
+		posting.Entries[i].TransactionID = posting.Transaction.ID
+	}
+	return tx.Create(&posting.Entries).Error
+}
+
+func (r *sqliteRepository) LedgerAccountBalance(accountID uint) (int64, error) {
+	var balance int64
+	err := r.db.Model(&entities.LedgerEntry{}).
+		Where("account_id = ?", accountID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *sqliteRepository) ListLedgerEntries(accountID uint, offset, limit int) ([]entities.LedgerEntry, int64, error) {
+	var total int64
+	if err := r.db.Model(&entities.LedgerEntry{}).Where("account_id = ?", accountID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []entities.LedgerEntry
+	err := r.db.Model(&entities.LedgerEntry{}).
+		Select("ledger_entries.*, ledger_transactions.kind, ledger_transactions.description, ledger_transactions.payment_id").
+		Joins("JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id").
+		Where("ledger_entries.account_id = ?", accountID).
+		Order("ledger_entries.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+func (r *sqliteRepository) CreateSavedSearch(search *entities.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *sqliteRepository) GetSavedSearch(id int) (*entities.SavedSearch, error) {
+	var search entities.SavedSearch
+	if err := r.db.First(&search, id).Error; err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *sqliteRepository) UpdateSavedSearch(search *entities.SavedSearch) error {
+	return r.db.Save(search).Error
+}
+
+func (r *sqliteRepository) DeleteSavedSearch(id uint) error {
+	return r.db.Delete(&entities.SavedSearch{}, id).Error
+}
+
+func (r *sqliteRepository) ListSavedSearches(userID uint) ([]entities.SavedSearch, error) {
+	var searches []entities.SavedSearch
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&searches).Error
+	return searches, err
+}
+
+func (r *sqliteRepository) ListDueSavedSearches(now time.Time, limit int) ([]entities.SavedSearch, error) {
+	var searches []entities.SavedSearch
+	err := r.db.Where("next_run_at <= ?", now).
+		Order("next_run_at").Order("id").
+		Limit(limit).
+		Find(&searches).Error
+	return searches, err
+}
+
+func (r *sqliteRepository) MarkSavedSearchRun(id uint, lastBookID uint, lastRunAt, nextRunAt time.Time) error {
+	return r.db.Model(&entities.SavedSearch{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_book_id": lastBookID,
+		"last_run_at":  lastRunAt,
+		"next_run_at":  nextRunAt,
+	}).Error
+}
+
+func (r *sqliteRepository) MaxBookID() (uint, error) {
+	var max uint
+	err := r.db.Model(&entities.Book{}).Select("COALESCE(MAX(id), 0)").Scan(&max).Error
+	return max, err
+}
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// SearchBooks 基于 FTS5 外部内容表 books_fts 的检索，按 bm25 排序（书名权重高于作者）；
+// 驱动未启用 FTS5 时迁移不会创建 books_fts，此时退化为模糊匹配
+func (r *sqliteRepository) SearchBooks(terms []string, collapse bool, offset, limit int) ([]entities.BookSearchHit, int64, error) {
+	if len(terms) == 0 {
+		return []entities.BookSearchHit{}, 0, nil
+	}
+	if !r.db.Migrator().HasTable("books_fts") {
+		return r.searchBooksLike(terms, collapse, offset, limit)
+	}
+
+	// 检索词只含字母和数字，加引号后作为前缀查询，多个词之间是 AND 关系
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"*`
+	}
+	match := strings.Join(quoted, " ")
+
+	countSQL := `SELECT count(*) FROM books_fts WHERE books_fts MATCH ?`
+	editionColumn, collapseCondition := "", ""
+	args := []interface{}{
+		entities.HighlightStart, entities.HighlightStop,
+		entities.HighlightStart, entities.HighlightStop,
+		match,
+	}
+	if collapse {
+		countSQL = `SELECT count(DISTINCT books.work_id) FROM books_fts JOIN books ON books.id = books_fts.rowid WHERE books_fts MATCH ?`
+		editionColumn = ", " + editionCountColumn
+		collapseCondition = ` AND books.id IN (SELECT MIN(b.id) FROM books_fts JOIN books b ON b.id = books_fts.rowid
+			WHERE books_fts MATCH ? GROUP BY b.work_id)`
+		args = append(args, match)
+	}
+	args = append(args, limit, offset)
+
+	var total int64
+	if err := r.db.Raw(countSQL, match).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []entities.BookSearchHit
+	if err := r.db.Raw(`SELECT books.*,
+			-bm25(books_fts, 10.0, 5.0) AS rank,
+			highlight(books_fts, 0, ?, ?) AS title_snippet,
+			highlight(books_fts, 1, ?, ?) AS author_snippet`+editionColumn+`
+		FROM books_fts JOIN books ON books.id = books_fts.rowid
+		WHERE books_fts MATCH ?`+collapseCondition+`
+		ORDER BY rank DESC, books.id
+		LIMIT ? OFFSET ?`, args...).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
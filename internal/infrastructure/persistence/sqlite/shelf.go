@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// shelfColumns 书架查询同时统计图书数量
+const shelfColumns = "shelves.*, (SELECT COUNT(*) FROM shelf_items si WHERE si.shelf_id = shelves.id) AS book_count"
+
+func (r *sqliteRepository) EnsureShelves(shelves []entities.Shelf) error {
+	if len(shelves) == 0 {
+		return nil
+	}
+	// 并发的首次访问可能同时创建，按 (user_id, name) 唯一索引忽略已存在的
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&shelves).Error
+}
+
+func (r *sqliteRepository) CreateShelf(shelf *entities.Shelf) error {
+	return r.db.Create(shelf).Error
+}
+
+func (r *sqliteRepository) GetShelf(id int) (*entities.Shelf, error) {
+	var shelf entities.Shelf
+	if err := r.db.Model(&entities.Shelf{}).Select(shelfColumns).Where("shelves.id = ?", id).First(&shelf).Error; err != nil {
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+func (r *sqliteRepository) GetShelfByShareToken(token string) (*entities.Shelf, error) {
+	var shelf entities.Shelf
+	if err := r.db.Model(&entities.Shelf{}).Select(shelfColumns).Where("shelves.share_token = ?", token).First(&shelf).Error; err != nil {
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+func (r *sqliteRepository) UpdateShelf(shelf *entities.Shelf) error {
+	return r.db.Save(shelf).Error
+}
+
+func (r *sqliteRepository) DeleteShelf(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shelf_id = ?", id).Delete(&entities.ShelfItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Shelf{}, id).Error
+	})
+}
+
+func (r *sqliteRepository) ListShelves(userID uint) ([]entities.Shelf, error) {
+	var shelves []entities.Shelf
+	err := r.db.Model(&entities.Shelf{}).Select(shelfColumns).
+		Where("shelves.user_id = ?", userID).
+		Order("CASE WHEN shelves.kind = 'custom' THEN 1 ELSE 0 END, shelves.id").
+		Find(&shelves).Error
+	return shelves, err
+}
+
+func (r *sqliteRepository) AddShelfItem(item *entities.ShelfItem, exclusiveShelfIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(exclusiveShelfIDs) > 0 {
+			err := tx.Where("book_id = ? AND shelf_id IN ? AND shelf_id <> ?", item.BookID, exclusiveShelfIDs, item.ShelfID).
+				Delete(&entities.ShelfItem{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		// 已在书架上时保留原来的加入时间
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+	})
+}
+
+func (r *sqliteRepository) RemoveShelfItem(shelfID, bookID uint) error {
+	return r.db.Where("shelf_id = ? AND book_id = ?", shelfID, bookID).Delete(&entities.ShelfItem{}).Error
+}
+
+func (r *sqliteRepository) ListShelfItems(shelfID uint, offset, limit int) ([]entities.ShelfItem, int64, error) {
+	var items []entities.ShelfItem
+	var total int64
+
+	query := r.db.Model(&entities.ShelfItem{}).Where("shelf_id = ?", shelfID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("added_at DESC, id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *sqliteRepository) GetReadingProgress(userID uint, bookIDs []uint) (map[uint]entities.ReadingProgress, error) {
+	result := make(map[uint]entities.ReadingProgress)
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	var records []entities.ReadingProgress
+	if err := r.db.Where("user_id = ? AND book_id IN ?", userID, bookIDs).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result[record.BookID] = record
+	}
+	return result, nil
+}
+
+func (r *sqliteRepository) SaveReadingProgress(progress *entities.ReadingProgress) error {
+	return r.db.Save(progress).Error
+}
+
+func (r *sqliteRepository) ListFinishedReading(userID uint, from, to time.Time) ([]entities.ReadingProgress, error) {
+	var records []entities.ReadingProgress
+	err := r.db.Where("user_id = ? AND finished_at >= ? AND finished_at < ?", userID, from, to).
+		Order("finished_at").
+		Find(&records).Error
+	return records, err
+}
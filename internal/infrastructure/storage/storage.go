@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/azel-ko/final-ddd/internal/domain/storage"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+const defaultLocalDir = "./data/uploads"
+
+// NewStorage 根据配置创建对象存储，目前支持本地文件系统（local，默认）
+func NewStorage(cfg config.StorageConfig) (storage.ObjectStorage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		dir := cfg.LocalDir
+		if dir == "" {
+			dir = defaultLocalDir
+		}
+		return NewLocalStorage(dir)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
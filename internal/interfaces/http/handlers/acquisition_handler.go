@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AcquisitionHandler struct {
+	acquisitionService *services.AcquisitionService
+}
+
+func NewAcquisitionHandler(acquisitionService *services.AcquisitionService) *AcquisitionHandler {
+	return &AcquisitionHandler{acquisitionService: acquisitionService}
+}
+
+// Suggest 读者荐购一本书
+func (h *AcquisitionHandler) Suggest(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.SuggestAcquisitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Suggest(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List 采购列表，?status= 按状态筛选；读者只能看到自己提交的
+func (h *AcquisitionHandler) List(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var query dto.AcquisitionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	response, err := h.acquisitionService.ListAcquisitions(userID, role, page, pageSize, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get 采购记录及其状态历史
+func (h *AcquisitionHandler) Get(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.acquisitionService.GetAcquisition(userID, role, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AcquisitionHandler) Approve(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.AcquisitionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Approve(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AcquisitionHandler) Reject(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.AcquisitionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Reject(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Order 记录下单的供应商、数量和金额
+func (h *AcquisitionHandler) Order(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.OrderAcquisitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Order(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Receive 到货并编目入藏
+func (h *AcquisitionHandler) Receive(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReceiveAcquisitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Receive(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Cancel 取消采购；读者只能撤回自己尚未审核的荐购
+func (h *AcquisitionHandler) Cancel(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.AcquisitionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.acquisitionService.Cancel(userID, role, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
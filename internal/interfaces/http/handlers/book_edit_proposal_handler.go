@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BookEditProposalHandler struct {
+	proposalService *services.BookEditProposalService
+}
+
+func NewBookEditProposalHandler(proposalService *services.BookEditProposalService) *BookEditProposalHandler {
+	return &BookEditProposalHandler{proposalService: proposalService}
+}
+
+// Propose 对图书提出修改建议
+func (h *BookEditProposalHandler) Propose(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ProposeBookEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.proposalService.Propose(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List 修改建议列表，?status= 按状态、?book_id= 按图书筛选；读者只能看到自己提交的
+func (h *BookEditProposalHandler) List(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var query dto.EditProposalListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	response, err := h.proposalService.List(userID, role, page, pageSize, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get 修改建议及其与图书现在的值的逐字段对照
+func (h *BookEditProposalHandler) Get(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.proposalService.Get(userID, role, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BookEditProposalHandler) Approve(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReviewEditProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.proposalService.Approve(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BookEditProposalHandler) Reject(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReviewEditProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.proposalService.Reject(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Withdraw 提交人撤回尚未审核的建议
+func (h *BookEditProposalHandler) Withdraw(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.proposalService.Withdraw(userID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
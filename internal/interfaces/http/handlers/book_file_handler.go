@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/pkg/signedurl"
+	"github.com/gin-gonic/gin"
+)
+
+type BookFileHandler struct {
+	bookFileService *services.BookFileService
+}
+
+func NewBookFileHandler(bookFileService *services.BookFileService) *BookFileHandler {
+	return &BookFileHandler{bookFileService: bookFileService}
+}
+
+// List 列出图书的电子书文件
+func (h *BookFileHandler) List(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	response, err := h.bookFileService.List(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Upload 上传 EPUB 或 PDF 文件。文件可以放在 multipart 的 file 字段中，
+// 也可以直接作为请求体，此时用 filename 查询参数指定文件名
+func (h *BookFileHandler) Upload(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	body, fileName, err := uploadSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fileName == "" {
+		fileName = c.Query("filename")
+	}
+
+	response, err := h.bookFileService.Upload(c.Request.Context(), userID, id, body, fileName)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Delete 删除电子书文件
+func (h *BookFileHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	fileID, ok := parseIDParam(c, "fileId")
+	if !ok {
+		return
+	}
+	if err := h.bookFileService.Delete(c.Request.Context(), id, fileID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book file deleted successfully"})
+}
+
+// IssueLink 为当前用户生成短时有效的签名下载链接
+func (h *BookFileHandler) IssueLink(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	fileID, ok := parseIDParam(c, "fileId")
+	if !ok {
+		return
+	}
+
+	response, err := h.bookFileService.IssueLink(userID, id, fileID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Download 凭签名链接下载文件，不需要登录
+func (h *BookFileHandler) Download(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	object, file, err := h.bookFileService.Open(c.Request.Context(), id,
+		c.Query(signedurl.ExpiresParam), c.Query(signedurl.SignatureParam))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer object.Body.Close()
+
+	// 链接只在短时间内有效，不允许中间缓存保存文件
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}))
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, nil)
+}
@@ -1,31 +1,38 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/azel-ko/final-ddd/internal/application/dto"
 	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
 )
 
 type BookHandler struct {
-	bookService *services.BookService
+	bookService           *services.BookService
+	recommendationService *services.RecommendationService
 }
 
-func NewBookHandler(bookService *services.BookService) *BookHandler {
-	return &BookHandler{bookService: bookService}
+func NewBookHandler(bookService *services.BookService, recommendationService *services.RecommendationService) *BookHandler {
+	return &BookHandler{bookService: bookService, recommendationService: recommendationService}
 }
 
 func (h *BookHandler) Create(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
 	var req dto.CreateBookRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	response, err := h.bookService.CreateBook(&req)
+	response, err := h.bookService.CreateBook(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -41,9 +48,15 @@ func (h *BookHandler) Get(c *gin.Context) {
 	}
 	response, err := h.bookService.GetBook(id)
 	if err != nil {
+		// 已合并的图书跳转到保留下来的那本，旧链接仍然有效
+		if target, ok := h.bookService.MergedInto(id); ok {
+			c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/api/books/%d", target))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.recordInteraction(c, response.ID, entities.InteractionView)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -51,14 +64,19 @@ func (h *BookHandler) GetByISBN(c *gin.Context) {
 	isbnStr := c.Param("isbn")
 	response, err := h.bookService.GetBookByISBN(isbnStr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
+	h.recordInteraction(c, response.ID, entities.InteractionISBNLookup)
 
 	c.JSON(http.StatusOK, response)
 }
 
 func (h *BookHandler) Update(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr) // 将字符串 ID 转换为 int
 	if err != nil {
@@ -71,9 +89,9 @@ func (h *BookHandler) Update(c *gin.Context) {
 		return
 	}
 
-	response, err := h.bookService.UpdateBook(id, &req)
+	response, err := h.bookService.UpdateBook(userID, id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -81,15 +99,19 @@ func (h *BookHandler) Update(c *gin.Context) {
 }
 
 func (h *BookHandler) Delete(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr) // 将字符串 ID 转换为 int
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
 		return
 	}
-	err = h.bookService.DeleteBook(id)
+	err = h.bookService.DeleteBook(userID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -97,8 +119,11 @@ func (h *BookHandler) Delete(c *gin.Context) {
 }
 
 func (h *BookHandler) ListBooks(c *gin.Context) {
-	title := c.Query("title")
-	author := c.Query("author")
+	var query dto.BookListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("pageSize", "10")
@@ -113,11 +138,176 @@ func (h *BookHandler) ListBooks(c *gin.Context) {
 		pageSize = 10
 	}
 
-	response, err := h.bookService.ListBooks(page, pageSize, title, author)
+	response, err := h.bookService.ListBooks(page, pageSize, &query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Scan 上传条码照片识别 ISBN。图片可以直接作为请求体，也可以放在 multipart 的 file 字段中
+func (h *BookHandler) Scan(c *gin.Context) {
+	body, _, err := uploadSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.bookService.ScanISBN(c.Request.Context(), body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if response.Book != nil {
+		h.recordInteraction(c, response.Book.ID, entities.InteractionISBNLookup)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Lookup 按 ISBN 预览外部书目中的图书信息，不会创建图书
+func (h *BookHandler) Lookup(c *gin.Context) {
+	var query dto.BookLookupQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.bookService.LookupMetadata(c.Request.Context(), query.ISBN)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if response.ExistingBookID != nil {
+		h.recordInteraction(c, *response.ExistingBookID, entities.InteractionISBNLookup)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Search 全文检索图书，?q= 为检索语句
+func (h *BookHandler) Search(c *gin.Context) {
+	var query dto.BookSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	response, err := h.bookService.SearchBooks(page, pageSize, &query)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// SetAuthors 替换图书的作者、编者和译者
+func (h *BookHandler) SetAuthors(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.SetBookAuthorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.bookService.SetBookAuthors(userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetCategories 替换图书所属的分类
+func (h *BookHandler) SetCategories(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.SetBookCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.bookService.SetBookCategories(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetTags 替换图书的标签
+func (h *BookHandler) SetTags(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.SetBookTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.bookService.SetBookTags(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Similar 看过这本书的读者也看过的图书，?limit= 默认 10 本
+func (h *BookHandler) Similar(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.SimilarBooksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.recommendationService.SimilarBooks(id, query.Limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// recordInteraction 为推荐记录当前用户与图书的交互
+func (h *BookHandler) recordInteraction(c *gin.Context, bookID uint, kind entities.InteractionKind) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return
+	}
+	if id, ok := userID.(uint); ok {
+		h.recommendationService.RecordInteraction(id, bookID, kind)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/pkg/bookio"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CatalogHandler struct {
+	catalogService *services.CatalogService
+}
+
+func NewCatalogHandler(catalogService *services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+// Import 导入书目。文件可以直接作为请求体，也可以放在 multipart 的 file 字段中；
+// 两种方式都是边读边导入，不会把整个文件缓存在内存或磁盘上。
+// ?format= 未指定时按上传文件的扩展名判断；?progress=true 时以 NDJSON 流返回进度
+func (h *CatalogHandler) Import(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var query dto.CatalogImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Mapping = c.QueryMap("map")
+
+	body, filename, err := uploadSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	formatName := query.Format
+	if formatName == "" {
+		formatName = filepath.Ext(filename)
+	}
+	format, err := bookio.ParseFormat(formatName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mapping, err := services.CatalogMapping(format, query.Mapping)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	opts := services.ImportOptions{Format: format, Mapping: mapping, DryRun: query.DryRun, ActorID: userID}
+	if !query.Progress {
+		report, err := h.catalogService.Import(c.Request.Context(), body, opts)
+		if err != nil {
+			if report == nil {
+				respondError(c, err)
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": report})
+			return
+		}
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	emit := func(event dto.ImportProgressEvent) {
+		if err := encoder.Encode(event); err == nil {
+			c.Writer.Flush()
+		}
+	}
+	opts.Progress = func(report dto.ImportReport) {
+		emit(dto.ImportProgressEvent{Type: "progress", Report: &report})
+	}
+
+	report, err := h.catalogService.Import(c.Request.Context(), body, opts)
+	if err != nil {
+		emit(dto.ImportProgressEvent{Type: "error", Error: err.Error(), Report: report})
+		return
+	}
+	emit(dto.ImportProgressEvent{Type: "report", Report: report})
+}
+
+// Export 以流的方式导出全部图书
+func (h *CatalogHandler) Export(c *gin.Context) {
+	var query dto.CatalogExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := bookio.ParseFormat(query.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mapping, err := services.CatalogMapping(format, c.QueryMap("map"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="books.%s"`, format.Extension()))
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，中途出错只能记录日志并截断输出
+	count, err := h.catalogService.Export(c.Request.Context(), c.Writer, format, mapping)
+	if err != nil {
+		logger.Error("book export aborted", zap.Int("exported", count), zap.Error(err))
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CirculationHandler struct {
+	circulationService *services.CirculationService
+}
+
+func NewCirculationHandler(circulationService *services.CirculationService) *CirculationHandler {
+	return &CirculationHandler{circulationService: circulationService}
+}
+
+func (h *CirculationHandler) AddCopy(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.CreateBookCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.circulationService.AddCopy(bookID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CirculationHandler) ListCopies(c *gin.Context) {
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.circulationService.ListCopies(bookID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CirculationHandler) UpdateCopy(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.UpdateBookCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.circulationService.UpdateCopy(id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CirculationHandler) Checkout(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.circulationService.Checkout(userID, role, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CirculationHandler) Return(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	loanID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.circulationService.Return(userID, role, loanID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CirculationHandler) Renew(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	loanID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.circulationService.Renew(userID, role, loanID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MyLoans 当前用户的借阅记录，?active=true 时只返回未归还的
+func (h *CirculationHandler) MyLoans(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.circulationService.ListUserLoans(userID, c.Query("active") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+// coverCacheControl 封面地址带有内容版本，同一地址的内容不会变化，可以长期缓存
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+type CoverHandler struct {
+	coverService *services.CoverService
+}
+
+func NewCoverHandler(coverService *services.CoverService) *CoverHandler {
+	return &CoverHandler{coverService: coverService}
+}
+
+// Upload 上传封面。图片可以直接作为请求体，也可以放在 multipart 的 file 字段中
+func (h *CoverHandler) Upload(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	body, _, err := uploadSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.coverService.Upload(c.Request.Context(), id, body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Fetch 从 URL 下载封面，请求体为空时使用图书已有的 cover_url
+func (h *CoverHandler) Fetch(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.FetchCoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.coverService.Fetch(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Delete 移除图书的封面
+func (h *CoverHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.coverService.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cover deleted successfully"})
+}
+
+// Serve 输出封面文件，支持条件请求和范围请求
+func (h *CoverHandler) Serve(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	version, name := c.Param("version"), c.Param("file")
+	object, err := h.coverService.Open(c.Request.Context(), id, version, name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer object.Body.Close()
+
+	c.Header("Cache-Control", coverCacheControl)
+	c.Header("ETag", strconv.Quote(fmt.Sprintf("%s-%s", version, name)))
+	c.Header("Content-Type", object.ContentType)
+	if content, ok := object.Body.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, name, object.ModTime, content)
+		return
+	}
+	if match := c.GetHeader("If-None-Match"); match != "" && match == c.Writer.Header().Get("ETag") {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, nil)
+}
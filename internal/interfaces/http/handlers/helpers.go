@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+	"github.com/azel-ko/final-ddd/internal/domain/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser 读取认证中间件写入上下文的用户 ID 和角色
+func currentUser(c *gin.Context) (uint, string, bool) {
+	userIDAuth, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, "", false
+	}
+
+	userID, ok := userIDAuth.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user ID type in context"})
+		return 0, "", false
+	}
+
+	role, _ := c.Get("userRole")
+	roleStr, _ := role.(string)
+	return userID, roleStr, true
+}
+
+// parseIDParam 将路径参数解析为 int，失败时直接返回 400
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// uploadSource 返回上传内容的流：multipart 请求读取 file 字段，其余情况使用请求体
+func uploadSource(c *gin.Context) (io.Reader, string, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, "", nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", stderrors.New("missing file field")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// respondError 根据服务层返回的错误类型选择 HTTP 状态码
+func respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case stderrors.Is(err, errors.ErrNotFound):
+		status = http.StatusNotFound
+	case stderrors.Is(err, errors.ErrInvalidInput),
+		stderrors.Is(err, entities.ErrInvalidISBN),
+		stderrors.Is(err, entities.ErrUnsupportedCoverImage),
+		stderrors.Is(err, storage.ErrHostNotAllowed),
+		stderrors.Is(err, entities.ErrWorkMergeSelf),
+		stderrors.Is(err, entities.ErrWorkSplitAll),
+		stderrors.Is(err, entities.ErrEditionNotInWork),
+		stderrors.Is(err, entities.ErrInvalidTimezone),
+		stderrors.Is(err, entities.ErrInvalidOpeningHours),
+		stderrors.Is(err, entities.ErrTransferSameBranch),
+		stderrors.Is(err, entities.ErrBookMergeSelf),
+		stderrors.Is(err, entities.ErrUnsupportedBookFile),
+		stderrors.Is(err, entities.ErrEditProposalEmpty),
+		stderrors.Is(err, payment.ErrInvalidSignature):
+		status = http.StatusBadRequest
+	case stderrors.Is(err, entities.ErrCoverTooLarge),
+		stderrors.Is(err, entities.ErrBookFileTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case stderrors.Is(err, storage.ErrFetchFailed),
+		stderrors.Is(err, payment.ErrUnknownIntent):
+		status = http.StatusBadGateway
+	case stderrors.Is(err, errors.ErrForbidden),
+		stderrors.Is(err, entities.ErrDownloadLinkInvalid),
+		stderrors.Is(err, entities.ErrMembershipRequired),
+		stderrors.Is(err, entities.ErrDigitalAccessNotIncluded):
+		status = http.StatusForbidden
+	case stderrors.Is(err, metadata.ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	case stderrors.Is(err, errors.ErrEmailAlreadyExists),
+		stderrors.Is(err, errors.ErrISBNAlreadyExists),
+		stderrors.Is(err, errors.ErrBarcodeAlreadyExists),
+		stderrors.Is(err, entities.ErrCopyNotAvailable),
+		stderrors.Is(err, entities.ErrCopyAlreadyOnLoan),
+		stderrors.Is(err, entities.ErrLoanNotActive),
+		stderrors.Is(err, entities.ErrLoanOverdue),
+		stderrors.Is(err, entities.ErrRenewLimitReached),
+		stderrors.Is(err, entities.ErrHoldAlreadyExists),
+		stderrors.Is(err, entities.ErrHoldNotActive),
+		stderrors.Is(err, entities.ErrHoldNotNeeded),
+		stderrors.Is(err, entities.ErrAuthorInUse),
+		stderrors.Is(err, entities.ErrCategoryHasChildren),
+		stderrors.Is(err, entities.ErrCategoryCycle),
+		stderrors.Is(err, entities.ErrReviewAlreadyExists),
+		stderrors.Is(err, entities.ErrReviewChanged),
+		stderrors.Is(err, entities.ErrShelfNameTaken),
+		stderrors.Is(err, entities.ErrDefaultShelf),
+		stderrors.Is(err, entities.ErrInvalidAcquisitionTransition),
+		stderrors.Is(err, entities.ErrAcquisitionChanged),
+		stderrors.Is(err, entities.ErrAcquisitionAlreadySuggested),
+		stderrors.Is(err, entities.ErrBookAlreadyInCatalog),
+		stderrors.Is(err, entities.ErrBranchNameTaken),
+		stderrors.Is(err, entities.ErrBranchInUse),
+		stderrors.Is(err, entities.ErrInvalidTransferTransition),
+		stderrors.Is(err, entities.ErrTransferChanged),
+		stderrors.Is(err, entities.ErrTransferAlreadyOpen),
+		stderrors.Is(err, entities.ErrCopyInTransit),
+		stderrors.Is(err, entities.ErrDuplicateResolved),
+		stderrors.Is(err, entities.ErrDownloadLimitReached),
+		stderrors.Is(err, entities.ErrEditProposalResolved),
+		stderrors.Is(err, entities.ErrEditProposalConflict),
+		stderrors.Is(err, entities.ErrSavedSearchNameTaken),
+		stderrors.Is(err, entities.ErrSavedSearchLimit),
+		stderrors.Is(err, entities.ErrLoanLimitReached),
+		stderrors.Is(err, entities.ErrMembershipExists),
+		stderrors.Is(err, entities.ErrMembershipPlanInactive),
+		stderrors.Is(err, entities.ErrMembershipPlanNameTaken),
+		stderrors.Is(err, entities.ErrMembershipPlanInUse),
+		stderrors.Is(err, entities.ErrNothingToPay),
+		stderrors.Is(err, entities.ErrPaymentNotCapturable),
+		stderrors.Is(err, entities.ErrPaymentNotRefundable),
+		stderrors.Is(err, entities.ErrPaymentChanged),
+		stderrors.Is(err, payment.ErrProviderDeclined):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	holdService *services.HoldService
+}
+
+func NewHoldHandler(holdService *services.HoldService) *HoldHandler {
+	return &HoldHandler{holdService: holdService}
+}
+
+func (h *HoldHandler) Place(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.holdService.PlaceHold(userID, bookID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *HoldHandler) Cancel(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	holdID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.holdService.CancelHold(userID, role, holdID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MyHolds 当前用户的预约，?active=true 时只返回排队中和待取的
+func (h *HoldHandler) MyHolds(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.holdService.ListUserHolds(userID, c.Query("active") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HoldHandler) PickupList(c *gin.Context) {
+	response, err := h.holdService.PickupList()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
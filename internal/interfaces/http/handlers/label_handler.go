@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	labelService *services.LabelService
+}
+
+func NewLabelHandler(labelService *services.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+// BookBarcode 图书 ISBN 的 EAN-13 条码，?format=png|svg
+func (h *LabelHandler) BookBarcode(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.BarcodeImageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.labelService.BookBarcode(id, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	sendLabelFile(c, file, "inline")
+}
+
+// BookLabel 图书内部编号标签，?symbology=code128|qr&format=png|svg
+func (h *LabelHandler) BookLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.LabelImageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.labelService.BookLabel(id, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	sendLabelFile(c, file, "inline")
+}
+
+// CopyLabel 副本条码标签
+func (h *LabelHandler) CopyLabel(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var query dto.LabelImageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.labelService.CopyLabel(id, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	sendLabelFile(c, file, "inline")
+}
+
+// Sheet 批量生成标签页 PDF
+func (h *LabelHandler) Sheet(c *gin.Context) {
+	var req dto.LabelSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.labelService.Sheet(&req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	sendLabelFile(c, file, "attachment")
+}
+
+func sendLabelFile(c *gin.Context, file *dto.LabelFile, disposition string) {
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
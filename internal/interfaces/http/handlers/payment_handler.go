@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// PaymentSignatureHeader webhook 请求携带签名的请求头
+	PaymentSignatureHeader = "X-Payment-Signature"
+	// idempotencyKeyHeader 退款请求携带幂等键的请求头
+	idempotencyKeyHeader = "Idempotency-Key"
+	// maxWebhookPayload webhook 请求体的大小上限
+	maxWebhookPayload = 1 << 20
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// Create 为当前用户发起一笔支付
+func (h *PaymentHandler) Create(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.paymentService.CreateIntent(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Mine 当前用户的支付记录，?status= 按状态筛选
+func (h *PaymentHandler) Mine(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	h.list(c, userID)
+}
+
+// UserPayments 指定读者的支付记录
+func (h *PaymentHandler) UserPayments(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.list(c, uint(id))
+}
+
+func (h *PaymentHandler) list(c *gin.Context, userID uint) {
+	var query dto.PaymentListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, pageSize := pagination(c)
+
+	response, err := h.paymentService.List(userID, page, pageSize, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get 支付详情，读者只能查看自己的
+func (h *PaymentHandler) Get(c *gin.Context) {
+	userID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.paymentService.Get(userID, role, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Capture 对已授权的支付重新扣款，已扣款时直接返回
+func (h *PaymentHandler) Capture(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.paymentService.Capture(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Refund 退款，必须携带 Idempotency-Key 请求头
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	actorID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" || len(key) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header is required (at most 64 characters)"})
+		return
+	}
+	var req dto.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.paymentService.Refund(c.Request.Context(), actorID, id, key, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Webhook 接收支付渠道的通知，不经过登录认证，以请求头中的签名校验来源
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayload))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(c.Request.Context(), payload, c.GetHeader(PaymentSignatureHeader)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// MyBalance 当前用户的账户余额
+func (h *PaymentHandler) MyBalance(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	h.balance(c, userID)
+}
+
+// UserBalance 指定读者的账户余额
+func (h *PaymentHandler) UserBalance(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.balance(c, uint(id))
+}
+
+func (h *PaymentHandler) balance(c *gin.Context, userID uint) {
+	response, err := h.paymentService.Balance(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MyTransactions 当前用户的账户流水
+func (h *PaymentHandler) MyTransactions(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	h.transactions(c, userID)
+}
+
+// UserTransactions 指定读者的账户流水
+func (h *PaymentHandler) UserTransactions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.transactions(c, uint(id))
+}
+
+func (h *PaymentHandler) transactions(c *gin.Context, userID uint) {
+	page, pageSize := pagination(c)
+
+	response, err := h.paymentService.Transactions(userID, page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Charge 向读者登记一笔费用
+func (h *PaymentHandler) Charge(c *gin.Context) {
+	actorID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.paymentService.Charge(actorID, uint(id), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// pagination 读取 page、pageSize 查询参数，非法时使用默认值
+func pagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
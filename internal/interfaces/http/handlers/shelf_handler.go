@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ShelfHandler struct {
+	shelfService *services.ShelfService
+}
+
+func NewShelfHandler(shelfService *services.ShelfService) *ShelfHandler {
+	return &ShelfHandler{shelfService: shelfService}
+}
+
+// List 当前用户的书架
+func (h *ShelfHandler) List(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.shelfService.ListShelves(userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShelfHandler) Create(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.ShelfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.shelfService.CreateShelf(userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ShelfHandler) Rename(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ShelfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.shelfService.RenameShelf(userID, shelfID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShelfHandler) Delete(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.shelfService.DeleteShelf(userID, shelfID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shelf deleted successfully"})
+}
+
+// ListBooks 书架上的图书及阅读进度
+func (h *ShelfHandler) ListBooks(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, pageSize := shelfPagination(c)
+
+	response, err := h.shelfService.ListShelfBooks(userID, shelfID, page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddBook 把图书放上书架，放到默认书架上即移动阅读状态
+func (h *ShelfHandler) AddBook(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.AddShelfBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.shelfService.AddBook(userID, shelfID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShelfHandler) RemoveBook(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	bookID, ok := parseIDParam(c, "bookId")
+	if !ok {
+		return
+	}
+
+	if err := h.shelfService.RemoveBook(userID, shelfID, bookID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book removed from shelf"})
+}
+
+// Share 生成书架的分享链接
+func (h *ShelfHandler) Share(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.shelfService.ShareShelf(userID, shelfID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Unshare 取消书架的分享链接
+func (h *ShelfHandler) Unshare(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	shelfID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.shelfService.UnshareShelf(userID, shelfID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateProgress 更新当前用户某本书的阅读进度
+func (h *ShelfHandler) UpdateProgress(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	bookID, ok := parseIDParam(c, "bookId")
+	if !ok {
+		return
+	}
+	var req dto.ReadingProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.shelfService.UpdateProgress(userID, bookID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Stats 当前用户某一年的阅读统计，?year= 默认为今年
+func (h *ShelfHandler) Stats(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var query dto.ReadingStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.shelfService.Stats(userID, query.Year)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Shared 通过分享链接查看书架，不需要认证
+func (h *ShelfHandler) Shared(c *gin.Context) {
+	page, pageSize := shelfPagination(c)
+
+	response, err := h.shelfService.SharedShelf(c.Param("token"), page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func shelfPagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TransferHandler struct {
+	transferService *services.TransferService
+}
+
+func NewTransferHandler(transferService *services.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+// Request 申请把副本调拨到另一个分馆
+func (h *TransferHandler) Request(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req dto.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.transferService.RequestTransfer(userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List 调拨列表，?branch_id= 匹配调出或调入馆
+func (h *TransferHandler) List(c *gin.Context) {
+	var query dto.TransferListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	response, err := h.transferService.ListTransfers(page, pageSize, &query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TransferHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.transferService.GetTransfer(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Ship 调出馆发出副本，副本进入在途状态
+func (h *TransferHandler) Ship(c *gin.Context) {
+	h.transition(c, h.transferService.Ship)
+}
+
+// Receive 调入馆签收副本
+func (h *TransferHandler) Receive(c *gin.Context) {
+	h.transition(c, h.transferService.Receive)
+}
+
+func (h *TransferHandler) Cancel(c *gin.Context) {
+	h.transition(c, h.transferService.Cancel)
+}
+
+func (h *TransferHandler) transition(c *gin.Context, apply func(id int) (*dto.TransferResponse, error)) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := apply(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
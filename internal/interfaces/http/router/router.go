@@ -4,21 +4,32 @@ import (
 	"embed"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/azel-ko/final-ddd/internal/application/dto"
 	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/domain/mail"
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
 	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	domainstorage "github.com/azel-ko/final-ddd/internal/domain/storage"
 	"github.com/azel-ko/final-ddd/internal/infrastructure/cache"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/metadata"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/storage"
 	"github.com/azel-ko/final-ddd/internal/interfaces/http/handlers"
 	"github.com/azel-ko/final-ddd/internal/interfaces/http/middleware"
 	"github.com/azel-ko/final-ddd/pkg/auth"
 	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/azel-ko/final-ddd/internal/pkg/scheduler"
 	"github.com/gin-gonic/gin"
 )
 
 //go:embed frontend/dist/*
 var embeddedFiles embed.FS
 
-func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.RedisCache) *gin.Engine {
+func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.RedisCache, store domainstorage.ObjectStorage, mailer mail.Mailer, payments payment.Provider, jobs *scheduler.Scheduler) *gin.Engine {
 	jwtManager := auth.NewJWTManager(cfg.JWT.Key)
 	gin.SetMode(cfg.App.Env)
 	r := gin.Default()
@@ -32,12 +43,73 @@ func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.Red
 
 	authService := services.NewAuthService(repo, jwtManager, redisCache)
 	userService := services.NewUserService(repo)
-	bookService := services.NewBookService(repo)
+	bus := eventbus.New()
+	bookService := services.NewBookService(repo, metadata.NewProvider(cfg.Metadata, redisCache), bus)
+	membershipService := services.NewMembershipService(repo, cfg.Memberships)
+	circulationService := services.NewCirculationService(repo, membershipService, cfg.Circulation, bus)
+	holdService := services.NewHoldService(repo, membershipService, cfg.Circulation, bus)
+	authorService := services.NewAuthorService(repo)
+	catalogService := services.NewCatalogService(repo, bookService)
+	categoryService := services.NewCategoryService(repo)
+	tagService := services.NewTagService(repo)
+	reviewService := services.NewReviewService(repo, cfg.Reviews)
+	shelfService := services.NewShelfService(repo)
+	recommendationService := services.NewRecommendationService(repo, cfg.Recommendations)
+	workService := services.NewWorkService(repo)
+	bookRevisionService := services.NewBookRevisionService(repo, bookService)
+	bookMergeService := services.NewBookMergeService(repo, bookService, bus, cfg.Duplicates)
+	acquisitionService := services.NewAcquisitionService(repo, bookService)
+	editProposalService := services.NewBookEditProposalService(repo, bookService)
+	labelService := services.NewLabelService(repo)
+	branchService := services.NewBranchService(repo)
+	transferService := services.NewTransferService(repo, bus)
+	coverService := services.NewCoverService(repo, store, storage.NewHTTPFetcher(intervalOr(cfg.Covers.FetchTimeout, 10*time.Second), cfg.Covers.AllowPrivateHosts), cfg.Covers)
+	bus.Subscribe(events.CopyAvailableEvent, holdService.HandleCopyAvailable)
+	bookFileService := services.NewBookFileService(repo, bookService, membershipService, store, cfg.Ebooks, cfg.JWT.Key)
+	savedSearchService := services.NewSavedSearchService(repo, bookService, mailer, cfg.SavedSearches)
+	notificationService := services.NewNotificationService(repo)
+	paymentService := services.NewPaymentService(repo, payments, bus, cfg.Payments)
+	bus.Subscribe(events.BookDeletedEvent, coverService.HandleBookDeleted)
+	bus.Subscribe(events.BookDeletedEvent, bookFileService.HandleBookDeleted)
+	bus.Subscribe(events.PaymentCapturedEvent, membershipService.HandlePaymentCaptured)
 	healthHandler := handlers.NewHealthHandler()
 
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
-	bookHandler := handlers.NewBookHandler(bookService)
+	bookHandler := handlers.NewBookHandler(bookService, recommendationService)
+	circulationHandler := handlers.NewCirculationHandler(circulationService)
+	holdHandler := handlers.NewHoldHandler(holdService)
+	authorHandler := handlers.NewAuthorHandler(authorService)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	coverHandler := handlers.NewCoverHandler(coverService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	shelfHandler := handlers.NewShelfHandler(shelfService)
+	workHandler := handlers.NewWorkHandler(workService)
+	bookRevisionHandler := handlers.NewBookRevisionHandler(bookRevisionService)
+	bookMergeHandler := handlers.NewBookMergeHandler(bookMergeService)
+	acquisitionHandler := handlers.NewAcquisitionHandler(acquisitionService)
+	editProposalHandler := handlers.NewBookEditProposalHandler(editProposalService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	branchHandler := handlers.NewBranchHandler(branchService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	bookFileHandler := handlers.NewBookFileHandler(bookFileService)
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	membershipHandler := handlers.NewMembershipHandler(membershipService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+
+	// 后台任务
+	jobs.Every("expire-holds", intervalOr(cfg.Circulation.HoldExpiryInterval, 15*time.Minute), holdService.ExpireHolds)
+	jobs.Every("recompute-similar-books", intervalOr(cfg.Recommendations.Interval, time.Hour), recommendationService.Recompute)
+	jobs.Every("find-duplicate-books", intervalOr(cfg.Duplicates.Interval, 24*time.Hour), bookMergeService.FindDuplicates)
+	jobs.Every("prune-download-grants", intervalOr(cfg.Ebooks.LinkTTL, 15*time.Minute), bookFileService.PruneGrants)
+	jobs.Every("run-saved-searches", intervalOr(cfg.SavedSearches.Interval, 15*time.Minute), savedSearchService.Run)
+	jobs.Every("expire-memberships", intervalOr(cfg.Memberships.ExpiryInterval, time.Hour), membershipService.ExpireMemberships)
+
+	staffOnly := middleware.RequireRole(entities.RoleAdmin, entities.RoleLibrarian)
+	adminOnly := middleware.RequireRole(entities.RoleAdmin)
 
 	// 健康检查路由
 	r.GET("/api/health", healthHandler.Check)
@@ -45,6 +117,15 @@ func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.Red
 	r.POST("/api/auth/login", authHandler.Login)
 	r.POST("/api/auth/register", authHandler.Register)
 
+	// 封面图片供 <img> 直接引用，不需要认证
+	r.GET(dto.CoverURLPrefix+"/:id/:version/:file", coverHandler.Serve)
+	// 分享的书架凭链接中的令牌访问
+	r.GET(dto.SharedShelfURLPrefix+"/:token", shelfHandler.Shared)
+	// 电子书凭签名链接下载，链接由已登录的读者申请
+	r.GET(dto.EbookDownloadURLPrefix+"/:id", bookFileHandler.Download)
+	// 支付渠道的 webhook 以签名校验来源
+	r.POST("/api/payments/webhook", paymentHandler.Webhook)
+
 	api := r.Group("/api")
 	api.Use(middleware.AuthMiddleware(jwtManager))
 	{
@@ -52,24 +133,226 @@ func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.Red
 		{
 			users.GET("/me", userHandler.GetSelf)    // New route for getting self profile
 			users.PUT("/me", userHandler.UpdateSelf) // New route for updating self profile
+			users.GET("/me/loans", circulationHandler.MyLoans)
+			users.GET("/me/holds", holdHandler.MyHolds)
+			users.GET("/me/reviews", reviewHandler.MyReviews)
+			users.GET("/me/membership", membershipHandler.Mine)
+			users.GET("/me/balance", paymentHandler.MyBalance)
+			users.GET("/me/transactions", paymentHandler.MyTransactions)
+			shelves := users.Group("/me/shelves")
+			{
+				shelves.GET("/", shelfHandler.List)
+				shelves.POST("/", shelfHandler.Create)
+				shelves.GET("/stats", shelfHandler.Stats)
+				shelves.PUT("/progress/:bookId", shelfHandler.UpdateProgress)
+				shelves.PUT("/:id", shelfHandler.Rename)
+				shelves.DELETE("/:id", shelfHandler.Delete)
+				shelves.GET("/:id/books", shelfHandler.ListBooks)
+				shelves.POST("/:id/books", shelfHandler.AddBook)
+				shelves.DELETE("/:id/books/:bookId", shelfHandler.RemoveBook)
+				shelves.POST("/:id/share", shelfHandler.Share)
+				shelves.DELETE("/:id/share", shelfHandler.Unshare)
+			}
+			searches := users.Group("/me/searches")
+			{
+				searches.GET("/", savedSearchHandler.List)
+				searches.POST("/", savedSearchHandler.Create)
+				searches.GET("/:id", savedSearchHandler.Get)
+				searches.PUT("/:id", savedSearchHandler.Update)
+				searches.DELETE("/:id", savedSearchHandler.Delete)
+				searches.GET("/:id/books", savedSearchHandler.Books)
+			}
+			notifications := users.Group("/me/notifications")
+			{
+				notifications.GET("/", notificationHandler.List)
+				notifications.POST("/read", notificationHandler.MarkAllRead)
+				notifications.POST("/:id/read", notificationHandler.MarkRead)
+			}
 			users.POST("/", userHandler.Create)      // Admin/System task, or initial user creation if not via /register
 			users.GET("/:id", userHandler.Get)       // Admin/System task
 			users.PUT("/:id", userHandler.Update)    // Admin/System task
 			users.DELETE("/:id", userHandler.Delete) // Admin/System task
+			users.GET("/:id/membership", staffOnly, membershipHandler.Get)
+			users.POST("/:id/membership", staffOnly, membershipHandler.Enroll)
+			users.POST("/:id/membership/renew", staffOnly, membershipHandler.Renew)
+			users.GET("/:id/balance", staffOnly, paymentHandler.UserBalance)
+			users.GET("/:id/transactions", staffOnly, paymentHandler.UserTransactions)
+			users.GET("/:id/payments", staffOnly, paymentHandler.UserPayments)
+			users.POST("/:id/charges", staffOnly, paymentHandler.Charge)
 		}
 
 		books := api.Group("/books")
 		{
 			books.GET("/", bookHandler.ListBooks) // New route for listing books with pagination and filtering
-			books.POST("/", bookHandler.Create)
+			books.POST("/", staffOnly, bookHandler.Create)
+			books.GET("/search", bookHandler.Search)
+			books.POST("/lookup", bookHandler.Lookup)
+			books.POST("/scan", staffOnly, bookHandler.Scan)
+			books.POST("/import", staffOnly, catalogHandler.Import)
+			books.GET("/export", staffOnly, catalogHandler.Export)
+			books.GET("/duplicates", adminOnly, bookMergeHandler.ListDuplicates)
+			books.POST("/duplicates/:id/merge", adminOnly, bookMergeHandler.MergeDuplicate)
+			books.POST("/duplicates/:id/dismiss", adminOnly, bookMergeHandler.Dismiss)
 			books.GET("/:id", bookHandler.Get)
-			books.PUT("/:id", bookHandler.Update)
-			books.DELETE("/:id", bookHandler.Delete)
+			books.PUT("/:id", staffOnly, bookHandler.Update)
+			books.DELETE("/:id", staffOnly, bookHandler.Delete)
+			books.GET("/:id/copies", circulationHandler.ListCopies)
+			books.POST("/:id/copies", staffOnly, circulationHandler.AddCopy)
+			books.POST("/:id/holds", holdHandler.Place)
+			books.GET("/:id/similar", bookHandler.Similar)
+			books.GET("/:id/history", bookRevisionHandler.History)
+			books.GET("/:id/history/:revision", bookRevisionHandler.Get)
+			books.GET("/:id/diff", bookRevisionHandler.Diff)
+			books.POST("/:id/revert", adminOnly, bookRevisionHandler.Revert)
+			books.POST("/:id/merge", adminOnly, bookMergeHandler.MergeBook)
+			books.POST("/:id/proposals", editProposalHandler.Propose)
+			books.GET("/:id/reviews", reviewHandler.ListForBook)
+			books.POST("/:id/reviews", reviewHandler.Create)
+			books.PUT("/:id/authors", staffOnly, bookHandler.SetAuthors)
+			books.PUT("/:id/categories", staffOnly, bookHandler.SetCategories)
+			books.PUT("/:id/tags", staffOnly, bookHandler.SetTags)
+			books.PUT("/:id/cover", staffOnly, coverHandler.Upload)
+			books.POST("/:id/cover/fetch", staffOnly, coverHandler.Fetch)
+			books.DELETE("/:id/cover", staffOnly, coverHandler.Delete)
+			books.GET("/:id/files", bookFileHandler.List)
+			books.POST("/:id/files", staffOnly, bookFileHandler.Upload)
+			books.DELETE("/:id/files/:fileId", staffOnly, bookFileHandler.Delete)
+			books.POST("/:id/files/:fileId/link", bookFileHandler.IssueLink)
+			books.GET("/:id/barcode", labelHandler.BookBarcode)
+			books.GET("/:id/label", staffOnly, labelHandler.BookLabel)
 			isbn := books.Group("/isbn")
 			{
 				isbn.GET("/:isbn", bookHandler.GetByISBN) // Changed :id to :isbn for clarity
 			}
 		}
+
+		works := api.Group("/works")
+		{
+			works.GET("/", workHandler.List)
+			works.GET("/:id", workHandler.Get)
+			works.PUT("/:id", staffOnly, workHandler.Update)
+			works.POST("/:id/merge", staffOnly, workHandler.Merge)
+			works.POST("/:id/split", staffOnly, workHandler.Split)
+		}
+
+		authors := api.Group("/authors")
+		{
+			authors.GET("/", authorHandler.List)
+			authors.POST("/", staffOnly, authorHandler.Create)
+			authors.GET("/:id", authorHandler.Get)
+			authors.PUT("/:id", staffOnly, authorHandler.Update)
+			authors.DELETE("/:id", staffOnly, authorHandler.Delete)
+		}
+
+		categories := api.Group("/categories")
+		{
+			categories.GET("/", categoryHandler.Tree)
+			categories.POST("/", staffOnly, categoryHandler.Create)
+			categories.PUT("/:id", staffOnly, categoryHandler.Update)
+			categories.DELETE("/:id", staffOnly, categoryHandler.Delete)
+		}
+
+		tags := api.Group("/tags")
+		{
+			tags.GET("/", tagHandler.List)
+			tags.PUT("/:id", staffOnly, tagHandler.Rename)
+			tags.DELETE("/:id", staffOnly, tagHandler.Delete)
+		}
+
+		reviews := api.Group("/reviews")
+		{
+			reviews.GET("/moderation", staffOnly, reviewHandler.ModerationQueue)
+			reviews.PUT("/:id", reviewHandler.Update)
+			reviews.DELETE("/:id", reviewHandler.Delete)
+			reviews.POST("/:id/approve", staffOnly, reviewHandler.Approve)
+			reviews.POST("/:id/reject", staffOnly, reviewHandler.Reject)
+		}
+
+		copies := api.Group("/copies")
+		{
+			copies.PUT("/:id", staffOnly, circulationHandler.UpdateCopy)
+			copies.GET("/:id/label", staffOnly, labelHandler.CopyLabel)
+		}
+
+		loans := api.Group("/loans")
+		{
+			loans.POST("/", circulationHandler.Checkout)
+			loans.POST("/:id/return", circulationHandler.Return)
+			loans.POST("/:id/renew", circulationHandler.Renew)
+		}
+
+		holds := api.Group("/holds")
+		{
+			holds.GET("/pickup", staffOnly, holdHandler.PickupList)
+			holds.DELETE("/:id", holdHandler.Cancel)
+		}
+
+		labels := api.Group("/labels")
+		{
+			labels.POST("/sheet", staffOnly, labelHandler.Sheet)
+		}
+
+		acquisitions := api.Group("/acquisitions")
+		{
+			acquisitions.POST("/", acquisitionHandler.Suggest)
+			acquisitions.GET("/", acquisitionHandler.List)
+			acquisitions.GET("/:id", acquisitionHandler.Get)
+			acquisitions.POST("/:id/approve", staffOnly, acquisitionHandler.Approve)
+			acquisitions.POST("/:id/reject", staffOnly, acquisitionHandler.Reject)
+			acquisitions.POST("/:id/order", staffOnly, acquisitionHandler.Order)
+			acquisitions.POST("/:id/receive", staffOnly, acquisitionHandler.Receive)
+			acquisitions.POST("/:id/cancel", acquisitionHandler.Cancel)
+		}
+
+		proposals := api.Group("/proposals")
+		{
+			proposals.GET("/", editProposalHandler.List)
+			proposals.GET("/:id", editProposalHandler.Get)
+			proposals.POST("/:id/approve", staffOnly, editProposalHandler.Approve)
+			proposals.POST("/:id/reject", staffOnly, editProposalHandler.Reject)
+			proposals.POST("/:id/withdraw", editProposalHandler.Withdraw)
+		}
+
+		branches := api.Group("/branches")
+		{
+			branches.GET("/", branchHandler.List)
+			branches.GET("/:id", branchHandler.Get)
+			branches.POST("/", staffOnly, branchHandler.Create)
+			branches.PUT("/:id", staffOnly, branchHandler.Update)
+			branches.DELETE("/:id", staffOnly, branchHandler.Delete)
+		}
+
+		plans := api.Group("/membership-plans")
+		{
+			plans.GET("/", membershipHandler.ListPlans)
+			plans.POST("/", adminOnly, membershipHandler.CreatePlan)
+			plans.PUT("/:id", adminOnly, membershipHandler.UpdatePlan)
+			plans.DELETE("/:id", adminOnly, membershipHandler.DeletePlan)
+		}
+
+		memberships := api.Group("/memberships")
+		{
+			memberships.GET("/", staffOnly, membershipHandler.List)
+		}
+
+		payments := api.Group("/payments")
+		{
+			payments.POST("/", paymentHandler.Create)
+			payments.GET("/", paymentHandler.Mine)
+			payments.GET("/:id", paymentHandler.Get)
+			payments.POST("/:id/capture", staffOnly, paymentHandler.Capture)
+			payments.POST("/:id/refund", staffOnly, paymentHandler.Refund)
+		}
+
+		transfers := api.Group("/transfers")
+		{
+			transfers.POST("/", staffOnly, transferHandler.Request)
+			transfers.GET("/", staffOnly, transferHandler.List)
+			transfers.GET("/:id", staffOnly, transferHandler.Get)
+			transfers.POST("/:id/ship", staffOnly, transferHandler.Ship)
+			transfers.POST("/:id/receive", staffOnly, transferHandler.Receive)
+			transfers.POST("/:id/cancel", staffOnly, transferHandler.Cancel)
+		}
 	}
 
 	// 获取嵌入的文件系统
@@ -109,3 +392,11 @@ func Setup(cfg *config.Config, repo repository.Repository, redisCache *cache.Red
 	})
 	return r
 }
+
+// intervalOr 未配置任务间隔或超时时使用默认值
+func intervalOr(interval, fallback time.Duration) time.Duration {
+	if interval <= 0 {
+		return fallback
+	}
+	return interval
+}
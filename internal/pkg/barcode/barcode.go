@@ -0,0 +1,196 @@
+// Package barcode 生成 EAN-13、Code 128 一维码和 QR 二维码，并渲染为 PNG 或 SVG，只依赖标准库
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// 支持的码制
+const (
+	KindEAN13   = "ean13"
+	KindCode128 = "code128"
+	KindQR      = "qr"
+)
+
+var (
+	ErrInvalidContent = errors.New("content cannot be encoded in this symbology")
+	ErrContentTooLong = errors.New("content is too long for this symbology")
+)
+
+// Barcode 以模块为单位的条码图案。一维码只有一行（Height 为 1），渲染时按条高拉伸
+type Barcode struct {
+	Kind      string
+	Text      string // 人眼可读的文字，SVG 和标签中印在条码下方
+	Width     int
+	Height    int
+	QuietZone int // 四周需要留白的模块数
+
+	dark []bool
+}
+
+func newBarcode(kind, text string, width, height, quietZone int) *Barcode {
+	return &Barcode{Kind: kind, Text: text, Width: width, Height: height, QuietZone: quietZone, dark: make([]bool, width*height)}
+}
+
+// Dark 模块 (x, y) 是否为深色，超出范围的模块视为浅色
+func (b *Barcode) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return false
+	}
+	return b.dark[y*b.Width+x]
+}
+
+func (b *Barcode) set(x, y int, dark bool) {
+	b.dark[y*b.Width+x] = dark
+}
+
+// IsLinear 是否为一维码
+func (b *Barcode) IsLinear() bool {
+	return b.Height == 1
+}
+
+// Runs 返回第 y 行连续深色模块的起点和长度，供矢量输出合并相邻的条
+func (b *Barcode) Runs(y int) [][2]int {
+	var runs [][2]int
+	for x := 0; x < b.Width; {
+		if !b.Dark(x, y) {
+			x++
+			continue
+		}
+		start := x
+		for x < b.Width && b.Dark(x, y) {
+			x++
+		}
+		runs = append(runs, [2]int{start, x - start})
+	}
+	return runs
+}
+
+// Encode 按码制编码内容
+func Encode(kind, content string) (*Barcode, error) {
+	switch kind {
+	case KindEAN13:
+		return EncodeEAN13(content)
+	case KindCode128:
+		return EncodeCode128(content)
+	case KindQR:
+		return EncodeQR(content)
+	}
+	return nil, fmt.Errorf("unsupported symbology %q", kind)
+}
+
+// RenderOptions 渲染参数
+type RenderOptions struct {
+	Scale     int // 每个模块的像素数
+	BarHeight int // 一维码的条高（模块数），二维码忽略
+}
+
+func (o RenderOptions) normalize() RenderOptions {
+	if o.Scale < 1 {
+		o.Scale = 2
+	}
+	if o.BarHeight < 1 {
+		o.BarHeight = 50
+	}
+	return o
+}
+
+// rows 渲染后的行数（模块），一维码为条高
+func (b *Barcode) rows(opts RenderOptions) int {
+	if b.IsLinear() {
+		return opts.BarHeight
+	}
+	return b.Height
+}
+
+// Image 渲染为灰度图像，包含留白；PNG 中不绘制文字
+func (b *Barcode) Image(opts RenderOptions) *image.Gray {
+	opts = opts.normalize()
+	rows := b.rows(opts)
+	width := (b.Width + 2*b.QuietZone) * opts.Scale
+	height := (rows + 2*b.verticalQuietZone()) * opts.Scale
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for row := 0; row < rows; row++ {
+		y := row
+		if b.IsLinear() {
+			y = 0
+		}
+		for x := 0; x < b.Width; x++ {
+			if !b.Dark(x, y) {
+				continue
+			}
+			px := (x + b.QuietZone) * opts.Scale
+			py := (row + b.verticalQuietZone()) * opts.Scale
+			for dy := 0; dy < opts.Scale; dy++ {
+				for dx := 0; dx < opts.Scale; dx++ {
+					img.SetGray(px+dx, py+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// verticalQuietZone 一维码上下只留一个模块，二维码四周留白相同
+func (b *Barcode) verticalQuietZone() int {
+	if b.IsLinear() {
+		return 1
+	}
+	return b.QuietZone
+}
+
+// WriteSVG 渲染为 SVG，一维码在条下方印出文字
+func (b *Barcode) WriteSVG(w io.Writer, opts RenderOptions) error {
+	opts = opts.normalize()
+	rows := b.rows(opts)
+	scale := opts.Scale
+	width := (b.Width + 2*b.QuietZone) * scale
+	barsHeight := (rows + 2*b.verticalQuietZone()) * scale
+	height := barsHeight
+	fontSize := 0
+	if b.IsLinear() && b.Text != "" {
+		fontSize = 8 * scale
+		height += fontSize + scale
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, width, height, width, height)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#fff"/><g fill="#000">`, width, height)
+	if b.IsLinear() {
+		for _, run := range b.Runs(0) {
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d"/>`,
+				(run[0]+b.QuietZone)*scale, scale, run[1]*scale, rows*scale)
+		}
+	} else {
+		for y := 0; y < b.Height; y++ {
+			for _, run := range b.Runs(y) {
+				fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d"/>`,
+					(run[0]+b.QuietZone)*scale, (y+b.QuietZone)*scale, run[1]*scale, scale)
+			}
+		}
+	}
+	sb.WriteString(`</g>`)
+	if fontSize > 0 {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+			width/2, barsHeight+fontSize, fontSize, escapeXML(b.Text))
+	}
+	sb.WriteString(`</svg>`)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+func escapeXML(s string) string {
+	return xmlEscaper.Replace(s)
+}
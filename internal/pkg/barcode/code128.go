@@ -0,0 +1,93 @@
+package barcode
+
+// code128Widths 每个码值的条、空宽度（模块数），依次为条、空、条、空、条、空；106 为终止符，多一个条
+var code128Widths = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB    = 104
+	code128StartC    = 105
+	code128Stop      = 106
+	code128QuietZone = 10
+
+	// code128MaxLength 标签上可读的最大长度，更长的内容应使用 QR 码
+	code128MaxLength = 80
+)
+
+// EncodeCode128 编码可打印 ASCII 文本
+func EncodeCode128(text string) (*Barcode, error) {
+	if text == "" {
+		return nil, ErrInvalidContent
+	}
+	if len(text) > code128MaxLength {
+		return nil, ErrContentTooLong
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return nil, ErrInvalidContent
+		}
+	}
+
+	values := code128Values(text)
+	checksum := values[0]
+	for i := 1; i < len(values); i++ {
+		checksum += i * values[i]
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	width := 0
+	for _, value := range values {
+		for _, c := range code128Widths[value] {
+			width += int(c - '0')
+		}
+	}
+	b := newBarcode(KindCode128, text, width, 1, code128QuietZone)
+	x := 0
+	for _, value := range values {
+		for i, c := range code128Widths[value] {
+			n := int(c - '0')
+			for ; n > 0; n-- {
+				b.set(x, 0, i%2 == 0)
+				x++
+			}
+		}
+	}
+	return b, nil
+}
+
+// code128Values 把文本转换为码值序列（含起始符，不含校验符和终止符）。
+// 偶数位纯数字使用 C 字符集两位一组编码，其余使用 B 字符集
+func code128Values(text string) []int {
+	if len(text) >= 4 && len(text)%2 == 0 && digitRun(text) == len(text) {
+		values := []int{code128StartC}
+		for i := 0; i < len(text); i += 2 {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+		}
+		return values
+	}
+	values := []int{code128StartB}
+	for i := 0; i < len(text); i++ {
+		values = append(values, int(text[i])-32)
+	}
+	return values
+}
+
+// digitRun 文本开头连续数字的个数
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,325 @@
+package barcode
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+var ErrNotFound = errors.New("no barcode found in image")
+
+const (
+	// scanMaxDimension 大图先缩小到此尺寸以内，手机照片中的条码模块宽度通常仍有数个像素
+	scanMaxDimension = 1200
+	// scanAngleStep 扫描线的角度间隔。倾斜只会等比例拉长所有条空，
+	// 只要扫描线仍穿过全部条，偏差十几度也能识别
+	scanAngleStep = 10
+	scanLines     = 25
+
+	ean13Runs = 59 // 3 + 6×4 + 5 + 6×4 + 3 个条空
+
+	// 条空宽度与理想宽度的累计偏差上限（模块数）
+	maxGuardError = 1.2
+	maxDigitError = 1.6
+)
+
+// digitWidths 数字编码对应的四个条空宽度
+type digitWidths [4]float64
+
+var ean13LWidths, ean13GWidths, ean13RWidths [10]digitWidths
+
+func init() {
+	for d := 0; d < 10; d++ {
+		ean13LWidths[d] = patternWidths(ean13L[d])
+		ean13GWidths[d] = patternWidths(ean13G[d])
+		ean13RWidths[d] = patternWidths(ean13R[d])
+	}
+}
+
+// patternWidths 把 7 位模块图案转换为四段连续同色的宽度
+func patternWidths(pattern string) digitWidths {
+	var widths digitWidths
+	run := 0
+	for i := 0; i < len(pattern); i++ {
+		if i > 0 && pattern[i] != pattern[i-1] {
+			run++
+		}
+		widths[run]++
+	}
+	return widths
+}
+
+// ScanEAN13 在图像中查找 EAN-13 条码并返回校验通过的 13 位数字。
+// 以多个角度的平行扫描线采样，每条线分别用全局和局部阈值二值化，
+// 多条扫描线的结果投票，出现次数最多的结果胜出，以应对旋转、低对比度和局部反光
+func ScanEAN13(img image.Image) (string, error) {
+	gray := newLuminance(img)
+
+	votes := make(map[string]int)
+	for angle := 0; angle < 180; angle += scanAngleStep {
+		theta := float64(angle) * math.Pi / 180
+		for line := 0; line < scanLines; line++ {
+			profile := gray.scanline(theta, line)
+			if len(profile) < ean13Width {
+				continue
+			}
+			for _, dark := range binarize(profile) {
+				runs := runLengths(dark)
+				if code, ok := decodeEAN13Runs(runs); ok {
+					votes[code]++
+				}
+				reverse(runs)
+				if code, ok := decodeEAN13Runs(runs); ok {
+					votes[code]++
+				}
+			}
+		}
+	}
+
+	best, bestVotes := "", 0
+	for code, n := range votes {
+		if n > bestVotes || n == bestVotes && code < best {
+			best, bestVotes = code, n
+		}
+	}
+	if bestVotes == 0 {
+		return "", ErrNotFound
+	}
+	return best, nil
+}
+
+// luminance 缩放后的灰度图
+type luminance struct {
+	width, height int
+	pix           []float64
+}
+
+func newLuminance(img image.Image) *luminance {
+	bounds := img.Bounds()
+	factor := 1
+	for max(bounds.Dx(), bounds.Dy())/factor > scanMaxDimension {
+		factor++
+	}
+	l := &luminance{width: bounds.Dx() / factor, height: bounds.Dy() / factor}
+	l.pix = make([]float64, l.width*l.height)
+	for y := 0; y < l.height; y++ {
+		for x := 0; x < l.width; x++ {
+			// 按缩小倍数做区域平均
+			var sum float64
+			for dy := 0; dy < factor; dy++ {
+				for dx := 0; dx < factor; dx++ {
+					r, g, b, _ := img.At(bounds.Min.X+x*factor+dx, bounds.Min.Y+y*factor+dy).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+				}
+			}
+			l.pix[y*l.width+x] = sum / float64(factor*factor) / 257
+		}
+	}
+	return l
+}
+
+// at 双线性插值取样，坐标超出图像时返回 false
+func (l *luminance) at(x, y float64) (float64, bool) {
+	if x < 0 || y < 0 || x > float64(l.width-1) || y > float64(l.height-1) {
+		return 0, false
+	}
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, l.width-1), min(y0+1, l.height-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+	top := l.pix[y0*l.width+x0]*(1-fx) + l.pix[y0*l.width+x1]*fx
+	bottom := l.pix[y1*l.width+x0]*(1-fx) + l.pix[y1*l.width+x1]*fx
+	return top*(1-fy) + bottom*fy, true
+}
+
+// scanline 沿角度 theta 的第 index 条平行线采样，平行线均匀分布在图像中部
+func (l *luminance) scanline(theta float64, index int) []float64 {
+	cx, cy := float64(l.width-1)/2, float64(l.height-1)/2
+	dx, dy := math.Cos(theta), math.Sin(theta)
+	nx, ny := -dy, dx
+
+	// 平行线覆盖垂直方向上图像投影的 80%
+	extent := math.Abs(nx)*float64(l.width) + math.Abs(ny)*float64(l.height)
+	offset := (float64(index)/float64(scanLines-1) - 0.5) * extent * 0.8
+	half := math.Hypot(float64(l.width), float64(l.height)) / 2
+
+	var profile []float64
+	for t := -half; t <= half; t++ {
+		v, ok := l.at(cx+nx*offset+dx*t, cy+ny*offset+dy*t)
+		if ok {
+			profile = append(profile, v)
+		} else if len(profile) > 0 {
+			break
+		}
+	}
+	return profile
+}
+
+// binarize 返回两种二值化结果：以整条线亮度分布的高低百分位中点为阈值，
+// 以及以滑动窗口内均值为阈值（应对光照不均）。对比度过低的线不参与识别
+func binarize(profile []float64) [][]bool {
+	sorted := append([]float64(nil), profile...)
+	sort.Float64s(sorted)
+	low, high := sorted[len(sorted)/20], sorted[len(sorted)*19/20]
+	if high-low < 8 {
+		return nil
+	}
+
+	global := make([]bool, len(profile))
+	mid := (low + high) / 2
+	for i, v := range profile {
+		global[i] = v < mid
+	}
+
+	// 窗口取扫描线长度的 1/8，至少覆盖几个条空
+	window := max(len(profile)/16, 4)
+	prefix := make([]float64, len(profile)+1)
+	for i, v := range profile {
+		prefix[i+1] = prefix[i] + v
+	}
+	local := make([]bool, len(profile))
+	minContrast := (high - low) / 8
+	for i, v := range profile {
+		from, to := max(i-window, 0), min(i+window+1, len(profile))
+		mean := (prefix[to] - prefix[from]) / float64(to-from)
+		local[i] = v < mean-minContrast/2
+	}
+	return [][]bool{global, local}
+}
+
+// run 一段连续同色的采样点
+type run struct {
+	dark  bool
+	width float64
+}
+
+func runLengths(dark []bool) []run {
+	var runs []run
+	for i, d := range dark {
+		if i == 0 || d != dark[i-1] {
+			runs = append(runs, run{dark: d})
+		}
+		runs[len(runs)-1].width++
+	}
+	return runs
+}
+
+func reverse(runs []run) {
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+}
+
+// decodeEAN13Runs 在条空序列中查找左侧留白后的起始符，按固定的 59 段解码。
+// 每个数字按自身四段的总宽归一化到 7 个模块，可以容忍透视造成的宽度渐变
+func decodeEAN13Runs(runs []run) (string, bool) {
+	for i := 0; i+ean13Runs <= len(runs); i++ {
+		if !runs[i].dark {
+			continue
+		}
+		symbol := runs[i : i+ean13Runs]
+		total := 0.0
+		for _, r := range symbol {
+			total += r.width
+		}
+		module := total / ean13Width
+		if module < 1 {
+			continue
+		}
+		// 两侧要有留白，起止符外侧直接接着条说明不是条码的开头
+		if i > 0 && runs[i-1].width < 3*module {
+			continue
+		}
+		if i+ean13Runs < len(runs) && runs[i+ean13Runs].width < 3*module {
+			continue
+		}
+		if code, ok := decodeEAN13Symbol(symbol, module); ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+func decodeEAN13Symbol(symbol []run, module float64) (string, bool) {
+	if guardError(symbol[0:3], module) > maxGuardError ||
+		guardError(symbol[27:32], module) > maxGuardError ||
+		guardError(symbol[56:59], module) > maxGuardError {
+		return "", false
+	}
+
+	digits := make([]byte, 13)
+	parity := make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		d, isG, ok := matchDigit(symbol[3+4*i:7+4*i], true)
+		if !ok {
+			return "", false
+		}
+		digits[i+1] = '0' + byte(d)
+		parity[i] = 'L'
+		if isG {
+			parity[i] = 'G'
+		}
+	}
+	for i := 0; i < 6; i++ {
+		d, _, ok := matchDigit(symbol[32+4*i:36+4*i], false)
+		if !ok {
+			return "", false
+		}
+		digits[i+7] = '0' + byte(d)
+	}
+
+	first := -1
+	for d, p := range ean13Parity {
+		if p == string(parity) {
+			first = d
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+	digits[0] = '0' + byte(first)
+
+	code := string(digits)
+	if int(code[12]-'0') != ean13CheckDigit(code) {
+		return "", false
+	}
+	return code, true
+}
+
+// guardError 起始符、中间分隔符、终止符的每段都应为一个模块宽
+func guardError(runs []run, module float64) float64 {
+	var err float64
+	for _, r := range runs {
+		err += math.Abs(r.width/module - 1)
+	}
+	return err
+}
+
+// matchDigit 找出与四段宽度最接近的数字编码；左侧在 L、G 两组中查找，右侧只有 R 组
+func matchDigit(runs []run, left bool) (int, bool, bool) {
+	total := runs[0].width + runs[1].width + runs[2].width + runs[3].width
+	var widths digitWidths
+	for i, r := range runs {
+		widths[i] = r.width * 7 / total
+	}
+
+	bestDigit, bestG, bestError := -1, false, math.MaxFloat64
+	try := func(table *[10]digitWidths, isG bool) {
+		for d, pattern := range table {
+			var err float64
+			for i := range pattern {
+				err += math.Abs(widths[i] - pattern[i])
+			}
+			if err < bestError {
+				bestDigit, bestG, bestError = d, isG, err
+			}
+		}
+	}
+	if left {
+		try(&ean13LWidths, false)
+		try(&ean13GWidths, true)
+	} else {
+		try(&ean13RWidths, false)
+	}
+	return bestDigit, bestG, bestError <= maxDigitError
+}
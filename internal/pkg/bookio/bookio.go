@@ -0,0 +1,245 @@
+// Package bookio 以流的方式读写 CSV、JSON Lines、MARC21 和 MARCXML 格式的书目记录，
+// 每次只在内存中保留一条记录，可以处理比内存大的文件
+package bookio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format 书目文件格式
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatMARC21  Format = "marc21"
+	FormatMARCXML Format = "marcxml"
+)
+
+var ErrUnknownFormat = errors.New("unknown catalog format")
+
+// ParseFormat 解析格式名称，接受常见的别名和扩展名
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson", "json":
+		return FormatJSONL, nil
+	case "marc21", "marc", "mrc", "iso2709":
+		return FormatMARC21, nil
+	case "marcxml", "xml":
+		return FormatMARCXML, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// ContentType 导出时使用的 MIME 类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatMARC21:
+		return "application/marc"
+	case FormatMARCXML:
+		return "application/marcxml+xml"
+	}
+	return "application/octet-stream"
+}
+
+// Extension 导出文件的扩展名
+func (f Format) Extension() string {
+	switch f {
+	case FormatMARC21:
+		return "mrc"
+	case FormatMARCXML:
+		return "xml"
+	}
+	return string(f)
+}
+
+// 书目记录的字段名
+const (
+	FieldISBN        = "isbn"
+	FieldTitle       = "title"
+	FieldAuthor      = "author"
+	FieldPublisher   = "publisher"
+	FieldPublishDate = "publish_date"
+	FieldCoverURL    = "cover_url"
+	FieldSubtitle    = "subtitle"
+	FieldEdition     = "edition"
+	FieldPageCount   = "page_count"
+	FieldDescription = "description"
+)
+
+// Fields 全部字段，CSV 导出时按此顺序输出列；后加的字段排在末尾，旧的导出文件列序不变
+var Fields = []string{
+	FieldISBN, FieldTitle, FieldAuthor, FieldPublisher, FieldPublishDate, FieldCoverURL,
+	FieldSubtitle, FieldEdition, FieldPageCount, FieldDescription,
+}
+
+// Record 一条书目记录，字段值均为未经校验的原始文本
+type Record struct {
+	Position int  // 记录在源文件中的位置：CSV、JSONL 为行号，MARC 为记录序号
+	ID       uint // 导出时为图书 ID，导入时忽略
+
+	ISBN        string
+	Title       string
+	Author      string // 多个责任者以 "; " 分隔
+	Publisher   string
+	PublishDate string
+	CoverURL    string
+	Subtitle    string
+	Edition     string
+	PageCount   string // 页数的文本，导入时由调用方解析
+	Description string
+}
+
+func (r *Record) field(name string) *string {
+	switch name {
+	case FieldISBN:
+		return &r.ISBN
+	case FieldTitle:
+		return &r.Title
+	case FieldAuthor:
+		return &r.Author
+	case FieldPublisher:
+		return &r.Publisher
+	case FieldPublishDate:
+		return &r.PublishDate
+	case FieldCoverURL:
+		return &r.CoverURL
+	case FieldSubtitle:
+		return &r.Subtitle
+	case FieldEdition:
+		return &r.Edition
+	case FieldPageCount:
+		return &r.PageCount
+	case FieldDescription:
+		return &r.Description
+	}
+	return nil
+}
+
+// Get 按字段名读取值
+func (r *Record) Get(name string) string {
+	if p := r.field(name); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// Set 按字段名写入值，未知字段被忽略
+func (r *Record) Set(name, value string) {
+	if p := r.field(name); p != nil {
+		*p = value
+	}
+}
+
+// Mapping 字段映射：字段名 → 源文件中的位置。
+// CSV 为列名，JSONL 为键名，MARC 为 "tag$code"，多个来源用逗号分隔并按顺序合并
+type Mapping map[string]string
+
+// DefaultMapping 各格式默认的字段映射
+func DefaultMapping(format Format) Mapping {
+	switch format {
+	case FormatMARC21, FormatMARCXML:
+		return Mapping{
+			FieldISBN:        "020$a",
+			FieldTitle:       "245$a",
+			FieldAuthor:      "100$a,110$a,700$a,710$a",
+			FieldPublisher:   "264$b,260$b",
+			FieldPublishDate: "264$c,260$c",
+			FieldCoverURL:    "856$u",
+			FieldSubtitle:    "245$b",
+			FieldEdition:     "250$a",
+			FieldPageCount:   "300$a",
+			FieldDescription: "520$a",
+		}
+	}
+	mapping := make(Mapping, len(Fields))
+	for _, field := range Fields {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// WithOverrides 在默认映射上应用自定义映射，字段名无效时返回错误
+func (m Mapping) WithOverrides(overrides map[string]string) (Mapping, error) {
+	merged := make(Mapping, len(m))
+	for field, source := range m {
+		merged[field] = source
+	}
+	for field, source := range overrides {
+		if (&Record{}).field(field) == nil {
+			return nil, fmt.Errorf("unknown field %q in mapping", field)
+		}
+		merged[field] = strings.TrimSpace(source)
+	}
+	return merged, nil
+}
+
+// Reader 逐条读取书目记录，读完时返回 io.EOF
+type Reader interface {
+	Read() (*Record, error)
+}
+
+// Writer 逐条写出书目记录，Close 写出格式需要的结尾但不关闭底层的 io.Writer
+type Writer interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// NewReader 创建指定格式的读取器，mapping 为 nil 时使用默认映射
+func NewReader(format Format, r io.Reader, mapping Mapping) (Reader, error) {
+	if mapping == nil {
+		mapping = DefaultMapping(format)
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r, mapping)
+	case FormatJSONL:
+		return newJSONLReader(r, mapping), nil
+	case FormatMARC21:
+		return &marcRecordReader{source: newMARC21Reader(r), mapping: parseMARCMapping(mapping)}, nil
+	case FormatMARCXML:
+		return &marcRecordReader{source: newMARCXMLReader(r), mapping: parseMARCMapping(mapping)}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// NewWriter 创建指定格式的写出器；CSV 和 JSONL 按 mapping 命名列和键，MARC 使用标准字段
+func NewWriter(format Format, w io.Writer, mapping Mapping) (Writer, error) {
+	if mapping == nil {
+		mapping = DefaultMapping(format)
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, mapping), nil
+	case FormatJSONL:
+		return newJSONLWriter(w, mapping), nil
+	case FormatMARC21:
+		return newMARC21Writer(w), nil
+	case FormatMARCXML:
+		return newMARCXMLWriter(w), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// RecordError 某条记录无法解析，Position 为其在源文件中的位置
+type RecordError struct {
+	Position int
+	Err      error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Position, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,124 @@
+package bookio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int // 字段名 → 列序号
+}
+
+func newCSVReader(r io.Reader, mapping Mapping) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("csv: missing header row")
+		}
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+
+	// 列名不区分大小写，忽略首列可能带有的 BOM
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+	columns := make(map[string]int)
+	for field, column := range mapping {
+		if i, ok := positions[strings.ToLower(column)]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns[FieldISBN]; !ok {
+		return nil, fmt.Errorf("csv: no column %q for field %s", mapping[FieldISBN], FieldISBN)
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (*Record, error) {
+	for {
+		row, err := r.reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &RecordError{Position: parseErr.StartLine, Err: parseErr.Err}
+			}
+			return nil, err
+		}
+		line, _ := r.reader.FieldPos(0)
+		if isBlankRow(row) {
+			continue
+		}
+
+		record := &Record{Position: line}
+		for field, i := range r.columns {
+			if i < len(row) {
+				record.Set(field, strings.TrimSpace(row[i]))
+			}
+		}
+		return record, nil
+	}
+}
+
+func isBlankRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	mapping Mapping
+	started bool
+}
+
+func newCSVWriter(w io.Writer, mapping Mapping) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), mapping: mapping}
+}
+
+func (w *csvWriter) Write(record *Record) error {
+	if !w.started {
+		header := make([]string, len(Fields))
+		for i, field := range Fields {
+			header[i] = w.column(field)
+		}
+		if err := w.writer.Write(header); err != nil {
+			return err
+		}
+		w.started = true
+	}
+
+	row := make([]string, len(Fields))
+	for i, field := range Fields {
+		row[i] = record.Get(field)
+	}
+	return w.writer.Write(row)
+}
+
+// column 导出时的列名：映射中有多个来源时取第一个
+func (w *csvWriter) column(field string) string {
+	if column := strings.TrimSpace(strings.Split(w.mapping[field], ",")[0]); column != "" {
+		return column
+	}
+	return field
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -0,0 +1,111 @@
+package bookio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type jsonlReader struct {
+	reader  *bufio.Reader
+	mapping Mapping
+	line    int
+}
+
+func newJSONLReader(r io.Reader, mapping Mapping) *jsonlReader {
+	return &jsonlReader{reader: bufio.NewReaderSize(r, 64*1024), mapping: mapping}
+}
+
+func (r *jsonlReader) Read() (*Record, error) {
+	for {
+		data, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(data) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var object map[string]interface{}
+		if decodeErr := json.Unmarshal(data, &object); decodeErr != nil {
+			return nil, &RecordError{Position: r.line, Err: decodeErr}
+		}
+
+		record := &Record{Position: r.line}
+		for field, key := range r.mapping {
+			if value, ok := object[key]; ok {
+				record.Set(field, jsonText(value))
+			}
+		}
+		return record, nil
+	}
+}
+
+// jsonText 把 JSON 值转换为文本：字符串数组（如多个作者）以 "; " 连接
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := jsonText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "; ")
+	}
+	return fmt.Sprint(value)
+}
+
+type jsonlWriter struct {
+	writer  *bufio.Writer
+	mapping Mapping
+}
+
+func newJSONLWriter(w io.Writer, mapping Mapping) *jsonlWriter {
+	return &jsonlWriter{writer: bufio.NewWriter(w), mapping: mapping}
+}
+
+func (w *jsonlWriter) Write(record *Record) error {
+	object := make(map[string]interface{}, len(Fields)+1)
+	if record.ID != 0 {
+		object["id"] = record.ID
+	}
+	for _, field := range Fields {
+		key := strings.TrimSpace(strings.Split(w.mapping[field], ",")[0])
+		if key == "" {
+			key = field
+		}
+		object[key] = record.Get(field)
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(data); err != nil {
+		return err
+	}
+	return w.writer.WriteByte('\n')
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.writer.Flush()
+}
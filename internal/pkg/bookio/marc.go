@@ -0,0 +1,257 @@
+package bookio
+
+import (
+	"strconv"
+	"strings"
+)
+
+// marcRecord MARC 记录的通用表示，MARC21 和 MARCXML 共用
+type marcRecord struct {
+	leader string
+	fields []marcField
+}
+
+// marcField 控制字段（00X）只有 value，数据字段有指示符和子字段
+type marcField struct {
+	tag       string
+	value     string
+	ind1      byte
+	ind2      byte
+	subfields []marcSubfield
+}
+
+type marcSubfield struct {
+	code  byte
+	value string
+}
+
+func (f marcField) isControl() bool {
+	return strings.HasPrefix(f.tag, "00")
+}
+
+// marcSource 逐条读取 MARC 记录，读完时返回 io.EOF
+type marcSource interface {
+	next() (*marcRecord, int, error)
+}
+
+// marcSpec 映射中的一个来源：字段标识和子字段代码
+type marcSpec struct {
+	tag  string
+	code byte
+}
+
+func parseMARCMapping(mapping Mapping) map[string][]marcSpec {
+	specs := make(map[string][]marcSpec, len(mapping))
+	for field, source := range mapping {
+		for _, item := range strings.Split(source, ",") {
+			item = strings.TrimSpace(item)
+			tag, code, ok := strings.Cut(item, "$")
+			if !ok || len(tag) != 3 || len(code) != 1 {
+				continue
+			}
+			specs[field] = append(specs[field], marcSpec{tag: tag, code: code[0]})
+		}
+	}
+	return specs
+}
+
+// marcFieldJoiners 同一字段有多个来源值时的连接方式；其余字段只取第一个值
+var marcFieldJoiners = map[string]string{
+	FieldTitle:       ": ",
+	FieldSubtitle:    ": ",
+	FieldAuthor:      "; ",
+	FieldDescription: "\n\n",
+}
+
+type marcRecordReader struct {
+	source  marcSource
+	mapping map[string][]marcSpec
+}
+
+func (r *marcRecordReader) Read() (*Record, error) {
+	marc, position, err := r.source.next()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{Position: position}
+	for field, specs := range r.mapping {
+		var values []string
+		for _, spec := range specs {
+			values = append(values, marc.values(spec)...)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		joiner, ok := marcFieldJoiners[field]
+		if !ok {
+			values = values[:1]
+		}
+		record.Set(field, strings.Join(values, joiner))
+	}
+	record.ISBN = cleanMARCISBN(record.ISBN)
+	record.PageCount = marcPageCount(record.PageCount)
+	return record, nil
+}
+
+// values 返回记录中指定字段和子字段的全部值，已去掉 ISBD 结尾标点
+func (m *marcRecord) values(spec marcSpec) []string {
+	var values []string
+	for _, field := range m.fields {
+		if field.tag != spec.tag {
+			continue
+		}
+		if field.isControl() {
+			values = append(values, strings.TrimSpace(field.value))
+			continue
+		}
+		for _, subfield := range field.subfields {
+			if subfield.code != spec.code {
+				continue
+			}
+			value := strings.TrimSpace(subfield.value)
+			if !freeTextTags[field.tag] {
+				value = trimISBDPunctuation(value)
+			}
+			if personalNameTags[field.tag] && field.ind1 == '1' && subfield.code == 'a' {
+				value = uninvertName(value)
+			}
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// freeTextTags 内容为完整句子的附注字段，结尾的句点属于正文，不按 ISBD 标点去除
+var freeTextTags = map[string]bool{"500": true, "520": true}
+
+// personalNameTags 个人名称字段，第一指示符为 1 时 $a 为 "姓, 名" 的倒置形式
+var personalNameTags = map[string]bool{"100": true, "600": true, "700": true}
+
+// uninvertName 把 "Orwell, George" 还原为 "George Orwell"，与图书作者文本的写法一致
+func uninvertName(name string) string {
+	surname, forename, ok := strings.Cut(name, ", ")
+	if !ok || strings.Contains(forename, ",") {
+		return name
+	}
+	return strings.TrimSpace(forename) + " " + strings.TrimSpace(surname)
+}
+
+// trimISBDPunctuation 去掉编目规则在子字段末尾添加的 " /"、" :"、" ;"、","、"." 等标点，
+// 姓名缩写结尾的句点（如 "J.D."）予以保留
+func trimISBDPunctuation(value string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), " /:;,=")
+	if strings.HasSuffix(value, ".") {
+		body := value[:len(value)-1]
+		word := body[strings.LastIndexAny(body, " .")+1:]
+		if len(word) > 1 {
+			value = body
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// cleanMARCISBN 020$a 常带有装帧说明，如 "0441172717 (pbk.)"，只取开头的号码部分
+func cleanMARCISBN(value string) string {
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == 'X' || r == 'x' || r == '-')
+	})
+	if end >= 0 {
+		value = value[:end]
+	}
+	return value
+}
+
+// marcPageCount 从 300$a 的篇幅说明（如 "xii, 412 p."、"412 pages"）中取出页数，
+// 有多个数字时取最大的一个；没有数字时原样返回，由调用方报告无效值
+func marcPageCount(extent string) string {
+	best, digits := "", ""
+	for _, r := range extent + " " {
+		if r >= '0' && r <= '9' {
+			digits += string(r)
+			continue
+		}
+		digits = strings.TrimLeft(digits, "0")
+		if len(digits) > len(best) || len(digits) == len(best) && digits > best {
+			best = digits
+		}
+		digits = ""
+	}
+	if best == "" {
+		return extent
+	}
+	return best
+}
+
+// toMARC 把书目记录转换为 MARC 记录，使用 RDA 的常用字段
+func toMARC(record *Record) *marcRecord {
+	marc := &marcRecord{}
+	if record.ID != 0 {
+		marc.fields = append(marc.fields, marcField{tag: "001", value: strconv.FormatUint(uint64(record.ID), 10)})
+	}
+	if record.ISBN != "" {
+		marc.fields = append(marc.fields, dataField("020", ' ', ' ', 'a', record.ISBN))
+	}
+
+	authors := splitAuthors(record.Author)
+	for i, name := range authors {
+		tag := "700"
+		if i == 0 {
+			tag = "100"
+		}
+		// 姓名按图书作者文本的顺序写出，第一指示符为 0（直序）
+		marc.fields = append(marc.fields, dataField(tag, '0', ' ', 'a', name))
+	}
+
+	// 有主要责任者时题名不作为检索点（第一指示符为 1）
+	titleInd1 := byte('0')
+	if len(authors) > 0 {
+		titleInd1 = '1'
+	}
+	title := dataField("245", titleInd1, '0', 'a', record.Title)
+	if record.Subtitle != "" {
+		title.subfields = append(title.subfields, marcSubfield{code: 'b', value: record.Subtitle})
+	}
+	marc.fields = append(marc.fields, title)
+	if record.Edition != "" {
+		marc.fields = append(marc.fields, dataField("250", ' ', ' ', 'a', record.Edition))
+	}
+
+	if record.Publisher != "" || record.PublishDate != "" {
+		field := marcField{tag: "264", ind1: ' ', ind2: '1'}
+		if record.Publisher != "" {
+			field.subfields = append(field.subfields, marcSubfield{code: 'b', value: record.Publisher})
+		}
+		if record.PublishDate != "" {
+			field.subfields = append(field.subfields, marcSubfield{code: 'c', value: record.PublishDate})
+		}
+		marc.fields = append(marc.fields, field)
+	}
+	if record.PageCount != "" {
+		marc.fields = append(marc.fields, dataField("300", ' ', ' ', 'a', record.PageCount+" pages"))
+	}
+	if record.Description != "" {
+		marc.fields = append(marc.fields, dataField("520", ' ', ' ', 'a', record.Description))
+	}
+	if record.CoverURL != "" {
+		marc.fields = append(marc.fields, dataField("856", '4', '2', 'u', record.CoverURL))
+	}
+	return marc
+}
+
+func dataField(tag string, ind1, ind2, code byte, value string) marcField {
+	return marcField{tag: tag, ind1: ind1, ind2: ind2, subfields: []marcSubfield{{code: code, value: value}}}
+}
+
+// splitAuthors 拆分以 "; " 连接的责任者
+func splitAuthors(text string) []string {
+	var names []string
+	for _, name := range strings.Split(text, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,214 @@
+package bookio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ISO 2709 的分隔符和长度限制
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+
+	marcLeaderLength    = 24
+	marcMaxRecordLength = 99999
+	marcMaxFieldLength  = 9999
+)
+
+var (
+	errMARCRecordTooLong = errors.New("marc21: record exceeds 99999 bytes")
+	errMARCTruncated     = errors.New("marc21: truncated record")
+)
+
+type marc21Reader struct {
+	reader *bufio.Reader
+	count  int
+}
+
+func newMARC21Reader(r io.Reader) *marc21Reader {
+	return &marc21Reader{reader: bufio.NewReaderSize(r, 64*1024)}
+}
+
+func (r *marc21Reader) next() (*marcRecord, int, error) {
+	for {
+		data, err := r.readRecord()
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		r.count++
+		if err != nil {
+			return nil, 0, &RecordError{Position: r.count, Err: err}
+		}
+		// 记录之间的换行等空白不算记录
+		if len(bytes.TrimSpace(data)) == 0 {
+			r.count--
+			continue
+		}
+
+		record, err := decodeMARC21(data)
+		if err != nil {
+			return nil, 0, &RecordError{Position: r.count, Err: err}
+		}
+		return record, r.count, nil
+	}
+}
+
+// readRecord 读取到下一个记录结束符为止。超长的记录会被丢弃到结束符，
+// 这样后面的记录仍然可以继续读取，内存占用不超过一条记录的上限
+func (r *marc21Reader) readRecord() ([]byte, error) {
+	var data []byte
+	tooLong := false
+	for {
+		chunk, err := r.reader.ReadSlice(marcRecordTerminator)
+		if !tooLong {
+			if len(data)+len(chunk) > marcMaxRecordLength+1 {
+				tooLong = true
+				data = nil
+			} else {
+				data = append(data, chunk...)
+			}
+		}
+
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF:
+			if tooLong {
+				return nil, errMARCRecordTooLong
+			}
+			if len(bytes.TrimSpace(data)) == 0 {
+				return nil, io.EOF
+			}
+			return nil, errMARCTruncated
+		case err != nil:
+			return nil, err
+		}
+
+		if tooLong {
+			return nil, errMARCRecordTooLong
+		}
+		return data, nil
+	}
+}
+
+// decodeMARC21 解析一条 ISO 2709 记录；不依赖头标中的记录长度，只按目录定位字段。
+// 非 UTF-8 编码（MARC-8）的记录中无法识别的字节会被替换
+func decodeMARC21(data []byte) (*marcRecord, error) {
+	data = bytes.TrimLeft(data, "\r\n")
+	if len(data) < marcLeaderLength+1 {
+		return nil, errMARCTruncated
+	}
+	leader := string(data[:marcLeaderLength])
+	base, err := strconv.Atoi(strings.TrimSpace(leader[12:17]))
+	if err != nil || base <= marcLeaderLength || base > len(data) {
+		return nil, fmt.Errorf("marc21: invalid base address %q", leader[12:17])
+	}
+
+	directory := data[marcLeaderLength : base-1]
+	if len(directory)%12 != 0 {
+		return nil, errors.New("marc21: malformed directory")
+	}
+
+	record := &marcRecord{leader: leader}
+	for i := 0; i < len(directory); i += 12 {
+		entry := string(directory[i : i+12])
+		length, err1 := strconv.Atoi(entry[3:7])
+		start, err2 := strconv.Atoi(entry[7:12])
+		if err1 != nil || err2 != nil || base+start+length > len(data) {
+			return nil, fmt.Errorf("marc21: invalid directory entry %q", entry)
+		}
+		raw := bytes.TrimSuffix(data[base+start:base+start+length], []byte{marcFieldTerminator})
+		field := marcField{tag: entry[:3]}
+
+		if field.isControl() {
+			field.value = validText(raw)
+			record.fields = append(record.fields, field)
+			continue
+		}
+		if len(raw) < 2 {
+			continue
+		}
+		field.ind1, field.ind2 = raw[0], raw[1]
+		for _, part := range bytes.Split(raw[2:], []byte{marcSubfieldDelimiter}) {
+			if len(part) == 0 {
+				continue
+			}
+			field.subfields = append(field.subfields, marcSubfield{code: part[0], value: validText(part[1:])})
+		}
+		record.fields = append(record.fields, field)
+	}
+	return record, nil
+}
+
+func validText(raw []byte) string {
+	return strings.ToValidUTF8(string(raw), "\uFFFD")
+}
+
+type marc21Writer struct {
+	writer *bufio.Writer
+}
+
+func newMARC21Writer(w io.Writer) *marc21Writer {
+	return &marc21Writer{writer: bufio.NewWriter(w)}
+}
+
+func (w *marc21Writer) Write(record *Record) error {
+	data, err := encodeMARC21(toMARC(record))
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(data)
+	return err
+}
+
+func (w *marc21Writer) Close() error {
+	return w.writer.Flush()
+}
+
+// encodeMARC21 生成 UTF-8 编码的 ISO 2709 记录
+func encodeMARC21(record *marcRecord) ([]byte, error) {
+	var directory, body bytes.Buffer
+	for _, field := range record.fields {
+		start := body.Len()
+		if field.isControl() {
+			body.WriteString(field.value)
+		} else {
+			body.WriteByte(field.ind1)
+			body.WriteByte(field.ind2)
+			for _, subfield := range field.subfields {
+				body.WriteByte(marcSubfieldDelimiter)
+				body.WriteByte(subfield.code)
+				body.WriteString(subfield.value)
+			}
+		}
+		body.WriteByte(marcFieldTerminator)
+
+		length := body.Len() - start
+		if length > marcMaxFieldLength {
+			return nil, fmt.Errorf("marc21: field %s exceeds %d bytes", field.tag, marcMaxFieldLength)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", field.tag, length, start)
+	}
+	directory.WriteByte(marcFieldTerminator)
+
+	base := marcLeaderLength + directory.Len()
+	length := base + body.Len() + 1
+	if length > marcMaxRecordLength {
+		return nil, errMARCRecordTooLong
+	}
+
+	var out bytes.Buffer
+	out.Grow(length)
+	// 新记录、文字资料、专著、UTF-8、ISBD 著录
+	fmt.Fprintf(&out, "%05dnam a22%05d i 4500", length, base)
+	out.Write(directory.Bytes())
+	out.Write(body.Bytes())
+	out.WriteByte(marcRecordTerminator)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package bookio
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+)
+
+const marcXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// marcXMLReader 逐个解码 <record> 元素，不会把整个 <collection> 读入内存
+type marcXMLReader struct {
+	decoder *xml.Decoder
+	count   int
+}
+
+func newMARCXMLReader(r io.Reader) *marcXMLReader {
+	return &marcXMLReader{decoder: xml.NewDecoder(bufio.NewReaderSize(r, 64*1024))}
+}
+
+func (r *marcXMLReader) next() (*marcRecord, int, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var element xmlRecord
+		if err := r.decoder.DecodeElement(&element, &start); err != nil {
+			return nil, 0, err
+		}
+		r.count++
+		return element.toMARC(), r.count, nil
+	}
+}
+
+func (x *xmlRecord) toMARC() *marcRecord {
+	record := &marcRecord{leader: x.Leader}
+	for _, control := range x.ControlFields {
+		record.fields = append(record.fields, marcField{tag: control.Tag, value: control.Value})
+	}
+	for _, data := range x.DataFields {
+		field := marcField{tag: data.Tag, ind1: indicator(data.Ind1), ind2: indicator(data.Ind2)}
+		for _, subfield := range data.Subfields {
+			if subfield.Code == "" {
+				continue
+			}
+			field.subfields = append(field.subfields, marcSubfield{code: subfield.Code[0], value: subfield.Value})
+		}
+		record.fields = append(record.fields, field)
+	}
+	return record
+}
+
+func indicator(value string) byte {
+	if value == "" {
+		return ' '
+	}
+	return value[0]
+}
+
+type marcXMLWriter struct {
+	writer  *bufio.Writer
+	encoder *xml.Encoder
+	started bool
+}
+
+func newMARCXMLWriter(w io.Writer) *marcXMLWriter {
+	buffered := bufio.NewWriter(w)
+	encoder := xml.NewEncoder(buffered)
+	encoder.Indent("  ", "  ")
+	return &marcXMLWriter{writer: buffered, encoder: encoder}
+}
+
+func (w *marcXMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.writer.WriteString(xml.Header + `<collection xmlns="` + marcXMLNamespace + `">` + "\n")
+	return err
+}
+
+func (w *marcXMLWriter) Write(record *Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	marc := toMARC(record)
+	element := xmlRecord{Leader: "00000nam a2200000 i 4500"}
+	for _, field := range marc.fields {
+		if field.isControl() {
+			element.ControlFields = append(element.ControlFields, xmlControlField{Tag: field.tag, Value: field.value})
+			continue
+		}
+		data := xmlDataField{Tag: field.tag, Ind1: string(field.ind1), Ind2: string(field.ind2)}
+		for _, subfield := range field.subfields {
+			data.Subfields = append(data.Subfields, xmlSubfield{Code: string(subfield.code), Value: subfield.value})
+		}
+		element.DataFields = append(element.DataFields, data)
+	}
+	return w.encoder.Encode(element)
+}
+
+func (w *marcXMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.writer.WriteString("\n</collection>\n"); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -13,6 +15,18 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Log      LogConfig      `mapstructure:"log"`
+	Circulation CirculationConfig `mapstructure:"circulation"`
+	Metadata    MetadataConfig    `mapstructure:"metadata"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Covers      CoverConfig       `mapstructure:"covers"`
+	Reviews     ReviewConfig      `mapstructure:"reviews"`
+	Recommendations RecommendationConfig `mapstructure:"recommendations"`
+	Duplicates      DuplicateConfig      `mapstructure:"duplicates"`
+	Ebooks          EbookConfig          `mapstructure:"ebooks"`
+	Mail            MailConfig           `mapstructure:"mail"`
+	SavedSearches   SavedSearchConfig    `mapstructure:"saved_searches"`
+	Memberships     MembershipConfig     `mapstructure:"memberships"`
+	Payments        PaymentConfig        `mapstructure:"payments"`
 }
 
 // App 应用配置
@@ -68,6 +82,95 @@ type LogConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// CirculationConfig 借阅规则配置
+type CirculationConfig struct {
+	LoanDays    int `mapstructure:"loan_days"`    // 借期（天）
+	MaxRenewals int `mapstructure:"max_renewals"` // 最大续借次数
+
+	HoldShelfDays      int           `mapstructure:"hold_shelf_days"`      // 预约到书后的保留天数
+	HoldExpiryInterval time.Duration `mapstructure:"hold_expiry_interval"` // 检查过期预约的间隔
+}
+
+// MetadataConfig 外部书目查询配置
+type MetadataConfig struct {
+	Provider string        `mapstructure:"provider"`  // openlibrary 或 fake，留空关闭外部查询
+	BaseURL  string        `mapstructure:"base_url"`  // 外部书目服务地址
+	Timeout  time.Duration `mapstructure:"timeout"`   // 单次查询超时
+	CacheTTL time.Duration `mapstructure:"cache_ttl"` // 查询结果缓存时间，为 0 时不缓存
+}
+
+// StorageConfig 对象存储配置（封面图片等上传文件）
+type StorageConfig struct {
+	Driver   string `mapstructure:"driver"`    // 存储实现，目前支持 local（默认）
+	LocalDir string `mapstructure:"local_dir"` // local 存储的根目录
+}
+
+// CoverConfig 图书封面配置
+type CoverConfig struct {
+	MaxSize           int64         `mapstructure:"max_size"`            // 封面原图的最大字节数
+	FetchTimeout      time.Duration `mapstructure:"fetch_timeout"`       // 从 URL 下载封面的超时
+	AllowPrivateHosts bool          `mapstructure:"allow_private_hosts"` // 是否允许从回环、内网地址下载封面
+}
+
+// ReviewConfig 书评审核配置
+type ReviewConfig struct {
+	RequireApproval bool     `mapstructure:"require_approval"` // 所有书评都需审核后公开；为 false 时只有命中屏蔽词的书评需要审核
+	BannedWords     []string `mapstructure:"banned_words"`     // 屏蔽词，不区分大小写
+}
+
+// RecommendationConfig 相似图书推荐配置
+type RecommendationConfig struct {
+	Interval        time.Duration `mapstructure:"interval"`           // 重新计算相似度的间隔
+	Window          time.Duration `mapstructure:"window"`             // 参与计算的交互记录时间范围，更早的记录会被清理
+	MinTogether     int           `mapstructure:"min_together"`       // 至少有多少位读者同时看过两本书才认为相似
+	PerBook         int           `mapstructure:"per_book"`           // 每本书保存的相似图书数量
+	MaxBooksPerUser int           `mapstructure:"max_books_per_user"` // 看过的书超过此数的读者（多为馆员或爬虫）不参与计算
+}
+
+// DuplicateConfig 重复图书检测配置
+type DuplicateConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`  // 重新检测的间隔
+	Threshold float64       `mapstructure:"threshold"` // 书名和作者的综合相似度达到此值（0 到 1）才加入审核队列
+}
+
+// EbookConfig 电子书借阅配置
+type EbookConfig struct {
+	MaxSize       int64         `mapstructure:"max_size"`       // 电子书文件的最大字节数
+	LinkTTL       time.Duration `mapstructure:"link_ttl"`       // 下载链接的有效期，期间占用该书的一个并发名额
+	MaxConcurrent int           `mapstructure:"max_concurrent"` // 每本书同时持有有效下载链接的读者数上限，0 表示使用默认值
+	SigningKey    string        `mapstructure:"signing_key"`    // 下载链接的签名密钥，为空时从 jwt.key 派生并在启动时告警
+}
+
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"` // 发送方式：log（默认，只写日志）或 smtp
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"` // 默认 587
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"` // 发件人地址
+}
+
+// SavedSearchConfig 保存的检索及新书提醒配置
+type SavedSearchConfig struct {
+	Interval   time.Duration `mapstructure:"interval"`     // 检查到期检索的间隔
+	MaxResults int           `mapstructure:"max_results"`  // 每条通知或邮件中最多列出的图书数
+	MaxPerUser int           `mapstructure:"max_per_user"` // 每位读者最多保存的检索数
+}
+
+// MembershipConfig 会员资格配置
+type MembershipConfig struct {
+	Required       bool          `mapstructure:"required"`        // 未办理会员的读者是否也不能借阅、预约和下载电子书
+	ExpiryInterval time.Duration `mapstructure:"expiry_interval"` // 检查宽限期已过的会员资格的间隔
+}
+
+// PaymentConfig 在线支付配置
+type PaymentConfig struct {
+	Provider      string `mapstructure:"provider"`       // 支付渠道，目前支持 fake（默认，仅用于开发和测试）
+	Currency      string `mapstructure:"currency"`       // ISO 4217 币种，金额均以该币种的最小单位（分）记录
+	WebhookSecret string `mapstructure:"webhook_secret"` // 校验 webhook 签名的密钥
+}
+
 var AppConfig Config
 
 func Load() (*Config, error) {
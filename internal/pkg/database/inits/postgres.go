@@ -12,9 +12,6 @@ type PostgreSQLInitializer struct {
 }
 
 func (p *PostgreSQLInitializer) Initialize() error {
-	// 使用新的配置方法获取连接信息
-	dbURL := p.config.Database.GetDatabaseURL()
-	
 	// 构建没有特定数据库的DSN用于初始连接
 	var initialDSN string
 	if p.config.Database.URL != "" {
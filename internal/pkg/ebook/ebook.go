@@ -0,0 +1,247 @@
+// Package ebook 识别 EPUB、PDF 电子书文件，并从 EPUB 的 OPF 包文件中读取书目信息，只依赖标准库
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Format 电子书文件格式
+type Format string
+
+const (
+	FormatEPUB Format = "epub"
+	FormatPDF  Format = "pdf"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported e-book format")
+	ErrInvalidEPUB       = errors.New("invalid EPUB file")
+)
+
+// ContentType 下载时使用的 MIME 类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+const epubMimeType = "application/epub+zip"
+
+// Detect 按文件内容识别格式：PDF 以 "%PDF-" 开头，EPUB 是第一个条目为 mimetype 的 ZIP 包
+func Detect(data []byte) (Format, error) {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return FormatPDF, nil
+	}
+	// EPUB 规范要求 mimetype 不压缩且位于偏移 30 处（紧跟第一个本地文件头）
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) && len(data) > 30+len("mimetype")+len(epubMimeType) &&
+		string(data[30:30+len("mimetype")]) == "mimetype" &&
+		string(data[38:38+len(epubMimeType)]) == epubMimeType {
+		return FormatEPUB, nil
+	}
+	// 不严格遵守规范的打包工具可能压缩 mimetype 或调整顺序，再按 ZIP 内容检查一次
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+			if content, err := readEntry(archive, "mimetype", 64); err == nil && strings.TrimSpace(string(content)) == epubMimeType {
+				return FormatEPUB, nil
+			}
+		}
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Metadata OPF 中的书目信息，缺少的字段为空
+type Metadata struct {
+	Title       string
+	Subtitle    string
+	Creators    []string
+	ISBN        string // 标识符中第一个形如 ISBN 的值，未校验
+	Publisher   string
+	Date        string // 原样保留，常见 YYYY、YYYY-MM-DD 或完整时间戳
+	Language    string
+	Description string // 已去掉 HTML 标签
+}
+
+const (
+	maxContainerSize = 64 << 10
+	maxPackageSize   = 4 << 20
+)
+
+// ReadEPUBMetadata 读取 META-INF/container.xml 找到 OPF 包文件，并解析其中的 Dublin Core 元数据
+func ReadEPUBMetadata(data []byte) (*Metadata, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEPUB, err)
+	}
+
+	containerXML, err := readEntry(archive, "META-INF/container.xml", maxContainerSize)
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(containerXML, &container); err != nil {
+		return nil, fmt.Errorf("%w: container.xml: %v", ErrInvalidEPUB, err)
+	}
+	packagePath := ""
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType == "" || rootfile.MediaType == "application/oebps-package+xml" {
+			packagePath = rootfile.FullPath
+			break
+		}
+	}
+	if packagePath == "" {
+		return nil, fmt.Errorf("%w: no package document", ErrInvalidEPUB)
+	}
+
+	opf, err := readEntry(archive, path.Clean(packagePath), maxPackageSize)
+	if err != nil {
+		return nil, err
+	}
+	return parsePackage(opf)
+}
+
+// opfPackage OPF 中用到的部分。encoding/xml 按本地名匹配，dc: 前缀的元素都能读到
+type opfPackage struct {
+	Metadata struct {
+		Titles []struct {
+			ID    string `xml:"id,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"title"`
+		Creators []struct {
+			Value string `xml:",chardata"`
+		} `xml:"creator"`
+		Identifiers []struct {
+			Scheme string `xml:"scheme,attr"`
+			Value  string `xml:",chardata"`
+		} `xml:"identifier"`
+		Publisher   []string `xml:"publisher"`
+		Date        []string `xml:"date"`
+		Language    []string `xml:"language"`
+		Description []string `xml:"description"`
+		Meta        []struct {
+			Refines  string `xml:"refines,attr"`
+			Property string `xml:"property,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+}
+
+var isbnPattern = regexp.MustCompile(`(?i)(?:^|[^0-9])((?:97[89][- ]?)?(?:[0-9][- ]?){9}[0-9X])(?:$|[^0-9])`)
+
+func parsePackage(opf []byte) (*Metadata, error) {
+	var pkg opfPackage
+	decoder := xml.NewDecoder(bytes.NewReader(opf))
+	// 部分旧文件声明 ISO-8859-1 等编码，按原字节处理即可满足常见的 ASCII 元数据
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("%w: package document: %v", ErrInvalidEPUB, err)
+	}
+	m := pkg.Metadata
+
+	// EPUB 3 用 refines 标注标题类型，subtitle 单独取出，其余标题中的第一个作为书名
+	titleTypes := make(map[string]string)
+	for _, meta := range m.Meta {
+		if meta.Property == "title-type" {
+			titleTypes[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
+	}
+	result := &Metadata{}
+	for _, title := range m.Titles {
+		value := clean(title.Value)
+		if value == "" {
+			continue
+		}
+		if title.ID != "" && titleTypes[title.ID] == "subtitle" {
+			if result.Subtitle == "" {
+				result.Subtitle = value
+			}
+		} else if result.Title == "" {
+			result.Title = value
+		}
+	}
+	for _, creator := range m.Creators {
+		if value := clean(creator.Value); value != "" {
+			result.Creators = append(result.Creators, value)
+		}
+	}
+	for _, identifier := range m.Identifiers {
+		value := clean(identifier.Value)
+		if strings.EqualFold(identifier.Scheme, "ISBN") || strings.HasPrefix(strings.ToLower(value), "urn:isbn:") || isbnPattern.MatchString(value) {
+			if match := isbnPattern.FindStringSubmatch(value); match != nil {
+				result.ISBN = match[1]
+				break
+			}
+		}
+	}
+	result.Publisher = first(m.Publisher)
+	result.Date = first(m.Date)
+	result.Language = first(m.Language)
+	result.Description = stripTags(first(m.Description))
+	return result, nil
+}
+
+func readEntry(archive *zip.Reader, name string, limit int64) ([]byte, error) {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidEPUB, name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidEPUB, name, err)
+		}
+		if int64(len(content)) > limit {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidEPUB, name)
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("%w: missing %s", ErrInvalidEPUB, name)
+}
+
+func first(values []string) string {
+	for _, value := range values {
+		if value = clean(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// clean 合并连续空白
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var (
+	// blockTagPattern 段落、换行等块级标签换成空格，其余标签直接去掉，避免在 <b>、<i> 等行内标签处多出空格
+	blockTagPattern = regexp.MustCompile(`(?i)</?(?:p|br|div|li|ul|ol|h[1-6]|blockquote|tr|td)\b[^>]*>`)
+	tagPattern      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripTags 描述中常带有 HTML 片段（有时已被转义一次），只保留文字
+func stripTags(s string) string {
+	s = html.UnescapeString(s)
+	s = blockTagPattern.ReplaceAllString(s, " ")
+	return clean(html.UnescapeString(tagPattern.ReplaceAllString(s, "")))
+}
@@ -0,0 +1,71 @@
+// Package imaging 提供生成缩略图所需的简单图像处理，只依赖标准库
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit 计算在不放大的前提下，按原始宽高比缩放到 maxWidth×maxHeight 以内的尺寸
+func Fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	w, h := maxWidth, height*maxWidth/width
+	if h > maxHeight {
+		w, h = width*maxHeight/height, maxHeight
+	}
+	return max(w, 1), max(h, 1)
+}
+
+// Thumbnail 把图像缩放到 maxWidth×maxHeight 以内（不放大），使用区域平均采样。
+// 透明区域以 background 填充，返回的图像不透明，可以直接编码为 JPEG
+func Thumbnail(src image.Image, maxWidth, maxHeight int, background color.Color) *image.RGBA {
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	width, height := Fit(bounds.Dx(), bounds.Dy(), maxWidth, maxHeight)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return flat
+	}
+	return downscale(flat, width, height)
+}
+
+// downscale 对每个目标像素取其在原图中覆盖区域的平均值
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, (y+1)*srcH/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, (x+1)*srcW/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,251 @@
+// Package labelsheet 把条码标签按网格排版成可直接打印的 PDF，只依赖标准库。
+// 文字使用 PDF 内置的 Helvetica 字体，只能印出 WinAnsi 字符集中的字符；
+// 含有其他字符（如中文书名）的文字行整行略去，标签上只保留条码和其余文字行，不会印出乱码
+package labelsheet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+)
+
+// Paper 纸张尺寸，单位为 PostScript 点（1/72 英寸）
+type Paper struct {
+	Width  float64
+	Height float64
+}
+
+var (
+	PaperA4     = Paper{Width: 595.28, Height: 841.89}
+	PaperLetter = Paper{Width: 612, Height: 792}
+)
+
+var ErrInvalidLayout = errors.New("invalid label sheet layout")
+
+const (
+	defaultMargin = 36 // 半英寸页边距
+	cellPadding   = 4
+	fontSize      = 7
+	lineHeight    = fontSize * 1.2
+	// charWidth Helvetica 平均字宽约为字号的一半，用于估算截断长度
+	charWidth = fontSize * 0.5
+)
+
+// Layout 每页 Columns×Rows 个标签
+type Layout struct {
+	Paper   Paper
+	Columns int
+	Rows    int
+	Margin  float64 // 为 0 时使用半英寸
+}
+
+// Label 一个标签：条码及印在条码下方的若干行文字，无法用 WinAnsi 编码的行不印出
+type Label struct {
+	Code  *barcode.Barcode
+	Lines []string
+}
+
+// Write 按版式输出 PDF，标签依次从左到右、从上到下填满每一页
+func Write(w io.Writer, layout Layout, labels []Label) error {
+	if layout.Columns < 1 || layout.Rows < 1 || layout.Paper.Width <= 0 || layout.Paper.Height <= 0 {
+		return ErrInvalidLayout
+	}
+	if layout.Margin <= 0 {
+		layout.Margin = defaultMargin
+	}
+	cellWidth := (layout.Paper.Width - 2*layout.Margin) / float64(layout.Columns)
+	cellHeight := (layout.Paper.Height - 2*layout.Margin) / float64(layout.Rows)
+	if cellWidth <= 2*cellPadding || cellHeight <= 2*cellPadding {
+		return ErrInvalidLayout
+	}
+
+	perPage := layout.Columns * layout.Rows
+	var pages [][]byte
+	for start := 0; start < len(labels) || start == 0; start += perPage {
+		end := min(start+perPage, len(labels))
+		var content bytes.Buffer
+		for i, label := range labels[start:end] {
+			x := layout.Margin + float64(i%layout.Columns)*cellWidth
+			// PDF 坐标原点在左下角，第一行标签在页面顶部
+			y := layout.Paper.Height - layout.Margin - float64(i/layout.Columns+1)*cellHeight
+			drawLabel(&content, label, x, y, cellWidth, cellHeight)
+		}
+		pages = append(pages, content.Bytes())
+	}
+	return writeDocument(w, layout.Paper, pages)
+}
+
+// drawLabel 在 (x, y) 为左下角的格子内绘制标签：条码居上，文字在下
+func drawLabel(out *bytes.Buffer, label Label, x, y, width, height float64) {
+	innerWidth := width - 2*cellPadding
+	var lines []string
+	for _, line := range label.Lines {
+		if line != "" && encodable(line) {
+			lines = append(lines, line)
+		}
+	}
+	maxLines := int((height - 2*cellPadding) / 2 / lineHeight)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	textHeight := float64(len(lines)) * lineHeight
+
+	maxChars := int(innerWidth / charWidth)
+	for i, line := range lines {
+		baseline := y + cellPadding + textHeight - float64(i+1)*lineHeight + (lineHeight - fontSize)
+		fmt.Fprintf(out, "BT /F1 %d Tf %s %s Td (%s) Tj ET\n",
+			fontSize, num(x+cellPadding), num(baseline), pdfString(truncate(line, maxChars)))
+	}
+
+	if label.Code != nil {
+		drawBarcode(out, label.Code, x+cellPadding, y+cellPadding+textHeight, innerWidth, height-2*cellPadding-textHeight)
+	}
+}
+
+// drawBarcode 把条码缩放到给定区域内居中绘制，每段连续的深色模块画成一个矩形
+func drawBarcode(out *bytes.Buffer, code *barcode.Barcode, x, y, width, height float64) {
+	if height <= 0 {
+		return
+	}
+	modulesWide := float64(code.Width + 2*code.QuietZone)
+	module := width / modulesWide
+	barHeight := height
+	if !code.IsLinear() {
+		module = min(module, height/float64(code.Height+2*code.QuietZone))
+		barHeight = module * float64(code.Height)
+	}
+	left := x + (width-module*modulesWide)/2 + float64(code.QuietZone)*module
+	top := y + height
+	if !code.IsLinear() {
+		top = y + (height+barHeight)/2
+	}
+
+	out.WriteString("0 g\n")
+	if code.IsLinear() {
+		for _, run := range code.Runs(0) {
+			fmt.Fprintf(out, "%s %s %s %s re\n", num(left+float64(run[0])*module), num(y), num(float64(run[1])*module), num(barHeight))
+		}
+	} else {
+		for row := 0; row < code.Height; row++ {
+			for _, run := range code.Runs(row) {
+				fmt.Fprintf(out, "%s %s %s %s re\n", num(left+float64(run[0])*module), num(top-float64(row+1)*module), num(float64(run[1])*module), num(module))
+			}
+		}
+	}
+	out.WriteString("f\n")
+}
+
+// writeDocument 写出文档结构：1 为目录，2 为页树，3 为字体，之后每页依次占用页面和内容流两个对象
+func writeDocument(w io.Writer, paper Paper, pages [][]byte) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			num(paper.Width), num(paper.Height), 5+2*i))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// num 以最多两位小数输出坐标
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// truncate 超出宽度的文字截断并以 ... 结尾
+func truncate(s string, maxChars int) string {
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s
+	}
+	if maxChars <= 3 {
+		return string(runes[:max(maxChars, 0)])
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// winAnsiExtra WinAnsi 在 0x80–0x9F 区间额外收录的字符
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// winAnsi 返回字符在 WinAnsi 中的编码
+func winAnsi(r rune) (byte, bool) {
+	switch {
+	case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		// 可打印 ASCII 和 0xA0 以上的部分与 Latin-1 一致
+		return byte(r), true
+	}
+	b, ok := winAnsiExtra[r]
+	return b, ok
+}
+
+// encodable 文字的每个字符都能用 WinAnsi 编码
+func encodable(s string) bool {
+	for _, r := range s {
+		if _, ok := winAnsi(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pdfString 转为 WinAnsi 编码的 PDF 字面字符串内容，调用方需先用 encodable 检查
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		b, _ := winAnsi(r)
+		switch {
+		case b == '\\' || b == '(' || b == ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b < 0x7f:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "\\%03o", b)
+		}
+	}
+	return sb.String()
+}
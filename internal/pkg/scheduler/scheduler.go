@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Job 定时任务
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler 简单的进程内定时任务调度器，每个任务在独立的 goroutine 中按固定间隔执行
+type Scheduler struct {
+	entries []entry
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every 注册一个按固定间隔执行的任务，需在 Start 之前调用
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start 启动所有任务，ctx 取消后任务停止
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, e := range s.entries {
+		go s.loop(ctx, e)
+	}
+	logger.Info("Scheduler started", zap.Int("jobs", len(s.entries)))
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, e)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("scheduled job panicked", zap.String("job", e.name), zap.Any("panic", r))
+		}
+	}()
+
+	start := time.Now()
+	if err := e.job(ctx); err != nil {
+		logger.Error("scheduled job failed", zap.String("job", e.name), zap.Error(err))
+		return
+	}
+	logger.Debug("scheduled job finished", zap.String("job", e.name), zap.Duration("latency", time.Since(start)))
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+)
+
+// TestReceiveAcquisitionUpdatesExistingBook 到货的 ISBN 已在馆藏中时，填写的书名和作者经由 BookService 写到已有图书上并留下修订
+func TestReceiveAcquisitionUpdatesExistingBook(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	books := services.NewBookService(repo, nil, eventbus.New())
+	acquisitions := services.NewAcquisitionService(repo, books)
+	ctx := context.Background()
+
+	book, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{ISBN: "9780441172719", Title: "Dune", Author: "F. Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := orderedAcquisition(t, acquisitions, "Dune")
+	received, err := acquisitions.Receive(ctx, 1, id, &dto.ReceiveAcquisitionRequest{
+		ISBN: "978-0-441-17271-9", Title: "Dune (Deluxe Edition)", Author: "Frank Herbert",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.BookID == nil || *received.BookID != book.ID {
+		t.Fatalf("acquisition linked to %v, want book %d", received.BookID, book.ID)
+	}
+	if received.Title != "Dune (Deluxe Edition)" || received.Author != "Frank Herbert" {
+		t.Fatalf("acquisition metadata: %q by %q", received.Title, received.Author)
+	}
+
+	updated, err := repo.GetBook(int(book.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Dune (Deluxe Edition)" || updated.Author != "Frank Herbert" {
+		t.Fatalf("book metadata not applied: %q by %q", updated.Title, updated.Author)
+	}
+	assertRevisions(t, repo, book.ID, entities.BookRevisionUpdate, entities.BookRevisionCreate)
+}
+
+// TestReceiveAcquisitionIsAtomic 采购的状态转换失败时，到货时新建或修改的图书一并回滚
+func TestReceiveAcquisitionIsAtomic(t *testing.T) {
+	repo, db := newTestRepository(t)
+	books := services.NewBookService(repo, nil, eventbus.New())
+	acquisitions := services.NewAcquisitionService(repo, books)
+	ctx := context.Background()
+
+	book, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{ISBN: "9780441172719", Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := orderedAcquisition(t, acquisitions, "Dune")
+	created := orderedAcquisition(t, acquisitions, "Children of Dune")
+
+	// 只让采购的更新失败，模拟采购已被其他请求改动
+	if err := db.Exec("CREATE TRIGGER block_acquisitions BEFORE UPDATE ON acquisitions BEGIN SELECT RAISE(ABORT, 'acquisitions locked'); END").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := acquisitions.Receive(ctx, 1, existing, &dto.ReceiveAcquisitionRequest{ISBN: "9780441172719", Title: "Dune (Deluxe Edition)"}); err == nil {
+		t.Fatal("expected receive to fail")
+	}
+	unchanged, err := repo.GetBook(int(book.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.Title != "Dune" {
+		t.Fatalf("book title changed to %q although the acquisition was not received", unchanged.Title)
+	}
+	assertRevisions(t, repo, book.ID, entities.BookRevisionCreate)
+
+	if _, err := acquisitions.Receive(ctx, 1, created, &dto.ReceiveAcquisitionRequest{ISBN: "9780441104024", Author: "Frank Herbert"}); err == nil {
+		t.Fatal("expected receive to fail")
+	}
+	if _, err := repo.GetBookByISBN("9780441104024"); err == nil {
+		t.Fatal("book was created although the acquisition was not received")
+	}
+
+	for _, id := range []int{existing, created} {
+		acquisition, err := repo.GetAcquisition(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acquisition.Status != entities.AcquisitionStatusOrdered || acquisition.BookID != nil {
+			t.Fatalf("acquisition %d: status %s, book %v", id, acquisition.Status, acquisition.BookID)
+		}
+	}
+}
+
+// orderedAcquisition 由 Bob 荐购、Alice 审核并下单，返回采购 ID
+func orderedAcquisition(t *testing.T, acquisitions *services.AcquisitionService, title string) int {
+	t.Helper()
+	suggested, err := acquisitions.Suggest(context.Background(), 2, &dto.SuggestAcquisitionRequest{Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := int(suggested.ID)
+	if _, err := acquisitions.Approve(1, id, &dto.AcquisitionNoteRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquisitions.Order(1, id, &dto.OrderAcquisitionRequest{Vendor: "Ace", Quantity: 1, Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+)
+
+// TestEncodeEAN13CheckDigit 12 位输入补上校验位，13 位输入的校验位必须正确
+func TestEncodeEAN13CheckDigit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"978030640615", "9780306406157", nil},
+		{"400638133393", "4006381333931", nil},
+		{"979103200018", "9791032000182", nil},
+		{"978000000000", "9780000000002", nil},
+		{"9780306406157", "9780306406157", nil},
+		{"9780306406158", "", barcode.ErrInvalidContent},
+		{"97803064061a", "", barcode.ErrInvalidContent},
+		{"97803064", "", barcode.ErrInvalidContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			code, err := barcode.EncodeEAN13(tt.input)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.Text != tt.want {
+				t.Fatalf("got %s, want %s", code.Text, tt.want)
+			}
+			// 起始符、中间分隔符和终止符各占固定位置
+			modules := moduleString(code)
+			if len(modules) != 95 || modules[:3] != "101" || modules[45:50] != "01010" || modules[92:] != "101" {
+				t.Fatalf("guard patterns missing: %s", modules)
+			}
+		})
+	}
+}
+
+// TestEncodeCode128 按码值表核对条空序列：偶数位纯数字用 C 字符集，其余用 B 字符集，校验符按加权和取模
+func TestEncodeCode128(t *testing.T) {
+	tests := []struct {
+		input  string
+		values []string // 每个码值的条空宽度，依次为起始符、数据、校验符、终止符
+	}{
+		// C 字符集：105 12 34 56 78，校验 (105+12+68+168+312)%103 = 47
+		{"12345678", []string{"211232", "112232", "131123", "331121", "241112", "133121", "2331112"}},
+		// B 字符集：104 P J J 1 2 3 C，校验 879%103 = 55
+		{"PJJ123C", []string{"211214", "313121", "112133", "112133", "123221", "223211", "221132", "131321", "311321", "2331112"}},
+		// 奇数位数字不能两两成组，使用 B 字符集：104 1 2 3，校验 (104+17+36+57)%103 = 8
+		{"123", []string{"211214", "123221", "223211", "221132", "132212", "2331112"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			code, err := barcode.EncodeCode128(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want strings.Builder
+			for _, widths := range tt.values {
+				for i, c := range widths {
+					bit := "1"
+					if i%2 == 1 {
+						bit = "0"
+					}
+					want.WriteString(strings.Repeat(bit, int(c-'0')))
+				}
+			}
+			if got := moduleString(code); got != want.String() {
+				t.Fatalf("modules:\n got %s\nwant %s", got, want.String())
+			}
+		})
+	}
+
+	for _, input := range []string{"", "tab\there", "日本"} {
+		if _, err := barcode.EncodeCode128(input); !errors.Is(err, barcode.ErrInvalidContent) {
+			t.Errorf("%q: expected ErrInvalidContent, got %v", input, err)
+		}
+	}
+	if _, err := barcode.EncodeCode128(strings.Repeat("A", 81)); !errors.Is(err, barcode.ErrContentTooLong) {
+		t.Errorf("expected ErrContentTooLong, got %v", err)
+	}
+}
+
+// TestEncodeQRVersion 选择能容纳内容的最小版本（M 级纠错下的字节容量），
+// 并在格式信息中写入 M 级纠错和所选掩码，版本 7 起写入版本信息
+func TestEncodeQRVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{1, 1}, {14, 1}, {15, 2}, {26, 2}, {27, 3}, {42, 3}, {43, 4}, {62, 4},
+		{63, 5}, {84, 5}, {106, 6}, {122, 7}, {123, 8}, {180, 9}, {181, 10}, {213, 10},
+	}
+	for _, tt := range tests {
+		code, err := barcode.EncodeQR(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tt.length, err)
+		}
+		if want := 17 + 4*tt.version; code.Width != want || code.Height != want {
+			t.Fatalf("%d bytes: size %dx%d, want version %d (%d)", tt.length, code.Width, code.Height, tt.version, want)
+		}
+
+		format := qrFormatBits(code)
+		if ecl := format >> 3; ecl != 0 {
+			t.Fatalf("%d bytes: error correction level bits %02b, want M (00)", tt.length, ecl)
+		}
+		if tt.version >= 7 {
+			if got := qrVersionBits(code); got != tt.version {
+				t.Fatalf("%d bytes: version information %d, want %d", tt.length, got, tt.version)
+			}
+		}
+	}
+
+	if _, err := barcode.EncodeQR(strings.Repeat("a", 214)); !errors.Is(err, barcode.ErrContentTooLong) {
+		t.Fatalf("expected ErrContentTooLong, got %v", err)
+	}
+}
+
+// moduleString 一维码的模块序列，深色为 1
+func moduleString(code *barcode.Barcode) string {
+	var sb strings.Builder
+	for x := 0; x < code.Width; x++ {
+		if code.Dark(x, 0) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// qrFormatBits 读取左上角的格式信息，去掉固定掩码并校验 BCH 码后返回 5 位数据（纠错级别和掩码编号），
+// 右上和左下的副本必须与之一致
+func qrFormatBits(code *barcode.Barcode) int {
+	size := code.Width
+	read := func(coords [][2]int) int {
+		bits := 0
+		for i, c := range coords {
+			if code.Dark(c[0], c[1]) {
+				bits |= 1 << i
+			}
+		}
+		return bits
+	}
+
+	var primary, secondary [][2]int
+	for i := 0; i <= 5; i++ {
+		primary = append(primary, [2]int{8, i})
+	}
+	primary = append(primary, [2]int{8, 7}, [2]int{8, 8}, [2]int{7, 8})
+	for i := 9; i < 15; i++ {
+		primary = append(primary, [2]int{14 - i, 8})
+	}
+	for i := 0; i < 8; i++ {
+		secondary = append(secondary, [2]int{size - 1 - i, 8})
+	}
+	for i := 8; i < 15; i++ {
+		secondary = append(secondary, [2]int{8, size - 15 + i})
+	}
+
+	bits := read(primary)
+	if read(secondary) != bits {
+		return -1
+	}
+	bits ^= 0x5412
+	data := bits >> 10
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	if bits != data<<10|rem {
+		return -1
+	}
+	return data
+}
+
+// qrVersionBits 读取右上角的版本信息（BCH(18,6) 的高 6 位）
+func qrVersionBits(code *barcode.Barcode) int {
+	bits := 0
+	for i := 0; i < 18; i++ {
+		if code.Dark(code.Width-11+i%3, i/3) {
+			bits |= 1 << i
+		}
+	}
+	return bits >> 12
+}
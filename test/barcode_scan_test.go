@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+)
+
+// TestScanEAN13Corpus 逐个识别 testdata/barcodes 下的图片。
+// 文件名以期望的 13 位条码开头；以 none_ 开头的图片中没有 EAN-13，应当识别失败
+func TestScanEAN13Corpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "barcodes", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("fixture corpus is empty")
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			img := loadImage(t, file)
+			got, err := barcode.ScanEAN13(img)
+
+			if strings.HasPrefix(name, "none_") {
+				if !errors.Is(err, barcode.ErrNotFound) {
+					t.Fatalf("expected no barcode, got %q (err %v)", got, err)
+				}
+				return
+			}
+			want, _, _ := strings.Cut(name, "_")
+			if err != nil {
+				t.Fatalf("scan failed: %v", err)
+			}
+			if got != want {
+				t.Fatalf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestScanEAN13RoundTrip 生成的条码图片应能被识别回原内容
+func TestScanEAN13RoundTrip(t *testing.T) {
+	for _, isbn := range []string{"9780000000002", "9781234567897", "9799999999990", "4006381333931"} {
+		code, err := barcode.EncodeEAN13(isbn)
+		if err != nil {
+			t.Fatalf("encode %s: %v", isbn, err)
+		}
+		got, err := barcode.ScanEAN13(code.Image(barcode.RenderOptions{Scale: 2}))
+		if err != nil || got != isbn {
+			t.Fatalf("round trip %s: got %q, err %v", isbn, got, err)
+		}
+	}
+}
+
+func loadImage(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/events"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+// TestMergeBooksKeepsOneHoldPerReader 读者在两本书上都有预约时合并后只保留一个：
+// 已到书的优先于排队的；两个都已到书时取消被合并图书上的，空出的副本分配给队首的预约
+func TestMergeBooksKeepsOneHoldPerReader(t *testing.T) {
+	repo, db := newTestRepository(t)
+	if err := db.Exec("INSERT INTO users (name, email, password, role) VALUES ('Carol', 'carol@example.com', 'x', 'user')").Error; err != nil {
+		t.Fatal(err)
+	}
+	bus := eventbus.New()
+	books := services.NewBookService(repo, nil, bus)
+	memberships := services.NewMembershipService(repo, config.MembershipConfig{})
+	circulation := services.NewCirculationService(repo, memberships, config.CirculationConfig{}, bus)
+	holds := services.NewHoldService(repo, memberships, config.CirculationConfig{HoldShelfDays: 3}, bus)
+	bus.Subscribe(events.CopyAvailableEvent, holds.HandleCopyAvailable)
+	merges := services.NewBookMergeService(repo, books, bus, config.DuplicateConfig{})
+
+	survivor := &entities.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719"}
+	duplicate := &entities.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"}
+	for _, book := range []*entities.Book{survivor, duplicate} {
+		if err := repo.CreateBook(book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addCopy := func(bookID uint, barcode string) uint {
+		bookCopy, err := circulation.AddCopy(int(bookID), &dto.CreateBookCopyRequest{Barcode: barcode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&entities.BookCopy{}).Where("id = ?", bookCopy.ID).Update("status", entities.CopyStatusOnHold).Error; err != nil {
+			t.Fatal(err)
+		}
+		return bookCopy.ID
+	}
+	s1, d1, d2 := addCopy(survivor.ID, "S1"), addCopy(duplicate.ID, "D1"), addCopy(duplicate.ID, "D2")
+
+	now := time.Now()
+	placeHold := func(bookID, userID uint, copyID *uint) uint {
+		hold := entities.NewHold(bookID, userID, now)
+		if copyID != nil {
+			hold.MarkReady(*copyID, now, time.Hour)
+		}
+		if err := db.Create(hold).Error; err != nil {
+			t.Fatal(err)
+		}
+		return hold.ID
+	}
+	bobReady := placeHold(duplicate.ID, 2, &d1)
+	bobWaiting := placeHold(survivor.ID, 2, nil)
+	aliceKept := placeHold(survivor.ID, 1, &s1)
+	aliceDropped := placeHold(duplicate.ID, 1, &d2)
+	carol := placeHold(survivor.ID, 3, nil)
+
+	if _, err := merges.MergeBook(1, int(survivor.ID), &dto.MergeBookRequest{DuplicateID: duplicate.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uint]struct {
+		status string
+		copyID *uint
+	}{
+		bobReady:     {entities.HoldStatusReady, &d1},
+		bobWaiting:   {entities.HoldStatusCancelled, nil},
+		aliceKept:    {entities.HoldStatusReady, &s1},
+		aliceDropped: {entities.HoldStatusCancelled, &d2},
+		carol:        {entities.HoldStatusReady, &d2},
+	}
+	for id, w := range want {
+		hold, err := repo.GetHold(int(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hold.BookID != survivor.ID || hold.Status != w.status || (w.copyID != nil && (hold.CopyID == nil || *hold.CopyID != *w.copyID)) {
+			t.Errorf("hold %d of user %d: book %d, status %s, copy %v; want %s on copy %v", id, hold.UserID, hold.BookID, hold.Status, hold.CopyID, w.status, w.copyID)
+		}
+	}
+
+	var doubled int64
+	err := db.Model(&entities.Hold{}).
+		Where("status IN ?", []string{entities.HoldStatusWaiting, entities.HoldStatusReady}).
+		Group("book_id, user_id").Having("COUNT(*) > 1").Count(&doubled).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doubled != 0 {
+		t.Fatalf("%d readers still have two active holds on one book", doubled)
+	}
+}
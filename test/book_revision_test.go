@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+)
+
+// TestBookWritesAreAtomicWithRevisions 修订写入失败时，图书的新建、修改和删除应一起回滚
+func TestBookWritesAreAtomicWithRevisions(t *testing.T) {
+	repo, db := newTestRepository(t)
+	books := services.NewBookService(repo, nil, eventbus.New())
+
+	created, err := books.CreateBook(context.Background(), 1, &dto.CreateBookRequest{
+		Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revisions, total, err := repo.ListBookRevisions(created.ID, 0, 10)
+	if err != nil || total != 1 || revisions[0].Action != entities.BookRevisionCreate {
+		t.Fatalf("expected one create revision, got %d (err %v)", total, err)
+	}
+
+	// 让修订无法写入
+	if err := db.Exec("ALTER TABLE book_revisions RENAME TO book_revisions_off").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := books.CreateBook(context.Background(), 1, &dto.CreateBookRequest{
+		Title: "Emma", Author: "Jane Austen", ISBN: "9780141439587",
+	}); err == nil {
+		t.Fatal("create succeeded without a revision")
+	}
+	if _, err := repo.GetBookByISBN("9780141439587"); err == nil {
+		t.Fatal("book was committed without its revision")
+	}
+
+	if _, err := books.UpdateBook(1, int(created.ID), &dto.UpdateBookRequest{
+		Title: "Dune Messiah", Author: "Frank Herbert", ISBN: "9780441172719",
+	}); err == nil {
+		t.Fatal("update succeeded without a revision")
+	}
+	if book, err := repo.GetBook(int(created.ID)); err != nil || book.Title != "Dune" {
+		t.Fatalf("update was committed without its revision: %+v (err %v)", book, err)
+	}
+
+	if err := books.DeleteBook(1, int(created.ID)); err == nil {
+		t.Fatal("delete succeeded without a revision")
+	}
+	if _, err := repo.GetBook(int(created.ID)); err != nil {
+		t.Fatalf("delete was committed without its revision: %v", err)
+	}
+}
+
+// TestApproveEditProposalIsAtomicWithRevision 修订写入失败时，修改建议的字段和通过状态都不应提交
+func TestApproveEditProposalIsAtomicWithRevision(t *testing.T) {
+	repo, db := newTestRepository(t)
+	books := services.NewBookService(repo, nil, eventbus.New())
+	proposals := services.NewBookEditProposalService(repo, books)
+
+	created, err := books.CreateBook(context.Background(), 1, &dto.CreateBookRequest{
+		Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher := "Ace"
+	proposal, err := proposals.Propose(2, int(created.ID), &dto.ProposeBookEditRequest{Publisher: &publisher})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 修订表仍可读取，只有写入失败，失败发生在建议的字段写入之后
+	if err := db.Exec("CREATE TRIGGER block_revisions BEFORE INSERT ON book_revisions BEGIN SELECT RAISE(ABORT, 'revisions disabled'); END").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proposals.Approve(1, int(proposal.ID), &dto.ReviewEditProposalRequest{}); err == nil {
+		t.Fatal("approval succeeded without a revision")
+	}
+	if book, err := repo.GetBook(int(created.ID)); err != nil || book.Publisher != "" {
+		t.Fatalf("proposed change was committed without its revision: %+v (err %v)", book, err)
+	}
+	if stored, err := repo.GetBookEditProposal(int(proposal.ID)); err != nil || stored.Status != entities.EditProposalStatusPending {
+		t.Fatalf("proposal was resolved without a revision: %+v (err %v)", stored, err)
+	}
+
+	if err := db.Exec("DROP TRIGGER block_revisions").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proposals.Approve(1, int(proposal.ID), &dto.ReviewEditProposalRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	assertRevisions(t, repo, created.ID, entities.BookRevisionUpdate, entities.BookRevisionCreate)
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/pkg/bookio"
+)
+
+// TestCatalogImportRecordsRevisions 导入经由 BookService 写入：新建和更新都留下修订，未变化的记录不产生修订
+func TestCatalogImportRecordsRevisions(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	books := services.NewBookService(repo, nil, eventbus.New())
+	catalog := services.NewCatalogService(repo, books)
+
+	run := func(csv string) {
+		t.Helper()
+		report, err := catalog.Import(context.Background(), strings.NewReader(csv), services.ImportOptions{Format: bookio.FormatCSV, ActorID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Failed > 0 {
+			t.Fatalf("import failed: %+v", report.Errors)
+		}
+	}
+
+	run("isbn,title,author\n9780441172719,Dune,Frank Herbert\n")
+	book, err := repo.GetBookByISBN("9780441172719")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRevisions(t, repo, book.ID, entities.BookRevisionCreate)
+
+	run("isbn,title,author\n9780441172719,Dune,Frank Herbert\n")
+	assertRevisions(t, repo, book.ID, entities.BookRevisionCreate)
+
+	run("isbn,title,author,publisher\n9780441172719,Dune,Frank Herbert,Ace\n")
+	assertRevisions(t, repo, book.ID, entities.BookRevisionUpdate, entities.BookRevisionCreate)
+}
+
+// TestCatalogRoundTrip 导出再导入到空库后，各格式都保留副标题、版次、页数和简介
+func TestCatalogRoundTrip(t *testing.T) {
+	source, _ := newTestRepository(t)
+	books := services.NewBookService(source, nil, eventbus.New())
+	original, err := books.CreateBook(context.Background(), 1, &dto.CreateBookRequest{
+		ISBN:        "9780441172719",
+		Title:       "Dune",
+		Subtitle:    "Deluxe Edition",
+		Author:      "Frank Herbert",
+		Publisher:   "Ace",
+		PublishDate: "1990-09-01",
+		Edition:     "40th anniversary edition",
+		PageCount:   535,
+		Description: "Set on the desert planet Arrakis.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []bookio.Format{bookio.FormatCSV, bookio.FormatJSONL, bookio.FormatMARC21, bookio.FormatMARCXML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := services.NewCatalogService(source, books).Export(context.Background(), &buf, format, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			target, _ := newTestRepository(t)
+			catalog := services.NewCatalogService(target, services.NewBookService(target, nil, eventbus.New()))
+			report, err := catalog.Import(context.Background(), &buf, services.ImportOptions{Format: format})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != 1 || report.Failed > 0 {
+				t.Fatalf("import report: %+v", report)
+			}
+
+			book, err := target.GetBookByISBN(original.ISBN)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [][2]interface{}{
+				{book.Title, original.Title},
+				{book.Subtitle, original.Subtitle},
+				{book.Author, original.Author},
+				{book.Publisher, original.Publisher},
+				{book.PublishDate, original.PublishDate},
+				{book.Edition, original.Edition},
+				{book.PageCount, original.PageCount},
+				{book.Description, original.Description},
+			}
+			for _, pair := range got {
+				if pair[0] != pair[1] {
+					t.Errorf("got %v, want %v", pair[0], pair[1])
+				}
+			}
+		})
+	}
+}
+
+// TestCatalogImportMARCPageCount 从 300$a 的篇幅说明中取页数，没有数字时报告记录错误
+func TestCatalogImportMARCPageCount(t *testing.T) {
+	tests := []struct {
+		extent string
+		want   int
+		failed bool
+	}{
+		{"412 p.", 412, false},
+		{"xii, 412 pages ;", 412, false},
+		{"1 online resource", 1, false},
+		{"unpaged", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.extent, func(t *testing.T) {
+			repo, _ := newTestRepository(t)
+			catalog := services.NewCatalogService(repo, services.NewBookService(repo, nil, eventbus.New()))
+			xml := `<collection xmlns="http://www.loc.gov/MARC21/slim"><record>` +
+				`<datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780441172719</subfield></datafield>` +
+				`<datafield tag="100" ind1="1" ind2=" "><subfield code="a">Herbert, Frank.</subfield></datafield>` +
+				`<datafield tag="245" ind1="1" ind2="0"><subfield code="a">Dune /</subfield></datafield>` +
+				`<datafield tag="300" ind1=" " ind2=" "><subfield code="a">` + tt.extent + `</subfield></datafield>` +
+				`</record></collection>`
+			report, err := catalog.Import(context.Background(), strings.NewReader(xml), services.ImportOptions{Format: bookio.FormatMARCXML})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.failed {
+				if report.Failed != 1 {
+					t.Fatalf("expected the record to fail: %+v", report)
+				}
+				return
+			}
+			book, err := repo.GetBookByISBN("9780441172719")
+			if err != nil {
+				t.Fatalf("%v (report %+v)", err, report)
+			}
+			if book.PageCount != tt.want {
+				t.Fatalf("page count: got %d, want %d", book.PageCount, tt.want)
+			}
+		})
+	}
+}
+
+// assertRevisions 校验图书的修订动作，按修订号从新到旧
+func assertRevisions(t *testing.T, repo repository.Repository, bookID uint, actions ...string) {
+	t.Helper()
+	revisions, _, err := repo.ListBookRevisions(bookID, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, revision := range revisions {
+		got = append(got, revision.Action)
+	}
+	if strings.Join(got, ",") != strings.Join(actions, ",") {
+		t.Fatalf("revisions: got %v, want %v", got, actions)
+	}
+}
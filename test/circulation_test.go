@@ -0,0 +1,260 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	apperrors "github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"gorm.io/gorm"
+)
+
+// newCirculation 借期 14 天、最多续借 2 次的流通服务，不要求会员资格
+func newCirculation(repo repository.Repository) *services.CirculationService {
+	memberships := services.NewMembershipService(repo, config.MembershipConfig{})
+	return services.NewCirculationService(repo, memberships, config.CirculationConfig{LoanDays: 14, MaxRenewals: 2}, eventbus.New())
+}
+
+// TestCheckoutAndReturn 借出后副本变为借出状态并按借期计算应还日期；归还后副本恢复可借，借阅不能重复归还
+func TestCheckoutAndReturn(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	circulation := newCirculation(repo)
+	copies := addCopies(t, repo, circulation, 1)
+
+	before := time.Now()
+	loan, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due := loan.DueAt.Sub(loan.CheckedOutAt); due != 14*24*time.Hour || loan.CheckedOutAt.Before(before) || loan.Overdue {
+		t.Fatalf("loan checked out at %v, due %v, overdue %v", loan.CheckedOutAt, loan.DueAt, loan.Overdue)
+	}
+	assertCopyStatus(t, repo, copies[0], entities.CopyStatusOnLoan)
+
+	// 已借出的副本不能再借，读者也不能为他人借书或归还他人的借阅
+	if _, err := circulation.Checkout(1, entities.RoleAdmin, &dto.CheckoutRequest{CopyID: copies[0]}); !errors.Is(err, entities.ErrCopyNotAvailable) {
+		t.Fatalf("expected ErrCopyNotAvailable, got %v", err)
+	}
+	if _, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[0], UserID: 1}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := circulation.Return(3, entities.RoleUser, int(loan.ID)); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+
+	returned, err := circulation.Return(2, entities.RoleUser, int(loan.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if returned.ReturnedAt == nil || returned.Overdue {
+		t.Fatalf("returned at %v, overdue %v", returned.ReturnedAt, returned.Overdue)
+	}
+	assertCopyStatus(t, repo, copies[0], entities.CopyStatusAvailable)
+	if _, err := circulation.Return(1, entities.RoleAdmin, int(loan.ID)); !errors.Is(err, entities.ErrLoanNotActive) {
+		t.Fatalf("expected ErrLoanNotActive, got %v", err)
+	}
+
+	// 馆员可以代读者办理
+	again, err := circulation.Checkout(1, entities.RoleLibrarian, &dto.CheckoutRequest{Barcode: fmt.Sprintf("C%d-000", loan.BookID), UserID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.UserID != 2 {
+		t.Fatalf("loan made for user %d, want 2", again.UserID)
+	}
+}
+
+// TestOneActiveLoanPerCopy 同一副本同时只有一笔未归还的借阅：并发借出只有一笔成功，
+// 副本状态与借阅不一致时借出在事务内被拒绝
+func TestOneActiveLoanPerCopy(t *testing.T) {
+	repo, db := newTestRepository(t)
+	circulation := newCirculation(repo)
+	copies := addCopies(t, repo, circulation, 1)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 8)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := circulation.Checkout(1, entities.RoleAdmin, &dto.CheckoutRequest{CopyID: copies[0], UserID: 2})
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d concurrent checkouts succeeded, want 1", succeeded)
+	}
+	assertActiveLoans(t, db, copies[0], 1)
+
+	// 副本被误标为可借时，仍由借阅记录判断已借出
+	if err := db.Model(&entities.BookCopy{}).Where("id = ?", copies[0]).Update("status", entities.CopyStatusAvailable).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := circulation.Checkout(1, entities.RoleAdmin, &dto.CheckoutRequest{CopyID: copies[0]}); !errors.Is(err, entities.ErrCopyAlreadyOnLoan) {
+		t.Fatalf("expected ErrCopyAlreadyOnLoan, got %v", err)
+	}
+	assertActiveLoans(t, db, copies[0], 1)
+}
+
+// TestRenewLoan 每次续借从原应还日期顺延一个借期，达到次数上限后拒绝；
+// 基于过期数据的续借不会覆盖已保存的续借
+func TestRenewLoan(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	circulation := newCirculation(repo)
+	copies := addCopies(t, repo, circulation, 1)
+
+	loan, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := repo.GetLoan(int(loan.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dueAt := loan.DueAt
+	for i := 1; i <= 2; i++ {
+		renewed, err := circulation.Renew(2, entities.RoleUser, int(loan.ID))
+		if err != nil {
+			t.Fatalf("renewal %d: %v", i, err)
+		}
+		dueAt = dueAt.Add(14 * 24 * time.Hour)
+		if renewed.RenewCount != i || !renewed.DueAt.Equal(dueAt) {
+			t.Fatalf("renewal %d: count %d, due %v, want %v", i, renewed.RenewCount, renewed.DueAt, dueAt)
+		}
+	}
+	if _, err := circulation.Renew(2, entities.RoleUser, int(loan.ID)); !errors.Is(err, entities.ErrRenewLimitReached) {
+		t.Fatalf("expected ErrRenewLimitReached, got %v", err)
+	}
+	if _, err := circulation.Renew(1, entities.RoleAdmin, int(loan.ID)); !errors.Is(err, entities.ErrRenewLimitReached) {
+		t.Fatalf("staff renewal: expected ErrRenewLimitReached, got %v", err)
+	}
+
+	if err := stale.Renew(time.Now(), 14*24*time.Hour, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RenewLoan(stale); !errors.Is(err, entities.ErrLoanNotActive) {
+		t.Fatalf("stale renewal: expected ErrLoanNotActive, got %v", err)
+	}
+	saved, err := repo.GetLoan(int(loan.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.RenewCount != 2 || !saved.DueAt.Equal(dueAt) {
+		t.Fatalf("saved loan: count %d, due %v, want 2, %v", saved.RenewCount, saved.DueAt, dueAt)
+	}
+
+	if _, err := circulation.Return(2, entities.RoleUser, int(loan.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := circulation.Renew(2, entities.RoleUser, int(loan.ID)); !errors.Is(err, entities.ErrLoanNotActive) {
+		t.Fatalf("expected ErrLoanNotActive, got %v", err)
+	}
+}
+
+// TestOverdueLoan 过了应还日期的借阅标记为逾期且不能续借，仍可归还
+func TestOverdueLoan(t *testing.T) {
+	repo, db := newTestRepository(t)
+	circulation := newCirculation(repo)
+	copies := addCopies(t, repo, circulation, 2)
+
+	overdue, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[1]}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&entities.Loan{}).Where("id = ?", overdue.ID).Update("due_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loans, err := circulation.ListUserLoans(2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loans) != 2 {
+		t.Fatalf("got %d active loans, want 2", len(loans))
+	}
+	for _, loan := range loans {
+		if want := loan.ID == overdue.ID; loan.Overdue != want {
+			t.Errorf("loan %d: overdue %v, want %v", loan.ID, loan.Overdue, want)
+		}
+	}
+
+	if _, err := circulation.Renew(2, entities.RoleUser, int(overdue.ID)); !errors.Is(err, entities.ErrLoanOverdue) {
+		t.Fatalf("expected ErrLoanOverdue, got %v", err)
+	}
+	returned, err := circulation.Return(2, entities.RoleUser, int(overdue.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已归还的借阅不再算逾期
+	if returned.Overdue {
+		t.Fatal("returned loan is still reported as overdue")
+	}
+	loans, err = circulation.ListUserLoans(2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loans) != 1 || loans[0].Overdue {
+		t.Fatalf("active loans after return: %+v", loans)
+	}
+}
+
+// addCopies 新建一本图书并在默认分馆为其添加 n 个副本，返回副本 ID
+func addCopies(t *testing.T, repo repository.Repository, circulation *services.CirculationService, n int) []uint {
+	t.Helper()
+	book := &entities.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "9780441172719"}
+	if err := repo.CreateBook(book); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint, n)
+	for i := range ids {
+		bookCopy, err := circulation.AddCopy(int(book.ID), &dto.CreateBookCopyRequest{Barcode: fmt.Sprintf("C%d-%03d", book.ID, i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = bookCopy.ID
+	}
+	return ids
+}
+
+func assertCopyStatus(t *testing.T, repo repository.Repository, copyID uint, want string) {
+	t.Helper()
+	bookCopy, err := repo.GetBookCopy(int(copyID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bookCopy.Status != want {
+		t.Fatalf("copy %d is %s, want %s", copyID, bookCopy.Status, want)
+	}
+}
+
+func assertActiveLoans(t *testing.T, db *gorm.DB, copyID uint, want int64) {
+	t.Helper()
+	var active int64
+	if err := db.Model(&entities.Loan{}).Where("copy_id = ? AND returned_at IS NULL", copyID).Count(&active).Error; err != nil {
+		t.Fatal(err)
+	}
+	if active != want {
+		t.Fatalf("copy %d has %d active loans, want %d", copyID, active, want)
+	}
+}
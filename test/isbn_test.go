@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+)
+
+// TestParseISBN ISBN-10 转换为 ISBN-13，校验位错误或不是 978、979 开头的 EAN-13 都不是 ISBN
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{"9780306406157", "9780306406157", true},
+		{"978-0-441-17271-9", "9780441172719", true},
+		{"9791032000182", "9791032000182", true},
+		{"0-306-40615-2", "9780306406157", true},
+		{"080442957x", "9780804429573", true},
+		{"9780306406158", "", false},
+		{"0306406153", "", false},
+		{"4006381333931", "", false},
+		{"5901234123457", "", false},
+		{"97803064061", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			isbn, err := entities.ParseISBN(tt.input)
+			if !tt.valid {
+				if err != entities.ErrInvalidISBN {
+					t.Fatalf("expected ErrInvalidISBN, got %q, %v", isbn, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isbn.String() != tt.want {
+				t.Fatalf("got %s, want %s", isbn, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/barcode"
+	"github.com/azel-ko/final-ddd/internal/pkg/labelsheet"
+)
+
+// TestLabelSheetLayout 25 个标签按 3×8 排成两页：每个条码落在自己的格子内，
+// 无法用 WinAnsi 编码的书名整行略去，第二页光栅化后能识别出条码
+func TestLabelSheetLayout(t *testing.T) {
+	isbns := []string{"9780306406157", "9780441172719", "9791032000182"}
+	titles := []string{"Dune", "三体", "Café “Society”"}
+	var labels []labelsheet.Label
+	for i := 0; i < 25; i++ {
+		code, err := barcode.EncodeEAN13(isbns[i%len(isbns)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		labels = append(labels, labelsheet.Label{Code: code, Lines: []string{titles[i%len(titles)], code.Text}})
+	}
+
+	layout := labelsheet.Layout{Paper: labelsheet.PaperA4, Columns: 3, Rows: 8}
+	var buf bytes.Buffer
+	if err := labelsheet.Write(&buf, layout, labels); err != nil {
+		t.Fatal(err)
+	}
+	pages := pdfPages(t, buf.Bytes())
+	if len(pages) != 2 {
+		t.Fatalf("got %d pages, want 2", len(pages))
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 2")) || !bytes.Contains(buf.Bytes(), []byte("/MediaBox [0 0 595.28 841.89]")) {
+		t.Fatal("page tree or media box missing")
+	}
+
+	first := string(pages[0])
+	for _, text := range []string{"(Dune) Tj", "(Caf\\351 \\223Society\\224) Tj", "(9780441172719) Tj"} {
+		if !strings.Contains(first, text) {
+			t.Errorf("page 1 is missing %s", text)
+		}
+	}
+	if strings.Contains(first, "?") {
+		t.Error("unencodable text was printed as ?")
+	}
+	// 每个标签印两行，书名为中文的 8 个标签只印条码号
+	if got := strings.Count(first, " Tj "); got != 24*2-8 {
+		t.Errorf("page 1 has %d text lines, want %d", got, 24*2-8)
+	}
+
+	const margin = 36
+	cellWidth := (layout.Paper.Width - 2*margin) / 3
+	cellHeight := (layout.Paper.Height - 2*margin) / 8
+	for _, rect := range pdfRects(pages[0]) {
+		column := math.Floor((rect[0] - margin) / cellWidth)
+		row := math.Floor((layout.Paper.Height - margin - rect[1] - rect[3]) / cellHeight)
+		left, top := margin+column*cellWidth, layout.Paper.Height-margin-row*cellHeight
+		if column < 0 || column > 2 || row < 0 || row > 7 ||
+			rect[0]+rect[2] > left+cellWidth+0.01 || rect[1] < top-cellHeight-0.01 {
+			t.Fatalf("bar %v crosses its cell (column %v, row %v)", rect, column, row)
+		}
+	}
+
+	// 第二页只有第 25 个标签，光栅化后裁出它所在的左上角格子
+	const scale = 3
+	img := rasterize(pages[1], layout.Paper, scale)
+	cell := image.Rect(margin*scale, margin*scale, int((margin+cellWidth)*scale), int((margin+cellHeight)*scale))
+	got, err := barcode.ScanEAN13(img.SubImage(cell))
+	if err != nil || got != isbns[24%len(isbns)] {
+		t.Fatalf("scan page 2: got %q, err %v", got, err)
+	}
+}
+
+// TestLabelSheetInvalidLayout 行列数无效、纸张为空或格子小于内边距时拒绝排版
+func TestLabelSheetInvalidLayout(t *testing.T) {
+	for _, layout := range []labelsheet.Layout{
+		{Paper: labelsheet.PaperA4, Columns: 0, Rows: 8},
+		{Paper: labelsheet.PaperA4, Columns: 3, Rows: -1},
+		{Paper: labelsheet.PaperA4, Columns: 200, Rows: 8},
+		{Columns: 3, Rows: 8},
+	} {
+		if err := labelsheet.Write(io.Discard, layout, nil); err != labelsheet.ErrInvalidLayout {
+			t.Errorf("%+v: expected ErrInvalidLayout, got %v", layout, err)
+		}
+	}
+}
+
+var pdfStreamPattern = regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+
+// pdfPages 按顺序解压各页的内容流
+func pdfPages(t *testing.T, pdf []byte) [][]byte {
+	t.Helper()
+	var pages [][]byte
+	for _, match := range pdfStreamPattern.FindAllSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(string(pdf[match[2]:match[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(pdf[match[1] : match[1]+length]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, content)
+	}
+	return pages
+}
+
+// pdfRects 内容流中的全部矩形（x, y, 宽, 高）
+func pdfRects(content []byte) [][4]float64 {
+	var rects [][4]float64
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 || fields[4] != "re" {
+			continue
+		}
+		var rect [4]float64
+		for i := range rect {
+			rect[i], _ = strconv.ParseFloat(fields[i], 64)
+		}
+		rects = append(rects, rect)
+	}
+	return rects
+}
+
+// rasterize 以每点 scale 个像素把内容流中的矩形画成灰度图，PDF 的 y 轴朝上
+func rasterize(content []byte, paper labelsheet.Paper, scale float64) *image.Gray {
+	width, height := int(paper.Width*scale), int(paper.Height*scale)
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for _, rect := range pdfRects(content) {
+		x0, x1 := int(math.Round(rect[0]*scale)), int(math.Round((rect[0]+rect[2])*scale))
+		y0, y1 := int(math.Round((paper.Height-rect[1]-rect[3])*scale)), int(math.Round((paper.Height-rect[1])*scale))
+		for y := max(y0, 0); y < min(y1, height); y++ {
+			for x := max(x0, 0); x < min(x1, width); x++ {
+				img.Pix[y*img.Stride+x] = 0
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+)
+
+// TestExpireMembershipsSkipsRowsItCannotDowngrade 一整批都无法降级时定时任务仍按 ID 向后翻页并结束，
+// 不会反复取回同一批
+func TestExpireMembershipsSkipsRowsItCannotDowngrade(t *testing.T) {
+	repo, db := newTestRepository(t)
+	plan := &entities.MembershipPlan{Name: "Basic", DurationDays: 30, Active: true}
+	if err := repo.CreateMembershipPlan(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	const total = 201
+	lapsed := time.Now().Add(-time.Hour)
+	var users, memberships []string
+	for i := 0; i < total; i++ {
+		users = append(users, fmt.Sprintf("('Reader %d', 'reader%d@example.com', 'x', 'user')", i, i))
+		memberships = append(memberships, fmt.Sprintf("(%d, %d, '%[3]s', '%[3]s', '%[3]s', '%[3]s', '%[3]s')", i+3, plan.ID, lapsed.UTC().Format("2006-01-02 15:04:05")))
+	}
+	for _, stmt := range []string{
+		"INSERT INTO users (name, email, password, role) VALUES " + strings.Join(users, ", "),
+		"INSERT INTO memberships (user_id, plan_id, starts_at, ends_at, grace_ends_at, created_at, updated_at) VALUES " + strings.Join(memberships, ", "),
+		// 前 200 条的更新被忽略，模拟降级时条件不成立
+		"CREATE TRIGGER skip_downgrade BEFORE UPDATE ON memberships WHEN OLD.id <= 200 BEGIN SELECT RAISE(IGNORE); END",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service := services.NewMembershipService(repo, config.MembershipConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.ExpireMemberships(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var restricted int64
+	if err := db.Model(&entities.User{}).Where("role = ?", entities.RoleRestricted).Count(&restricted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if restricted != 1 {
+		t.Fatalf("got %d restricted users, want 1", restricted)
+	}
+}
+
+// TestDowngradeMembershipAfterRenewal 读取后续期过的会员资格不会被降级
+func TestDowngradeMembershipAfterRenewal(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	memberships := services.NewMembershipService(repo, config.MembershipConfig{})
+	plan := &entities.MembershipPlan{Name: "Basic", DurationDays: 30, Active: true}
+	if err := repo.CreateMembershipPlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	startsAt := time.Now().AddDate(0, 0, -31)
+	if _, err := memberships.Enroll(1, 2, &dto.EnrollMembershipRequest{PlanID: plan.ID, StartsAt: &startsAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	lapsed, err := repo.ListLapsedMemberships(now, 0, 10)
+	if err != nil || len(lapsed) != 1 {
+		t.Fatalf("lapsed memberships: %v, %v", lapsed, err)
+	}
+	if _, err := memberships.Renew(1, 2, &dto.RenewMembershipRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := repo.DowngradeMembership(&lapsed[0], now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("renewed membership was downgraded")
+	}
+	user, err := repo.GetUser(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != entities.RoleUser {
+		t.Fatalf("role %s, want %s", user.Role, entities.RoleUser)
+	}
+}
+
+// TestCheckoutLoanLimit 在借数量在借出的事务内比对计划上限，同一读者并发借出也不会超出
+func TestCheckoutLoanLimit(t *testing.T) {
+	repo, db := newTestRepository(t)
+	memberships := services.NewMembershipService(repo, config.MembershipConfig{})
+	circulation := services.NewCirculationService(repo, memberships, config.CirculationConfig{}, eventbus.New())
+	plan := &entities.MembershipPlan{Name: "Basic", DurationDays: 30, MaxLoans: 2, Active: true}
+	if err := repo.CreateMembershipPlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memberships.Enroll(1, 2, &dto.EnrollMembershipRequest{PlanID: plan.ID}); err != nil {
+		t.Fatal(err)
+	}
+	copies := addCopies(t, repo, circulation, 6)
+
+	if _, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copies[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, copyID := range copies[1:] {
+		wg.Add(1)
+		go func(copyID uint) {
+			defer wg.Done()
+			// 并发事务在 SQLite 上可能因数据库被锁失败，这里只关心在借数量
+			circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copyID})
+		}(copyID)
+	}
+	wg.Wait()
+
+	var active int64
+	if err := db.Model(&entities.Loan{}).Where("user_id = ? AND returned_at IS NULL", 2).Count(&active).Error; err != nil {
+		t.Fatal(err)
+	}
+	if active > int64(plan.MaxLoans) {
+		t.Fatalf("%d active loans, limit is %d", active, plan.MaxLoans)
+	}
+	for _, copyID := range copies[1:] {
+		if _, err := circulation.Checkout(2, entities.RoleUser, &dto.CheckoutRequest{CopyID: copyID}); err == nil {
+			active++
+		} else if !errors.Is(err, entities.ErrLoanLimitReached) && !errors.Is(err, entities.ErrCopyNotAvailable) {
+			t.Fatalf("checkout copy %d: %v", copyID, err)
+		}
+	}
+	if active != int64(plan.MaxLoans) {
+		t.Fatalf("%d active loans, want %d", active, plan.MaxLoans)
+	}
+
+	// 馆员不受会员计划的上限限制
+	for _, copyID := range copies[1:] {
+		circulation.Checkout(1, entities.RoleAdmin, &dto.CheckoutRequest{CopyID: copyID})
+	}
+	var staff int64
+	if err := db.Model(&entities.Loan{}).Where("user_id = ? AND returned_at IS NULL", 1).Count(&staff).Error; err != nil {
+		t.Fatal(err)
+	}
+	if staff != int64(len(copies))-active {
+		t.Fatalf("staff has %d loans, want %d", staff, int64(len(copies))-active)
+	}
+}
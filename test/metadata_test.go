@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	apperrors "github.com/azel-ko/final-ddd/internal/application/errors"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/metadata"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	inframetadata "github.com/azel-ko/final-ddd/internal/infrastructure/metadata"
+	"github.com/azel-ko/final-ddd/internal/interfaces/http/handlers"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+var goodOmens = metadata.BookMetadata{
+	ISBN:        "978-0-06-085398-3",
+	Title:       "Good Omens",
+	Authors:     []string{"Neil Gaiman", "Terry Pratchett"},
+	Publisher:   "William Morrow",
+	PublishDate: "2006",
+	CoverURL:    "https://covers.example.com/good-omens.jpg",
+}
+
+// TestAutoFillOnlyEmptyFields 自动补全只填写请求中留空的字段，已填写的保持不变
+func TestAutoFillOnlyEmptyFields(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	provider := &countingProvider{provider: inframetadata.NewFakeProvider(goodOmens)}
+	books := services.NewBookService(repo, provider, eventbus.New())
+	ctx := context.Background()
+
+	book, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{
+		ISBN: "9780060853983", Title: "Good Omens (Anniversary Edition)", Publisher: "Gollancz", AutoFill: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Title != "Good Omens (Anniversary Edition)" || book.Publisher != "Gollancz" {
+		t.Fatalf("filled fields were overwritten: %q, %q", book.Title, book.Publisher)
+	}
+	if book.Author != "Neil Gaiman; Terry Pratchett" || book.PublishDate != "2006" || book.CoverURL != goodOmens.CoverURL {
+		t.Fatalf("empty fields were not filled: %q, %q, %q", book.Author, book.PublishDate, book.CoverURL)
+	}
+
+	// 没有开启自动补全时不查询外部书目
+	if _, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{ISBN: "9780441172719", Title: "Dune", Author: "Frank Herbert"}); err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 {
+		t.Fatalf("provider called %d times, want 1", provider.calls)
+	}
+
+	lookup, err := books.LookupMetadata(ctx, "0-06-085398-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookup.ExistingBookID == nil || *lookup.ExistingBookID != book.ID || lookup.Source != "fake" {
+		t.Fatalf("lookup: existing %v, source %q", lookup.ExistingBookID, lookup.Source)
+	}
+	if _, err := books.LookupMetadata(ctx, "9780306406157"); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// TestCachedProviderTTL 命中的记录按 TTL 缓存，没有记录的缓存时间较短，服务不可用不缓存
+func TestCachedProviderTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := &memoryCache{entries: make(map[string]memoryCacheEntry), now: func() time.Time { return now }}
+	provider := &countingProvider{provider: inframetadata.NewFakeProvider(goodOmens)}
+	cached := inframetadata.NewCachedProvider(provider, cache, 24*time.Hour)
+	ctx := context.Background()
+	isbn, _ := entities.ParseISBN("9780060853983")
+	missing, _ := entities.ParseISBN("9780441172719")
+
+	lookup := func(isbn entities.ISBN, wantCalls int) (*metadata.BookMetadata, error) {
+		t.Helper()
+		record, err := cached.LookupISBN(ctx, isbn)
+		if provider.calls != wantCalls {
+			t.Fatalf("%s at %s: provider called %d times, want %d", isbn, now.Format(time.RFC3339), provider.calls, wantCalls)
+		}
+		return record, err
+	}
+
+	if record, err := lookup(isbn, 1); err != nil || record.Title != "Good Omens" {
+		t.Fatalf("miss: %v, %v", record, err)
+	}
+	now = now.Add(23 * time.Hour)
+	if record, err := lookup(isbn, 1); err != nil || record.Title != "Good Omens" || len(record.Authors) != 2 {
+		t.Fatalf("hit: %v, %v", record, err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := lookup(isbn, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lookup(missing, 3); !errors.Is(err, metadata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := lookup(missing, 3); !errors.Is(err, metadata.ErrNotFound) {
+		t.Fatalf("expected cached ErrNotFound, got %v", err)
+	}
+	now = now.Add(time.Hour + time.Second)
+	if _, err := lookup(missing, 4); !errors.Is(err, metadata.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	provider.err = metadata.ErrUnavailable
+	other, _ := entities.ParseISBN("9780306406157")
+	for calls := 5; calls <= 6; calls++ {
+		if _, err := lookup(other, calls); !errors.Is(err, metadata.ErrUnavailable) {
+			t.Fatalf("expected ErrUnavailable, got %v", err)
+		}
+	}
+}
+
+// TestLookupMetadataUnavailable 外部书目不可用时预览返回 503；新建图书时书名和作者已填写则不受影响
+func TestLookupMetadataUnavailable(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	books := services.NewBookService(repo, inframetadata.NewProvider(config.MetadataConfig{}, nil), eventbus.New())
+	ctx := context.Background()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/books/lookup", handlers.NewBookHandler(books, nil).Lookup)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/books/lookup?isbn=9780060853983", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want 503: %s", recorder.Code, recorder.Body.String())
+	}
+
+	if _, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{ISBN: "9780060853983", AutoFill: true}); !errors.Is(err, metadata.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	book, err := books.CreateBook(ctx, 1, &dto.CreateBookRequest{ISBN: "9780060853983", Title: "Good Omens", Author: "Neil Gaiman", AutoFill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Publisher != "" {
+		t.Fatalf("publisher %q filled without metadata", book.Publisher)
+	}
+}
+
+// countingProvider 记录查询次数，err 不为空时直接返回该错误
+type countingProvider struct {
+	provider metadata.MetadataProvider
+	calls    int
+	err      error
+}
+
+func (p *countingProvider) LookupISBN(ctx context.Context, isbn entities.ISBN) (*metadata.BookMetadata, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.provider.LookupISBN(ctx, isbn)
+}
+
+type memoryCacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// memoryCache 以 JSON 保存条目的内存缓存，与 Redis 一样按写入时的 TTL 过期
+type memoryCache struct {
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(entry.data, dest)
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.entries[key] = memoryCacheEntry{data: data, expiresAt: c.now().Add(expiration)}
+	return nil
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/infrastructure/migration"
+)
+
+// TestAuthorTablesMigrationDeduplicatesNames 升级前作者文本中重复的姓名只关联一次，迁移不会因主键冲突中止
+func TestAuthorTablesMigrationDeduplicatesNames(t *testing.T) {
+	_, db := newTestRepositoryWithData(t,
+		"INSERT INTO books (title, author, isbn) VALUES ('A', 'Smith; Smith', '9780306406157'), ('B', 'A & A', '9780441172719'), ('C', 'Jane Roe; John Doe; Jane Roe', '9791032000182')",
+	)
+
+	want := map[uint][]string{1: {"Smith"}, 2: {"A"}, 3: {"Jane Roe", "John Doe"}}
+	for bookID, names := range want {
+		var links []struct {
+			Name     string
+			Position int
+		}
+		if err := db.Table("book_authors").Select("authors.name, book_authors.position").
+			Joins("JOIN authors ON authors.id = book_authors.author_id").
+			Where("book_authors.book_id = ?", bookID).Order("book_authors.position").Scan(&links).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(links) != len(names) {
+			t.Fatalf("book %d: got %+v, want %v", bookID, links, names)
+		}
+		for i, link := range links {
+			if link.Name != names[i] || link.Position != i {
+				t.Fatalf("book %d: got %+v, want %v", bookID, links, names)
+			}
+		}
+	}
+
+	var authors int64
+	if err := db.Model(&migration.Author{}).Count(&authors).Error; err != nil {
+		t.Fatal(err)
+	}
+	if authors != 4 {
+		t.Fatalf("got %d authors, want 4", authors)
+	}
+}
@@ -0,0 +1,323 @@
+package test
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/application/dto"
+	"github.com/azel-ko/final-ddd/internal/application/services"
+	"github.com/azel-ko/final-ddd/internal/domain/entities"
+	"github.com/azel-ko/final-ddd/internal/domain/payment"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/eventbus"
+	payinfra "github.com/azel-ko/final-ddd/internal/infrastructure/payment"
+	"github.com/azel-ko/final-ddd/internal/pkg/config"
+	"gorm.io/gorm"
+)
+
+// TestVerifySignature 签名头为 "t=<unix>,v1=<hex>"，时间戳与当前时间相差超过 5 分钟即视为重放
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"payment.authorized","intent":"pi_1"}`)
+	now := time.Unix(1_700_000_000, 0)
+	signed := payinfra.SignPayload("whsec", payload, now)
+	_, v1, _ := strings.Cut(signed, ",")
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		now     time.Time
+		valid   bool
+	}{
+		{"valid", "whsec", payload, signed, now, true},
+		{"extra fields and spaces", "whsec", payload, "v0=abc, " + strings.Replace(signed, ",", " , ", 1), now, true},
+		{"within tolerance after", "whsec", payload, signed, now.Add(5 * time.Minute), true},
+		{"within tolerance before", "whsec", payload, signed, now.Add(-5 * time.Minute), true},
+		{"replayed after tolerance", "whsec", payload, signed, now.Add(5*time.Minute + time.Second), false},
+		{"timestamp from the future", "whsec", payload, signed, now.Add(-5*time.Minute - time.Second), false},
+		{"timestamp swapped", "whsec", payload, "t=1700000100," + v1, now, false},
+		{"wrong secret", "other", payload, signed, now, false},
+		{"tampered payload", "whsec", []byte(`{"id":"evt_1","type":"payment.authorized","intent":"pi_2"}`), signed, now, false},
+		{"missing timestamp", "whsec", payload, v1, now, false},
+		{"missing signature", "whsec", payload, strings.Split(signed, ",")[0], now, false},
+		{"signature not hex", "whsec", payload, "t=1700000000,v1=zz", now, false},
+		{"empty header", "whsec", payload, "", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := payinfra.VerifySignature(tt.secret, tt.payload, tt.header, tt.now)
+			if tt.valid && err != nil {
+				t.Fatalf("expected a valid signature, got %v", err)
+			}
+			if !tt.valid && !stderrors.Is(err, payment.ErrInvalidSignature) {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+// TestFakeProvider 模拟渠道的授权、失败 webhook，以及按幂等键缓存结果的扣款和退款
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := payinfra.NewFakeProvider("whsec")
+	intent, err := provider.CreateIntent(ctx, payment.IntentRequest{Amount: 1000, Currency: "CNY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("webhooks", func(t *testing.T) {
+		other, err := provider.CreateIntent(ctx, payment.IntentRequest{Amount: 500, Currency: "CNY"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tests := []struct {
+			name     string
+			simulate func(ref string) ([]byte, string, error)
+			ref      string
+			want     string
+			err      error
+		}{
+			{"fail", provider.Fail, other.Ref, payment.EventFailed, nil},
+			{"authorize", provider.Authorize, intent.Ref, payment.EventAuthorized, nil},
+			{"authorize unknown intent", provider.Authorize, "fake_pi_missing", "", payment.ErrUnknownIntent},
+			{"fail unknown intent", provider.Fail, "fake_pi_missing", "", payment.ErrUnknownIntent},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				body, signature, err := tt.simulate(tt.ref)
+				if tt.err != nil {
+					if !stderrors.Is(err, tt.err) {
+						t.Fatalf("expected %v, got %v", tt.err, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				event, err := provider.ParseWebhook(body, signature)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if event.Type != tt.want || event.Ref != tt.ref || event.ID == "" {
+					t.Fatalf("unexpected event %+v", event)
+				}
+				if _, err := payinfra.NewFakeProvider("other").ParseWebhook(body, signature); !stderrors.Is(err, payment.ErrInvalidSignature) {
+					t.Fatalf("webhook verified with the wrong secret: %v", err)
+				}
+			})
+		}
+	})
+
+	// 按顺序执行：同一幂等键重复调用返回第一次的结果，即使渠道状态已经变化
+	steps := []struct {
+		name   string
+		call   func() (string, error)
+		err    error
+		sameAs string // 期望返回的退款编号与该步骤相同
+	}{
+		{"capture over the authorized amount", func() (string, error) { return "", provider.Capture(ctx, intent.Ref, 2000, "capture-big") }, payment.ErrProviderDeclined, ""},
+		{"capture", func() (string, error) { return "", provider.Capture(ctx, intent.Ref, 1000, "capture-1") }, nil, ""},
+		{"capture retried with the same key", func() (string, error) { return "", provider.Capture(ctx, intent.Ref, 1000, "capture-1") }, nil, ""},
+		{"capture with a new key", func() (string, error) { return "", provider.Capture(ctx, intent.Ref, 1000, "capture-2") }, payment.ErrProviderDeclined, ""},
+		{"declined key stays declined", func() (string, error) { return "", provider.Capture(ctx, intent.Ref, 2000, "capture-big") }, payment.ErrProviderDeclined, ""},
+		{"partial refund", func() (string, error) { return provider.Refund(ctx, intent.Ref, 400, "refund-a") }, nil, ""},
+		{"partial refund retried", func() (string, error) { return provider.Refund(ctx, intent.Ref, 400, "refund-a") }, nil, "partial refund"},
+		{"over-refund", func() (string, error) { return provider.Refund(ctx, intent.Ref, 601, "refund-b") }, payment.ErrProviderDeclined, ""},
+		{"refund the rest", func() (string, error) { return provider.Refund(ctx, intent.Ref, 600, "refund-c") }, nil, ""},
+		{"nothing left", func() (string, error) { return provider.Refund(ctx, intent.Ref, 1, "refund-d") }, payment.ErrProviderDeclined, ""},
+		{"unknown intent", func() (string, error) { return provider.Refund(ctx, "fake_pi_missing", 1, "refund-e") }, payment.ErrUnknownIntent, ""},
+	}
+	refs := make(map[string]string)
+	for _, step := range steps {
+		ref, err := step.call()
+		if !stderrors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.name, step.err, err)
+		}
+		refs[step.name] = ref
+		if step.sameAs != "" && ref != refs[step.sameAs] {
+			t.Fatalf("%s: got refund %q, want %q", step.name, ref, refs[step.sameAs])
+		}
+	}
+}
+
+// TestPaymentRefunds 结清欠款的支付经 webhook 授权后自动扣款，重复的 webhook 和扣款只入账一次；
+// 部分退款、重复的幂等键、超额退款依次执行，分录始终借贷平衡，读者余额不受退款影响
+func TestPaymentRefunds(t *testing.T) {
+	repo, db := newTestRepository(t)
+	provider := payinfra.NewFakeProvider("whsec")
+	payments := services.NewPaymentService(repo, provider, eventbus.New(), config.PaymentConfig{})
+	ctx := context.Background()
+
+	p := capturedPayment(t, payments, provider, 1000)
+	if _, err := payments.Capture(ctx, int(p.ID)); err != nil {
+		t.Fatal(err)
+	}
+	assertLedgerBalanced(t, db, 2)
+
+	steps := []struct {
+		name     string
+		key      string
+		amount   int64
+		err      error
+		refunded int64
+		status   string
+		refunds  int
+	}{
+		{"partial refund", "a", 300, nil, 300, entities.PaymentStatusCaptured, 1},
+		{"same key again", "a", 300, nil, 300, entities.PaymentStatusCaptured, 1},
+		{"same key, different amount", "a", 500, nil, 300, entities.PaymentStatusCaptured, 1},
+		{"over-refund", "b", 701, entities.ErrPaymentNotRefundable, 300, entities.PaymentStatusCaptured, 1},
+		{"refund the rest", "c", 0, nil, 1000, entities.PaymentStatusRefunded, 2},
+		{"nothing left", "d", 1, entities.ErrPaymentNotRefundable, 1000, entities.PaymentStatusRefunded, 2},
+	}
+	for _, step := range steps {
+		_, err := payments.Refund(ctx, 1, int(p.ID), step.key, &dto.RefundPaymentRequest{Amount: step.amount})
+		if !stderrors.Is(err, step.err) {
+			t.Fatalf("%s: expected %v, got %v", step.name, step.err, err)
+		}
+		current, err := payments.Get(1, entities.RoleAdmin, int(p.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.RefundedAmount != step.refunded || current.Status != step.status || len(current.Refunds) != step.refunds {
+			t.Fatalf("%s: refunded %d, status %s, %d refunds", step.name, current.RefundedAmount, current.Status, len(current.Refunds))
+		}
+		for _, refund := range current.Refunds {
+			if refund.Status != entities.RefundStatusSucceeded || refund.ProviderRef == "" {
+				t.Fatalf("%s: refund not completed: %+v", step.name, refund)
+			}
+		}
+	}
+	// 扣款、两次退款，共三张凭证
+	assertLedgerBalanced(t, db, 4)
+
+	balance, err := payments.Balance(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Balance != 0 {
+		t.Fatalf("refunds changed the balance to %d", balance.Balance)
+	}
+}
+
+// failingRefunds 渠道退款总是失败，其余操作与 fake 渠道相同
+type failingRefunds struct {
+	*payinfra.FakeProvider
+}
+
+func (failingRefunds) Refund(context.Context, string, int64, string) (string, error) {
+	return "", stderrors.New("provider unavailable")
+}
+
+// TestRefundReservation 退款先在库中预留：同一幂等键只能预留一次，渠道失败时撤销预留，之后可以用同一幂等键重试
+func TestRefundReservation(t *testing.T) {
+	repo, db := newTestRepository(t)
+	provider := payinfra.NewFakeProvider("whsec")
+	payments := services.NewPaymentService(repo, provider, eventbus.New(), config.PaymentConfig{})
+	ctx := context.Background()
+	p := capturedPayment(t, payments, provider, 1000)
+
+	stored, err := repo.GetPayment(int(p.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := &entities.PaymentRefund{PaymentID: p.ID, IdempotencyKey: "k", Amount: 400, ActorID: 1}
+	if reserved, err := repo.ReserveRefund(stored, first); err != nil || !reserved {
+		t.Fatalf("first reservation: %v, %v", reserved, err)
+	}
+	duplicate := &entities.PaymentRefund{PaymentID: p.ID, IdempotencyKey: "k", Amount: 400, ActorID: 1}
+	if reserved, err := repo.ReserveRefund(stored, duplicate); err != nil || reserved {
+		t.Fatalf("duplicate key reserved again: %v, %v", reserved, err)
+	}
+	over := &entities.PaymentRefund{PaymentID: p.ID, IdempotencyKey: "over", Amount: 601, ActorID: 1}
+	if _, err := repo.ReserveRefund(stored, over); !stderrors.Is(err, entities.ErrPaymentNotRefundable) {
+		t.Fatalf("expected ErrPaymentNotRefundable, got %v", err)
+	}
+	if stored, _ = repo.GetPayment(int(p.ID)); stored.RefundedAmount != 400 {
+		t.Fatalf("reserved %d, want 400", stored.RefundedAmount)
+	}
+	if err := repo.ReleaseRefund(first); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := services.NewPaymentService(repo, failingRefunds{provider}, eventbus.New(), config.PaymentConfig{})
+	if _, err := failing.Refund(ctx, 1, int(p.ID), "retry", &dto.RefundPaymentRequest{Amount: 250}); err == nil {
+		t.Fatal("refund succeeded although the provider failed")
+	}
+	current, err := payments.Get(1, entities.RoleAdmin, int(p.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.RefundedAmount != 0 || len(current.Refunds) != 0 {
+		t.Fatalf("failed refund kept its reservation: refunded %d, %d refunds", current.RefundedAmount, len(current.Refunds))
+	}
+
+	if current, err = payments.Refund(ctx, 1, int(p.ID), "retry", &dto.RefundPaymentRequest{Amount: 250}); err != nil {
+		t.Fatal(err)
+	}
+	if current.RefundedAmount != 250 || len(current.Refunds) != 1 || current.Refunds[0].Status != entities.RefundStatusSucceeded {
+		t.Fatalf("retry: refunded %d, refunds %+v", current.RefundedAmount, current.Refunds)
+	}
+	// 费用、扣款和一次退款
+	assertLedgerBalanced(t, db, 3)
+}
+
+// capturedPayment 向读者 Bob 登记一笔费用，发起结清欠款的支付，并投递两次授权 webhook 完成扣款
+func capturedPayment(t *testing.T, payments *services.PaymentService, provider *payinfra.FakeProvider, amount int64) *dto.PaymentResponse {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := payments.Charge(1, 2, &dto.ChargeRequest{Amount: amount, Description: "Lost book"}); err != nil {
+		t.Fatal(err)
+	}
+	intent, err := payments.CreateIntent(ctx, 2, &dto.CreatePaymentRequest{Purpose: entities.PaymentPurposeBalance})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intent.Amount != amount || intent.Status != entities.PaymentStatusPending {
+		t.Fatalf("unexpected intent %+v", intent)
+	}
+
+	body, signature, err := provider.Authorize(intent.ProviderRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := payments.HandleWebhook(ctx, body, signature); err != nil {
+			t.Fatalf("webhook delivery %d: %v", i+1, err)
+		}
+	}
+	if err := payments.HandleWebhook(ctx, body, "t=1,v1=00"); !stderrors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	p, err := payments.Get(2, entities.RoleUser, int(intent.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != entities.PaymentStatusCaptured {
+		t.Fatalf("payment not captured: %s", p.Status)
+	}
+	return p
+}
+
+// assertLedgerBalanced 每张凭证的分录合计为 0，并核对凭证数
+func assertLedgerBalanced(t *testing.T, db *gorm.DB, transactions int64) {
+	t.Helper()
+	var unbalanced int64
+	if err := db.Raw("SELECT COUNT(*) FROM (SELECT transaction_id FROM ledger_entries GROUP BY transaction_id HAVING SUM(amount) <> 0) AS t").Scan(&unbalanced).Error; err != nil {
+		t.Fatal(err)
+	}
+	if unbalanced != 0 {
+		t.Fatalf("%d ledger transactions do not balance", unbalanced)
+	}
+	var count int64
+	if err := db.Table("ledger_transactions").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != transactions {
+		t.Fatalf("got %d ledger transactions, want %d", count, transactions)
+	}
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/azel-ko/final-ddd/internal/domain/repository"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/migration"
+	"github.com/azel-ko/final-ddd/internal/infrastructure/persistence/sqlite"
+	migr "github.com/azel-ko/final-ddd/internal/pkg/database/migration"
+	"github.com/azel-ko/final-ddd/internal/pkg/logger"
+	gorm_sqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestRepository 在临时目录中创建 SQLite 数据库并执行全部迁移。
+// 用户表和图书表的初始迁移使用了 MySQL 专有的默认值，这里直接建表并标记为已执行，
+// 同时写入管理员 Alice（ID 1）和读者 Bob（ID 2）
+func newTestRepository(t *testing.T) (repository.Repository, *gorm.DB) {
+	return newTestRepositoryWithData(t)
+}
+
+// newTestRepositoryWithData 同 newTestRepository，seed 中的语句在建表之后、执行迁移之前运行，
+// 用于模拟升级前已有的数据
+func newTestRepositoryWithData(t *testing.T, seed ...string) (repository.Repository, *gorm.DB) {
+	t.Helper()
+	logger.Init("error")
+
+	db, err := gorm.Open(gorm_sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range append([]string{
+		"CREATE TABLE users (id integer primary key autoincrement, name text not null, email text not null unique, password text not null, role text not null default 'user', created_at datetime, updated_at datetime)",
+		"CREATE TABLE books (id integer primary key autoincrement, title text not null, author text not null, isbn text not null unique, created_at datetime, updated_at datetime)",
+		"INSERT INTO users (name, email, password, role) VALUES ('Alice', 'alice@example.com', 'x', 'admin'), ('Bob', 'bob@example.com', 'x', 'user')",
+	}, seed...) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.AutoMigrate(&migr.SchemaMigration{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"002_create_users_table", "002_create_books_table"} {
+		if err := db.Create(&migr.SchemaMigration{MigrationID: id}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrator := migr.NewMigrator(db)
+	migration.RegisterMigrations(migrator)
+	if err := migrator.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return sqlite.NewSQLiteRepository(db), db
+}
@@ -0,0 +1,38 @@
+package test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azel-ko/final-ddd/internal/pkg/signedurl"
+)
+
+// TestDeriveKey 派生密钥与主密钥不同且随标签变化，主密钥签发的链接不能通过派生密钥的校验
+func TestDeriveKey(t *testing.T) {
+	const secret = "jwt-secret"
+	derived := signedurl.DeriveKey(secret, "ebook download links")
+	if derived == secret || len(derived) != 32 {
+		t.Fatalf("derived key should be a distinct 32-byte key, got %d bytes", len(derived))
+	}
+	if signedurl.DeriveKey(secret, "ebook download links") != derived {
+		t.Fatal("derivation is not deterministic")
+	}
+	if signedurl.DeriveKey(secret, "other purpose") == derived {
+		t.Fatal("different labels produced the same key")
+	}
+
+	now := time.Now()
+	link := signedurl.NewSigner(secret).Sign("/api/ebooks/download/1", now.Add(time.Minute))
+	path, rawQuery, _ := strings.Cut(link, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = signedurl.NewSigner(derived).Verify(path, query.Get(signedurl.ExpiresParam), query.Get(signedurl.SignatureParam), now)
+	if !errors.Is(err, signedurl.ErrInvalidSignature) {
+		t.Fatalf("link signed with the master key verified under the derived key: %v", err)
+	}
+}